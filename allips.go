@@ -0,0 +1,162 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/netip"
+	"sync"
+
+	"github.com/pouriyajamshidi/tcping/v2/pkg/tcping"
+)
+
+// newPingers creates the pingers of every port of the target, or only
+// of opts.Port if there are no ports, so that they are probed in parallel.
+// All the ports are probed on the same address.
+func newPingers(opts tcping.Options, allIPs bool, ports []uint16) ([]*tcping.Pinger, error) {
+	if len(ports) == 0 {
+		return newAddrPingers(opts, allIPs)
+	}
+
+	var pingers []*tcping.Pinger
+	for _, port := range ports {
+		portOpts := opts
+		portOpts.Port = port
+
+		portPingers, err := newAddrPingers(portOpts, allIPs)
+		if err != nil {
+			return nil, err
+		}
+		pingers = append(pingers, portPingers...)
+
+		// pin the address the first port was resolved to
+		if !allIPs {
+			opts.Hosts = map[string][]netip.Addr{opts.Hostname: {portPingers[0].Statistics().IP}}
+		}
+	}
+
+	return pingers, nil
+}
+
+// newAddrPingers creates the pinger of the target or, with --all-ips,
+// one pinger per address of the target so that they are probed in parallel.
+func newAddrPingers(opts tcping.Options, allIPs bool) ([]*tcping.Pinger, error) {
+	if !allIPs {
+		pinger, err := tcping.New(opts)
+		if err != nil {
+			return nil, err
+		}
+		return []*tcping.Pinger{pinger}, nil
+	}
+
+	addrs, err := tcping.ResolveAll(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	var pingers []*tcping.Pinger
+	for _, addr := range addrs {
+		// pin the address, so the hostname is still printed
+		ipOpts := opts
+		ipOpts.Hosts = map[string][]netip.Addr{opts.Hostname: {addr}}
+
+		pinger, err := tcping.New(ipOpts)
+		if err != nil {
+			return nil, err
+		}
+		pingers = append(pingers, pinger)
+	}
+
+	return pingers, nil
+}
+
+// runPingers runs the pingers in parallel until all of them
+// return or ctx is done.
+func runPingers(ctx context.Context, pingers []*tcping.Pinger) {
+	var wg sync.WaitGroup
+	for _, pinger := range pingers {
+		wg.Add(1)
+		go func(pinger *tcping.Pinger) {
+			defer wg.Done()
+			pinger.RunContext(ctx)
+		}(pinger)
+	}
+	wg.Wait()
+}
+
+// printSummary prints the combined summary of the pingers,
+// after their own statistics, if there are several.
+func printSummary(printer tcping.Printer, pingers []*tcping.Pinger) {
+	if len(pingers) < 2 {
+		return
+	}
+
+	stats := make([]tcping.Statistics, len(pingers))
+	for i, pinger := range pingers {
+		stats[i] = pinger.Statistics()
+	}
+
+	for _, line := range formatSummary(stats) {
+		printer.PrintInfo("%s", line)
+	}
+}
+
+// formatSummary formats the combined summary of the statistics,
+// the probes of all of them and one line per target.
+func formatSummary(stats []tcping.Statistics) []string {
+	var (
+		successful, unsuccessful uint
+		rtt                      tcping.RttResult
+		rttSum                   float64
+		targets                  []string
+	)
+
+	for _, s := range stats {
+		successful += s.TotalSuccessfulProbes
+		unsuccessful += s.TotalUnsuccessfulProbes
+
+		if r := s.RttResults; r.HasResults {
+			if !rtt.HasResults || r.Min < rtt.Min {
+				rtt.Min = r.Min
+			}
+			rtt.Max = max(rtt.Max, r.Max)
+			rtt.HasResults = true
+			rttSum += float64(r.Average) * float64(s.TotalSuccessfulProbes)
+		}
+
+		target := s.Hostname
+		if !s.IsIP {
+			target += fmt.Sprintf(" (%s)", s.IP)
+		}
+		target += fmt.Sprintf(" port %d", s.Port)
+		if s.Service != "" {
+			target += fmt.Sprintf(" (%s)", s.Service)
+		}
+		line := fmt.Sprintf("%s: %d/%d received, %.2f%% packet loss", target,
+			s.TotalSuccessfulProbes, s.TotalSuccessfulProbes+s.TotalUnsuccessfulProbes,
+			packetLoss(s.TotalSuccessfulProbes, s.TotalUnsuccessfulProbes))
+		if s.RttResults.HasResults {
+			line += fmt.Sprintf(", rtt avg %.3f ms", s.RttResults.Average)
+		}
+		targets = append(targets, line)
+	}
+
+	lines := []string{
+		fmt.Sprintf("--- combined TCPing statistics of %d targets ---", len(stats)),
+		fmt.Sprintf("%d probes transmitted | %d received, %.2f%% packet loss",
+			successful+unsuccessful, successful, packetLoss(successful, unsuccessful)),
+	}
+	if rtt.HasResults {
+		lines = append(lines, fmt.Sprintf("rtt min/avg/max: %.3f/%.3f/%.3f ms",
+			rtt.Min, rttSum/float64(successful), rtt.Max))
+	}
+
+	return append(lines, targets...)
+}
+
+// packetLoss returns the percentage of the unsuccessful probes.
+func packetLoss(successful, unsuccessful uint) float64 {
+	if successful+unsuccessful == 0 {
+		return 0
+	}
+	return float64(unsuccessful) / float64(successful+unsuccessful) * 100
+}
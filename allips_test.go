@@ -0,0 +1,101 @@
+package main
+
+import (
+	"net/netip"
+	"testing"
+	"time"
+
+	"github.com/pouriyajamshidi/tcping/v2/pkg/tcping"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewPingers(t *testing.T) {
+	opts := tcping.Options{
+		Printer:               &discardPrinter{},
+		Hostname:              "backend.test",
+		Port:                  18080,
+		IntervalBetweenProbes: time.Second,
+		Hosts: map[string][]netip.Addr{
+			"backend.test": {netip.MustParseAddr("127.0.0.1"), netip.MustParseAddr("127.0.0.2")},
+		},
+	}
+
+	pingers, err := newPingers(opts, false, nil)
+	assert.NoError(t, err)
+	assert.Len(t, pingers, 1)
+
+	pingers, err = newPingers(opts, true, nil)
+	assert.NoError(t, err)
+	if assert.Len(t, pingers, 2) {
+		assert.Equal(t, netip.MustParseAddr("127.0.0.1"), pingers[0].Statistics().IP)
+		assert.Equal(t, netip.MustParseAddr("127.0.0.2"), pingers[1].Statistics().IP)
+	}
+}
+
+func TestNewPingersPorts(t *testing.T) {
+	opts := tcping.Options{
+		Printer:               &discardPrinter{},
+		Hostname:              "backend.test",
+		Port:                  18080,
+		IntervalBetweenProbes: time.Second,
+		Hosts: map[string][]netip.Addr{
+			"backend.test": {netip.MustParseAddr("127.0.0.1"), netip.MustParseAddr("127.0.0.2")},
+		},
+	}
+
+	// every port is probed on the same address
+	pingers, err := newPingers(opts, false, []uint16{80, 443, 8443})
+	assert.NoError(t, err)
+	if assert.Len(t, pingers, 3) {
+		for i, port := range []uint16{80, 443, 8443} {
+			s := pingers[i].Statistics()
+			assert.Equal(t, port, s.Port)
+			assert.Equal(t, pingers[0].Statistics().IP, s.IP)
+		}
+	}
+
+	pingers, err = newPingers(opts, true, []uint16{80, 443})
+	assert.NoError(t, err)
+	assert.Len(t, pingers, 4)
+}
+
+func TestParsePorts(t *testing.T) {
+	ports, err := parsePorts("80, 443,8443")
+	assert.NoError(t, err)
+	assert.Equal(t, []uint16{80, 443, 8443}, ports)
+
+	_, err = parsePorts("80,https")
+	assert.ErrorContains(t, err, `invalid port "https"`)
+
+	_, err = parsePorts("443,80,443")
+	assert.ErrorContains(t, err, "port 443 is given twice")
+}
+
+func TestFormatSummary(t *testing.T) {
+	ip := netip.MustParseAddr("192.0.2.1")
+	lines := formatSummary([]tcping.Statistics{
+		{
+			Hostname: "example.com", IP: ip, Port: 443, Service: "https",
+			TotalSuccessfulProbes: 3, TotalUnsuccessfulProbes: 1,
+			RttResults: tcping.RttResult{HasResults: true, Min: 10, Average: 20, Max: 30},
+		},
+		{
+			Hostname: "example.com", IP: ip, Port: 8443,
+			TotalSuccessfulProbes: 1, TotalUnsuccessfulProbes: 0,
+			RttResults: tcping.RttResult{HasResults: true, Min: 5, Average: 40, Max: 40},
+		},
+		{
+			Hostname: "example.com", IP: ip, Port: 8080,
+			TotalUnsuccessfulProbes: 4,
+		},
+	})
+
+	assert.Equal(t, []string{
+		"--- combined TCPing statistics of 3 targets ---",
+		"9 probes transmitted | 4 received, 55.56% packet loss",
+		"rtt min/avg/max: 5.000/25.000/40.000 ms",
+		"example.com (192.0.2.1) port 443 (https): 3/4 received, 25.00% packet loss, rtt avg 20.000 ms",
+		"example.com (192.0.2.1) port 8443: 1/1 received, 0.00% packet loss, rtt avg 40.000 ms",
+		"example.com (192.0.2.1) port 8080: 0/4 received, 100.00% packet loss",
+	}, lines)
+}
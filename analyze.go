@@ -0,0 +1,110 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"math"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/pouriyajamshidi/tcping/v2/pkg/tcping"
+)
+
+// analyzePercentiles are the percentiles of the RTTs printed by `tcping analyze`.
+var analyzePercentiles = []float64{50, 90, 95, 99, 99.9}
+
+// runAnalyze regenerates the statistics of the samples
+// written with --samples-file, without probing again.
+func runAnalyze(args []string) {
+	flags := flag.NewFlagSet("analyze", flag.ExitOnError)
+	availabilityWindow := flags.Duration("availability-window", 0, "break the statistics down into windows of this size, e.g. --availability-window 1h.")
+	rttThreshold := flags.Duration("rtt-threshold", 0, "count the successful probes slower than this as degraded, e.g. --rtt-threshold 150ms.")
+	outliers := flags.Float64("outliers", 0, "count the probes whose RTT is more than <k> standard deviations above the mean of the previous ones.")
+	outliersWindow := flags.Uint("outliers-window", 0, "compare the RTTs to the mean of the last <n> ones with --outliers instead of all the previous ones.")
+	warmup := flags.Uint("warmup", 0, "leave the RTTs of the first <n> probes out of the statistics.")
+	heatmap := flags.Duration("heatmap", 0, "print a heatmap of the RTTs per time bucket of this size, e.g. --heatmap 1m.")
+	outputJSON := flags.Bool("j", false, "output in JSON format.")
+	flags.Usage = func() {
+		colorRed("Usage: %s analyze [--availability-window <duration>] [--rtt-threshold <duration>] [--outliers <k>] [--outliers-window <n>] [--warmup <n>] [--heatmap <duration>] [-j] <samples file>\n", os.Args[0])
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+
+	args = flags.Args()
+	// the window needs 10 RTTs like the ones of the probes
+	if len(args) != 1 || *outliers < 0 || *heatmap < 0 || (*outliersWindow > 0 && *outliersWindow < 10) {
+		flags.Usage()
+		os.Exit(1)
+	}
+
+	printer := tcping.NewPlainPrinter()
+	if *outputJSON {
+		printer = tcping.NewJSONPrinter(false)
+	}
+
+	f, err := openSamples(args[0])
+	if err != nil {
+		printer.PrintError("Unable to open the samples file: %s", err)
+		os.Exit(1)
+	}
+	defer f.Close()
+
+	samples, err := tcping.ReadSamples(f)
+	if err != nil {
+		printer.PrintError("Unable to read %s: %s", args[0], err)
+		os.Exit(1)
+	}
+
+	s := tcping.AnalyzeSamples(samples, tcping.Options{
+		IntervalBetweenProbes: time.Second,
+		AvailabilityWindow:    *availabilityWindow,
+		RTTThreshold:          *rttThreshold,
+		OutlierStdDevs:        *outliers,
+		OutlierWindow:         *outliersWindow,
+		Warmup:                *warmup,
+	})
+	printer.PrintStatistics(s)
+
+	if line := formatPercentiles(samples); line != "" {
+		printer.PrintInfo("%s", line)
+	}
+
+	if *heatmap > 0 {
+		for _, line := range formatHeatmap(samples, *heatmap) {
+			printer.PrintInfo("%s", line)
+		}
+	}
+}
+
+// formatPercentiles lists the percentiles of the RTTs of the successful
+// samples, e.g. "rtt p50/p90/p95/p99/p99.9: 1.000/.../5.000 ms".
+func formatPercentiles(samples []tcping.Sample) string {
+	var rtts []float32
+	for _, s := range samples {
+		if s.Success {
+			rtts = append(rtts, s.RTT)
+		}
+	}
+	if len(rtts) == 0 {
+		return ""
+	}
+	slices.Sort(rtts)
+
+	names := make([]string, 0, len(analyzePercentiles))
+	values := make([]string, 0, len(analyzePercentiles))
+	for _, p := range analyzePercentiles {
+		names = append(names, fmt.Sprintf("p%g", p))
+		values = append(values, fmt.Sprintf("%.3f", percentile(rtts, p)))
+	}
+
+	return fmt.Sprintf("rtt %s: %s ms", strings.Join(names, "/"), strings.Join(values, "/"))
+}
+
+// percentile returns the nearest-rank percentile of the sorted RTTs.
+func percentile(sorted []float32, p float64) float32 {
+	rank := int(math.Ceil(p / 100 * float64(len(sorted))))
+
+	return sorted[max(rank, 1)-1]
+}
@@ -0,0 +1,40 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/pouriyajamshidi/tcping/v2/pkg/tcping"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFormatPercentiles(t *testing.T) {
+	var samples []tcping.Sample
+	for i := 1; i <= 100; i++ {
+		samples = append(samples, tcping.Sample{Success: true, RTT: float32(i)})
+	}
+	samples = append(samples, tcping.Sample{FailureReason: tcping.FailureTimeout})
+
+	assert.Equal(t, "rtt p50/p90/p95/p99/p99.9: 50.000/90.000/95.000/99.000/100.000 ms", formatPercentiles(samples))
+	assert.Equal(t, "", formatPercentiles(samples[100:]))
+}
+
+func TestFormatHeatmap(t *testing.T) {
+	start := time.Date(2023, 9, 10, 14, 0, 0, 0, time.Local)
+	samples := []tcping.Sample{
+		{Time: start.Add(time.Minute), Success: true, RTT: 30},
+		{Time: start, Success: true, RTT: 0.5},
+		{Time: start.Add(10 * time.Second), Success: true, RTT: 1},
+		{Time: start.Add(20 * time.Second), Success: true, RTT: 1.5},
+		{Time: start.Add(30 * time.Second), FailureReason: tcping.FailureTimeout},
+	}
+
+	lines := formatHeatmap(samples, time.Minute)
+	assert.Equal(t, []string{
+		"rtt heatmap per 1m0s in ms, shaded by the share of the probes:",
+		"                      <1   <2   <5  <10  <20  <50 <100 <200 <500 500+ fail",
+		"2023-09-10 14:00:00  ░░░  ▒▒▒                                          ░░░",
+		"2023-09-10 14:01:00                           ███",
+	}, lines)
+	assert.Nil(t, formatHeatmap(nil, time.Minute))
+}
@@ -0,0 +1,153 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/pouriyajamshidi/tcping/v2/pkg/tcping"
+)
+
+// apiHistorySize is the number of the most recent probes served on /history.
+const apiHistorySize = 100
+
+// apiServer serves the live statistics over HTTP
+// while the probing continues.
+type apiServer struct {
+	// statistics returns the current statistics.
+	statistics func() tcping.Statistics
+	addr       string
+	// history holds the most recent probes, the oldest first.
+	history   []apiProbe
+	historyMu sync.Mutex
+}
+
+// apiProbe is the JSON representation of a probe served on /history.
+type apiProbe struct {
+	Seq       uint      `json:"seq"`
+	Timestamp time.Time `json:"timestamp"`
+	Hostname  string    `json:"hostname"`
+	Addr      string    `json:"addr"`
+	Port      uint16    `json:"port"`
+	// Rtt in milliseconds, only set for successful probes.
+	Rtt     float32 `json:"rtt,omitempty"`
+	Streak  uint    `json:"streak"`
+	Success bool    `json:"success"`
+}
+
+// apiTarget is the JSON representation of a target served on /targets.
+type apiTarget struct {
+	Hostname string `json:"hostname"`
+	Addr     string `json:"addr"`
+	Port     uint16 `json:"port"`
+	// Up is the outcome of the last probe.
+	Up bool `json:"up"`
+}
+
+func newAPIServer(addr string) *apiServer {
+	return &apiServer{addr: addr}
+}
+
+// listen starts serving the statistics in the background.
+// It only returns an error if the address can't be listened on.
+func (s *apiServer) listen(statistics func() tcping.Statistics) error {
+	s.statistics = statistics
+
+	ln, err := net.Listen("tcp", s.addr)
+	if err != nil {
+		return err
+	}
+
+	srv := &http.Server{
+		Handler:           s.handler(),
+		ReadHeaderTimeout: 5 * time.Second,
+	}
+
+	go func() {
+		if err := srv.Serve(ln); err != nil {
+			fmt.Fprintf(os.Stderr, "The API server has stopped: %s\n", err)
+		}
+	}()
+
+	return nil
+}
+
+func (s *apiServer) handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/stats", s.handleStats)
+	mux.HandleFunc("/targets", s.handleTargets)
+	mux.HandleFunc("/history", s.handleHistory)
+
+	return mux
+}
+
+// recordProbe adds the probe to the history.
+// It is meant to be used as the OnProbe hook.
+func (s *apiServer) recordProbe(r tcping.Result) {
+	s.historyMu.Lock()
+	defer s.historyMu.Unlock()
+
+	if len(s.history) == apiHistorySize {
+		s.history = append(s.history[:0], s.history[1:]...)
+	}
+
+	s.history = append(s.history, apiProbe{
+		Seq:       r.Seq,
+		Timestamp: r.Time,
+		Hostname:  r.Hostname,
+		Addr:      r.IP.String(),
+		Port:      r.Port,
+		Rtt:       r.RTT,
+		Streak:    r.Streak,
+		Success:   r.Success,
+	})
+}
+
+// handleStats serves the statistics in the same format as the JSON output.
+func (s *apiServer) handleStats(w http.ResponseWriter, r *http.Request) {
+	data := tcping.NewStatisticsJSONData(s.statistics())
+	data.Timestamp = time.Now()
+
+	writeJSON(w, r, data)
+}
+
+func (s *apiServer) handleTargets(w http.ResponseWriter, r *http.Request) {
+	stats := s.statistics()
+	target := apiTarget{
+		Hostname: stats.Hostname,
+		Addr:     stats.IP.String(),
+		Port:     stats.Port,
+	}
+
+	s.historyMu.Lock()
+	if len(s.history) > 0 {
+		target.Up = s.history[len(s.history)-1].Success
+	}
+	s.historyMu.Unlock()
+
+	writeJSON(w, r, []apiTarget{target})
+}
+
+func (s *apiServer) handleHistory(w http.ResponseWriter, r *http.Request) {
+	s.historyMu.Lock()
+	history := append([]apiProbe{}, s.history...)
+	s.historyMu.Unlock()
+
+	writeJSON(w, r, history)
+}
+
+// writeJSON encodes v as the response body. Only GET requests are allowed.
+func writeJSON(w http.ResponseWriter, r *http.Request, v any) {
+	if r.Method != http.MethodGet {
+		w.Header().Set("Allow", http.MethodGet)
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(v)
+}
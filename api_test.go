@@ -0,0 +1,87 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"testing"
+	"time"
+
+	"github.com/pouriyajamshidi/tcping/v2/pkg/tcping"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestAPIServer() *apiServer {
+	s := newAPIServer("")
+	s.statistics = func() tcping.Statistics {
+		return tcping.Statistics{
+			Hostname:                "example.com",
+			IP:                      netip.MustParseAddr("93.184.216.34"),
+			Port:                    443,
+			TotalSuccessfulProbes:   3,
+			TotalUnsuccessfulProbes: 1,
+		}
+	}
+
+	return s
+}
+
+func TestAPIStats(t *testing.T) {
+	s := newTestAPIServer()
+
+	rec := httptest.NewRecorder()
+	s.handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/stats", nil))
+
+	var data tcping.JSONData
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+	assert.NoError(t, json.NewDecoder(rec.Body).Decode(&data))
+	assert.Equal(t, "example.com", data.Hostname)
+	assert.Equal(t, uint(4), data.TotalPackets)
+	assert.Equal(t, "25.00", data.TotalPacketLoss)
+}
+
+func TestAPIHistory(t *testing.T) {
+	s := newTestAPIServer()
+	ip := netip.MustParseAddr("93.184.216.34")
+
+	for i := 0; i < apiHistorySize+5; i++ {
+		s.recordProbe(tcping.Result{
+			Time:    time.Now(),
+			IP:      ip,
+			Port:    443,
+			Streak:  uint(i + 1),
+			Success: i%2 == 0,
+		})
+	}
+
+	rec := httptest.NewRecorder()
+	s.handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/history", nil))
+
+	var history []apiProbe
+	assert.NoError(t, json.NewDecoder(rec.Body).Decode(&history))
+	if assert.Len(t, history, apiHistorySize) {
+		// the oldest probes should have been dropped
+		assert.Equal(t, uint(6), history[0].Streak)
+		assert.Equal(t, uint(apiHistorySize+5), history[apiHistorySize-1].Streak)
+	}
+
+	rec = httptest.NewRecorder()
+	s.handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/targets", nil))
+
+	var targets []apiTarget
+	assert.NoError(t, json.NewDecoder(rec.Body).Decode(&targets))
+	assert.Equal(t, []apiTarget{
+		{Hostname: "example.com", Addr: "93.184.216.34", Port: 443, Up: true},
+	}, targets)
+}
+
+func TestAPIMethodNotAllowed(t *testing.T) {
+	s := newTestAPIServer()
+
+	rec := httptest.NewRecorder()
+	s.handler().ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/stats", nil))
+
+	assert.Equal(t, http.StatusMethodNotAllowed, rec.Code)
+}
@@ -0,0 +1,355 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/pouriyajamshidi/tcping/v2/pkg/tcping"
+)
+
+// the margins of the plot area of the charts of `tcping chart`,
+// leaving room for the labels of the axes.
+const (
+	chartMarginLeft   = 80
+	chartMarginRight  = 20
+	chartMarginTop    = 20
+	chartMarginBottom = 40
+	// chartTicks is the number of intervals of both axes.
+	chartTicks = 4
+)
+
+var (
+	chartBackground = color.RGBA{0xff, 0xff, 0xff, 0xff}
+	chartGrid       = color.RGBA{0xdd, 0xdd, 0xdd, 0xff}
+	chartAxis       = color.RGBA{0x33, 0x33, 0x33, 0xff}
+	chartRTT        = color.RGBA{0x1f, 0x77, 0xb4, 0xff}
+	chartFailure    = color.RGBA{0xd6, 0x27, 0x28, 0xff}
+)
+
+// runChart renders the RTTs of the samples written with
+// --samples-file over time, as a PNG or an SVG image.
+func runChart(args []string) {
+	flags := flag.NewFlagSet("chart", flag.ExitOnError)
+	output := flags.String("o", "", "write the chart to this file, a PNG or an SVG image depending on its extension.")
+	width := flags.Int("width", 1000, "width of the chart in pixels.")
+	height := flags.Int("height", 400, "height of the chart in pixels.")
+	flags.Usage = func() {
+		colorRed("Usage: %s chart -o <chart.png|chart.svg> [--width <pixels>] [--height <pixels>] <samples file>\n", os.Args[0])
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+
+	args = flags.Args()
+	minWidth, minHeight := chartMarginLeft+chartMarginRight+chartTicks, chartMarginTop+chartMarginBottom+chartTicks
+	if len(args) != 1 || *output == "" || *width < minWidth || *height < minHeight {
+		flags.Usage()
+		os.Exit(1)
+	}
+
+	printer := tcping.NewPlainPrinter()
+
+	var render func(chart, io.Writer) error
+	switch ext := strings.ToLower(filepath.Ext(*output)); ext {
+	case ".png":
+		render = chart.png
+	case ".svg":
+		render = chart.svg
+	default:
+		printer.PrintError("Unsupported chart format %q, use .png or .svg", ext)
+		os.Exit(1)
+	}
+
+	f, err := openSamples(args[0])
+	if err != nil {
+		printer.PrintError("Unable to open the samples file: %s", err)
+		os.Exit(1)
+	}
+	samples, err := tcping.ReadSamples(f)
+	f.Close()
+	if err != nil {
+		printer.PrintError("Unable to read %s: %s", args[0], err)
+		os.Exit(1)
+	}
+	if len(samples) == 0 {
+		printer.PrintError("No samples in %s", args[0])
+		os.Exit(1)
+	}
+
+	out, err := os.Create(*output)
+	if err == nil {
+		err = render(newChart(samples, *width, *height), out)
+		if closeErr := out.Close(); err == nil {
+			err = closeErr
+		}
+	}
+	if err != nil {
+		printer.PrintError("Unable to write the chart: %s", err)
+		os.Exit(1)
+	}
+
+	printer.PrintInfo("Wrote the chart of %d samples to %s", len(samples), *output)
+}
+
+// chartPoint is a successful probe, in pixels.
+type chartPoint struct {
+	x, y float64
+}
+
+// chartTick is a labelled tick of an axis, at pos pixels.
+type chartTick struct {
+	pos   float64
+	label string
+}
+
+// chart is an RTT-over-time chart laid out in pixels,
+// ready to be rendered in any format.
+type chart struct {
+	width, height int
+	// lines are the runs of successful probes, broken by the failed ones.
+	lines [][]chartPoint
+	// failures are the x of the failed probes.
+	failures []float64
+	xTicks   []chartTick
+	yTicks   []chartTick
+}
+
+// newChart lays the samples out in a chart of the given size,
+// the time on the x axis and the RTT in milliseconds on the y axis.
+func newChart(samples []tcping.Sample, width, height int) chart {
+	samples = slices.Clone(samples)
+	slices.SortStableFunc(samples, func(a, b tcping.Sample) int {
+		return a.Time.Compare(b.Time)
+	})
+
+	start, end := samples[0].Time, samples[len(samples)-1].Time
+	span := end.Sub(start)
+	if span <= 0 {
+		span = time.Second
+	}
+
+	var maxRTT float32
+	for _, s := range samples {
+		if s.Success {
+			maxRTT = max(maxRTT, s.RTT)
+		}
+	}
+	top := niceCeil(float64(maxRTT))
+
+	c := chart{width: width, height: height}
+	left, right := float64(chartMarginLeft), float64(width-chartMarginRight)
+	upper, lower := float64(chartMarginTop), float64(height-chartMarginBottom)
+	x := func(t time.Time) float64 {
+		return left + float64(t.Sub(start))/float64(span)*(right-left)
+	}
+	y := func(rtt float64) float64 {
+		return lower - rtt/top*(lower-upper)
+	}
+
+	var line []chartPoint
+	for _, s := range samples {
+		if !s.Success {
+			if len(line) > 0 {
+				c.lines = append(c.lines, line)
+				line = nil
+			}
+			c.failures = append(c.failures, x(s.Time))
+			continue
+		}
+		line = append(line, chartPoint{x(s.Time), y(float64(s.RTT))})
+	}
+	if len(line) > 0 {
+		c.lines = append(c.lines, line)
+	}
+
+	for i := 0; i <= chartTicks; i++ {
+		rtt := top * float64(i) / chartTicks
+		c.yTicks = append(c.yTicks, chartTick{y(rtt), fmt.Sprintf("%g ms", rtt)})
+
+		t := start.Add(span * time.Duration(i) / chartTicks)
+		c.xTicks = append(c.xTicks, chartTick{x(t), t.Format(time.TimeOnly)})
+	}
+
+	return c
+}
+
+// niceCeil rounds v up to 1, 2 or 5 times a power of ten, 1 if v isn't positive.
+func niceCeil(v float64) float64 {
+	if v <= 0 {
+		return 1
+	}
+
+	exp := math.Pow(10, math.Floor(math.Log10(v)))
+	for _, m := range []float64{1, 2, 5} {
+		if m*exp >= v {
+			return m * exp
+		}
+	}
+	return 10 * exp
+}
+
+// svgColor formats the color for SVG.
+func svgColor(c color.RGBA) string {
+	return fmt.Sprintf("#%02x%02x%02x", c.R, c.G, c.B)
+}
+
+// svg renders the chart as an SVG image.
+func (c chart) svg(w io.Writer) error {
+	var b strings.Builder
+	left, right := float64(chartMarginLeft), float64(c.width-chartMarginRight)
+	upper, lower := float64(chartMarginTop), float64(c.height-chartMarginBottom)
+
+	fmt.Fprintf(&b, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d" font-family="sans-serif" font-size="12">`+"\n",
+		c.width, c.height, c.width, c.height)
+	fmt.Fprintf(&b, `<rect width="100%%" height="100%%" fill="%s"/>`+"\n", svgColor(chartBackground))
+
+	for _, tick := range c.yTicks {
+		fmt.Fprintf(&b, `<line x1="%.1f" y1="%.1f" x2="%.1f" y2="%.1f" stroke="%s"/>`+"\n",
+			left, tick.pos, right, tick.pos, svgColor(chartGrid))
+		fmt.Fprintf(&b, `<text x="%.1f" y="%.1f" text-anchor="end" dominant-baseline="middle" fill="%s">%s</text>`+"\n",
+			left-8, tick.pos, svgColor(chartAxis), tick.label)
+	}
+	for _, tick := range c.xTicks {
+		// the last label would overflow the image
+		anchor := "middle"
+		if tick.pos == right {
+			anchor = "end"
+		}
+		fmt.Fprintf(&b, `<text x="%.1f" y="%.1f" text-anchor="%s" fill="%s">%s</text>`+"\n",
+			tick.pos, lower+20, anchor, svgColor(chartAxis), tick.label)
+	}
+
+	for _, x := range c.failures {
+		fmt.Fprintf(&b, `<line x1="%.1f" y1="%.1f" x2="%.1f" y2="%.1f" stroke="%s" stroke-opacity="0.5"/>`+"\n",
+			x, upper, x, lower, svgColor(chartFailure))
+	}
+	for _, line := range c.lines {
+		points := make([]string, len(line))
+		for i, p := range line {
+			points[i] = fmt.Sprintf("%.1f,%.1f", p.x, p.y)
+		}
+		fmt.Fprintf(&b, `<polyline points="%s" fill="none" stroke="%s" stroke-width="1.5"/>`+"\n",
+			strings.Join(points, " "), svgColor(chartRTT))
+	}
+
+	fmt.Fprintf(&b, `<polyline points="%.1f,%.1f %.1f,%.1f %.1f,%.1f" fill="none" stroke="%s"/>`+"\n",
+		left, upper, left, lower, right, lower, svgColor(chartAxis))
+	b.WriteString("</svg>\n")
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// png renders the chart as a PNG image.
+func (c chart) png(w io.Writer) error {
+	img := image.NewRGBA(image.Rect(0, 0, c.width, c.height))
+	for x := 0; x < c.width; x++ {
+		for y := 0; y < c.height; y++ {
+			img.SetRGBA(x, y, chartBackground)
+		}
+	}
+
+	left, right := float64(chartMarginLeft), float64(c.width-chartMarginRight)
+	upper, lower := float64(chartMarginTop), float64(c.height-chartMarginBottom)
+
+	for _, tick := range c.yTicks {
+		drawLine(img, left, tick.pos, right, tick.pos, chartGrid)
+		drawText(img, int(left)-8-textWidth(tick.label), int(tick.pos)-glyphHeight*glyphScale/2, tick.label, chartAxis)
+	}
+	for _, tick := range c.xTicks {
+		// the last label would overflow the image
+		x := min(int(tick.pos)-textWidth(tick.label)/2, c.width-textWidth(tick.label))
+		drawText(img, x, int(lower)+10, tick.label, chartAxis)
+	}
+
+	for _, x := range c.failures {
+		drawLine(img, x, upper, x, lower, chartFailure)
+	}
+	for _, line := range c.lines {
+		for i := range line {
+			prev := line[max(i-1, 0)]
+			drawLine(img, prev.x, prev.y, line[i].x, line[i].y, chartRTT)
+		}
+	}
+
+	drawLine(img, left, upper, left, lower, chartAxis)
+	drawLine(img, left, lower, right, lower, chartAxis)
+
+	return png.Encode(w, img)
+}
+
+// drawLine draws a one pixel wide line between the points.
+func drawLine(img *image.RGBA, x0, y0, x1, y1 float64, c color.RGBA) {
+	steps := int(math.Max(math.Abs(x1-x0), math.Abs(y1-y0)))
+	for i := 0; i <= steps; i++ {
+		t := 0.0
+		if steps > 0 {
+			t = float64(i) / float64(steps)
+		}
+		img.SetRGBA(int(math.Round(x0+(x1-x0)*t)), int(math.Round(y0+(y1-y0)*t)), c)
+	}
+}
+
+// the size of the glyphs of the labels of the PNG charts,
+// drawn glyphScale times larger, with a pixel between them.
+const (
+	glyphWidth  = 3
+	glyphHeight = 5
+	glyphScale  = 2
+)
+
+// glyphs are the characters of the labels of the PNG charts,
+// as there's no font to draw text with in the standard library.
+var glyphs = map[rune][glyphHeight]string{
+	'0': {"###", "#.#", "#.#", "#.#", "###"},
+	'1': {".#.", "##.", ".#.", ".#.", "###"},
+	'2': {"###", "..#", "###", "#..", "###"},
+	'3': {"###", "..#", "###", "..#", "###"},
+	'4': {"#.#", "#.#", "###", "..#", "..#"},
+	'5': {"###", "#..", "###", "..#", "###"},
+	'6': {"###", "#..", "###", "#.#", "###"},
+	'7': {"###", "..#", "..#", "..#", "..#"},
+	'8': {"###", "#.#", "###", "#.#", "###"},
+	'9': {"###", "#.#", "###", "..#", "###"},
+	'.': {"...", "...", "...", "...", ".#."},
+	':': {"...", ".#.", "...", ".#.", "..."},
+	'-': {"...", "...", "###", "...", "..."},
+	'e': {"...", "###", "###", "#..", "###"},
+	'm': {"...", "...", "###", "###", "#.#"},
+	's': {"...", ".##", "#..", "..#", "##."},
+	' ': {"...", "...", "...", "...", "..."},
+}
+
+// textWidth returns the width of the text drawn with drawText.
+func textWidth(text string) int {
+	return len([]rune(text)) * (glyphWidth + 1) * glyphScale
+}
+
+// drawText draws the text with its top left corner at x, y.
+// The characters without a glyph are left blank.
+func drawText(img *image.RGBA, x, y int, text string, c color.RGBA) {
+	for _, r := range text {
+		for row, bits := range glyphs[r] {
+			for col, bit := range bits {
+				if bit != '#' {
+					continue
+				}
+				for dx := 0; dx < glyphScale; dx++ {
+					for dy := 0; dy < glyphScale; dy++ {
+						img.SetRGBA(x+col*glyphScale+dx, y+row*glyphScale+dy, c)
+					}
+				}
+			}
+		}
+		x += (glyphWidth + 1) * glyphScale
+	}
+}
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"bytes"
+	"image/png"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pouriyajamshidi/tcping/v2/pkg/tcping"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewChart(t *testing.T) {
+	start := time.Date(2023, 9, 10, 14, 0, 0, 0, time.Local)
+	samples := []tcping.Sample{
+		{Time: start, Success: true, RTT: 10},
+		{Time: start.Add(time.Second), Success: true, RTT: 15},
+		{Time: start.Add(2 * time.Second), FailureReason: tcping.FailureTimeout},
+		{Time: start.Add(4 * time.Second), Success: true, RTT: 5},
+	}
+
+	c := newChart(samples, 500, 260)
+
+	// the plot area is 400x200 pixels, up to 20 ms
+	if assert.Len(t, c.lines, 2) {
+		assert.Equal(t, []chartPoint{{80, 120}, {180, 70}}, c.lines[0])
+		assert.Equal(t, []chartPoint{{480, 170}}, c.lines[1])
+	}
+	assert.Equal(t, []float64{280}, c.failures)
+	assert.Equal(t, chartTick{20, "20 ms"}, c.yTicks[len(c.yTicks)-1])
+	assert.Equal(t, chartTick{80, "14:00:00"}, c.xTicks[0])
+	assert.Equal(t, chartTick{480, "14:00:04"}, c.xTicks[len(c.xTicks)-1])
+
+	var svg bytes.Buffer
+	assert.NoError(t, c.svg(&svg))
+	assert.True(t, strings.HasPrefix(svg.String(), "<svg "))
+	assert.Contains(t, svg.String(), `<polyline points="80.0,120.0 180.0,70.0"`)
+	assert.Contains(t, svg.String(), ">20 ms</text>")
+
+	var img bytes.Buffer
+	assert.NoError(t, c.png(&img))
+	decoded, err := png.Decode(&img)
+	if assert.NoError(t, err) {
+		assert.Equal(t, 500, decoded.Bounds().Dx())
+		assert.Equal(t, 260, decoded.Bounds().Dy())
+	}
+}
+
+func TestNiceCeil(t *testing.T) {
+	assert.Equal(t, 1.0, niceCeil(0))
+	assert.Equal(t, 2.0, niceCeil(1.006))
+	assert.Equal(t, 50.0, niceCeil(23))
+	assert.Equal(t, 100.0, niceCeil(60))
+}
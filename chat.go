@@ -0,0 +1,109 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/pouriyajamshidi/tcping/v2/pkg/tcping"
+)
+
+// chatNotifier posts a message to a chat service when the target
+// goes down, comes back up, gets degraded or, optionally, responds too slowly.
+type chatNotifier struct {
+	// send posts the message to the chat service.
+	send func(message string) error
+	// service is the name of the chat service used in the error messages.
+	service string
+	pending sync.WaitGroup
+	// rttThreshold in ms, above which an alert is sent. 0 means never.
+	rttThreshold float32
+	// slow prevents sending the RTT alert for every slow probe.
+	// It's reset once the RTT gets back under the threshold.
+	slow bool
+}
+
+func newSlackNotifier(cfg *slackConfig, rttThreshold float32) *chatNotifier {
+	client := &http.Client{Timeout: webhookTimeout}
+
+	return &chatNotifier{
+		service:      "Slack",
+		rttThreshold: rttThreshold,
+		send: func(message string) error {
+			return postJSON(client, cfg.WebhookURL, map[string]string{"text": message}, nil)
+		},
+	}
+}
+
+func newDiscordNotifier(cfg *discordConfig, rttThreshold float32) *chatNotifier {
+	client := &http.Client{Timeout: webhookTimeout}
+
+	return &chatNotifier{
+		service:      "Discord",
+		rttThreshold: rttThreshold,
+		send: func(message string) error {
+			return postJSON(client, cfg.WebhookURL, map[string]string{"content": message}, nil)
+		},
+	}
+}
+
+func newTelegramNotifier(cfg *telegramConfig, rttThreshold float32) *chatNotifier {
+	client := &http.Client{Timeout: webhookTimeout}
+	url := fmt.Sprintf("https://api.telegram.org/bot%s/sendMessage", cfg.Token)
+
+	return &chatNotifier{
+		service:      "Telegram",
+		rttThreshold: rttThreshold,
+		send: func(message string) error {
+			return postJSON(client, url, map[string]string{
+				"chat_id": cfg.ChatID,
+				"text":    message,
+			}, nil)
+		},
+	}
+}
+
+// Notify posts the state change prefixed with its event in upper case,
+// e.g. "[DOWN]", "[UP]", "[DEGRADED]" or "[DEGRADED-END]".
+func (n *chatNotifier) Notify(change tcping.StateChange) {
+	n.post(fmt.Sprintf("[%s] TCPING: %s", strings.ToUpper(stateChangeEvent(change)), change.Summary()))
+}
+
+func (n *chatNotifier) NotifyProbe(r tcping.Result) {
+	if !r.Success || n.rttThreshold == 0 {
+		return
+	}
+
+	if r.RTT <= n.rttThreshold {
+		n.slow = false
+		return
+	}
+
+	if n.slow {
+		return
+	}
+	n.slow = true
+
+	target := tcping.StateChange{Hostname: r.Hostname, IP: r.IP.String(), Port: r.Port}.Target()
+	n.post(fmt.Sprintf("[SLOW] TCPING: %s responded in %.3f ms, which is above the %.3f ms threshold",
+		target, r.RTT, n.rttThreshold))
+}
+
+func (n *chatNotifier) NotifyStatistics(_ tcping.Statistics) {
+	n.pending.Wait()
+}
+
+// post sends the message in the background and reports the failure on stderr,
+// so that it doesn't interfere with the printer's output.
+func (n *chatNotifier) post(message string) {
+	n.pending.Add(1)
+	go func() {
+		defer n.pending.Done()
+
+		if err := n.send(message); err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to send the %s notification: %s\n", n.service, err)
+		}
+	}()
+}
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"net/netip"
+	"sync"
+	"testing"
+
+	"github.com/pouriyajamshidi/tcping/v2/pkg/tcping"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestChatNotifierRttThreshold(t *testing.T) {
+	var (
+		mu       sync.Mutex
+		messages []string
+	)
+
+	n := &chatNotifier{
+		service:      "test",
+		rttThreshold: 100,
+		send: func(message string) error {
+			mu.Lock()
+			defer mu.Unlock()
+			messages = append(messages, message)
+			return nil
+		},
+	}
+
+	// only the first probe of a slow streak should trigger an alert
+	for _, rtt := range []float32{50, 150, 200, 80, 120} {
+		n.NotifyProbe(tcping.Result{Hostname: "example.com", IP: netip.MustParseAddr("93.184.216.34"), Port: 443, RTT: rtt, Success: true})
+	}
+	n.NotifyStatistics(tcping.Statistics{})
+
+	assert.Len(t, messages, 2)
+}
+
+func TestChatNotifierStateChange(t *testing.T) {
+	var messages []string
+	n := &chatNotifier{
+		service: "test",
+		send: func(message string) error {
+			messages = append(messages, message)
+			return nil
+		},
+	}
+
+	change := tcping.StateChange{IP: "93.184.216.34", Port: 443, RTT: 200, Degraded: true}
+	n.Notify(change)
+	n.NotifyStatistics(tcping.Statistics{})
+	change.Up, change.Degraded = false, false
+	n.Notify(change)
+	n.NotifyStatistics(tcping.Statistics{})
+
+	assert.Equal(t, []string{
+		"[DEGRADED] TCPING: 93.184.216.34 on port 443 is degraded, a probe took 200.000 ms",
+		"[DOWN] TCPING: 93.184.216.34 on port 443 is not responding",
+	}, messages)
+}
@@ -0,0 +1,116 @@
+package main
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/pouriyajamshidi/tcping/v2/pkg/tcping"
+)
+
+// runCheck resolves the target and prints what would be probed and
+// where the results would go, without sending any probe, then exits.
+// The pingers are created as usual, so invalid options fail the check
+// and the resolution of the hostname is printed.
+func runCheck(opts tcping.Options, servers []server, allIPs bool, ports []uint16, output, samplesFile string) {
+	pingers, err := newPingers(opts, allIPs, ports)
+	if err != nil {
+		opts.Printer.PrintError("%s", err)
+		os.Exit(1)
+	}
+
+	for _, pinger := range pingers {
+		s := pinger.Statistics()
+		opts.Printer.PrintInfo("Target: %s port %d, probing %s", s.Hostname, s.Port, s.IP)
+	}
+
+	opts.Printer.PrintInfo("Interval: %s", opts.IntervalBetweenProbes)
+	opts.Printer.PrintInfo("Timeout: %s", describeTimeout(opts.Timeout))
+	if opts.ProbesBeforeQuit > 0 {
+		opts.Printer.PrintInfo("Probes: %d", opts.ProbesBeforeQuit)
+	} else {
+		opts.Printer.PrintInfo("Probes: until stopped")
+	}
+
+	opts.Printer.PrintInfo("Output: %s", output)
+	if samplesFile != "" {
+		opts.Printer.PrintInfo("Samples file: %s", samplesFile)
+	}
+	for _, n := range opts.Notifiers {
+		for _, desc := range describeNotifier(n) {
+			opts.Printer.PrintInfo("Notifier: %s", desc)
+		}
+	}
+	for _, srv := range servers {
+		opts.Printer.PrintInfo("Server: %s", describeServer(srv))
+	}
+
+	os.Exit(0)
+}
+
+// describeTimeout describes the timeout of the probes, 0 meaning none.
+func describeTimeout(timeout time.Duration) string {
+	if timeout == 0 {
+		return "none"
+	}
+	return timeout.String()
+}
+
+// describeNotifier describes the notifier, or the ones it's made of,
+// without their secrets, e.g. the tokens of the chat services.
+func describeNotifier(n tcping.Notifier) []string {
+	switch n := n.(type) {
+	case *desktopNotifier:
+		return []string{"desktop notifications"}
+	case *commandNotifier:
+		var commands []string
+		if n.onDown != "" {
+			commands = append(commands, fmt.Sprintf("run %q when down", n.onDown))
+		}
+		if n.onUp != "" {
+			commands = append(commands, fmt.Sprintf("run %q when up", n.onUp))
+		}
+		return []string{strings.Join(commands, ", ")}
+	case *webhookNotifier:
+		return []string{"webhook " + redactURL(n.url)}
+	case *pushgatewayNotifier:
+		return []string{fmt.Sprintf("Pushgateway %s, job %s", redactURL(n.url), n.job)}
+	case *chatNotifier:
+		return []string{n.service}
+	case *emailNotifier:
+		return []string{"email to " + strings.Join(n.cfg.To, ", ")}
+	case *incidentNotifier:
+		return []string{n.service}
+	case *configNotifiers:
+		var descs []string
+		for _, notifier := range n.current() {
+			descs = append(descs, describeNotifier(notifier)...)
+		}
+		return descs
+	default:
+		return []string{fmt.Sprintf("%T", n)}
+	}
+}
+
+// redactURL hides the password of the URL, if it has one.
+func redactURL(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return rawURL
+	}
+	return u.Redacted()
+}
+
+// describeServer describes the server serving the live statistics.
+func describeServer(srv server) string {
+	switch srv := srv.(type) {
+	case *apiServer:
+		return "HTTP on " + srv.addr
+	case *grpcServer:
+		return "gRPC on " + srv.addr
+	default:
+		return fmt.Sprintf("%T", srv)
+	}
+}
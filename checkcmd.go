@@ -0,0 +1,41 @@
+package main
+
+import (
+	"context"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/pouriyajamshidi/tcping/v2/pkg/tcping"
+)
+
+// setCheckCmd runs the command of --check-cmd after every successful
+// connection and fails the probes for which it exits with a nonzero status.
+func setCheckCmd(opts *tcping.Options, command *string) {
+	if *command == "" {
+		return
+	}
+
+	opts.SuccessCheck = func(ctx context.Context, r tcping.Result) error {
+		cmd := shellCommand(ctx, *command)
+		cmd.Env = append(os.Environ(), probeEnviron(r)...)
+		// the stdout is kept for the output of tcping
+		cmd.Stdout = os.Stderr
+		cmd.Stderr = os.Stderr
+
+		return cmd.Run()
+	}
+}
+
+// probeEnviron returns the probe as a list of environment
+// variables in the "key=value" form, like environ.
+func probeEnviron(r tcping.Result) []string {
+	return []string{
+		"TCPING_SEQ=" + strconv.FormatUint(uint64(r.Seq), 10),
+		"TCPING_HOSTNAME=" + r.Hostname,
+		"TCPING_IP=" + r.IP.String(),
+		"TCPING_PORT=" + strconv.Itoa(int(r.Port)),
+		"TCPING_TIMESTAMP=" + r.Time.Format(time.RFC3339),
+		"TCPING_RTT=" + strconv.FormatFloat(float64(r.RTT), 'f', 3, 32),
+	}
+}
@@ -0,0 +1,50 @@
+package main
+
+import (
+	"context"
+	"net/netip"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/pouriyajamshidi/tcping/v2/pkg/tcping"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProbeEnviron(t *testing.T) {
+	r := tcping.Result{
+		Seq:      7,
+		Time:     time.Date(2023, 9, 10, 12, 30, 0, 0, time.UTC),
+		Hostname: "example.com",
+		IP:       netip.MustParseAddr("93.184.216.34"),
+		Port:     443,
+		RTT:      12.5,
+	}
+
+	assert.Equal(t, []string{
+		"TCPING_SEQ=7",
+		"TCPING_HOSTNAME=example.com",
+		"TCPING_IP=93.184.216.34",
+		"TCPING_PORT=443",
+		"TCPING_TIMESTAMP=2023-09-10T12:30:00Z",
+		"TCPING_RTT=12.500",
+	}, probeEnviron(r))
+}
+
+func TestSetCheckCmd(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the command is run by sh")
+	}
+
+	var opts tcping.Options
+	command := `[ "$TCPING_PORT" = 443 ]`
+	setCheckCmd(&opts, &command)
+
+	assert.NoError(t, opts.SuccessCheck(context.Background(), tcping.Result{Port: 443}))
+	assert.EqualError(t, opts.SuccessCheck(context.Background(), tcping.Result{Port: 80}), "exit status 1")
+
+	opts = tcping.Options{}
+	command = ""
+	setCheckCmd(&opts, &command)
+	assert.Nil(t, opts.SuccessCheck)
+}
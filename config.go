@@ -0,0 +1,319 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/signal"
+	"reflect"
+	"sync"
+	"syscall"
+
+	"github.com/pouriyajamshidi/tcping/v2/pkg/tcping"
+)
+
+// config holds the settings read from the file passed with the --config flag.
+//
+// Secrets such as tokens belong here rather than on the
+// command line, where they would end up in the shell history.
+type config struct {
+	Notifiers notifiersConfig `json:"notifiers"`
+	// Targets are probed by `tcping daemon`.
+	Targets []targetConfig `json:"targets,omitempty"`
+	// Proxy authenticates to the proxy of the environment.
+	Proxy *proxyConfig `json:"proxy,omitempty"`
+}
+
+// proxyConfig holds the credentials of the proxy of HTTPS_PROXY, HTTP_PROXY
+// or ALL_PROXY, used when its URL doesn't carry them.
+type proxyConfig struct {
+	Username string `json:"username"`
+	Password string `json:"password,omitempty"`
+}
+
+type targetConfig struct {
+	Hostname string `json:"hostname"`
+	Port     uint16 `json:"port"`
+}
+
+// notifiersConfig holds the settings of the notifiers that
+// need more than a flag to be configured.
+type notifiersConfig struct {
+	Slack    *slackConfig    `json:"slack,omitempty"`
+	Discord  *discordConfig  `json:"discord,omitempty"`
+	Telegram *telegramConfig `json:"telegram,omitempty"`
+	Email    *emailConfig    `json:"email,omitempty"`
+
+	PagerDuty *pagerDutyConfig `json:"pagerduty,omitempty"`
+	Opsgenie  *opsgenieConfig  `json:"opsgenie,omitempty"`
+
+	// RttThresholdMs makes the chat notifiers send an alert
+	// when the RTT of a probe goes above it. 0 disables it.
+	RttThresholdMs float32 `json:"rtt_threshold_ms,omitempty"`
+}
+
+type slackConfig struct {
+	// WebhookURL is the incoming webhook URL of the channel to post to.
+	WebhookURL string `json:"webhook_url"`
+}
+
+type discordConfig struct {
+	// WebhookURL is the webhook URL of the channel to post to.
+	WebhookURL string `json:"webhook_url"`
+}
+
+type telegramConfig struct {
+	// Token is the token of the bot that sends the messages.
+	Token string `json:"token"`
+	// ChatID is the ID of the chat or the @username of the channel.
+	ChatID string `json:"chat_id"`
+}
+
+type emailConfig struct {
+	// Host of the SMTP server.
+	Host string `json:"host"`
+	// Port of the SMTP server. Defaults to 587.
+	Port uint16 `json:"port,omitempty"`
+	// ImplicitTLS should be set when the SMTP server expects
+	// TLS from the start, which is usually the case on port 465.
+	ImplicitTLS bool   `json:"implicit_tls,omitempty"`
+	Username    string `json:"username,omitempty"`
+	Password    string `json:"password,omitempty"`
+	From        string `json:"from"`
+	// To is the list of the recipients.
+	To []string `json:"to"`
+	// DowntimeThresholdSeconds is how long the target should be down
+	// before an email is sent. 0 sends it on the first failed probe.
+	DowntimeThresholdSeconds float64 `json:"downtime_threshold_seconds,omitempty"`
+}
+
+type pagerDutyConfig struct {
+	// RoutingKey is the integration key of the Events API v2 integration.
+	RoutingKey string `json:"routing_key"`
+	// Severity of the incidents. Defaults to "critical".
+	Severity string `json:"severity,omitempty"`
+}
+
+type opsgenieConfig struct {
+	APIKey string `json:"api_key"`
+	// APIURL defaults to https://api.opsgenie.com,
+	// use https://api.eu.opsgenie.com for the EU instance.
+	APIURL string `json:"api_url,omitempty"`
+	// Priority of the alerts, from P1 to P5. Defaults to "P1".
+	Priority string `json:"priority,omitempty"`
+}
+
+// loadConfig reads and validates the configuration file.
+func loadConfig(path string) (config, error) {
+	var cfg config
+
+	f, err := os.Open(path)
+	if err != nil {
+		return cfg, err
+	}
+	defer f.Close()
+
+	decoder := json.NewDecoder(f)
+	// catch typos in the setting names, they'd silently
+	// disable the alerting otherwise
+	decoder.DisallowUnknownFields()
+
+	if err := decoder.Decode(&cfg); err != nil {
+		return cfg, fmt.Errorf("parsing %s: %w", path, err)
+	}
+
+	cfg.setDefaults()
+
+	return cfg, cfg.validate()
+}
+
+// reloadOnHangup loads the configuration file again on SIGHUP and
+// applies it. An invalid file is reported and left unapplied.
+func reloadOnHangup(path string, printer tcping.Printer, apply func(cfg config)) {
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, syscall.SIGHUP)
+
+	go func() {
+		for range sigChan {
+			cfg, err := loadConfig(path)
+			if err != nil {
+				printer.PrintError("Invalid configuration file, keeping the previous one: %s", err)
+				continue
+			}
+
+			apply(cfg)
+			printer.PrintInfo("Reloaded the configuration file %s", path)
+		}
+	}()
+}
+
+// setDefaults fills in the optional settings that were left out.
+func (cfg *config) setDefaults() {
+	n := &cfg.Notifiers
+
+	if n.Email != nil && n.Email.Port == 0 {
+		n.Email.Port = 587
+	}
+
+	if n.PagerDuty != nil && n.PagerDuty.Severity == "" {
+		n.PagerDuty.Severity = "critical"
+	}
+
+	if n.Opsgenie != nil {
+		if n.Opsgenie.APIURL == "" {
+			n.Opsgenie.APIURL = opsgenieAPIURL
+		}
+		if n.Opsgenie.Priority == "" {
+			n.Opsgenie.Priority = "P1"
+		}
+	}
+}
+
+// validate makes sure the mandatory settings are present.
+func (cfg config) validate() error {
+	n := cfg.Notifiers
+
+	if n.Slack != nil && n.Slack.WebhookURL == "" {
+		return fmt.Errorf("notifiers.slack.webhook_url is required")
+	}
+
+	if n.Discord != nil && n.Discord.WebhookURL == "" {
+		return fmt.Errorf("notifiers.discord.webhook_url is required")
+	}
+
+	if n.Telegram != nil && (n.Telegram.Token == "" || n.Telegram.ChatID == "") {
+		return fmt.Errorf("notifiers.telegram.token and notifiers.telegram.chat_id are required")
+	}
+
+	if n.Email != nil {
+		if n.Email.Host == "" || n.Email.From == "" || len(n.Email.To) == 0 {
+			return fmt.Errorf("notifiers.email.host, notifiers.email.from and notifiers.email.to are required")
+		}
+
+		if n.Email.DowntimeThresholdSeconds < 0 {
+			return fmt.Errorf("notifiers.email.downtime_threshold_seconds can't be negative")
+		}
+	}
+
+	if n.PagerDuty != nil {
+		if n.PagerDuty.RoutingKey == "" {
+			return fmt.Errorf("notifiers.pagerduty.routing_key is required")
+		}
+
+		switch n.PagerDuty.Severity {
+		case "critical", "error", "warning", "info":
+		default:
+			return fmt.Errorf("notifiers.pagerduty.severity should be one of critical, error, warning or info")
+		}
+	}
+
+	if n.Opsgenie != nil {
+		if n.Opsgenie.APIKey == "" {
+			return fmt.Errorf("notifiers.opsgenie.api_key is required")
+		}
+
+		switch n.Opsgenie.Priority {
+		case "P1", "P2", "P3", "P4", "P5":
+		default:
+			return fmt.Errorf("notifiers.opsgenie.priority should be one of P1 to P5")
+		}
+	}
+
+	if n.RttThresholdMs < 0 {
+		return fmt.Errorf("notifiers.rtt_threshold_ms can't be negative")
+	}
+
+	if cfg.Proxy != nil && cfg.Proxy.Username == "" {
+		return fmt.Errorf("proxy.username is required")
+	}
+
+	for i, target := range cfg.Targets {
+		if target.Hostname == "" || target.Port == 0 {
+			return fmt.Errorf("targets[%d].hostname and targets[%d].port are required", i, i)
+		}
+	}
+
+	return nil
+}
+
+// newNotifiers creates the notifiers configured in the file.
+func newNotifiers(cfg notifiersConfig) []tcping.Notifier {
+	var notifiers []tcping.Notifier
+
+	if cfg.Slack != nil {
+		notifiers = append(notifiers, newSlackNotifier(cfg.Slack, cfg.RttThresholdMs))
+	}
+	if cfg.Discord != nil {
+		notifiers = append(notifiers, newDiscordNotifier(cfg.Discord, cfg.RttThresholdMs))
+	}
+	if cfg.Telegram != nil {
+		notifiers = append(notifiers, newTelegramNotifier(cfg.Telegram, cfg.RttThresholdMs))
+	}
+	if cfg.Email != nil {
+		notifiers = append(notifiers, newEmailNotifier(cfg.Email))
+	}
+	if cfg.PagerDuty != nil {
+		notifiers = append(notifiers, newPagerDutyNotifier(cfg.PagerDuty))
+	}
+	if cfg.Opsgenie != nil {
+		notifiers = append(notifiers, newOpsgenieNotifier(cfg.Opsgenie))
+	}
+
+	return notifiers
+}
+
+// configNotifiers notifies through the notifiers configured in the file,
+// which are replaced when the file is reloaded while the pinger keeps
+// probing. Each target needs its own, as the notifiers track its state.
+type configNotifiers struct {
+	mu        sync.Mutex
+	cfg       notifiersConfig
+	notifiers []tcping.Notifier
+}
+
+func newConfigNotifiers(cfg notifiersConfig) *configNotifiers {
+	return &configNotifiers{cfg: cfg, notifiers: newNotifiers(cfg)}
+}
+
+// reload replaces the notifiers if their settings have changed,
+// the new ones start over, e.g. without the ongoing outage.
+func (n *configNotifiers) reload(cfg notifiersConfig) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	if reflect.DeepEqual(cfg, n.cfg) {
+		return
+	}
+	n.cfg = cfg
+	n.notifiers = newNotifiers(cfg)
+}
+
+// current returns the notifiers to notify.
+func (n *configNotifiers) current() []tcping.Notifier {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	return n.notifiers
+}
+
+func (n *configNotifiers) Notify(change tcping.StateChange) {
+	for _, notifier := range n.current() {
+		notifier.Notify(change)
+	}
+}
+
+func (n *configNotifiers) NotifyProbe(r tcping.Result) {
+	for _, notifier := range n.current() {
+		if pn, ok := notifier.(tcping.ProbeNotifier); ok {
+			pn.NotifyProbe(r)
+		}
+	}
+}
+
+func (n *configNotifiers) NotifyStatistics(s tcping.Statistics) {
+	for _, notifier := range n.current() {
+		if sn, ok := notifier.(tcping.StatisticsNotifier); ok {
+			sn.NotifyStatistics(s)
+		}
+	}
+}
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// writeTestConfig writes the given content to a temporary
+// configuration file and returns its path.
+func writeTestConfig(t *testing.T, content string) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "tcping.json")
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+
+	return path
+}
+
+func TestLoadConfig(t *testing.T) {
+	path := writeTestConfig(t, `{
+		"notifiers": {
+			"rtt_threshold_ms": 150,
+			"slack": {"webhook_url": "https://hooks.slack.com/services/T/B/X"},
+			"telegram": {"token": "123:abc", "chat_id": "@tcping"}
+		}
+	}`)
+
+	cfg, err := loadConfig(path)
+	assert.NoError(t, err)
+	assert.Equal(t, float32(150), cfg.Notifiers.RttThresholdMs)
+	assert.Equal(t, "https://hooks.slack.com/services/T/B/X", cfg.Notifiers.Slack.WebhookURL)
+	assert.Nil(t, cfg.Notifiers.Discord)
+	assert.Equal(t, "@tcping", cfg.Notifiers.Telegram.ChatID)
+}
+
+func TestLoadConfigInvalid(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+	}{
+		{name: "unknown field", content: `{"notifiers": {"slak": {}}}`},
+		{name: "missing webhook url", content: `{"notifiers": {"discord": {}}}`},
+		{name: "missing telegram chat", content: `{"notifiers": {"telegram": {"token": "123:abc"}}}`},
+		{name: "missing email recipients", content: `{"notifiers": {"email": {"host": "smtp.example.com", "from": "a@example.com"}}}`},
+		{name: "invalid pagerduty severity", content: `{"notifiers": {"pagerduty": {"routing_key": "abc", "severity": "bad"}}}`},
+		{name: "missing opsgenie api key", content: `{"notifiers": {"opsgenie": {}}}`},
+		{name: "negative rtt threshold", content: `{"notifiers": {"rtt_threshold_ms": -1}}`},
+		{name: "missing proxy username", content: `{"proxy": {"password": "secret"}}`},
+		{name: "missing target port", content: `{"targets": [{"hostname": "example.com"}]}`},
+		{name: "malformed", content: `{"notifiers": `},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := loadConfig(writeTestConfig(t, tt.content))
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestLoadConfigEmailDefaults(t *testing.T) {
+	path := writeTestConfig(t, `{
+		"notifiers": {
+			"email": {"host": "smtp.example.com", "from": "tcping@example.com", "to": ["oncall@example.com"]}
+		}
+	}`)
+
+	cfg, err := loadConfig(path)
+	assert.NoError(t, err)
+	assert.Equal(t, uint16(587), cfg.Notifiers.Email.Port)
+	assert.False(t, cfg.Notifiers.Email.ImplicitTLS)
+}
+
+func TestLoadConfigIncidentDefaults(t *testing.T) {
+	path := writeTestConfig(t, `{
+		"notifiers": {
+			"pagerduty": {"routing_key": "abc"},
+			"opsgenie": {"api_key": "def"}
+		}
+	}`)
+
+	cfg, err := loadConfig(path)
+	assert.NoError(t, err)
+	assert.Equal(t, "critical", cfg.Notifiers.PagerDuty.Severity)
+	assert.Equal(t, opsgenieAPIURL, cfg.Notifiers.Opsgenie.APIURL)
+	assert.Equal(t, "P1", cfg.Notifiers.Opsgenie.Priority)
+}
+
+func TestConfigNotifiersReload(t *testing.T) {
+	cfg := notifiersConfig{Slack: &slackConfig{WebhookURL: "https://hooks.slack.com/services/T/B/X"}}
+	n := newConfigNotifiers(cfg)
+	notifiers := n.current()
+	assert.Len(t, notifiers, 1)
+
+	// the same settings keep the notifiers and their state
+	n.reload(notifiersConfig{Slack: &slackConfig{WebhookURL: "https://hooks.slack.com/services/T/B/X"}})
+	assert.Same(t, notifiers[0], n.current()[0])
+
+	n.reload(notifiersConfig{Discord: &discordConfig{WebhookURL: "https://discord.com/api/webhooks/1/x"}})
+	if assert.Len(t, n.current(), 1) {
+		assert.NotSame(t, notifiers[0], n.current()[0])
+	}
+
+	n.reload(notifiersConfig{})
+	assert.Empty(t, n.current())
+}
@@ -0,0 +1,512 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"net"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/pouriyajamshidi/tcping/v2/pkg/tcping"
+)
+
+// defaultSocketPath is where the daemon listens for the ctl commands.
+var defaultSocketPath = filepath.Join(os.TempDir(), "tcping.sock")
+
+// ctlRequest is a command sent by `tcping ctl` to the daemon.
+type ctlRequest struct {
+	// Command is one of "add", "remove", "list", "stats" or "stop".
+	Command  string `json:"command"`
+	Hostname string `json:"hostname,omitempty"`
+	Port     uint16 `json:"port,omitempty"`
+}
+
+// ctlResponse is the answer of the daemon to a ctlRequest.
+type ctlResponse struct {
+	Error      string            `json:"error,omitempty"`
+	Targets    []string          `json:"targets,omitempty"`
+	Statistics []tcping.JSONData `json:"statistics,omitempty"`
+}
+
+// daemon probes multiple targets and lets them be
+// managed through a control socket while it's running.
+type daemon struct {
+	// newPinger creates the pinger of a new target, notifying the notifier.
+	newPinger func(hostname string, port uint16, notifier tcping.Notifier) (*tcping.Pinger, error)
+	targets   map[string]*daemonTarget
+	// adding are the targets whose pinger is being created,
+	// which resolves the hostname without holding mu.
+	adding   map[string]bool
+	listener net.Listener
+	// notifiers are the settings of the notifiers of the targets.
+	notifiers notifiersConfig
+	// configTargets are the targets of the configuration file.
+	configTargets map[string]targetConfig
+	// stopped is closed once the daemon has been asked to stop.
+	stopped  chan struct{}
+	stopOnce sync.Once
+	mu       sync.Mutex
+}
+
+type daemonTarget struct {
+	pinger    *tcping.Pinger
+	notifiers *configNotifiers
+	// done is closed once the pinger has stopped probing.
+	done chan struct{}
+}
+
+func newDaemon(newPinger func(hostname string, port uint16, notifier tcping.Notifier) (*tcping.Pinger, error)) *daemon {
+	return &daemon{
+		newPinger:     newPinger,
+		targets:       make(map[string]*daemonTarget),
+		adding:        make(map[string]bool),
+		configTargets: make(map[string]targetConfig),
+		stopped:       make(chan struct{}),
+	}
+}
+
+// targetKey identifies a target of the daemon.
+func targetKey(hostname string, port uint16) string {
+	return net.JoinHostPort(hostname, strconv.Itoa(int(port)))
+}
+
+// listen serves the ctl commands on the unix socket until the daemon is stopped.
+func (d *daemon) listen(socketPath string) error {
+	// a previous daemon might have left its socket behind
+	if conn, err := net.Dial("unix", socketPath); err == nil {
+		conn.Close()
+		return fmt.Errorf("another daemon is already listening on %s", socketPath)
+	}
+	os.Remove(socketPath)
+
+	ln, err := net.Listen("unix", socketPath)
+	if err != nil {
+		return err
+	}
+	d.listener = ln
+	sdNotify("READY=1")
+
+	for {
+		conn, err := ln.Accept()
+		if err != nil {
+			select {
+			case <-d.stopped:
+				return nil
+			default:
+				return err
+			}
+		}
+
+		go d.serve(conn)
+	}
+}
+
+// serve answers the requests of a single ctl connection.
+func (d *daemon) serve(conn net.Conn) {
+	defer conn.Close()
+
+	decoder := json.NewDecoder(conn)
+	encoder := json.NewEncoder(conn)
+
+	for {
+		var req ctlRequest
+		if err := decoder.Decode(&req); err != nil {
+			return
+		}
+
+		encoder.Encode(d.handle(req))
+
+		if req.Command == "stop" {
+			d.stop()
+			return
+		}
+	}
+}
+
+// handle executes a single ctl command.
+func (d *daemon) handle(req ctlRequest) ctlResponse {
+	var err error
+
+	switch req.Command {
+	case "add":
+		err = d.add(req.Hostname, req.Port)
+	case "remove":
+		err = d.remove(req.Hostname, req.Port)
+	case "list":
+		return ctlResponse{Targets: d.list()}
+	case "stats":
+		var stats []tcping.JSONData
+		stats, err = d.statistics(req.Hostname, req.Port)
+		if err == nil {
+			return ctlResponse{Statistics: stats}
+		}
+	case "stop":
+	default:
+		err = fmt.Errorf("unknown command %q", req.Command)
+	}
+
+	if err != nil {
+		return ctlResponse{Error: err.Error()}
+	}
+
+	return ctlResponse{}
+}
+
+// add starts probing a new target. The pinger is created, resolving
+// the hostname, without holding the lock, so that the other commands
+// and the probes of the other targets aren't held up by the DNS.
+func (d *daemon) add(hostname string, port uint16) error {
+	key := targetKey(hostname, port)
+
+	d.mu.Lock()
+	if _, ok := d.targets[key]; ok || d.adding[key] {
+		d.mu.Unlock()
+		return fmt.Errorf("%s is already being probed", key)
+	}
+	d.adding[key] = true
+	notifiers := newConfigNotifiers(d.notifiers)
+	d.mu.Unlock()
+
+	pinger, err := d.newPinger(hostname, port, notifiers)
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	delete(d.adding, key)
+	if err != nil {
+		return err
+	}
+
+	select {
+	case <-d.stopped:
+		// releases what the pinger holds, e.g. its capture file
+		pinger.Shutdown()
+		return errors.New("the daemon is stopping")
+	default:
+	}
+
+	// the notifiers might have been reloaded in the meantime
+	notifiers.reload(d.notifiers)
+
+	target := &daemonTarget{pinger: pinger, notifiers: notifiers, done: make(chan struct{})}
+	d.targets[key] = target
+
+	go func() {
+		defer close(target.done)
+		pinger.Run()
+	}()
+
+	return nil
+}
+
+// remove stops probing the target and prints its final statistics.
+func (d *daemon) remove(hostname string, port uint16) error {
+	key := targetKey(hostname, port)
+
+	d.mu.Lock()
+	target, ok := d.targets[key]
+	delete(d.targets, key)
+	d.mu.Unlock()
+
+	if !ok {
+		return fmt.Errorf("%s is not being probed", key)
+	}
+
+	target.pinger.Stop()
+	<-target.done
+	target.pinger.Shutdown()
+
+	return nil
+}
+
+// reload applies the configuration file. Its targets that were removed
+// from the file stop being probed and the new ones start, while the
+// others keep probing, and their statistics, with the new notifiers.
+// The targets added with `tcping ctl` or on the command line are left
+// alone, unless they're in the file.
+func (d *daemon) reload(cfg config) error {
+	wanted := make(map[string]targetConfig, len(cfg.Targets))
+	for _, target := range cfg.Targets {
+		wanted[targetKey(target.Hostname, target.Port)] = target
+	}
+
+	d.mu.Lock()
+	d.notifiers = cfg.Notifiers
+	for _, target := range d.targets {
+		target.notifiers.reload(cfg.Notifiers)
+	}
+
+	var removed, added []targetConfig
+	for key, target := range d.configTargets {
+		if _, ok := wanted[key]; !ok {
+			removed = append(removed, target)
+			delete(d.configTargets, key)
+		}
+	}
+	for key, target := range wanted {
+		d.configTargets[key] = target
+		if _, ok := d.targets[key]; !ok && !d.adding[key] {
+			added = append(added, target)
+		}
+	}
+	d.mu.Unlock()
+
+	for _, target := range removed {
+		// it might have been removed with `tcping ctl` already
+		d.remove(target.Hostname, target.Port)
+	}
+
+	var errs []error
+	for _, target := range added {
+		if err := d.add(target.Hostname, target.Port); err != nil {
+			errs = append(errs, fmt.Errorf("unable to probe %s: %w", targetKey(target.Hostname, target.Port), err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// list returns the sorted targets of the daemon.
+func (d *daemon) list() []string {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	keys := make([]string, 0, len(d.targets))
+	for key := range d.targets {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
+
+// statistics returns the statistics of the given target,
+// or of all targets if hostname is empty.
+func (d *daemon) statistics(hostname string, port uint16) ([]tcping.JSONData, error) {
+	keys := []string{targetKey(hostname, port)}
+	if hostname == "" {
+		keys = d.list()
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	var stats []tcping.JSONData
+	for _, key := range keys {
+		target, ok := d.targets[key]
+		if !ok {
+			return nil, fmt.Errorf("%s is not being probed", key)
+		}
+
+		data := tcping.NewStatisticsJSONData(target.pinger.Statistics())
+		data.Timestamp = time.Now()
+		stats = append(stats, data)
+	}
+
+	return stats, nil
+}
+
+// stop stops probing all the targets and closes the control socket.
+func (d *daemon) stop() {
+	d.stopOnce.Do(func() {
+		sdNotify("STOPPING=1")
+		// before listing the targets, so that the ones still being added aren't started
+		close(d.stopped)
+
+		for _, key := range d.list() {
+			host, port, _ := net.SplitHostPort(key)
+			p, _ := strconv.ParseUint(port, 10, 16)
+			d.remove(host, uint16(p))
+		}
+
+		if d.listener != nil {
+			d.listener.Close()
+		}
+	})
+}
+
+// runDaemon implements the `tcping daemon` subcommand.
+func runDaemon(args []string) {
+	flags := flag.NewFlagSet("daemon", flag.ExitOnError)
+	socketPath := flags.String("socket", defaultSocketPath, "path of the control socket.")
+	interval := flags.Float64("i", 1, "interval between sending probes, in seconds.")
+	timeout := flags.Float64("t", 1, "time to wait for a response, in seconds. 0 means infinite timeout.")
+	outputJSON := flags.Bool("j", false, "output in JSON format.")
+	configPath := flags.String("config", "", "path to a JSON configuration file with the targets and the notifiers, reloaded on SIGHUP.")
+	flags.Usage = func() {
+		colorRed("Usage: %s daemon [--socket <path>] [-i <seconds>] [-t <seconds>] [-j] [--config <path>] [<hostname/ip> <port number>]...\n", os.Args[0])
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+
+	// every pinger has a printer of its own, as they print concurrently
+	newPrinter := func() tcping.Printer {
+		if *outputJSON {
+			return tcping.NewJSONPrinter(false)
+		}
+		if underSystemd() {
+			return tcping.NewJournalPrinter()
+		}
+
+		return tcping.NewPlainPrinter()
+	}
+	printer := newPrinter()
+
+	// ping the systemd watchdog if it's enabled
+	var hooks tcping.Hooks
+	if interval := watchdogInterval(); interval > 0 {
+		hooks.OnProbe = (&watchdog{interval: interval}).ping
+	}
+
+	d := newDaemon(func(hostname string, port uint16, notifier tcping.Notifier) (*tcping.Pinger, error) {
+		return tcping.New(tcping.Options{
+			Printer:               newPrinter(),
+			Hostname:              hostname,
+			Port:                  port,
+			Timeout:               secondsToDuration(*timeout),
+			IntervalBetweenProbes: secondsToDuration(*interval),
+			Hooks:                 hooks,
+			Notifiers:             []tcping.Notifier{notifier},
+		})
+	})
+
+	if *configPath != "" {
+		cfg, err := loadConfig(*configPath)
+		if err != nil {
+			printer.PrintError("Invalid configuration file: %s", err)
+			os.Exit(1)
+		}
+
+		if err := d.reload(cfg); err != nil {
+			printer.PrintError("%s", err)
+			os.Exit(1)
+		}
+
+		reloadOnHangup(*configPath, printer, func(cfg config) {
+			if err := d.reload(cfg); err != nil {
+				printer.PrintError("%s", err)
+			}
+		})
+	}
+
+	// the targets can also be given on the command line
+	targets := flags.Args()
+	if len(targets)%2 != 0 {
+		flags.Usage()
+		os.Exit(1)
+	}
+	for i := 0; i < len(targets); i += 2 {
+		port, err := strconv.ParseUint(targets[i+1], 10, 16)
+		if err == nil {
+			err = d.add(targets[i], uint16(port))
+		}
+		if err != nil {
+			printer.PrintError("Unable to probe %s: %s", targetKey(targets[i], uint16(port)), err)
+			os.Exit(1)
+		}
+	}
+
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
+	go func() {
+		<-sigChan
+		d.stop()
+	}()
+
+	if err := d.listen(*socketPath); err != nil {
+		d.stop()
+		printer.PrintError("Unable to listen on %s: %s", *socketPath, err)
+		os.Exit(1)
+	}
+	os.Remove(*socketPath)
+}
+
+// sendCtlRequest sends a single request to the daemon and returns its response.
+func sendCtlRequest(socketPath string, req ctlRequest) (ctlResponse, error) {
+	var resp ctlResponse
+
+	conn, err := net.Dial("unix", socketPath)
+	if err != nil {
+		return resp, fmt.Errorf("unable to reach the daemon: %w", err)
+	}
+	defer conn.Close()
+
+	if err := json.NewEncoder(conn).Encode(req); err != nil {
+		return resp, err
+	}
+
+	if err := json.NewDecoder(bufio.NewReader(conn)).Decode(&resp); err != nil {
+		return resp, err
+	}
+
+	if resp.Error != "" {
+		return resp, errors.New(resp.Error)
+	}
+
+	return resp, nil
+}
+
+// runCtl implements the `tcping ctl` subcommand.
+func runCtl(args []string) {
+	flags := flag.NewFlagSet("ctl", flag.ExitOnError)
+	socketPath := flags.String("socket", defaultSocketPath, "path of the control socket.")
+	flags.Usage = func() {
+		colorRed("Usage: %s ctl [--socket <path>] <command>\n\n", os.Args[0])
+		colorYellow("Commands:\n")
+		colorYellow("  add <hostname/ip> <port number>    : start probing a target.\n")
+		colorYellow("  remove <hostname/ip> <port number> : stop probing a target.\n")
+		colorYellow("  list                               : list the targets.\n")
+		colorYellow("  stats [<hostname/ip> <port number>] : print the statistics of one or all targets.\n")
+		colorYellow("  stop                               : stop the daemon.\n")
+	}
+	flags.Parse(args)
+
+	args = flags.Args()
+	if len(args) == 0 {
+		flags.Usage()
+		os.Exit(1)
+	}
+
+	req := ctlRequest{Command: args[0]}
+	switch {
+	case (req.Command == "add" || req.Command == "remove") && len(args) == 3,
+		req.Command == "stats" && len(args) == 3:
+		port, err := strconv.ParseUint(args[2], 10, 16)
+		if err != nil || port == 0 {
+			colorRed("Invalid port number: %s\n", args[2])
+			os.Exit(1)
+		}
+		req.Hostname = args[1]
+		req.Port = uint16(port)
+	case (req.Command == "list" || req.Command == "stats" || req.Command == "stop") && len(args) == 1:
+	default:
+		flags.Usage()
+		os.Exit(1)
+	}
+
+	resp, err := sendCtlRequest(*socketPath, req)
+	if err != nil {
+		colorRed("%s\n", err)
+		os.Exit(1)
+	}
+
+	switch req.Command {
+	case "list":
+		for _, target := range resp.Targets {
+			fmt.Println(target)
+		}
+	case "stats":
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "\t")
+		encoder.Encode(resp.Statistics)
+	}
+}
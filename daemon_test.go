@@ -0,0 +1,194 @@
+package main
+
+import (
+	"net"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/pouriyajamshidi/tcping/v2/pkg/tcping"
+	"github.com/stretchr/testify/assert"
+)
+
+// discardPrinter is a printer that prints nothing.
+type discardPrinter struct{}
+
+func (p *discardPrinter) PrintStart(_ string, _ uint16)         {}
+func (p *discardPrinter) PrintProbe(_ tcping.Result)            {}
+func (p *discardPrinter) PrintStateChange(_ tcping.StateChange) {}
+func (p *discardPrinter) PrintRetryingToResolve(_ string)       {}
+func (p *discardPrinter) PrintStatistics(_ tcping.Statistics)   {}
+func (p *discardPrinter) PrintVersion()                         {}
+func (p *discardPrinter) PrintInfo(_ string, _ ...any)          {}
+func (p *discardPrinter) PrintError(_ string, _ ...any)         {}
+
+func TestDaemon(t *testing.T) {
+	srv, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("test server: %v", err)
+	}
+	t.Cleanup(func() { srv.Close() })
+	port := uint16(srv.Addr().(*net.TCPAddr).Port)
+
+	d := newDaemon(func(hostname string, port uint16, _ tcping.Notifier) (*tcping.Pinger, error) {
+		return tcping.New(tcping.Options{
+			Printer:               &discardPrinter{},
+			Hostname:              hostname,
+			Port:                  port,
+			Timeout:               time.Second,
+			IntervalBetweenProbes: 10 * time.Millisecond,
+		})
+	})
+
+	socketPath := filepath.Join(t.TempDir(), "tcping.sock")
+	done := make(chan error)
+	go func() { done <- d.listen(socketPath) }()
+
+	assert.Eventually(t, func() bool {
+		_, err := sendCtlRequest(socketPath, ctlRequest{Command: "list"})
+		return err == nil
+	}, time.Second, 10*time.Millisecond)
+
+	_, err = sendCtlRequest(socketPath, ctlRequest{Command: "add", Hostname: "127.0.0.1", Port: port})
+	assert.NoError(t, err)
+
+	_, err = sendCtlRequest(socketPath, ctlRequest{Command: "add", Hostname: "127.0.0.1", Port: port})
+	assert.Error(t, err, "adding the same target twice should fail")
+
+	target := net.JoinHostPort("127.0.0.1", strconv.Itoa(int(port)))
+	resp, err := sendCtlRequest(socketPath, ctlRequest{Command: "list"})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{target}, resp.Targets)
+
+	assert.Eventually(t, func() bool {
+		resp, err := sendCtlRequest(socketPath, ctlRequest{Command: "stats"})
+		return err == nil && len(resp.Statistics) == 1 && resp.Statistics[0].TotalSuccessfulProbes > 0
+	}, time.Second, 10*time.Millisecond)
+
+	_, err = sendCtlRequest(socketPath, ctlRequest{Command: "remove", Hostname: "127.0.0.1", Port: port})
+	assert.NoError(t, err)
+
+	_, err = sendCtlRequest(socketPath, ctlRequest{Command: "stats", Hostname: "127.0.0.1", Port: port})
+	assert.Error(t, err)
+
+	_, err = sendCtlRequest(socketPath, ctlRequest{Command: "unknown"})
+	assert.EqualError(t, err, `unknown command "unknown"`)
+
+	_, err = sendCtlRequest(socketPath, ctlRequest{Command: "stop"})
+	assert.NoError(t, err)
+
+	select {
+	case err := <-done:
+		assert.NoError(t, err)
+	case <-time.After(time.Second):
+		t.Fatal("the daemon didn't stop")
+	}
+}
+
+func TestDaemonReload(t *testing.T) {
+	d := newDaemon(func(hostname string, port uint16, _ tcping.Notifier) (*tcping.Pinger, error) {
+		return tcping.New(tcping.Options{
+			Printer:               &discardPrinter{},
+			Hostname:              hostname,
+			Port:                  port,
+			Timeout:               time.Second,
+			IntervalBetweenProbes: 10 * time.Millisecond,
+		})
+	})
+	t.Cleanup(d.stop)
+
+	assert.NoError(t, d.add("127.0.0.1", 3))
+	assert.NoError(t, d.reload(config{Targets: []targetConfig{
+		{Hostname: "127.0.0.1", Port: 1},
+		{Hostname: "127.0.0.1", Port: 2},
+	}}))
+	assert.Equal(t, []string{"127.0.0.1:1", "127.0.0.1:2", "127.0.0.1:3"}, d.list())
+
+	d.mu.Lock()
+	kept := d.targets["127.0.0.1:1"].pinger
+	d.mu.Unlock()
+
+	assert.NoError(t, d.reload(config{Targets: []targetConfig{
+		{Hostname: "127.0.0.1", Port: 1},
+		{Hostname: "127.0.0.1", Port: 4},
+	}}))
+	// the target added with ctl stays and the remaining one keeps its statistics
+	assert.Equal(t, []string{"127.0.0.1:1", "127.0.0.1:3", "127.0.0.1:4"}, d.list())
+	d.mu.Lock()
+	assert.Same(t, kept, d.targets["127.0.0.1:1"].pinger)
+	d.mu.Unlock()
+}
+
+func TestDaemonAddWithoutLock(t *testing.T) {
+	resolving := make(chan struct{})
+	resolved := make(chan struct{})
+	d := newDaemon(func(hostname string, port uint16, _ tcping.Notifier) (*tcping.Pinger, error) {
+		close(resolving)
+		<-resolved
+		return tcping.New(tcping.Options{
+			Printer:               &discardPrinter{},
+			Hostname:              hostname,
+			Port:                  port,
+			Timeout:               time.Second,
+			IntervalBetweenProbes: 10 * time.Millisecond,
+		})
+	})
+	t.Cleanup(d.stop)
+
+	added := make(chan error)
+	go func() { added <- d.add("127.0.0.1", 1) }()
+	<-resolving
+
+	// the other commands don't wait for the pinger to be created
+	assert.Empty(t, d.list())
+	assert.EqualError(t, d.add("127.0.0.1", 1), "127.0.0.1:1 is already being probed")
+	assert.NoError(t, d.reload(config{Targets: []targetConfig{{Hostname: "127.0.0.1", Port: 1}}}))
+
+	close(resolved)
+	assert.NoError(t, <-added)
+	assert.Equal(t, []string{"127.0.0.1:1"}, d.list())
+}
+
+func TestDaemonAddWhileStopping(t *testing.T) {
+	resolving := make(chan struct{})
+	resolved := make(chan struct{})
+	statistics := make(chan tcping.Statistics, 1)
+	d := newDaemon(func(hostname string, port uint16, _ tcping.Notifier) (*tcping.Pinger, error) {
+		close(resolving)
+		<-resolved
+		return tcping.New(tcping.Options{
+			Printer:               &discardPrinter{},
+			Hostname:              hostname,
+			Port:                  port,
+			Timeout:               time.Second,
+			IntervalBetweenProbes: 10 * time.Millisecond,
+			Notifiers:             []tcping.Notifier{statisticsRecorder(statistics)},
+		})
+	})
+
+	added := make(chan error)
+	go func() { added <- d.add("127.0.0.1", 1) }()
+	<-resolving
+
+	d.stop()
+	close(resolved)
+	assert.EqualError(t, <-added, "the daemon is stopping")
+	assert.Empty(t, d.list())
+
+	// the pinger that wasn't started has been shut down
+	select {
+	case <-statistics:
+	case <-time.After(time.Second):
+		t.Fatal("the pinger wasn't shut down")
+	}
+}
+
+// statisticsRecorder passes the final statistics of the pinger to the channel.
+type statisticsRecorder chan tcping.Statistics
+
+func (r statisticsRecorder) Notify(tcping.StateChange) {}
+
+func (r statisticsRecorder) NotifyStatistics(s tcping.Statistics) {
+	r <- s
+}
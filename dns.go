@@ -0,0 +1,203 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"time"
+
+	"github.com/pouriyajamshidi/tcping/v2/pkg/tcping"
+)
+
+// newDNSResolver returns a resolver that sends the queries to the given
+// DNS server instead of the ones configured on the system.
+// The port defaults to 53.
+func newDNSResolver(server string) (*net.Resolver, error) {
+	addr, err := withDefaultPort(server, "53")
+	if err != nil {
+		return nil, err
+	}
+
+	return &net.Resolver{
+		PreferGo: true,
+		Dial: func(ctx context.Context, network, _ string) (net.Conn, error) {
+			var d net.Dialer
+			return d.DialContext(ctx, network, addr)
+		},
+	}, nil
+}
+
+// withDefaultPort adds the port to the address if it doesn't have one.
+func withDefaultPort(addr, port string) (string, error) {
+	if _, _, err := net.SplitHostPort(addr); err == nil {
+		return addr, nil
+	}
+
+	// a bare IPv6 address might be wrapped in brackets
+	host := addr
+	if len(host) > 1 && host[0] == '[' && host[len(host)-1] == ']' {
+		host = host[1 : len(host)-1]
+	}
+
+	if host == "" {
+		return "", &net.AddrError{Err: "missing address", Addr: addr}
+	}
+
+	return net.JoinHostPort(host, port), nil
+}
+
+const (
+	// dohContentType is the media type of the DNS messages sent over HTTPS, see RFC 8484.
+	dohContentType = "application/dns-message"
+	// dnsQueryTimeout limits a single query to an encrypted DNS server.
+	dnsQueryTimeout = 5 * time.Second
+)
+
+// newDoHResolver returns a resolver that sends the queries
+// to the given DNS-over-HTTPS endpoint.
+func newDoHResolver(endpoint string) (*net.Resolver, error) {
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return nil, err
+	}
+
+	if u.Scheme != "https" || u.Host == "" {
+		return nil, fmt.Errorf("%s is not an https URL", endpoint)
+	}
+
+	return dohResolver(&http.Client{Timeout: dnsQueryTimeout}, endpoint), nil
+}
+
+// dohResolver returns a resolver that POSTs the queries to the endpoint with the client.
+func dohResolver(client *http.Client, endpoint string) *net.Resolver {
+	return &net.Resolver{
+		PreferGo: true,
+		Dial: func(ctx context.Context, _, _ string) (net.Conn, error) {
+			return &dohConn{ctx: ctx, client: client, url: endpoint}, nil
+		},
+	}
+}
+
+// dohConn lets net.Resolver talk to a DNS-over-HTTPS server.
+//
+// Since it's not a net.PacketConn, the resolver writes the queries
+// prefixed with their length, as over TCP. Every query is POSTed
+// to the server and the answer is read back the same way.
+type dohConn struct {
+	ctx    context.Context
+	client *http.Client
+	url    string
+	query  bytes.Buffer
+	answer bytes.Reader
+}
+
+func (c *dohConn) Write(b []byte) (int, error) {
+	return c.query.Write(b)
+}
+
+func (c *dohConn) Read(b []byte) (int, error) {
+	if c.answer.Len() == 0 {
+		if err := c.roundTrip(); err != nil {
+			return 0, err
+		}
+	}
+
+	return c.answer.Read(b)
+}
+
+// roundTrip sends the pending query and buffers the answer.
+func (c *dohConn) roundTrip() error {
+	if c.query.Len() < 2 {
+		return io.ErrUnexpectedEOF
+	}
+
+	length := int(binary.BigEndian.Uint16(c.query.Bytes()))
+	if c.query.Len() < 2+length {
+		return io.ErrUnexpectedEOF
+	}
+
+	msg := c.query.Next(2 + length)[2:]
+
+	req, err := http.NewRequestWithContext(c.ctx, http.MethodPost, c.url, bytes.NewReader(msg))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", dohContentType)
+	req.Header.Set("Accept", dohContentType)
+	req.Header.Set("User-Agent", "tcping/"+tcping.Version)
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected response status %q from %s", resp.Status, c.url)
+	}
+
+	answer, err := io.ReadAll(io.LimitReader(resp.Body, 65535))
+	if err != nil {
+		return err
+	}
+
+	framed := make([]byte, 2+len(answer))
+	binary.BigEndian.PutUint16(framed, uint16(len(answer)))
+	copy(framed[2:], answer)
+	c.answer.Reset(framed)
+
+	return nil
+}
+
+func (c *dohConn) Close() error                       { return nil }
+func (c *dohConn) LocalAddr() net.Addr                { return nil }
+func (c *dohConn) RemoteAddr() net.Addr               { return nil }
+func (c *dohConn) SetDeadline(_ time.Time) error      { return nil }
+func (c *dohConn) SetReadDeadline(_ time.Time) error  { return nil }
+func (c *dohConn) SetWriteDeadline(_ time.Time) error { return nil }
+
+// newDoTResolver returns a resolver that sends the queries to the given
+// DNS-over-TLS server, after validating its certificate.
+// The port defaults to 853.
+func newDoTResolver(server string) (*net.Resolver, error) {
+	addr, err := withDefaultPort(server, "853")
+	if err != nil {
+		return nil, err
+	}
+
+	host, _, _ := net.SplitHostPort(addr)
+
+	return dotResolver(addr, &tls.Config{ServerName: host}), nil
+}
+
+// dotResolver returns a resolver that sends the queries over TLS to addr.
+func dotResolver(addr string, config *tls.Config) *net.Resolver {
+	return &net.Resolver{
+		PreferGo: true,
+		// tls.Conn is not a net.PacketConn, so the queries are framed as over TCP
+		Dial: func(ctx context.Context, _, _ string) (net.Conn, error) {
+			d := tls.Dialer{
+				NetDialer: &net.Dialer{Timeout: dnsQueryTimeout},
+				Config:    config,
+			}
+			return d.DialContext(ctx, "tcp", addr)
+		},
+	}
+}
+
+// setQuery returns the query sent by the dns prober.
+func setQuery(opts *tcping.Options, probe, name, qtype *string, udp *bool) tcping.DNSConfig {
+	if *probe != "dns" && (*name != "" || *qtype != "" || *udp) {
+		opts.Printer.PrintError("--query, --query-type and --query-udp can only be used with --probe dns.")
+		os.Exit(1)
+	}
+
+	return tcping.DNSConfig{Name: *name, Type: *qtype, UDP: *udp}
+}
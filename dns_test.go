@@ -0,0 +1,212 @@
+package main
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/binary"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/net/dns/dnsmessage"
+)
+
+// testDNSAddr is the address the test DNS servers answer with.
+var testDNSAddr = netip.MustParseAddr("192.0.2.1")
+
+// newTestDNSAnswer answers the A queries with testDNSAddr
+// and the rest of them with no records.
+func newTestDNSAnswer(query []byte) ([]byte, error) {
+	var msg dnsmessage.Message
+	if err := msg.Unpack(query); err != nil {
+		return nil, err
+	}
+
+	msg.Header.Response = true
+	msg.Header.RecursionAvailable = true
+	for _, q := range msg.Questions {
+		if q.Type != dnsmessage.TypeA {
+			continue
+		}
+
+		msg.Answers = append(msg.Answers, dnsmessage.Resource{
+			Header: dnsmessage.ResourceHeader{Name: q.Name, Type: q.Type, Class: q.Class, TTL: 60},
+			Body:   &dnsmessage.AResource{A: testDNSAddr.As4()},
+		})
+	}
+
+	return msg.Pack()
+}
+
+// serveTestDNS starts a DNS server over UDP and returns its address.
+func serveTestDNS(t *testing.T) string {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	go func() {
+		buf := make([]byte, 512)
+		for {
+			n, addr, err := conn.ReadFrom(buf)
+			if err != nil {
+				return
+			}
+
+			answer, err := newTestDNSAnswer(buf[:n])
+			if err == nil {
+				conn.WriteTo(answer, addr)
+			}
+		}
+	}()
+
+	return conn.LocalAddr().String()
+}
+
+func TestDNSResolver(t *testing.T) {
+	resolver, err := newDNSResolver(serveTestDNS(t))
+	assert.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	addrs, err := resolver.LookupNetIP(ctx, "ip", "example.test")
+	assert.NoError(t, err)
+	assert.Equal(t, []netip.Addr{testDNSAddr}, addrs)
+}
+
+func TestWithDefaultPort(t *testing.T) {
+	tests := []struct {
+		addr string
+		want string
+	}{
+		{addr: "1.1.1.1", want: "1.1.1.1:53"},
+		{addr: "1.1.1.1:5353", want: "1.1.1.1:5353"},
+		{addr: "2606:4700:4700::1111", want: "[2606:4700:4700::1111]:53"},
+		{addr: "[2606:4700:4700::1111]", want: "[2606:4700:4700::1111]:53"},
+		{addr: "[2606:4700:4700::1111]:5353", want: "[2606:4700:4700::1111]:5353"},
+		{addr: "dns.example.com", want: "dns.example.com:53"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.addr, func(t *testing.T) {
+			got, err := withDefaultPort(tt.addr, "53")
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+
+	_, err := withDefaultPort("", "53")
+	assert.Error(t, err)
+}
+
+func TestDoHResolver(t *testing.T) {
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, dohContentType, r.Header.Get("Content-Type"))
+
+		query, err := io.ReadAll(r.Body)
+		assert.NoError(t, err)
+
+		answer, err := newTestDNSAnswer(query)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		w.Header().Set("Content-Type", dohContentType)
+		w.Write(answer)
+	}))
+	defer srv.Close()
+
+	resolver := dohResolver(srv.Client(), srv.URL)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	addrs, err := resolver.LookupNetIP(ctx, "ip", "example.test")
+	assert.NoError(t, err)
+	assert.Equal(t, []netip.Addr{testDNSAddr}, addrs)
+}
+
+func TestNewDoHResolver(t *testing.T) {
+	_, err := newDoHResolver("https://cloudflare-dns.com/dns-query")
+	assert.NoError(t, err)
+
+	for _, endpoint := range []string{"http://cloudflare-dns.com/dns-query", "cloudflare-dns.com", "https://"} {
+		_, err := newDoHResolver(endpoint)
+		assert.Error(t, err, endpoint)
+	}
+}
+
+// serveTestDoT starts a DNS-over-TLS server and returns
+// its address and the config trusting its certificate.
+func serveTestDoT(t *testing.T) (string, *tls.Config) {
+	// borrow the certificate of an httptest server
+	srv := httptest.NewTLSServer(http.NotFoundHandler())
+	t.Cleanup(srv.Close)
+
+	ln, err := tls.Listen("tcp", "127.0.0.1:0", &tls.Config{Certificates: srv.TLS.Certificates})
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	t.Cleanup(func() { ln.Close() })
+
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+
+			go func() {
+				defer conn.Close()
+				for {
+					var length uint16
+					if err := binary.Read(conn, binary.BigEndian, &length); err != nil {
+						return
+					}
+
+					query := make([]byte, length)
+					if _, err := io.ReadFull(conn, query); err != nil {
+						return
+					}
+
+					answer, err := newTestDNSAnswer(query)
+					if err != nil {
+						return
+					}
+
+					binary.Write(conn, binary.BigEndian, uint16(len(answer)))
+					conn.Write(answer)
+				}
+			}()
+		}
+	}()
+
+	config := srv.Client().Transport.(*http.Transport).TLSClientConfig.Clone()
+	config.ServerName = "127.0.0.1"
+
+	return ln.Addr().String(), config
+}
+
+func TestDoTResolver(t *testing.T) {
+	addr, config := serveTestDoT(t)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	addrs, err := dotResolver(addr, config).LookupNetIP(ctx, "ip", "example.test")
+	assert.NoError(t, err)
+	assert.Equal(t, []netip.Addr{testDNSAddr}, addrs)
+
+	// the certificate is not valid for another name
+	config.ServerName = "dns.example.org"
+	_, err = dotResolver(addr, config).LookupNetIP(ctx, "ip", "example.test")
+	assert.Error(t, err)
+}
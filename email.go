@@ -0,0 +1,203 @@
+package main
+
+import (
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/smtp"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pouriyajamshidi/tcping/v2/pkg/tcping"
+)
+
+const emailTimeout = 10 * time.Second
+
+// emailNotifier sends an email once the target has been down for
+// longer than the threshold, and another one when it recovers.
+type emailNotifier struct {
+	cfg *emailConfig
+	// downSince is the time of the first failed probe of the current outage.
+	downSince time.Time
+	pending   sync.WaitGroup
+	threshold time.Duration
+	// failedProbes is the number of failed probes during the current outage.
+	failedProbes uint
+	// alerted means the email about the current outage has been sent,
+	// so the recovery email should be sent as well.
+	alerted bool
+}
+
+func newEmailNotifier(cfg *emailConfig) *emailNotifier {
+	return &emailNotifier{
+		cfg:       cfg,
+		threshold: secondsToDuration(cfg.DowntimeThresholdSeconds),
+	}
+}
+
+func (n *emailNotifier) Notify(change tcping.StateChange) {
+	// only the outages are emailed, a degradation, a trend or
+	// outliers mustn't start or end the current outage
+	if change.Degraded || change.Trend || change.Outlier {
+		return
+	}
+
+	if !change.Up {
+		n.downSince = change.When
+		n.failedProbes = 0
+		n.alerted = false
+		return
+	}
+
+	if !n.alerted {
+		return
+	}
+	n.alerted = false
+
+	subject := fmt.Sprintf("[UP] TCPING: %s", change.Target())
+	body := fmt.Sprintf("%s.\r\n\r\n"+
+		"outage started at: %s\r\n"+
+		"outage ended at:   %s\r\n"+
+		"outage duration:   %s\r\n"+
+		"failed probes:     %d\r\n"+
+		"recovery rtt:      %.3f ms\r\n",
+		change.Summary(),
+		n.downSince.Format(time.DateTime),
+		change.When.Format(time.DateTime),
+		tcping.DurationToString(change.Downtime),
+		n.failedProbes,
+		change.RTT,
+	)
+
+	n.send(subject, body)
+}
+
+func (n *emailNotifier) NotifyProbe(r tcping.Result) {
+	if r.Success || n.downSince.IsZero() {
+		return
+	}
+	n.failedProbes++
+
+	downtime := time.Since(n.downSince)
+	if n.alerted || downtime < n.threshold {
+		return
+	}
+	n.alerted = true
+
+	target := tcping.StateChange{Hostname: r.Hostname, IP: r.IP.String(), Port: r.Port}.Target()
+	subject := fmt.Sprintf("[DOWN] TCPING: %s", target)
+	body := fmt.Sprintf("%s is not responding.\r\n\r\n"+
+		"outage started at: %s\r\n"+
+		"down for:          %s\r\n"+
+		"failed probes:     %d\r\n",
+		target,
+		n.downSince.Format(time.DateTime),
+		tcping.DurationToString(downtime),
+		n.failedProbes,
+	)
+
+	n.send(subject, body)
+}
+
+func (n *emailNotifier) NotifyStatistics(_ tcping.Statistics) {
+	n.pending.Wait()
+}
+
+// send delivers the email in the background and reports the failure on stderr,
+// so that it doesn't interfere with the printer's output.
+func (n *emailNotifier) send(subject, body string) {
+	n.pending.Add(1)
+	go func() {
+		defer n.pending.Done()
+
+		if err := sendMail(n.cfg, subject, body); err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to send the email notification: %s\n", err)
+		}
+	}()
+}
+
+// sendMail sends a plain text email to all the recipients.
+//
+// Unless ImplicitTLS is set, the connection is upgraded
+// with STARTTLS whenever the server supports it.
+func sendMail(cfg *emailConfig, subject, body string) error {
+	addr := net.JoinHostPort(cfg.Host, strconv.Itoa(int(cfg.Port)))
+	tlsConfig := &tls.Config{ServerName: cfg.Host}
+
+	var conn net.Conn
+	var err error
+	dialer := &net.Dialer{Timeout: emailTimeout}
+
+	if cfg.ImplicitTLS {
+		conn, err = tls.DialWithDialer(dialer, "tcp", addr, tlsConfig)
+	} else {
+		conn, err = dialer.Dial("tcp", addr)
+	}
+	if err != nil {
+		return err
+	}
+	conn.SetDeadline(time.Now().Add(emailTimeout))
+
+	c, err := smtp.NewClient(conn, cfg.Host)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	defer c.Close()
+
+	if ok, _ := c.Extension("STARTTLS"); ok && !cfg.ImplicitTLS {
+		if err := c.StartTLS(tlsConfig); err != nil {
+			return err
+		}
+	}
+
+	if cfg.Username != "" {
+		if err := c.Auth(smtp.PlainAuth("", cfg.Username, cfg.Password, cfg.Host)); err != nil {
+			return err
+		}
+	}
+
+	if err := c.Mail(cfg.From); err != nil {
+		return err
+	}
+
+	for _, to := range cfg.To {
+		if err := c.Rcpt(to); err != nil {
+			return err
+		}
+	}
+
+	w, err := c.Data()
+	if err != nil {
+		return err
+	}
+
+	if _, err := w.Write(newMailMessage(cfg, subject, body, time.Now())); err != nil {
+		return err
+	}
+
+	if err := w.Close(); err != nil {
+		return err
+	}
+
+	return c.Quit()
+}
+
+// newMailMessage formats the headers and the body of the email.
+func newMailMessage(cfg *emailConfig, subject, body string, date time.Time) []byte {
+	var msg strings.Builder
+
+	fmt.Fprintf(&msg, "From: %s\r\n", cfg.From)
+	fmt.Fprintf(&msg, "To: %s\r\n", strings.Join(cfg.To, ", "))
+	fmt.Fprintf(&msg, "Subject: %s\r\n", subject)
+	fmt.Fprintf(&msg, "Date: %s\r\n", date.Format(time.RFC1123Z))
+	msg.WriteString("MIME-Version: 1.0\r\n")
+	msg.WriteString("Content-Type: text/plain; charset=UTF-8\r\n")
+	msg.WriteString("\r\n")
+	msg.WriteString(body)
+
+	return []byte(msg.String())
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"net/netip"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pouriyajamshidi/tcping/v2/pkg/tcping"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewMailMessage(t *testing.T) {
+	cfg := &emailConfig{
+		From: "tcping@example.com",
+		To:   []string{"a@example.com", "b@example.com"},
+	}
+	date := time.Date(2023, 9, 10, 12, 30, 0, 0, time.UTC)
+
+	msg := string(newMailMessage(cfg, "[DOWN] TCPING: example.com", "body\r\n", date))
+
+	headers, body, found := strings.Cut(msg, "\r\n\r\n")
+	assert.True(t, found)
+	assert.Equal(t, "body\r\n", body)
+	assert.Contains(t, headers, "From: tcping@example.com\r\n")
+	assert.Contains(t, headers, "To: a@example.com, b@example.com\r\n")
+	assert.Contains(t, headers, "Subject: [DOWN] TCPING: example.com\r\n")
+	assert.Contains(t, headers, "Date: Sun, 10 Sep 2023 12:30:00 +0000\r\n")
+}
+
+func TestEmailNotifierThreshold(t *testing.T) {
+	n := newEmailNotifier(&emailConfig{DowntimeThresholdSeconds: 60})
+
+	n.Notify(tcping.StateChange{When: time.Now().Add(-30 * time.Second)})
+	n.NotifyProbe(tcping.Result{Hostname: "example.com", IP: netip.MustParseAddr("93.184.216.34"), Port: 443})
+	assert.False(t, n.alerted, "should not alert before reaching the threshold")
+	assert.Equal(t, uint(1), n.failedProbes)
+
+	// recovering before the threshold should not send anything either
+	n.Notify(tcping.StateChange{When: time.Now(), Up: true})
+	assert.False(t, n.alerted)
+}
+
+func TestEmailNotifierNotOutage(t *testing.T) {
+	n := newEmailNotifier(&emailConfig{DowntimeThresholdSeconds: 60})
+	downSince := time.Now().Add(-30 * time.Second)
+
+	n.Notify(tcping.StateChange{When: downSince})
+	n.alerted = true
+
+	// the degradations, the trends and the outliers don't start or end the outage
+	n.Notify(tcping.StateChange{When: time.Now(), Degraded: true})
+	n.Notify(tcping.StateChange{When: time.Now(), Up: true, Degraded: true})
+	n.Notify(tcping.StateChange{When: time.Now(), Trend: true})
+	n.Notify(tcping.StateChange{When: time.Now(), Up: true, Trend: true})
+	n.Notify(tcping.StateChange{When: time.Now(), Outlier: true})
+	n.Notify(tcping.StateChange{When: time.Now(), Up: true, Outlier: true})
+	assert.Equal(t, downSince, n.downSince)
+	assert.True(t, n.alerted)
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/netip"
+	"os"
+
+	"github.com/pouriyajamshidi/tcping/v2/pkg/tcping"
+)
+
+// fastestIPProbes is the number of probes sent to every
+// address of the target with --fastest-ip before picking one.
+const fastestIPProbes = 3
+
+// setFastestIP probes every address of the target briefly, prints
+// how they compare and pins the one with the lowest average RTT.
+// Nothing is pinned if the target has a single address or none answered.
+func setFastestIP(opts *tcping.Options, fastestIP *bool) {
+	if !*fastestIP {
+		return
+	}
+
+	stats, err := tcping.CompareAddrs(context.Background(), *opts, fastestIPProbes)
+	if err != nil {
+		opts.Printer.PrintError("%s", err)
+		os.Exit(1)
+	}
+	if len(stats) < 2 {
+		return
+	}
+
+	for _, line := range formatComparison(stats) {
+		opts.Printer.PrintInfo("%s", line)
+	}
+
+	if !stats[0].RttResults.HasResults {
+		opts.Printer.PrintInfo("None of the addresses of %s answered, probing them as usual", opts.Hostname)
+		return
+	}
+	opts.Hosts = map[string][]netip.Addr{opts.Hostname: {stats[0].IP}}
+}
+
+// formatComparison formats the statistics of the addresses compared
+// with --fastest-ip, one line per address, the selected one first.
+func formatComparison(stats []tcping.Statistics) []string {
+	width := 0
+	for _, s := range stats {
+		width = max(width, len(s.IP.String()))
+	}
+
+	lines := []string{fmt.Sprintf("Compared the addresses of %s on port %d with %d probes each:",
+		stats[0].Hostname, stats[0].Port, fastestIPProbes)}
+	for i, s := range stats {
+		line := fmt.Sprintf("  %-*s  %.2f%% packet loss", width, s.IP, packetLoss(s.TotalSuccessfulProbes, s.TotalUnsuccessfulProbes))
+		if r := s.RttResults; r.HasResults {
+			line += fmt.Sprintf(", rtt min/avg/max %.3f/%.3f/%.3f ms", r.Min, r.Average, r.Max)
+		}
+		if i == 0 && s.RttResults.HasResults {
+			line += " (selected)"
+		}
+		lines = append(lines, line)
+	}
+
+	return lines
+}
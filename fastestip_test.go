@@ -0,0 +1,30 @@
+package main
+
+import (
+	"net/netip"
+	"testing"
+
+	"github.com/pouriyajamshidi/tcping/v2/pkg/tcping"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFormatComparison(t *testing.T) {
+	stats := []tcping.Statistics{
+		{
+			Hostname: "www.example.test", Port: 443, IP: netip.MustParseAddr("192.0.2.1"),
+			TotalSuccessfulProbes: 3,
+			RttResults:            tcping.RttResult{Min: 10, Average: 12, Max: 15, HasResults: true},
+		},
+		{
+			Hostname: "www.example.test", Port: 443, IP: netip.MustParseAddr("2001:db8::1"),
+			TotalSuccessfulProbes: 2, TotalUnsuccessfulProbes: 1,
+			RttResults: tcping.RttResult{Min: 80, Average: 85, Max: 90, HasResults: true},
+		},
+	}
+
+	assert.Equal(t, []string{
+		"Compared the addresses of www.example.test on port 443 with 3 probes each:",
+		"  192.0.2.1    0.00% packet loss, rtt min/avg/max 10.000/12.000/15.000 ms (selected)",
+		"  2001:db8::1  33.33% packet loss, rtt min/avg/max 80.000/85.000/90.000 ms",
+	}, formatComparison(stats))
+}
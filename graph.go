@@ -0,0 +1,178 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/pouriyajamshidi/tcping/v2/pkg/tcping"
+)
+
+// the size of the graph drawn with --graph, in characters.
+// Every character is a braille cell of 2x4 dots.
+const (
+	graphWidth  = 60
+	graphHeight = 8
+)
+
+// brailleDots are the bits of the dots of a braille cell,
+// by column and by row from the top.
+var brailleDots = [2][4]rune{
+	{0x01, 0x02, 0x04, 0x40},
+	{0x08, 0x10, 0x20, 0x80},
+}
+
+// graphColumn is a column of dots of the graph, made of
+// the probes of the run between the previous column and the next.
+type graphColumn struct {
+	probes int
+	failed int
+	rttSum float64
+}
+
+// rttGraph collects the RTTs of the probes to draw them
+// in the terminal at the end of the run, like gping.
+//
+// However long the run is, the graph keeps at most two columns of dots
+// per character: every time it would overflow, adjacent columns are merged,
+// and each column holds twice as many probes from then on.
+// It's safe for concurrent use.
+type rttGraph struct {
+	mu      sync.Mutex
+	columns []graphColumn
+	// perColumn is the number of probes of a full column.
+	perColumn int
+}
+
+// newRTTGraph returns an empty graph.
+func newRTTGraph() *rttGraph {
+	return &rttGraph{perColumn: 1}
+}
+
+// setGraph collects the probes of all the pingers for the graph
+// drawn at the end of the run, nil without --graph.
+func setGraph(opts *tcping.Options, graph *bool) *rttGraph {
+	if !*graph {
+		return nil
+	}
+
+	g := newRTTGraph()
+	addHooks(opts, tcping.Hooks{OnProbe: g.record})
+	return g
+}
+
+// record adds the probe to the graph.
+// It is meant to be used as the OnProbe hook.
+func (g *rttGraph) record(r tcping.Result) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if n := len(g.columns); n == 0 || g.columns[n-1].probes == g.perColumn {
+		if n == 2*graphWidth {
+			g.merge()
+		}
+		g.columns = append(g.columns, graphColumn{})
+	}
+
+	c := &g.columns[len(g.columns)-1]
+	c.probes++
+	if r.Success {
+		c.rttSum += float64(r.RTT)
+	} else {
+		c.failed++
+	}
+}
+
+// merge merges the columns pairwise, halving their number.
+func (g *rttGraph) merge() {
+	merged := g.columns[:0]
+	for i := 0; i < len(g.columns); i += 2 {
+		c := g.columns[i]
+		if i+1 < len(g.columns) {
+			next := g.columns[i+1]
+			c.probes += next.probes
+			c.failed += next.failed
+			c.rttSum += next.rttSum
+		}
+		merged = append(merged, c)
+	}
+
+	g.columns = merged
+	g.perColumn *= 2
+}
+
+// lines draws the average RTT of every column as bars of braille dots,
+// scaled to the highest one, above an axis marking the failed probes with ×.
+// It returns nil if no probe succeeded.
+func (g *rttGraph) lines() []string {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	var maxRTT float64
+	probes := 0
+	for _, c := range g.columns {
+		probes += c.probes
+		if c.probes > c.failed {
+			maxRTT = max(maxRTT, c.rttSum/float64(c.probes-c.failed))
+		}
+	}
+	if maxRTT == 0 {
+		return nil
+	}
+
+	// the number of dots of every column, at least one if it has RTTs
+	levels := make([]int, len(g.columns))
+	for i, c := range g.columns {
+		if c.probes > c.failed {
+			rtt := c.rttSum / float64(c.probes-c.failed)
+			levels[i] = max(int(rtt/maxRTT*graphHeight*4+0.5), 1)
+		}
+	}
+
+	top, bottom := fmt.Sprintf("%.3f ms", maxRTT), "0 ms"
+	width := max(len(top), len(bottom))
+
+	lines := []string{fmt.Sprintf("rtt over the run, %d probes:", probes)}
+	for row := 0; row < graphHeight; row++ {
+		label := ""
+		switch row {
+		case 0:
+			label = top
+		case graphHeight - 1:
+			label = bottom
+		}
+
+		var b strings.Builder
+		for cell := 0; cell < (len(levels)+1)/2; cell++ {
+			dots := rune(0x2800)
+			for col := 0; col < 2; col++ {
+				i := cell*2 + col
+				if i >= len(levels) {
+					break
+				}
+				for dot := 0; dot < 4; dot++ {
+					// the dots are counted from the bottom of the graph
+					if (graphHeight-1-row)*4+(3-dot) < levels[i] {
+						dots |= brailleDots[col][dot]
+					}
+				}
+			}
+			b.WriteRune(dots)
+		}
+		lines = append(lines, fmt.Sprintf("%*s ┤%s", width, label, b.String()))
+	}
+
+	var axis strings.Builder
+	for cell := 0; cell < (len(g.columns)+1)/2; cell++ {
+		mark := "─"
+		for i := cell * 2; i < min(cell*2+2, len(g.columns)); i++ {
+			if g.columns[i].failed > 0 {
+				mark = "×"
+			}
+		}
+		axis.WriteString(mark)
+	}
+	lines = append(lines, fmt.Sprintf("%*s └%s", width, "", axis.String()))
+
+	return lines
+}
@@ -0,0 +1,46 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/pouriyajamshidi/tcping/v2/pkg/tcping"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRTTGraph(t *testing.T) {
+	g := newRTTGraph()
+	assert.Nil(t, g.lines())
+
+	g.record(tcping.Result{Success: true, RTT: 10})
+	g.record(tcping.Result{Success: true, RTT: 5})
+	g.record(tcping.Result{FailureReason: tcping.FailureTimeout})
+
+	lines := g.lines()
+	if assert.Len(t, lines, graphHeight+2) {
+		assert.Equal(t, "rtt over the run, 3 probes:", lines[0])
+		// the first column is full, the second one half full
+		// and the third one, the failed probe, empty
+		assert.Equal(t, "10.000 ms ┤⡇⠀", lines[1])
+		assert.Equal(t, "          ┤⡇⠀", lines[4])
+		assert.Equal(t, "          ┤⣿⠀", lines[5])
+		assert.Equal(t, "     0 ms ┤⣿⠀", lines[graphHeight])
+		assert.Equal(t, "          └─×", lines[graphHeight+1])
+	}
+}
+
+func TestRTTGraphMerge(t *testing.T) {
+	g := newRTTGraph()
+	for i := 0; i < 2*graphWidth+1; i++ {
+		g.record(tcping.Result{Success: true, RTT: float32(i)})
+	}
+
+	// the columns were merged pairwise to make room for the last probe
+	assert.Len(t, g.columns, graphWidth+1)
+	assert.Equal(t, 2, g.perColumn)
+	assert.Equal(t, graphColumn{probes: 2, rttSum: 1}, g.columns[0])
+	assert.Equal(t, graphColumn{probes: 1, rttSum: 2 * graphWidth}, g.columns[graphWidth])
+
+	// the width of "120.000 ms", a space, the corner and a character per two columns
+	lines := g.lines()
+	assert.Len(t, []rune(lines[graphHeight+1]), 10+1+1+(graphWidth+2)/2)
+}
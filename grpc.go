@@ -0,0 +1,189 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"os"
+	"sync"
+
+	"github.com/pouriyajamshidi/tcping/v2/pkg/tcping"
+	"github.com/pouriyajamshidi/tcping/v2/pkg/tcpingpb"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// grpcSubscriberBuffer is the number of events buffered for every stream.
+// The events are dropped for the streams that fall behind,
+// so that a slow client never delays the probes.
+const grpcSubscriberBuffer = 64
+
+// grpcServer streams the probes and the state changes
+// and serves the statistics over gRPC.
+type grpcServer struct {
+	tcpingpb.UnimplementedTCPingServer
+
+	// statistics returns the current statistics.
+	statistics  func() tcping.Statistics
+	addr        string
+	subscribers map[chan *tcpingpb.Event]bool
+	mu          sync.Mutex
+}
+
+func newGRPCServer(addr string) *grpcServer {
+	return &grpcServer{
+		addr:        addr,
+		subscribers: make(map[chan *tcpingpb.Event]bool),
+	}
+}
+
+// listen starts serving in the background.
+// It only returns an error if the address can't be listened on.
+func (s *grpcServer) listen(statistics func() tcping.Statistics) error {
+	s.statistics = statistics
+
+	ln, err := net.Listen("tcp", s.addr)
+	if err != nil {
+		return err
+	}
+
+	srv := grpc.NewServer()
+	tcpingpb.RegisterTCPingServer(srv, s)
+
+	go func() {
+		if err := srv.Serve(ln); err != nil {
+			fmt.Fprintf(os.Stderr, "The gRPC server has stopped: %s\n", err)
+		}
+	}()
+
+	return nil
+}
+
+// publishProbe sends the probe to the streams.
+// It is meant to be used as the OnProbe hook.
+func (s *grpcServer) publishProbe(r tcping.Result) {
+	s.publish(&tcpingpb.Event{
+		Event: &tcpingpb.Event_Probe{Probe: &tcpingpb.Probe{
+			Time:     timestamppb.New(r.Time),
+			Hostname: r.Hostname,
+			Ip:       r.IP.String(),
+			Port:     uint32(r.Port),
+			Success:  r.Success,
+			RttMs:    r.RTT,
+			Streak:   uint64(r.Streak),
+		}},
+	})
+}
+
+// publishStateChange sends the state change to the streams.
+// It is meant to be used as the OnStateChange hook.
+func (s *grpcServer) publishStateChange(change tcping.StateChange) {
+	sc := &tcpingpb.StateChange{
+		Time:     timestamppb.New(change.When),
+		Hostname: change.Hostname,
+		Ip:       change.IP,
+		Port:     uint32(change.Port),
+		Up:       change.Up,
+		RttMs:    change.RTT,
+	}
+	if change.Up {
+		sc.Downtime = durationpb.New(change.Downtime)
+	}
+
+	s.publish(&tcpingpb.Event{
+		Event: &tcpingpb.Event_StateChange{StateChange: sc},
+	})
+}
+
+func (s *grpcServer) publish(event *tcpingpb.Event) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for ch, stateChangesOnly := range s.subscribers {
+		if stateChangesOnly && event.GetStateChange() == nil {
+			continue
+		}
+
+		select {
+		case ch <- event:
+		default:
+			// the client is too slow, drop the event
+		}
+	}
+}
+
+func (s *grpcServer) subscribe(stateChangesOnly bool) chan *tcpingpb.Event {
+	ch := make(chan *tcpingpb.Event, grpcSubscriberBuffer)
+
+	s.mu.Lock()
+	s.subscribers[ch] = stateChangesOnly
+	s.mu.Unlock()
+
+	return ch
+}
+
+func (s *grpcServer) unsubscribe(ch chan *tcpingpb.Event) {
+	s.mu.Lock()
+	delete(s.subscribers, ch)
+	s.mu.Unlock()
+}
+
+func (s *grpcServer) StreamEvents(req *tcpingpb.StreamEventsRequest, stream tcpingpb.TCPing_StreamEventsServer) error {
+	ch := s.subscribe(req.GetStateChangesOnly())
+	defer s.unsubscribe(ch)
+
+	for {
+		select {
+		case <-stream.Context().Done():
+			return nil
+		case event := <-ch:
+			if err := stream.Send(event); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func (s *grpcServer) GetStatistics(_ context.Context, _ *tcpingpb.GetStatisticsRequest) (*tcpingpb.Statistics, error) {
+	return newStatisticsMessage(s.statistics()), nil
+}
+
+// newStatisticsMessage converts the statistics to their gRPC representation.
+func newStatisticsMessage(s tcping.Statistics) *tcpingpb.Statistics {
+	totalPackets := s.TotalSuccessfulProbes + s.TotalUnsuccessfulProbes
+
+	msg := &tcpingpb.Statistics{
+		Hostname:                s.Hostname,
+		Ip:                      s.IP.String(),
+		Port:                    uint32(s.Port),
+		StartTime:               timestamppb.New(s.StartTime),
+		TotalSuccessfulProbes:   uint64(s.TotalSuccessfulProbes),
+		TotalUnsuccessfulProbes: uint64(s.TotalUnsuccessfulProbes),
+		TotalUptime:             durationpb.New(s.TotalUptime),
+		TotalDowntime:           durationpb.New(s.TotalDowntime),
+		LongestUptime:           durationpb.New(s.LongestUptime.Duration),
+		LongestDowntime:         durationpb.New(s.LongestDowntime.Duration),
+		RetriedHostnameLookups:  uint64(s.RetriedHostnameLookups),
+	}
+
+	if totalPackets > 0 {
+		msg.PacketLossPercent = float64(s.TotalUnsuccessfulProbes) / float64(totalPackets) * 100
+	}
+
+	if !s.LastSuccessfulProbe.IsZero() {
+		msg.LastSuccessfulProbe = timestamppb.New(s.LastSuccessfulProbe)
+	}
+
+	if !s.LastUnsuccessfulProbe.IsZero() {
+		msg.LastUnsuccessfulProbe = timestamppb.New(s.LastUnsuccessfulProbe)
+	}
+
+	if s.RttResults.HasResults {
+		msg.RttMinMs = s.RttResults.Min
+		msg.RttAvgMs = s.RttResults.Average
+		msg.RttMaxMs = s.RttResults.Max
+	}
+
+	return msg
+}
@@ -0,0 +1,104 @@
+package main
+
+import (
+	"context"
+	"net"
+	"net/netip"
+	"testing"
+	"time"
+
+	"github.com/pouriyajamshidi/tcping/v2/pkg/tcping"
+	"github.com/pouriyajamshidi/tcping/v2/pkg/tcpingpb"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// newTestGRPCClient serves s over an in-memory connection.
+func newTestGRPCClient(t *testing.T, s *grpcServer) tcpingpb.TCPingClient {
+	ln := bufconn.Listen(1024 * 1024)
+	srv := grpc.NewServer()
+	tcpingpb.RegisterTCPingServer(srv, s)
+	go srv.Serve(ln)
+	t.Cleanup(srv.Stop)
+
+	conn, err := grpc.Dial("bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return ln.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	return tcpingpb.NewTCPingClient(conn)
+}
+
+// waitForSubscribers waits until n streams are subscribed to s.
+func waitForSubscribers(t *testing.T, s *grpcServer, n int) {
+	assert.Eventually(t, func() bool {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		return len(s.subscribers) == n
+	}, time.Second, time.Millisecond)
+}
+
+func TestGRPCStreamEvents(t *testing.T) {
+	s := newGRPCServer("")
+	client := newTestGRPCClient(t, s)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	all, err := client.StreamEvents(ctx, &tcpingpb.StreamEventsRequest{})
+	assert.NoError(t, err)
+	changes, err := client.StreamEvents(ctx, &tcpingpb.StreamEventsRequest{StateChangesOnly: true})
+	assert.NoError(t, err)
+	waitForSubscribers(t, s, 2)
+
+	ip := netip.MustParseAddr("93.184.216.34")
+	s.publishProbe(tcping.Result{Time: time.Now(), IP: ip, Port: 443, Streak: 1})
+	s.publishStateChange(tcping.StateChange{When: time.Now(), IP: ip.String(), Port: 443, Up: true, Downtime: time.Minute})
+
+	event, err := all.Recv()
+	assert.NoError(t, err)
+	assert.Equal(t, "93.184.216.34", event.GetProbe().GetIp())
+	assert.Equal(t, uint64(1), event.GetProbe().GetStreak())
+
+	event, err = all.Recv()
+	assert.NoError(t, err)
+	assert.True(t, event.GetStateChange().GetUp())
+
+	// the probe should have been skipped
+	event, err = changes.Recv()
+	assert.NoError(t, err)
+	if assert.NotNil(t, event.GetStateChange()) {
+		assert.Equal(t, time.Minute, event.GetStateChange().GetDowntime().AsDuration())
+	}
+}
+
+func TestGRPCGetStatistics(t *testing.T) {
+	s := newGRPCServer("")
+	s.statistics = func() tcping.Statistics {
+		return tcping.Statistics{
+			Hostname:                "example.com",
+			IP:                      netip.MustParseAddr("93.184.216.34"),
+			Port:                    443,
+			TotalSuccessfulProbes:   3,
+			TotalUnsuccessfulProbes: 1,
+			RttResults:              tcping.RttResult{Min: 1, Average: 2, Max: 3, HasResults: true},
+		}
+	}
+	client := newTestGRPCClient(t, s)
+
+	stats, err := client.GetStatistics(context.Background(), &tcpingpb.GetStatisticsRequest{})
+	assert.NoError(t, err)
+	assert.Equal(t, "example.com", stats.GetHostname())
+	assert.Equal(t, uint32(443), stats.GetPort())
+	assert.Equal(t, float64(25), stats.GetPacketLossPercent())
+	assert.Equal(t, float32(2), stats.GetRttAvgMs())
+	assert.Nil(t, stats.GetLastSuccessfulProbe())
+}
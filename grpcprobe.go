@@ -0,0 +1,84 @@
+package main
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/pouriyajamshidi/tcping/v2/pkg/tcping"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+func init() {
+	tcping.RegisterProber("grpc", func(cfg tcping.ProberConfig) (tcping.Prober, error) {
+		p := &grpcProber{service: cfg.Service}
+		if cfg.TLSConfig != nil {
+			p.tlsConfig = cfg.TLSConfig.Clone()
+			if p.tlsConfig.ServerName == "" {
+				p.tlsConfig.ServerName = cfg.Hostname
+			}
+		}
+		return p, nil
+	})
+}
+
+// grpcProber calls the standard health check of gRPC,
+// grpc.health.v1.Health/Check, over the connection of the probe.
+type grpcProber struct {
+	// tlsConfig is nil for plaintext connections.
+	tlsConfig *tls.Config
+	// service is checked, the empty name stands for the whole server.
+	service string
+}
+
+func (p *grpcProber) Probe(conn net.Conn, timeout time.Duration) error {
+	ctx := context.Background()
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	creds := insecure.NewCredentials()
+	if p.tlsConfig != nil {
+		creds = credentials.NewTLS(p.tlsConfig)
+	}
+
+	// the connection of the probe is used once, instead of connecting again
+	var once sync.Once
+	dialer := func(_ context.Context, _ string) (net.Conn, error) {
+		c := net.Conn(nil)
+		once.Do(func() { c = conn })
+		if c == nil {
+			return nil, errors.New("the connection of the probe is already used")
+		}
+		return c, nil
+	}
+
+	client, err := grpc.DialContext(ctx, "passthrough:///"+conn.RemoteAddr().String(),
+		grpc.WithTransportCredentials(creds),
+		grpc.WithContextDialer(dialer),
+	)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	resp, err := healthpb.NewHealthClient(client).Check(ctx, &healthpb.HealthCheckRequest{Service: p.service})
+	if err != nil {
+		return fmt.Errorf("health check failed: %w", err)
+	}
+
+	if resp.GetStatus() != healthpb.HealthCheckResponse_SERVING {
+		return fmt.Errorf("the service is %s", resp.GetStatus())
+	}
+
+	return nil
+}
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"crypto/tls"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/pouriyajamshidi/tcping/v2/pkg/tcping"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+// serveTestHealth starts a gRPC server with the health service and
+// returns its address. "up" is SERVING and "down" is NOT_SERVING.
+func serveTestHealth(t *testing.T, opts ...grpc.ServerOption) string {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+
+	hs := health.NewServer()
+	hs.SetServingStatus("up", healthpb.HealthCheckResponse_SERVING)
+	hs.SetServingStatus("down", healthpb.HealthCheckResponse_NOT_SERVING)
+
+	srv := grpc.NewServer(opts...)
+	healthpb.RegisterHealthServer(srv, hs)
+	go srv.Serve(ln)
+	t.Cleanup(srv.Stop)
+
+	return ln.Addr().String()
+}
+
+// probeTestHealth connects to addr and runs the prober over the connection.
+func probeTestHealth(t *testing.T, addr string, p tcping.Prober) error {
+	conn, err := net.Dial("tcp", addr)
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	defer conn.Close()
+
+	return p.Probe(conn, 2*time.Second)
+}
+
+func TestGRPCProber(t *testing.T) {
+	addr := serveTestHealth(t)
+
+	for _, service := range []string{"", "up"} {
+		p, err := tcping.NewProber("grpc", tcping.ProberConfig{Service: service})
+		assert.NoError(t, err)
+		assert.NoError(t, probeTestHealth(t, addr, p), service)
+	}
+
+	err := probeTestHealth(t, addr, &grpcProber{service: "down"})
+	assert.EqualError(t, err, "the service is NOT_SERVING")
+
+	err = probeTestHealth(t, addr, &grpcProber{service: "unknown"})
+	assert.ErrorContains(t, err, "NotFound")
+}
+
+func TestGRPCProberTLS(t *testing.T) {
+	// borrow the certificate of an httptest server
+	certSrv := httptest.NewTLSServer(http.NotFoundHandler())
+	t.Cleanup(certSrv.Close)
+
+	creds := credentials.NewTLS(&tls.Config{Certificates: certSrv.TLS.Certificates})
+	addr := serveTestHealth(t, grpc.Creds(creds))
+
+	config := certSrv.Client().Transport.(*http.Transport).TLSClientConfig.Clone()
+	config.ServerName = "127.0.0.1"
+
+	assert.NoError(t, probeTestHealth(t, addr, &grpcProber{tlsConfig: config, service: "up"}))
+
+	// a plaintext health check fails against the TLS server
+	assert.Error(t, probeTestHealth(t, addr, &grpcProber{service: "up"}))
+}
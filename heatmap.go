@@ -0,0 +1,91 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/pouriyajamshidi/tcping/v2/pkg/tcping"
+)
+
+// heatmapEdges are the upper bounds in milliseconds of the RTT buckets
+// of `tcping analyze --heatmap`, the last bucket has no upper bound.
+var heatmapEdges = []float32{1, 2, 5, 10, 20, 50, 100, 200, 500}
+
+// heatmapShades shade the cells of the heatmap by the share
+// of the probes of their time bucket, from none to all of them.
+var heatmapShades = []rune{' ', '░', '▒', '▓', '█'}
+
+// formatHeatmap formats the samples as a heatmap with one row per time
+// bucket of the given size and one column per RTT bucket, followed by
+// the failed probes, so that the RTTs can be followed over a long run.
+func formatHeatmap(samples []tcping.Sample, bucket time.Duration) []string {
+	if len(samples) == 0 {
+		return nil
+	}
+
+	samples = slices.Clone(samples)
+	slices.SortStableFunc(samples, func(a, b tcping.Sample) int {
+		return a.Time.Compare(b.Time)
+	})
+
+	header := []string{fmt.Sprintf("%-19s", "")}
+	for i, edge := range heatmapEdges {
+		header = append(header, fmt.Sprintf("%4s", fmt.Sprintf("<%g", edge)))
+		if i == len(heatmapEdges)-1 {
+			header = append(header, fmt.Sprintf("%4s", fmt.Sprintf("%g+", edge)))
+		}
+	}
+	header = append(header, "fail")
+
+	lines := []string{
+		fmt.Sprintf("rtt heatmap per %s in ms, shaded by the share of the probes:", bucket),
+		strings.Join(header, " "),
+	}
+
+	for start := 0; start < len(samples); {
+		bucketStart := samples[start].Time.Truncate(bucket)
+		end := start
+		for end < len(samples) && samples[end].Time.Truncate(bucket).Equal(bucketStart) {
+			end++
+		}
+
+		lines = append(lines, formatHeatmapRow(bucketStart, samples[start:end]))
+		start = end
+	}
+
+	return lines
+}
+
+// formatHeatmapRow formats the samples of a time bucket as a row of the heatmap.
+func formatHeatmapRow(start time.Time, samples []tcping.Sample) string {
+	// the RTT buckets followed by the failures
+	counts := make([]int, len(heatmapEdges)+2)
+	for _, s := range samples {
+		if !s.Success {
+			counts[len(counts)-1]++
+			continue
+		}
+
+		i, _ := slices.BinarySearch(heatmapEdges, s.RTT)
+		// an RTT on an edge belongs to the next bucket
+		if i < len(heatmapEdges) && s.RTT == heatmapEdges[i] {
+			i++
+		}
+		counts[i]++
+	}
+
+	cells := []string{start.Format(time.DateTime)}
+	for _, count := range counts {
+		shade := heatmapShades[0]
+		if count > 0 {
+			share := float64(count) / float64(len(samples))
+			shade = heatmapShades[int(math.Ceil(share*float64(len(heatmapShades)-1)))]
+		}
+		cells = append(cells, " "+strings.Repeat(string(shade), 3))
+	}
+
+	return strings.TrimRight(strings.Join(cells, " "), " ")
+}
@@ -0,0 +1,141 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net/netip"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/pouriyajamshidi/tcping/v2/pkg/tcping"
+)
+
+// resolveFlag collects the values of the repeatable --resolve flag.
+type resolveFlag []string
+
+func (f *resolveFlag) String() string {
+	return strings.Join(*f, " ")
+}
+
+func (f *resolveFlag) Set(value string) error {
+	*f = append(*f, value)
+	return nil
+}
+
+// parseResolve parses a --resolve entry, either host:addr[,addr]...
+// or curl's host:port:addr[,addr]... The port is 0 if it's not given.
+func parseResolve(entry string) (string, uint16, []netip.Addr, error) {
+	host, rest, ok := strings.Cut(entry, ":")
+	if !ok || host == "" || rest == "" {
+		return "", 0, nil, fmt.Errorf("%q is not in the host:address format", entry)
+	}
+
+	// a bare IPv6 address contains colons as well
+	if addrs, err := parseAddrList(rest); err == nil {
+		return host, 0, addrs, nil
+	}
+
+	portStr, list, ok := strings.Cut(rest, ":")
+	if !ok {
+		return "", 0, nil, fmt.Errorf("invalid address in %q", entry)
+	}
+
+	port, err := strconv.ParseUint(portStr, 10, 16)
+	if err != nil || port == 0 {
+		return "", 0, nil, fmt.Errorf("invalid port in %q", entry)
+	}
+
+	addrs, err := parseAddrList(list)
+	if err != nil {
+		return "", 0, nil, fmt.Errorf("invalid address in %q: %w", entry, err)
+	}
+
+	return host, uint16(port), addrs, nil
+}
+
+// parseAddrList parses comma separated addresses,
+// IPv6 ones might be wrapped in brackets.
+func parseAddrList(list string) ([]netip.Addr, error) {
+	var addrs []netip.Addr
+	for _, s := range strings.Split(list, ",") {
+		s = strings.TrimSuffix(strings.TrimPrefix(s, "["), "]")
+
+		addr, err := netip.ParseAddr(s)
+		if err != nil {
+			return nil, err
+		}
+		addrs = append(addrs, addr)
+	}
+
+	return addrs, nil
+}
+
+// parseHostsFile parses a file in the format of /etc/hosts.
+// The hostnames are lowercased.
+func parseHostsFile(r io.Reader) (map[string][]netip.Addr, error) {
+	hosts := make(map[string][]netip.Addr)
+
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		text, _, _ := strings.Cut(scanner.Text(), "#")
+
+		fields := strings.Fields(text)
+		if len(fields) == 0 {
+			continue
+		}
+		if len(fields) == 1 {
+			return nil, fmt.Errorf("line %d: missing hostname", line)
+		}
+
+		addr, err := netip.ParseAddr(fields[0])
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+
+		for _, name := range fields[1:] {
+			name = strings.ToLower(name)
+			hosts[name] = append(hosts[name], addr)
+		}
+	}
+
+	return hosts, scanner.Err()
+}
+
+// setHosts forces the addresses of the hostnames given
+// with --hosts-file and --resolve, the latter taking precedence.
+func setHosts(opts *tcping.Options, resolve resolveFlag, hostsFile *string) {
+	hosts := make(map[string][]netip.Addr)
+
+	if *hostsFile != "" {
+		f, err := os.Open(*hostsFile)
+		if err == nil {
+			hosts, err = parseHostsFile(f)
+			f.Close()
+		}
+		if err != nil {
+			opts.Printer.PrintError("Unable to read the hosts file %s: %s", *hostsFile, err)
+			os.Exit(1)
+		}
+	}
+
+	for _, entry := range resolve {
+		host, port, addrs, err := parseResolve(entry)
+		if err != nil {
+			opts.Printer.PrintError("Invalid --resolve entry: %s", err)
+			os.Exit(1)
+		}
+
+		// like curl, entries for other ports are ignored
+		if port != 0 && port != opts.Port {
+			continue
+		}
+
+		hosts[strings.ToLower(host)] = addrs
+	}
+
+	if len(hosts) > 0 {
+		opts.Hosts = hosts
+	}
+}
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"net/netip"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseResolve(t *testing.T) {
+	tests := []struct {
+		entry string
+		host  string
+		port  uint16
+		addrs []string
+	}{
+		{entry: "example.com:192.0.2.10", host: "example.com", addrs: []string{"192.0.2.10"}},
+		{entry: "example.com:192.0.2.10,192.0.2.11", host: "example.com", addrs: []string{"192.0.2.10", "192.0.2.11"}},
+		{entry: "example.com:2001:db8::10", host: "example.com", addrs: []string{"2001:db8::10"}},
+		{entry: "example.com:443:192.0.2.10", host: "example.com", port: 443, addrs: []string{"192.0.2.10"}},
+		{entry: "example.com:443:[2001:db8::10]", host: "example.com", port: 443, addrs: []string{"2001:db8::10"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.entry, func(t *testing.T) {
+			host, port, addrs, err := parseResolve(tt.entry)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.host, host)
+			assert.Equal(t, tt.port, port)
+
+			var want []netip.Addr
+			for _, a := range tt.addrs {
+				want = append(want, netip.MustParseAddr(a))
+			}
+			assert.Equal(t, want, addrs)
+		})
+	}
+
+	for _, entry := range []string{"example.com", ":192.0.2.10", "example.com:", "example.com:443:nope", "example.com:0:192.0.2.10"} {
+		_, _, _, err := parseResolve(entry)
+		assert.Error(t, err, entry)
+	}
+}
+
+func TestParseHostsFile(t *testing.T) {
+	hosts, err := parseHostsFile(strings.NewReader(`
+# backends behind the load balancer
+192.0.2.10  Backend1.example.com backend1
+2001:db8::10 backend1.example.com # the IPv6 one
+`))
+	assert.NoError(t, err)
+	assert.Equal(t, map[string][]netip.Addr{
+		"backend1.example.com": {netip.MustParseAddr("192.0.2.10"), netip.MustParseAddr("2001:db8::10")},
+		"backend1":             {netip.MustParseAddr("192.0.2.10")},
+	}, hosts)
+
+	_, err = parseHostsFile(strings.NewReader("192.0.2.10\n"))
+	assert.EqualError(t, err, "line 1: missing hostname")
+
+	_, err = parseHostsFile(strings.NewReader("\nbackend1 192.0.2.10\n"))
+	assert.Error(t, err)
+}
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"net/http"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/pouriyajamshidi/tcping/v2/pkg/tcping"
+)
+
+// headerFlag collects the values of the repeatable --header flag.
+type headerFlag []string
+
+func (f *headerFlag) String() string {
+	return strings.Join(*f, ", ")
+}
+
+func (f *headerFlag) Set(value string) error {
+	*f = append(*f, value)
+	return nil
+}
+
+// parseHeaders parses the headers given in the "Name: value" format.
+func parseHeaders(headers []string) (http.Header, bool) {
+	header := make(http.Header)
+	for _, h := range headers {
+		name, value, ok := strings.Cut(h, ":")
+		name = strings.TrimSpace(name)
+		if !ok || name == "" {
+			return nil, false
+		}
+
+		header.Add(name, strings.TrimSpace(value))
+	}
+
+	return header, true
+}
+
+// setHTTP returns the request of the http and ws
+// probers and the response the http prober expects.
+func setHTTP(opts *tcping.Options, probe, method, path *string, headers headerFlag, expectStatus, expectBody *string) tcping.HTTPConfig {
+	var cfg tcping.HTTPConfig
+
+	isHTTP := *probe == "http"
+	if !isHTTP && (*method != "" || *expectStatus != "" || *expectBody != "") {
+		opts.Printer.PrintError("--http-method, --expect-status and --expect-body can only be used with --probe http.")
+		os.Exit(1)
+	}
+
+	// the upgrade of WebSockets is an HTTP request as well
+	isWebSocket := *probe == "ws" || *probe == "wss"
+	if !isHTTP && !isWebSocket && (*path != "" || len(headers) > 0) {
+		opts.Printer.PrintError("--http-path and --header can only be used with --probe http, ws or wss.")
+		os.Exit(1)
+	}
+
+	cfg.Method = strings.ToUpper(*method)
+	cfg.Path = *path
+	if cfg.Path != "" && !strings.HasPrefix(cfg.Path, "/") {
+		cfg.Path = "/" + cfg.Path
+	}
+
+	header, ok := parseHeaders(headers)
+	if !ok {
+		opts.Printer.PrintError("Invalid --header, the format is 'Name: value'.")
+		os.Exit(1)
+	}
+	cfg.Header = header
+
+	if *expectStatus != "" {
+		ranges, err := tcping.ParseStatusRanges(*expectStatus)
+		if err != nil {
+			opts.Printer.PrintError("Invalid --expect-status: %s", err)
+			os.Exit(1)
+		}
+		cfg.ExpectStatus = ranges
+	}
+
+	if *expectBody != "" {
+		re, err := regexp.Compile(*expectBody)
+		if err != nil {
+			opts.Printer.PrintError("Invalid --expect-body pattern: %s", err)
+			os.Exit(1)
+		}
+		cfg.ExpectBody = re
+	}
+
+	return cfg
+}
@@ -0,0 +1,23 @@
+package main
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseHeaders(t *testing.T) {
+	header, ok := parseHeaders([]string{"Host: www.example.com", "x-token:secret", "Accept: text/html", "Accept: */*"})
+	assert.True(t, ok)
+	assert.Equal(t, http.Header{
+		"Host":    {"www.example.com"},
+		"X-Token": {"secret"},
+		"Accept":  {"text/html", "*/*"},
+	}, header)
+
+	for _, h := range []string{"Host", ": value"} {
+		_, ok := parseHeaders([]string{h})
+		assert.False(t, ok, h)
+	}
+}
@@ -0,0 +1,191 @@
+package main
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pouriyajamshidi/tcping/v2/pkg/tcping"
+)
+
+const (
+	pagerDutyEventsURL = "https://events.pagerduty.com/v2/enqueue"
+	opsgenieAPIURL     = "https://api.opsgenie.com"
+	// incidentQueueSize is the number of state changes that can wait
+	// to be sent before the probes have to wait for the incident service.
+	incidentQueueSize = 256
+)
+
+// incidentNotifier opens an incident when the target goes down
+// and resolves it when the target comes back up. The same goes for
+// the degradations of the target, with a lower severity.
+//
+// Incidents are deduplicated per target, so restarting tcping
+// while the target is down doesn't open a new one.
+//
+// The state changes are sent one at a time and in order, so that
+// the resolve of a quick flap never overtakes its trigger.
+type incidentNotifier struct {
+	open    func(change tcping.StateChange) error
+	resolve func(change tcping.StateChange) error
+	// service is the name of the incident service used in the error messages.
+	service string
+	queue   chan tcping.StateChange
+	pending sync.WaitGroup
+}
+
+// newIncidentNotifier starts the goroutine sending the state changes
+// of the notifier to the incident service.
+func newIncidentNotifier(service string, open, resolve func(change tcping.StateChange) error) *incidentNotifier {
+	n := &incidentNotifier{
+		open:    open,
+		resolve: resolve,
+		service: service,
+		queue:   make(chan tcping.StateChange, incidentQueueSize),
+	}
+	go n.send()
+
+	return n
+}
+
+// dedupKey identifies the incidents of a target, the degradations
+// of the target being incidents of their own beside its outages.
+//
+// The IP address is left out on purpose, the incident
+// is about the service and not a specific address.
+func dedupKey(c tcping.StateChange) string {
+	key := "tcping/" + net.JoinHostPort(c.Hostname, strconv.Itoa(int(c.Port)))
+	if kind := incidentKind(c); kind != "" {
+		key += "/" + kind
+	}
+
+	return key
+}
+
+// incidentKind returns the kind of the incident of the state change,
+// or an empty string if it's an outage.
+func incidentKind(c tcping.StateChange) string {
+	switch {
+	case c.Outlier:
+		return "outlier"
+	case c.Trend:
+		return "trend"
+	case c.Degraded:
+		return "degraded"
+	default:
+		return ""
+	}
+}
+
+func newPagerDutyNotifier(cfg *pagerDutyConfig) *incidentNotifier {
+	client := &http.Client{Timeout: webhookTimeout}
+	send := func(action string, change tcping.StateChange) error {
+		event := map[string]any{
+			"routing_key":  cfg.RoutingKey,
+			"event_action": action,
+			"dedup_key":    dedupKey(change),
+		}
+
+		if action == "trigger" {
+			// the degradations are warnings rather than outages
+			severity := cfg.Severity
+			if incidentKind(change) != "" {
+				severity = "warning"
+			}
+
+			event["payload"] = map[string]any{
+				"summary":   change.Summary(),
+				"source":    change.Hostname,
+				"component": strconv.Itoa(int(change.Port)),
+				"severity":  severity,
+				"timestamp": change.When.Format(time.RFC3339),
+				"custom_details": map[string]any{
+					"ip":   change.IP,
+					"port": change.Port,
+				},
+			}
+		}
+
+		return postJSON(client, pagerDutyEventsURL, event, nil)
+	}
+
+	return newIncidentNotifier("PagerDuty",
+		func(change tcping.StateChange) error {
+			return send("trigger", change)
+		},
+		func(change tcping.StateChange) error {
+			return send("resolve", change)
+		},
+	)
+}
+
+func newOpsgenieNotifier(cfg *opsgenieConfig) *incidentNotifier {
+	client := &http.Client{Timeout: webhookTimeout}
+	headers := map[string]string{"Authorization": "GenieKey " + cfg.APIKey}
+	apiURL := strings.TrimSuffix(cfg.APIURL, "/")
+
+	return newIncidentNotifier("Opsgenie",
+		func(change tcping.StateChange) error {
+			message, priority := fmt.Sprintf("%s is not responding", change.Target()), cfg.Priority
+			if incidentKind(change) != "" {
+				// the degradations are low priority rather than outages
+				message, priority = change.Summary(), "P4"
+			}
+
+			return postJSON(client, apiURL+"/v2/alerts", map[string]any{
+				"message":     message,
+				"alias":       dedupKey(change),
+				"description": change.Summary(),
+				"priority":    priority,
+				"source":      "tcping",
+				"details": map[string]string{
+					"hostname": change.Hostname,
+					"ip":       change.IP,
+					"port":     strconv.Itoa(int(change.Port)),
+				},
+			}, headers)
+		},
+		func(change tcping.StateChange) error {
+			closeURL := fmt.Sprintf("%s/v2/alerts/%s/close?identifierType=alias",
+				apiURL, url.PathEscape(dedupKey(change)))
+
+			return postJSON(client, closeURL, map[string]string{
+				"source": "tcping",
+				"note":   change.Summary(),
+			}, headers)
+		},
+	)
+}
+
+func (n *incidentNotifier) Notify(change tcping.StateChange) {
+	n.pending.Add(1)
+	n.queue <- change
+}
+
+// send opens and resolves the incidents of the queued state changes,
+// waiting for each one to be delivered or given up on before the next.
+func (n *incidentNotifier) send() {
+	for change := range n.queue {
+		action, err := "open", error(nil)
+		if change.Up {
+			action, err = "resolve", n.resolve(change)
+		} else {
+			err = n.open(change)
+		}
+
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to %s the %s incident: %s\n", action, n.service, err)
+		}
+		n.pending.Done()
+	}
+}
+
+func (n *incidentNotifier) NotifyStatistics(_ tcping.Statistics) {
+	n.pending.Wait()
+}
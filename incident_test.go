@@ -0,0 +1,117 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/pouriyajamshidi/tcping/v2/pkg/tcping"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDedupKey(t *testing.T) {
+	assert.Equal(t, "tcping/example.com:443",
+		dedupKey(tcping.StateChange{Hostname: "example.com", IP: "93.184.216.34", Port: 443}))
+	assert.Equal(t, "tcping/[2001:db8::1]:22",
+		dedupKey(tcping.StateChange{Hostname: "2001:db8::1", IP: "2001:db8::1", Port: 22}))
+	assert.Equal(t, "tcping/example.com:443/degraded",
+		dedupKey(tcping.StateChange{Hostname: "example.com", IP: "93.184.216.34", Port: 443, Degraded: true}))
+	assert.Equal(t, "tcping/example.com:443/trend",
+		dedupKey(tcping.StateChange{Hostname: "example.com", IP: "93.184.216.34", Port: 443, Trend: true}))
+	assert.Equal(t, "tcping/example.com:443/outlier",
+		dedupKey(tcping.StateChange{Hostname: "example.com", IP: "93.184.216.34", Port: 443, Outlier: true}))
+}
+
+func TestOpsgenieNotifier(t *testing.T) {
+	type request struct {
+		path string
+		auth string
+		body map[string]any
+	}
+	requests := make(chan request, 2)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		req := request{path: r.URL.EscapedPath(), auth: r.Header.Get("Authorization")}
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&req.body))
+		requests <- req
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	t.Cleanup(srv.Close)
+
+	n := newOpsgenieNotifier(&opsgenieConfig{APIKey: "secret", APIURL: srv.URL, Priority: "P2"})
+	change := tcping.StateChange{When: time.Now(), Hostname: "example.com", IP: "93.184.216.34", Port: 443}
+
+	n.Notify(change)
+	n.NotifyStatistics(tcping.Statistics{})
+	opened := <-requests
+	assert.Equal(t, "/v2/alerts", opened.path)
+	assert.Equal(t, "GenieKey secret", opened.auth)
+	assert.Equal(t, "tcping/example.com:443", opened.body["alias"])
+	assert.Equal(t, "P2", opened.body["priority"])
+
+	change.Up = true
+	n.Notify(change)
+	n.NotifyStatistics(tcping.Statistics{})
+	resolved := <-requests
+	assert.Equal(t, "/v2/alerts/tcping%2Fexample.com:443/close", resolved.path)
+
+	// the degradation is an alert of its own, with a lower priority
+	change.Up, change.Degraded = false, true
+	n.Notify(change)
+	n.NotifyStatistics(tcping.Statistics{})
+	opened = <-requests
+	assert.Equal(t, "tcping/example.com:443/degraded", opened.body["alias"])
+	assert.Equal(t, "example.com (93.184.216.34) on port 443 is degraded, a probe took 0.000 ms", opened.body["message"])
+	assert.Equal(t, "P4", opened.body["priority"])
+
+	change.Up = true
+	n.Notify(change)
+	n.NotifyStatistics(tcping.Statistics{})
+	resolved = <-requests
+	assert.Equal(t, "/v2/alerts/tcping%2Fexample.com:443%2Fdegraded/close", resolved.path)
+
+	change.Up, change.Degraded, change.Trend = false, false, true
+	n.Notify(change)
+	n.NotifyStatistics(tcping.Statistics{})
+	opened = <-requests
+	assert.Equal(t, "tcping/example.com:443/trend", opened.body["alias"])
+	assert.Equal(t, "example.com (93.184.216.34) on port 443 is trending up, a probe took 0.000 ms", opened.body["message"])
+	assert.Equal(t, "P4", opened.body["priority"])
+
+	change.Trend, change.Outlier = false, true
+	n.Notify(change)
+	n.NotifyStatistics(tcping.Statistics{})
+	opened = <-requests
+	assert.Equal(t, "tcping/example.com:443/outlier", opened.body["alias"])
+	assert.Equal(t, "example.com (93.184.216.34) on port 443 has outlier RTTs, a probe took 0.000 ms", opened.body["message"])
+	assert.Equal(t, "P4", opened.body["priority"])
+}
+
+func TestIncidentNotifierOrder(t *testing.T) {
+	var sent []string
+	n := newIncidentNotifier("test",
+		func(change tcping.StateChange) error {
+			// a slow trigger mustn't be overtaken by the resolve
+			time.Sleep(50 * time.Millisecond)
+			sent = append(sent, "open")
+			return nil
+		},
+		func(change tcping.StateChange) error {
+			sent = append(sent, "resolve")
+			return nil
+		},
+	)
+
+	change := tcping.StateChange{When: time.Now(), Hostname: "example.com", IP: "93.184.216.34", Port: 443}
+	for i := 0; i < 2; i++ {
+		change.Up = false
+		n.Notify(change)
+		change.Up = true
+		n.Notify(change)
+	}
+	n.NotifyStatistics(tcping.Statistics{})
+
+	assert.Equal(t, []string{"open", "resolve", "open", "resolve"}, sent)
+}
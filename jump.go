@@ -0,0 +1,144 @@
+package main
+
+import (
+	"crypto/ed25519"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"os/user"
+	"path/filepath"
+	"strings"
+
+	"github.com/pouriyajamshidi/tcping/v2/pkg/tcping"
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/agent"
+	"golang.org/x/crypto/ssh/knownhosts"
+)
+
+// defaultIdentities are the private keys tried after the ones of the
+// agent, like ssh does. The keys protected by a passphrase are skipped.
+var defaultIdentities = []string{"id_ed25519", "id_ecdsa", "id_rsa"}
+
+// setJump tunnels the probes through the SSH server of --jump, given as
+// [user@]host[:port]. tcping authenticates with the keys of ssh-agent and
+// the default ones of ~/.ssh, and verifies the server with known_hosts.
+func setJump(opts *tcping.Options, jump *string) {
+	if *jump == "" {
+		return
+	}
+
+	username, addr, err := parseJump(*jump)
+	if err != nil {
+		colorRed("Invalid --jump: %s\n", err)
+		usage()
+	}
+
+	config, err := jumpConfig(username, addr)
+	if err != nil {
+		opts.Printer.PrintError("Unable to set up the SSH tunnel to %s: %s", addr, err)
+		os.Exit(1)
+	}
+
+	opts.Jump = &tcping.JumpHost{Addr: addr, Config: config}
+}
+
+// parseJump splits [user@]host[:port] into the user, defaulting to
+// the current one, and the address of the server, on port 22 by default.
+func parseJump(jump string) (string, string, error) {
+	username, host, found := strings.Cut(jump, "@")
+	if !found {
+		host = username
+		current, err := user.Current()
+		if err != nil {
+			return "", "", fmt.Errorf("unable to get the current user: %w", err)
+		}
+		username = current.Username
+	}
+	if username == "" || host == "" {
+		return "", "", errors.New("expected [user@]host[:port]")
+	}
+
+	if h, port, err := net.SplitHostPort(host); err == nil {
+		if h == "" || port == "" {
+			return "", "", errors.New("expected [user@]host[:port]")
+		}
+		return username, host, nil
+	}
+
+	return username, net.JoinHostPort(strings.Trim(host, "[]"), "22"), nil
+}
+
+// jumpConfig authenticates the user with the keys of ssh-agent and of
+// ~/.ssh, and verifies the host key of the server with ~/.ssh/known_hosts.
+func jumpConfig(username, addr string) (*ssh.ClientConfig, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return nil, err
+	}
+	sshDir := filepath.Join(home, ".ssh")
+
+	hostKeyCallback, err := knownhosts.New(filepath.Join(sshDir, "known_hosts"))
+	if err != nil {
+		return nil, fmt.Errorf("unable to read the known hosts: %w", err)
+	}
+
+	var signers []ssh.Signer
+	if sock := os.Getenv("SSH_AUTH_SOCK"); sock != "" {
+		if conn, err := net.Dial("unix", sock); err == nil {
+			if agentSigners, err := agent.NewClient(conn).Signers(); err == nil {
+				signers = append(signers, agentSigners...)
+			}
+		}
+	}
+	for _, name := range defaultIdentities {
+		pem, err := os.ReadFile(filepath.Join(sshDir, name))
+		if err != nil {
+			continue
+		}
+		if signer, err := ssh.ParsePrivateKey(pem); err == nil {
+			signers = append(signers, signer)
+		}
+	}
+	if len(signers) == 0 {
+		return nil, errors.New("no key in ssh-agent or in ~/.ssh")
+	}
+
+	return &ssh.ClientConfig{
+		User:              username,
+		Auth:              []ssh.AuthMethod{ssh.PublicKeys(signers...)},
+		HostKeyCallback:   hostKeyCallback,
+		HostKeyAlgorithms: knownHostKeyAlgorithms(hostKeyCallback, addr),
+	}, nil
+}
+
+// knownHostKeyAlgorithms returns the algorithms of the keys of the server in
+// known_hosts, so that it's asked for one of them rather than for a key it
+// has but which isn't known, which would fail like a changed key.
+func knownHostKeyAlgorithms(callback ssh.HostKeyCallback, addr string) []string {
+	// a throwaway key makes the callback list the known ones
+	pub, _, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		return nil
+	}
+	key, err := ssh.NewPublicKey(pub)
+	if err != nil {
+		return nil
+	}
+
+	var keyErr *knownhosts.KeyError
+	if !errors.As(callback(addr, &net.TCPAddr{}, key), &keyErr) {
+		return nil
+	}
+
+	var algorithms []string
+	for _, known := range keyErr.Want {
+		// the RSA keys sign with SHA-2 since OpenSSH 8.8
+		if known.Key.Type() == ssh.KeyAlgoRSA {
+			algorithms = append(algorithms, ssh.KeyAlgoRSASHA512, ssh.KeyAlgoRSASHA256)
+		}
+		algorithms = append(algorithms, known.Key.Type())
+	}
+
+	return algorithms
+}
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/knownhosts"
+)
+
+func TestParseJump(t *testing.T) {
+	tests := []struct {
+		jump, user, addr string
+	}{
+		{"admin@bastion.example.com", "admin", "bastion.example.com:22"},
+		{"admin@bastion.example.com:2222", "admin", "bastion.example.com:2222"},
+		{"admin@192.0.2.1", "admin", "192.0.2.1:22"},
+		{"admin@[2001:db8::1]:2222", "admin", "[2001:db8::1]:2222"},
+		{"admin@2001:db8::1", "admin", "[2001:db8::1]:22"},
+	}
+
+	for _, tt := range tests {
+		user, addr, err := parseJump(tt.jump)
+		assert.NoError(t, err, tt.jump)
+		assert.Equal(t, tt.user, user, tt.jump)
+		assert.Equal(t, tt.addr, addr, tt.jump)
+	}
+
+	for _, jump := range []string{"admin@", "@bastion", "admin@:22"} {
+		_, _, err := parseJump(jump)
+		assert.Error(t, err, jump)
+	}
+}
+
+func TestKnownHostKeyAlgorithms(t *testing.T) {
+	priv, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if !assert.NoError(t, err) {
+		return
+	}
+	key, err := ssh.NewPublicKey(&priv.PublicKey)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	path := filepath.Join(t.TempDir(), "known_hosts")
+	line := knownhosts.Line([]string{knownhosts.Normalize("bastion.example.com:22")}, key)
+	if err := os.WriteFile(path, []byte(line+"\n"), 0o600); err != nil {
+		t.Fatalf("write known hosts: %v", err)
+	}
+	callback, err := knownhosts.New(path)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	assert.Equal(t, []string{ssh.KeyAlgoECDSA256}, knownHostKeyAlgorithms(callback, "bastion.example.com:22"))
+	assert.Empty(t, knownHostKeyAlgorithms(callback, "other.example.com:22"))
+}
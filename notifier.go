@@ -0,0 +1,173 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pouriyajamshidi/tcping/v2/pkg/tcping"
+)
+
+// desktopNotifier raises a notification on the user's desktop
+// using the tools that ship with the operating system.
+type desktopNotifier struct{}
+
+// newDesktopNotifier makes sure the current platform
+// is capable of showing desktop notifications.
+func newDesktopNotifier() (*desktopNotifier, error) {
+	var tool string
+
+	switch runtime.GOOS {
+	case "linux", "freebsd", "openbsd", "netbsd":
+		tool = "notify-send"
+	case "darwin":
+		tool = "osascript"
+	case "windows":
+		tool = "powershell"
+	default:
+		return nil, fmt.Errorf("desktop notifications are not supported on %s", runtime.GOOS)
+	}
+
+	if _, err := exec.LookPath(tool); err != nil {
+		return nil, fmt.Errorf("desktop notifications require %q: %w", tool, err)
+	}
+
+	return &desktopNotifier{}, nil
+}
+
+func (n *desktopNotifier) Notify(change tcping.StateChange) {
+	cmd := desktopNotificationCommand(desktopNotificationTitle(change), change.Summary())
+
+	// Notifications are best effort, they should neither
+	// delay the next probe nor interrupt the output.
+	go cmd.Run()
+}
+
+// desktopNotificationTitle returns the title of the notification of the state change.
+func desktopNotificationTitle(change tcping.StateChange) string {
+	switch {
+	case change.Outlier && change.Up:
+		return "TCPING: target is back to its usual RTTs"
+	case change.Outlier:
+		return "TCPING: target has outlier RTTs"
+	case change.Trend && change.Up:
+		return "TCPING: target is no longer trending up"
+	case change.Trend:
+		return "TCPING: target is trending up"
+	case change.Degraded && change.Up:
+		return "TCPING: target is responsive again"
+	case change.Degraded:
+		return "TCPING: target is degraded"
+	case change.Up:
+		return "TCPING: target is up"
+	default:
+		return "TCPING: target is down"
+	}
+}
+
+// desktopNotificationCommand builds the platform specific
+// command that shows a notification with the given title and message.
+func desktopNotificationCommand(title, message string) *exec.Cmd {
+	switch runtime.GOOS {
+	case "darwin":
+		quote := strings.NewReplacer(`\`, `\\`, `"`, `\"`)
+		script := fmt.Sprintf(`display notification "%s" with title "%s"`,
+			quote.Replace(message), quote.Replace(title))
+
+		return exec.Command("osascript", "-e", script)
+	case "windows":
+		quote := strings.NewReplacer(`'`, `''`)
+		script := fmt.Sprintf(`Add-Type -AssemblyName System.Windows.Forms; `+
+			`$n = New-Object System.Windows.Forms.NotifyIcon; `+
+			`$n.Icon = [System.Drawing.SystemIcons]::Information; `+
+			`$n.Visible = $true; `+
+			`$n.ShowBalloonTip(5000, '%s', '%s', 'Info'); `+
+			`Start-Sleep -Seconds 6; $n.Dispose()`,
+			quote.Replace(title), quote.Replace(message))
+
+		return exec.Command("powershell", "-NoProfile", "-NonInteractive", "-Command", script)
+	default:
+		return exec.Command("notify-send", "--app-name=tcping", title, message)
+	}
+}
+
+// commandNotifier runs user provided commands when the target
+// goes down or comes back up.
+//
+// The details of the state change are passed to the
+// commands through the TCPING_* environment variables.
+type commandNotifier struct {
+	onDown string
+	onUp   string
+}
+
+func (n *commandNotifier) Notify(change tcping.StateChange) {
+	command := n.onDown
+	if change.Up {
+		command = n.onUp
+	}
+
+	if command == "" {
+		return
+	}
+
+	cmd := shellCommand(context.Background(), command)
+	cmd.Env = append(os.Environ(), environ(change)...)
+	cmd.Stdout = os.Stderr
+	cmd.Stderr = os.Stderr
+
+	// The command should not delay the next probe,
+	// so we don't wait for it to finish here.
+	go cmd.Run()
+}
+
+// environ returns the state change as a list of
+// environment variables in the "key=value" form.
+func environ(c tcping.StateChange) []string {
+	state := "down"
+	if c.Up {
+		state = "up"
+	}
+
+	env := []string{
+		"TCPING_STATE=" + state,
+		"TCPING_HOSTNAME=" + c.Hostname,
+		"TCPING_IP=" + c.IP,
+		"TCPING_PORT=" + strconv.Itoa(int(c.Port)),
+		"TCPING_TIMESTAMP=" + c.When.Format(time.RFC3339),
+		"TCPING_DOWNTIME=" + strconv.FormatFloat(c.Downtime.Seconds(), 'f', 3, 64),
+		"TCPING_RTT=" + strconv.FormatFloat(float64(c.RTT), 'f', 3, 32),
+	}
+
+	// set when the RTT crossed --rtt-threshold rather than the target going down or up
+	if c.Degraded {
+		env = append(env, "TCPING_DEGRADED=1")
+	}
+
+	// set when the outliers started or stopped with --outliers-notify
+	if c.Outlier {
+		env = append(env, "TCPING_OUTLIER=1")
+	}
+
+	// set when the RTTs started or stopped trending up with --trend-notify
+	if c.Trend {
+		env = append(env, "TCPING_TREND=1")
+	}
+
+	return env
+}
+
+// shellCommand returns a command that runs the given command line
+// through the shell of the operating system, killed once ctx is done.
+func shellCommand(ctx context.Context, command string) *exec.Cmd {
+	if runtime.GOOS == "windows" {
+		return exec.CommandContext(ctx, "cmd", "/C", command)
+	}
+
+	return exec.CommandContext(ctx, "sh", "-c", command)
+}
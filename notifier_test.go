@@ -0,0 +1,84 @@
+package main
+
+import (
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/pouriyajamshidi/tcping/v2/pkg/tcping"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEnviron(t *testing.T) {
+	when := time.Date(2023, 9, 10, 12, 30, 0, 0, time.UTC)
+	change := tcping.StateChange{
+		When:     when,
+		Hostname: "example.com",
+		IP:       "93.184.216.34",
+		Port:     443,
+		Downtime: 1500 * time.Millisecond,
+		RTT:      12.5,
+		Up:       true,
+	}
+
+	assert.Equal(t, []string{
+		"TCPING_STATE=up",
+		"TCPING_HOSTNAME=example.com",
+		"TCPING_IP=93.184.216.34",
+		"TCPING_PORT=443",
+		"TCPING_TIMESTAMP=2023-09-10T12:30:00Z",
+		"TCPING_DOWNTIME=1.500",
+		"TCPING_RTT=12.500",
+	}, environ(change))
+
+	change.Up = false
+	change.Trend = true
+	assert.Contains(t, environ(change), "TCPING_TREND=1")
+	assert.Contains(t, environ(change), "TCPING_STATE=down")
+
+	change.Trend = false
+	change.Outlier = true
+	assert.Contains(t, environ(change), "TCPING_OUTLIER=1")
+	assert.NotContains(t, environ(change), "TCPING_TREND=1")
+}
+
+func TestDesktopNotificationTitle(t *testing.T) {
+	change := tcping.StateChange{Hostname: "example.com", IP: "93.184.216.34", Port: 443}
+	assert.Equal(t, "TCPING: target is down", desktopNotificationTitle(change))
+
+	change.Up = true
+	assert.Equal(t, "TCPING: target is up", desktopNotificationTitle(change))
+
+	change.Up, change.Degraded = false, true
+	assert.Equal(t, "TCPING: target is degraded", desktopNotificationTitle(change))
+
+	change.Up = true
+	assert.Equal(t, "TCPING: target is responsive again", desktopNotificationTitle(change))
+
+	change.Up, change.Degraded, change.Trend = false, false, true
+	assert.Equal(t, "TCPING: target is trending up", desktopNotificationTitle(change))
+
+	change.Up = true
+	assert.Equal(t, "TCPING: target is no longer trending up", desktopNotificationTitle(change))
+
+	change.Up, change.Trend, change.Outlier = false, false, true
+	assert.Equal(t, "TCPING: target has outlier RTTs", desktopNotificationTitle(change))
+
+	change.Up = true
+	assert.Equal(t, "TCPING: target is back to its usual RTTs", desktopNotificationTitle(change))
+}
+
+func TestDesktopNotificationCommand(t *testing.T) {
+	cmd := desktopNotificationCommand(`say "hi"`, "it's down")
+
+	switch runtime.GOOS {
+	case "darwin":
+		assert.Equal(t, []string{"osascript", "-e",
+			`display notification "it's down" with title "say \"hi\""`}, cmd.Args)
+	case "windows":
+		assert.Equal(t, "powershell", cmd.Args[0])
+		assert.Contains(t, cmd.Args[len(cmd.Args)-1], `'say "hi"', 'it''s down'`)
+	default:
+		assert.Equal(t, []string{"notify-send", "--app-name=tcping", `say "hi"`, "it's down"}, cmd.Args)
+	}
+}
@@ -0,0 +1,121 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/pouriyajamshidi/tcping/v2/pkg/tcping"
+)
+
+// unescapePayload interprets the escape sequences of Go string
+// literals in the payload, e.g. \r\n or \x00, so that it can be
+// given on the command line.
+func unescapePayload(s string) ([]byte, error) {
+	unquoted, err := strconv.Unquote(`"` + strings.ReplaceAll(s, `"`, `\"`) + `"`)
+	if err != nil {
+		return nil, err
+	}
+
+	return []byte(unquoted), nil
+}
+
+// setPayload sets the payload sent after connecting
+// and the pattern its response must match.
+func setPayload(opts *tcping.Options, send, expect *string) {
+	if *send != "" {
+		payload, err := unescapePayload(*send)
+		if err != nil {
+			opts.Printer.PrintError("Invalid --send payload %q: %s", *send, err)
+			os.Exit(1)
+		}
+		opts.Send = payload
+	}
+
+	if *expect != "" {
+		re, err := regexp.Compile(*expect)
+		if err != nil {
+			opts.Printer.PrintError("Invalid --expect pattern: %s", err)
+			os.Exit(1)
+		}
+		opts.Expect = re
+	}
+}
+
+// sizeUnits are the multipliers of the units of parseSize,
+// the decimal ones like the rates of the links.
+var sizeUnits = map[string]int64{
+	"":    1,
+	"b":   1,
+	"k":   1000,
+	"kb":  1000,
+	"kib": 1 << 10,
+	"m":   1000 * 1000,
+	"mb":  1000 * 1000,
+	"mib": 1 << 20,
+	"g":   1000 * 1000 * 1000,
+	"gb":  1000 * 1000 * 1000,
+	"gib": 1 << 30,
+}
+
+// parseSize parses a size in bytes, with an optional unit, e.g. 1MB or 512KiB.
+func parseSize(s string) (int64, error) {
+	s = strings.TrimSpace(s)
+	number := strings.TrimRightFunc(s, unicode.IsLetter)
+	unit, ok := sizeUnits[strings.ToLower(s[len(number):])]
+	if !ok {
+		return 0, fmt.Errorf("unknown unit %q", s[len(number):])
+	}
+
+	n, err := strconv.ParseFloat(strings.TrimSpace(number), 64)
+	if err != nil || n <= 0 {
+		return 0, fmt.Errorf("invalid size %q", s)
+	}
+
+	return int64(n * float64(unit)), nil
+}
+
+// setGoodput sets the size of the payload streamed to measure the goodput.
+func setGoodput(opts *tcping.Options, size *string, echo *bool) {
+	if *size == "" {
+		if *echo {
+			colorRed("--payload-echo cannot be used without --payload-size.")
+			usage()
+		}
+		return
+	}
+
+	n, err := parseSize(*size)
+	if err != nil {
+		colorRed("Invalid --payload-size: %s\n", err)
+		usage()
+	}
+
+	opts.GoodputSize = n
+	opts.GoodputEcho = *echo
+}
+
+// setProber sets the prober checking the protocol of the service,
+// configured with the settings of the other flags.
+func setProber(opts *tcping.Options, name *string, cfg tcping.ProberConfig) {
+	if cfg.WebSocketPing && *name != "ws" && *name != "wss" {
+		opts.Printer.PrintError("--ws-ping can only be used with --probe ws or wss.")
+		os.Exit(1)
+	}
+
+	if *name == "" {
+		return
+	}
+
+	cfg.Hostname = opts.Hostname
+	prober, err := tcping.NewProber(*name, cfg)
+	if err != nil {
+		opts.Printer.PrintError("Invalid --probe: %s", err)
+		os.Exit(1)
+	}
+
+	opts.Prober = prober
+}
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUnescapePayload(t *testing.T) {
+	tests := []struct {
+		payload string
+		want    string
+	}{
+		{payload: `PING\r\n`, want: "PING\r\n"},
+		{payload: `GET / HTTP/1.0\r\n\r\n`, want: "GET / HTTP/1.0\r\n\r\n"},
+		{payload: `\x00\x01`, want: "\x00\x01"},
+		{payload: `say "hi"`, want: `say "hi"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.payload, func(t *testing.T) {
+			got, err := unescapePayload(tt.payload)
+			assert.NoError(t, err)
+			assert.Equal(t, []byte(tt.want), got)
+		})
+	}
+
+	_, err := unescapePayload(`\q`)
+	assert.Error(t, err)
+}
+
+func TestParseSize(t *testing.T) {
+	tests := []struct {
+		size string
+		want int64
+	}{
+		{size: "1MB", want: 1000000},
+		{size: "1mb", want: 1000000},
+		{size: "512KiB", want: 512 * 1024},
+		{size: "1.5G", want: 1500000000},
+		{size: "4096", want: 4096},
+		{size: "10 kB", want: 10000},
+	}
+	for _, tt := range tests {
+		t.Run(tt.size, func(t *testing.T) {
+			got, err := parseSize(tt.size)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+
+	for _, size := range []string{"MB", "1TB", "-1MB", "0"} {
+		_, err := parseSize(size)
+		assert.Error(t, err, size)
+	}
+}
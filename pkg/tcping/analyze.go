@@ -0,0 +1,168 @@
+package tcping
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"net/netip"
+	"slices"
+	"strconv"
+	"time"
+)
+
+// replayedFailure is the error of a failed sample,
+// classified as the reason it was written with.
+type replayedFailure FailureReason
+
+func (f replayedFailure) Error() string {
+	return string(f)
+}
+
+// discardPrinter drops the probes replayed by [AnalyzeSamples].
+type discardPrinter struct{}
+
+func (discardPrinter) PrintStart(hostname string, port uint16) {}
+func (discardPrinter) PrintProbe(r Result)                     {}
+func (discardPrinter) PrintStateChange(c StateChange)          {}
+func (discardPrinter) PrintRetryingToResolve(hostname string)  {}
+func (discardPrinter) PrintStatistics(s Statistics)            {}
+func (discardPrinter) PrintVersion()                           {}
+func (discardPrinter) PrintInfo(format string, args ...any)    {}
+func (discardPrinter) PrintError(format string, args ...any)   {}
+
+// ReadSamples reads the samples written by a [SampleWriter].
+// Only the timestamp and the outcome columns are required.
+func ReadSamples(r io.Reader) ([]Sample, error) {
+	cr := csv.NewReader(r)
+	header, err := cr.Read()
+	if err != nil {
+		return nil, fmt.Errorf("unable to read the header: %w", err)
+	}
+
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		columns[name] = i
+	}
+	for _, name := range []string{"timestamp", "outcome"} {
+		if _, ok := columns[name]; !ok {
+			return nil, fmt.Errorf("no %s column in the header", name)
+		}
+	}
+
+	var samples []Sample
+	for {
+		row, err := cr.Read()
+		if errors.Is(err, io.EOF) {
+			return samples, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		s, err := parseSample(row, columns)
+		if err != nil {
+			line, _ := cr.FieldPos(0)
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		samples = append(samples, s)
+	}
+}
+
+// parseSample parses a row of the samples with the given column indexes.
+func parseSample(row []string, columns map[string]int) (Sample, error) {
+	field := func(name string) string {
+		if i, ok := columns[name]; ok {
+			return row[i]
+		}
+		return ""
+	}
+
+	var s Sample
+	var err error
+	if s.Time, err = time.Parse(time.RFC3339Nano, field("timestamp")); err != nil {
+		return s, fmt.Errorf("invalid timestamp: %w", err)
+	}
+
+	if ip := field("ip"); ip != "" {
+		if s.IP, err = netip.ParseAddr(ip); err != nil {
+			return s, fmt.Errorf("invalid IP: %w", err)
+		}
+	}
+
+	if port := field("port"); port != "" {
+		p, err := strconv.ParseUint(port, 10, 16)
+		if err != nil {
+			return s, fmt.Errorf("invalid port: %w", err)
+		}
+		s.Port = uint16(p)
+	}
+
+	outcome := field("outcome")
+	if outcome != sampleSuccess {
+		s.FailureReason = FailureReason(outcome)
+		return s, nil
+	}
+
+	s.Success = true
+	rtt, err := strconv.ParseFloat(field("rtt_ms"), 32)
+	if err != nil {
+		return s, fmt.Errorf("invalid RTT: %w", err)
+	}
+	s.RTT = float32(rtt)
+
+	return s, nil
+}
+
+// AnalyzeSamples regenerates the statistics of the samples as if they had
+// just been probed with the options, e.g. Options.AvailabilityWindow.
+// Every sample lasts until the next one and the last one
+// as long as the one before it, or Options.IntervalBetweenProbes.
+func AnalyzeSamples(samples []Sample, opts Options) Statistics {
+	samples = slices.Clone(samples)
+	slices.SortStableFunc(samples, func(a, b Sample) int {
+		return a.Time.Compare(b.Time)
+	})
+
+	tcpStats := &stats{
+		printer:   discardPrinter{},
+		userInput: userInput{Options: opts},
+		isIP:      true,
+	}
+	tcpStats.keepRecentRtts(opts.RecentRTTs)
+	if len(samples) == 0 {
+		return tcpStats.statistics()
+	}
+
+	if tcpStats.userInput.Port == 0 {
+		tcpStats.userInput.Port = samples[0].Port
+	}
+	if tcpStats.userInput.Hostname == "" && samples[0].IP.IsValid() {
+		tcpStats.userInput.Hostname = samples[0].IP.String()
+	}
+	tcpStats.startTime = samples[0].Time
+
+	for _, s := range samples {
+		tcpStats.userInput.ip = s.IP
+		if s.Success {
+			tcpStats.handleConnSuccess(s.RTT, s.Time)
+		} else {
+			tcpStats.handleConnError(s.Time, replayedFailure(s.FailureReason))
+		}
+	}
+
+	last := samples[len(samples)-1].Time
+	elapsed := opts.IntervalBetweenProbes
+	if n := len(samples); n > 1 {
+		elapsed = last.Sub(samples[n-2].Time)
+	}
+	tcpStats.endTime = last.Add(elapsed)
+	if tcpStats.wasDown {
+		calcLongestDowntime(tcpStats, tcpStats.endTime.Sub(tcpStats.startOfDowntime))
+	} else {
+		calcLongestUptime(tcpStats, tcpStats.endTime.Sub(tcpStats.startOfUptime))
+	}
+	tcpStats.rttResults = tcpStats.rtt.results()
+
+	return tcpStats.statistics()
+}
@@ -0,0 +1,70 @@
+package tcping
+
+import (
+	"bytes"
+	"net/netip"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReadSamples(t *testing.T) {
+	when := time.Date(2023, 9, 10, 12, 30, 0, 0, time.UTC)
+	ip := netip.MustParseAddr("127.0.0.1")
+	written := []Sample{
+		{Time: when, IP: ip, Port: 443, Success: true, RTT: 1.5},
+		{Time: when.Add(time.Second), IP: ip, Port: 443, FailureReason: FailureTimeout},
+	}
+
+	var buf bytes.Buffer
+	w, err := NewSampleWriter(&buf)
+	if !assert.NoError(t, err) {
+		return
+	}
+	for _, s := range written {
+		assert.NoError(t, w.Write(s))
+	}
+
+	samples, err := ReadSamples(&buf)
+	assert.NoError(t, err)
+	assert.Equal(t, written, samples)
+
+	_, err = ReadSamples(strings.NewReader("timestamp,outcome\nyesterday,success\n"))
+	assert.ErrorContains(t, err, "line 2: invalid timestamp")
+
+	_, err = ReadSamples(strings.NewReader("ip,rtt_ms\n"))
+	assert.ErrorContains(t, err, "no timestamp column")
+}
+
+func TestAnalyzeSamples(t *testing.T) {
+	start := time.Date(2023, 9, 10, 12, 0, 0, 0, time.Local)
+	ip := netip.MustParseAddr("127.0.0.1")
+
+	var samples []Sample
+	for i := 0; i < 4; i++ {
+		samples = append(samples, Sample{Time: start.Add(time.Duration(i) * time.Second), IP: ip, Port: 443, Success: true, RTT: 10})
+	}
+	samples = append(samples,
+		Sample{Time: start.Add(4 * time.Second), IP: ip, Port: 443, FailureReason: FailureRefused},
+		Sample{Time: start.Add(6 * time.Second), IP: ip, Port: 443, Success: true, RTT: 20},
+	)
+
+	s := AnalyzeSamples(samples, Options{AvailabilityWindow: time.Hour})
+
+	assert.Equal(t, "127.0.0.1", s.Hostname)
+	assert.Equal(t, uint16(443), s.Port)
+	assert.Equal(t, uint(5), s.TotalSuccessfulProbes)
+	assert.Equal(t, uint(1), s.TotalUnsuccessfulProbes)
+	assert.Equal(t, map[FailureReason]uint{FailureRefused: 1}, s.FailureReasons)
+	assert.Equal(t, 2*time.Second, s.TotalDowntime)
+	assert.Equal(t, 6*time.Second, s.TotalUptime)
+	assert.Equal(t, 2*time.Second, s.LongestDowntime.Duration)
+	assert.Equal(t, 4*time.Second, s.LongestUptime.Duration)
+	assert.Equal(t, start, s.StartTime)
+	assert.Equal(t, start.Add(8*time.Second), s.EndTime)
+	assert.Equal(t, float32(12), s.RttResults.Average)
+	assert.Equal(t, 75.0, s.Availability)
+	assert.Len(t, s.AvailabilityWindows, 1)
+}
@@ -0,0 +1,172 @@
+package tcping
+
+import "time"
+
+// AvailabilityWindow holds the uptime, the downtime and the probes
+// of the target within one window of Options.AvailabilityWindow.
+type AvailabilityWindow struct {
+	Start              time.Time
+	Uptime             time.Duration
+	Downtime           time.Duration
+	SuccessfulProbes   uint
+	UnsuccessfulProbes uint
+	// AverageRTT is the average RTT of the successful probes in milliseconds.
+	AverageRTT float32
+}
+
+// Availability returns the percentage of the window the target was up.
+func (w AvailabilityWindow) Availability() float64 {
+	return availability(w.Uptime, w.Downtime)
+}
+
+// availability returns the uptime as a percentage of the monitored time,
+// 0 if nothing was monitored.
+func availability(uptime, downtime time.Duration) float64 {
+	total := uptime + downtime
+	if total == 0 {
+		return 0
+	}
+
+	return float64(uptime) / float64(total) * 100
+}
+
+// windowStart returns the start of the window of the given size that t falls in.
+// The windows of up to a day start at the local midnight, so that
+// e.g. 1h windows start on the hour and 24h windows are calendar days.
+func windowStart(t time.Time, window time.Duration) time.Time {
+	if window > 24*time.Hour {
+		return t.Truncate(window)
+	}
+
+	year, month, day := t.Date()
+	midnight := time.Date(year, month, day, 0, 0, 0, 0, t.Location())
+
+	return midnight.Add(t.Sub(midnight).Truncate(window))
+}
+
+// recordAvailability accounts the time of the previous probe and adds
+// the probe to its window of Options.AvailabilityWindow, with the RTT
+// in milliseconds if it was successful.
+//
+// A probe lasts until the next one starts, so its time is only accounted
+// then, as the uptime or the downtime depending on its result. This keeps
+// the totals right whatever the interval and the timeout of the probes.
+func (tcpStats *stats) recordAvailability(connTime time.Time, rtt float32, up bool) {
+	if !tcpStats.lastProbe.IsZero() {
+		elapsed := connTime.Sub(tcpStats.lastProbe)
+		if tcpStats.lastProbeUp {
+			tcpStats.totalUptime += elapsed
+		} else {
+			tcpStats.totalDowntime += elapsed
+		}
+
+		tcpStats.availabilityWindows = addTime(tcpStats.availabilityWindows,
+			tcpStats.userInput.AvailabilityWindow, tcpStats.lastProbe, connTime, tcpStats.lastProbeUp)
+	}
+	tcpStats.lastProbe = connTime
+	tcpStats.lastProbeUp = up
+
+	window := tcpStats.userInput.AvailabilityWindow
+	if window == 0 {
+		return
+	}
+	tcpStats.availabilityWindows = windowAt(tcpStats.availabilityWindows, window, connTime)
+	w := &tcpStats.availabilityWindows[len(tcpStats.availabilityWindows)-1]
+
+	if !up {
+		w.UnsuccessfulProbes += 1
+		return
+	}
+
+	w.SuccessfulProbes += 1
+	w.AverageRTT += (rtt - w.AverageRTT) / float32(w.SuccessfulProbes)
+}
+
+// windowAt returns the windows with the one of the given size that t falls in
+// as the last one, appended if it isn't already.
+// t must not be before the start of the last window.
+func windowAt(windows []AvailabilityWindow, window time.Duration, t time.Time) []AvailabilityWindow {
+	start := windowStart(t, window)
+	if n := len(windows); n == 0 || !windows[n-1].Start.Equal(start) {
+		windows = append(windows, AvailabilityWindow{Start: start})
+	}
+
+	return windows
+}
+
+// windowEnd returns the end of the window of the given size that starts at start.
+// The windows of up to a day end at the latest at the next midnight,
+// where the windows of the next day start.
+func windowEnd(start time.Time, window time.Duration) time.Time {
+	end := start.Add(window)
+	if window > 24*time.Hour {
+		return end
+	}
+
+	year, month, day := start.Date()
+	nextMidnight := time.Date(year, month, day+1, 0, 0, 0, 0, start.Location())
+	if end.After(nextMidnight) {
+		return nextMidnight
+	}
+
+	return end
+}
+
+// addTime adds the time from one probe to the next to the uptime or
+// the downtime of the windows, split at their boundaries so that each window
+// only gets the part within it. It returns the windows unchanged without windows.
+func addTime(windows []AvailabilityWindow, window time.Duration, from, to time.Time, up bool) []AvailabilityWindow {
+	if window == 0 {
+		return windows
+	}
+
+	for from.Before(to) {
+		windows = windowAt(windows, window, from)
+		w := &windows[len(windows)-1]
+
+		end := windowEnd(w.Start, window)
+		if end.After(to) {
+			end = to
+		}
+
+		if up {
+			w.Uptime += end.Sub(from)
+		} else {
+			w.Downtime += end.Sub(from)
+		}
+		from = end
+	}
+
+	return windows
+}
+
+// now returns the end of the statistics, the current time until they end.
+func (tcpStats *stats) now() time.Time {
+	if !tcpStats.endTime.IsZero() {
+		return tcpStats.endTime
+	}
+
+	return time.Now()
+}
+
+// accountedTime returns the uptime, the downtime and a copy of the windows
+// including the time since the last probe.
+func (tcpStats *stats) accountedTime() (time.Duration, time.Duration, []AvailabilityWindow) {
+	uptime, downtime := tcpStats.totalUptime, tcpStats.totalDowntime
+	windows := append([]AvailabilityWindow(nil), tcpStats.availabilityWindows...)
+	if tcpStats.lastProbe.IsZero() {
+		return uptime, downtime, windows
+	}
+
+	now := tcpStats.now()
+	elapsed := max(now.Sub(tcpStats.lastProbe), 0)
+	if tcpStats.lastProbeUp {
+		uptime += elapsed
+	} else {
+		downtime += elapsed
+	}
+
+	windows = addTime(windows, tcpStats.userInput.AvailabilityWindow, tcpStats.lastProbe, now, tcpStats.lastProbeUp)
+
+	return uptime, downtime, windows
+}
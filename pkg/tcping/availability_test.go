@@ -0,0 +1,109 @@
+package tcping
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWindowStart(t *testing.T) {
+	at := time.Date(2023, 9, 10, 14, 35, 20, 0, time.Local)
+
+	assert.Equal(t, time.Date(2023, 9, 10, 14, 0, 0, 0, time.Local), windowStart(at, time.Hour))
+	assert.Equal(t, time.Date(2023, 9, 10, 14, 30, 0, 0, time.Local), windowStart(at, 15*time.Minute))
+	assert.Equal(t, time.Date(2023, 9, 10, 0, 0, 0, 0, time.Local), windowStart(at, 24*time.Hour))
+}
+
+func TestAvailability(t *testing.T) {
+	stats := createTestStats(t)
+	stats.userInput.AvailabilityWindow = time.Hour
+
+	start := time.Date(2023, 9, 10, 14, 0, 0, 0, time.Local)
+	stats.handleConnSuccess(10, start)
+	stats.handleConnError(start.Add(45*time.Minute), nil)
+	stats.handleConnSuccess(20, start.Add(time.Hour))
+	stats.endTime = start.Add(75 * time.Minute)
+
+	s := stats.statistics()
+	assert.Equal(t, 80.0, s.Availability)
+	if assert.Len(t, s.AvailabilityWindows, 2) {
+		assert.Equal(t, start, s.AvailabilityWindows[0].Start)
+		assert.Equal(t, 75.0, s.AvailabilityWindows[0].Availability())
+		assert.Equal(t, uint(1), s.AvailabilityWindows[0].SuccessfulProbes)
+		assert.Equal(t, uint(1), s.AvailabilityWindows[0].UnsuccessfulProbes)
+		assert.Equal(t, float32(10), s.AvailabilityWindows[0].AverageRTT)
+		assert.Equal(t, start.Add(time.Hour), s.AvailabilityWindows[1].Start)
+		assert.Equal(t, 100.0, s.AvailabilityWindows[1].Availability())
+	}
+
+	data := NewStatisticsJSONData(s)
+	assert.Equal(t, "80.000", data.Availability)
+	if assert.Len(t, data.AvailabilityWindows, 2) {
+		assert.Equal(t, "10.000", data.AvailabilityWindows[0].LatencyAvg)
+	}
+}
+
+func TestAvailabilityAcrossWindows(t *testing.T) {
+	stats := createTestStats(t)
+	stats.userInput.AvailabilityWindow = time.Hour
+
+	// the probe gaps span several windows
+	start := time.Date(2023, 9, 10, 14, 0, 0, 0, time.Local)
+	stats.handleConnSuccess(10, start)
+	stats.handleConnError(start.Add(45*time.Minute), nil)
+	stats.handleConnSuccess(20, start.Add(135*time.Minute))
+	stats.endTime = start.Add(210 * time.Minute)
+
+	s := stats.statistics()
+	if assert.Len(t, s.AvailabilityWindows, 4) {
+		assert.Equal(t, 45*time.Minute, s.AvailabilityWindows[0].Uptime)
+		assert.Equal(t, 15*time.Minute, s.AvailabilityWindows[0].Downtime)
+
+		assert.Equal(t, start.Add(time.Hour), s.AvailabilityWindows[1].Start)
+		assert.Equal(t, time.Duration(0), s.AvailabilityWindows[1].Uptime)
+		assert.Equal(t, time.Hour, s.AvailabilityWindows[1].Downtime)
+		assert.Equal(t, uint(0), s.AvailabilityWindows[1].SuccessfulProbes)
+
+		assert.Equal(t, 45*time.Minute, s.AvailabilityWindows[2].Uptime)
+		assert.Equal(t, 15*time.Minute, s.AvailabilityWindows[2].Downtime)
+		assert.Equal(t, uint(1), s.AvailabilityWindows[2].SuccessfulProbes)
+
+		// the time since the last probe is split as well
+		assert.Equal(t, start.Add(3*time.Hour), s.AvailabilityWindows[3].Start)
+		assert.Equal(t, 30*time.Minute, s.AvailabilityWindows[3].Uptime)
+		assert.Equal(t, 100.0, s.AvailabilityWindows[3].Availability())
+	}
+
+	// and the windows of a day end at midnight
+	stats = createTestStats(t)
+	stats.userInput.AvailabilityWindow = 7 * time.Hour
+	stats.handleConnSuccess(10, time.Date(2023, 9, 10, 23, 0, 0, 0, time.Local))
+	stats.handleConnSuccess(10, time.Date(2023, 9, 11, 1, 0, 0, 0, time.Local))
+	if assert.Len(t, stats.availabilityWindows, 2) {
+		assert.Equal(t, time.Hour, stats.availabilityWindows[0].Uptime)
+		assert.Equal(t, time.Date(2023, 9, 11, 0, 0, 0, 0, time.Local), stats.availabilityWindows[1].Start)
+		assert.Equal(t, time.Hour, stats.availabilityWindows[1].Uptime)
+	}
+}
+
+func TestTimeAccounting(t *testing.T) {
+	stats := createTestStats(t)
+
+	// slower than the interval of the probes
+	start := time.Now()
+	stats.handleConnSuccess(10, start)
+	stats.handleConnSuccess(10, start.Add(3*time.Second))
+	stats.handleConnError(start.Add(5*time.Second), nil)
+	stats.handleConnSuccess(10, start.Add(5500*time.Millisecond))
+
+	assert.Equal(t, 5*time.Second, stats.totalUptime)
+	assert.Equal(t, 500*time.Millisecond, stats.totalDowntime)
+	assert.Equal(t, 500*time.Millisecond, stats.longestDowntime.Duration)
+
+	// the time since the last probe counts as well
+	stats.endTime = start.Add(6 * time.Second)
+	s := stats.statistics()
+	assert.Equal(t, 5500*time.Millisecond, s.TotalUptime)
+	assert.Equal(t, 500*time.Millisecond, s.TotalDowntime)
+}
@@ -0,0 +1,78 @@
+package tcping
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"strings"
+	"time"
+)
+
+// maxBannerSize limits how much of the banner is read
+// when the server doesn't end its first line.
+const maxBannerSize = 256
+
+// readBanner reads the first line the server sends after
+// connecting, within the timeout. The line break is trimmed.
+func readBanner(conn net.Conn, timeout time.Duration) (string, error) {
+	if timeout > 0 {
+		conn.SetReadDeadline(time.Now().Add(timeout))
+	}
+
+	var banner []byte
+	buf := make([]byte, maxBannerSize)
+	for len(banner) < maxBannerSize {
+		n, err := conn.Read(buf[:maxBannerSize-len(banner)])
+		banner = append(banner, buf[:n]...)
+		if i := bytes.IndexByte(banner, '\n'); i >= 0 {
+			banner = banner[:i]
+			break
+		}
+
+		if errors.Is(err, io.EOF) || errors.Is(err, os.ErrDeadlineExceeded) {
+			break
+		}
+		if err != nil {
+			return "", err
+		}
+	}
+
+	if len(banner) == 0 {
+		return "", errors.New("no banner received")
+	}
+
+	return strings.TrimSuffix(string(banner), "\r"), nil
+}
+
+// checkBanner reads the banner of the connection, printing the first
+// one and its changes. Probes without a banner fail.
+func (tcpStats *stats) checkBanner(conn net.Conn) error {
+	banner, err := readBanner(conn, tcpStats.userInput.Timeout)
+	if err != nil {
+		err = tcpStats.clientAuthError(err)
+		if tcpStats.banner != "" {
+			err = fmt.Errorf("the banner disappeared: %w", err)
+		}
+
+		tcpStats.printer.PrintError("Probe to %s on port %d failed: %s",
+			tcpStats.userInput.ip, tcpStats.userInput.Port, err)
+		return err
+	}
+
+	switch tcpStats.banner {
+	case banner:
+	case "":
+		tcpStats.printer.PrintInfo("Banner of %s on port %d: %q",
+			tcpStats.userInput.ip, tcpStats.userInput.Port, banner)
+	default:
+		tcpStats.printer.PrintError("The banner of %s on port %d changed from %q to %q",
+			tcpStats.userInput.ip, tcpStats.userInput.Port, tcpStats.banner, banner)
+	}
+
+	tcpStats.banner = banner
+
+	return nil
+}
@@ -0,0 +1,57 @@
+package tcping
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBanner(t *testing.T) {
+	srv, err := net.Listen("tcp", "127.0.0.1:12345")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	t.Cleanup(func() { srv.Close() })
+
+	// the banners sent to the consecutive connections, the third one is silent
+	banners := []string{"SSH-2.0-OpenSSH_9.6\r\n", "SSH-2.0-OpenSSH_9.6\r\n", "", "SSH-2.0-OpenSSH_9.7\r\nextra"}
+	go func() {
+		for _, banner := range banners {
+			c, err := srv.Accept()
+			if err != nil {
+				return
+			}
+
+			c.Write([]byte(banner))
+			c.Close()
+		}
+	}()
+
+	var results []Result
+	p, err := New(Options{
+		Printer:               &dummyPrinter{},
+		Hostname:              "127.0.0.1",
+		Port:                  12345,
+		IntervalBetweenProbes: 2 * time.Millisecond,
+		Timeout:               100 * time.Millisecond,
+		ProbesBeforeQuit:      uint(len(banners)),
+		Banner:                true,
+		Hooks: Hooks{
+			OnProbe: func(r Result) { results = append(results, r) },
+		},
+	})
+	assert.NoError(t, err)
+
+	p.Run()
+
+	if assert.Len(t, results, 4) {
+		assert.True(t, results[0].Success)
+		assert.Equal(t, "SSH-2.0-OpenSSH_9.6", results[0].Banner)
+		assert.True(t, results[1].Success)
+		assert.False(t, results[2].Success)
+		assert.True(t, results[3].Success)
+		assert.Equal(t, "SSH-2.0-OpenSSH_9.7", results[3].Banner)
+	}
+}
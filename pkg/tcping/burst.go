@@ -0,0 +1,130 @@
+package tcping
+
+import (
+	"context"
+	"errors"
+	"net"
+	"slices"
+	"sync"
+	"time"
+)
+
+// burst are the connections of the last probe with Options.Burst.
+type burst struct {
+	size     uint    // size is the number of connections made, 0 without a burst.
+	min      float32 // min is the lowest RTT of the burst in milliseconds.
+	median   float32 // median is the median RTT of the burst in milliseconds.
+	max      float32 // max is the highest RTT of the burst in milliseconds.
+	failures uint    // failures are the connections of the burst that failed.
+}
+
+// burstDial is a connection of the burst.
+type burstDial struct {
+	conn     net.Conn
+	duration time.Duration
+	err      error
+}
+
+// dialBurst makes the connections of the burst with dial, back to back or
+// at once with Options.BurstConcurrent, keeps the fastest one and closes
+// the others. It returns the RTT of the burst, the lowest one or the
+// median with Options.BurstMedian, and the error of the last connection
+// if none of them succeeded.
+func (tcpStats *stats) dialBurst(ctx context.Context, dial func() (net.Conn, error)) (net.Conn, time.Duration, error) {
+	var dials []burstDial
+	if tcpStats.userInput.BurstConcurrent {
+		dials = dialConcurrently(tcpStats.userInput.Burst, dial)
+	} else {
+		dials = dialBackToBack(ctx, tcpStats.userInput.Burst, dial)
+	}
+
+	var fastest net.Conn
+	var durations []time.Duration
+	var err error
+	b := burst{size: uint(len(dials))}
+	for _, d := range dials {
+		if d.err != nil {
+			err = d.err
+			b.failures++
+			continue
+		}
+
+		if fastest == nil || d.duration < slices.Min(durations) {
+			if fastest != nil {
+				fastest.Close()
+			}
+			fastest = d.conn
+		} else {
+			d.conn.Close()
+		}
+		durations = append(durations, d.duration)
+		tcpStats.burstRtts.add(nanoToMillisecond(d.duration.Nanoseconds()))
+	}
+
+	tcpStats.burst = b
+	if fastest == nil {
+		return nil, 0, err
+	}
+
+	slices.Sort(durations)
+	median := durations[len(durations)/2]
+	if len(durations)%2 == 0 {
+		median = (durations[len(durations)/2-1] + median) / 2
+	}
+	tcpStats.burst.min = nanoToMillisecond(durations[0].Nanoseconds())
+	tcpStats.burst.median = nanoToMillisecond(median.Nanoseconds())
+	tcpStats.burst.max = nanoToMillisecond(durations[len(durations)-1].Nanoseconds())
+	tcpStats.log().Debug("burst done", "size", b.size, "failures", b.failures,
+		"min", tcpStats.burst.min, "median", tcpStats.burst.median, "max", tcpStats.burst.max)
+
+	if tcpStats.userInput.BurstMedian {
+		return fastest, median, nil
+	}
+	return fastest, durations[0], nil
+}
+
+// dialBackToBack makes the connections one after the other.
+func dialBackToBack(ctx context.Context, size uint, dial func() (net.Conn, error)) []burstDial {
+	var dials []burstDial
+	for uint(len(dials)) < size {
+		start := time.Now()
+		conn, err := dial()
+		dials = append(dials, burstDial{conn, time.Since(start), err})
+
+		// the proxy or the tunnel failing isn't the target dropping SYNs
+		var proxyErr proxyError
+		if err != nil && (ctx.Err() != nil || errors.As(err, &proxyErr)) {
+			break
+		}
+	}
+
+	return dials
+}
+
+// dialConcurrently makes the connections at the same time, and keeps
+// them open until they're all done, so that they fill the accept queue
+// and count against the limits of connections per IP of the target.
+func dialConcurrently(size uint, dial func() (net.Conn, error)) []burstDial {
+	dials := make([]burstDial, size)
+
+	var wg sync.WaitGroup
+	for i := range dials {
+		wg.Add(1)
+		go func(d *burstDial) {
+			defer wg.Done()
+			start := time.Now()
+			d.conn, d.err = dial()
+			d.duration = time.Since(start)
+		}(&dials[i])
+	}
+	wg.Wait()
+
+	return dials
+}
+
+// takeBurst returns the burst of the probe, if any, and resets it.
+func (tcpStats *stats) takeBurst() burst {
+	b := tcpStats.burst
+	tcpStats.burst = burst{}
+	return b
+}
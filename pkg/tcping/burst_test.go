@@ -0,0 +1,164 @@
+package tcping
+
+import (
+	"context"
+	"errors"
+	"net"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDialBurst(t *testing.T) {
+	stats := createTestStats(t)
+	stats.userInput.Burst = 4
+
+	// the second connection is lost, the others take 1, 3 and 2 ms
+	var dials int
+	delays := []time.Duration{time.Millisecond, 0, 3 * time.Millisecond, 2 * time.Millisecond}
+	var conns []net.Conn
+	dial := func() (net.Conn, error) {
+		delay := delays[dials]
+		dials++
+		if delay == 0 {
+			return nil, syscall.ETIMEDOUT
+		}
+		time.Sleep(delay)
+		client, server := net.Pipe()
+		server.Close()
+		conns = append(conns, client)
+		return client, nil
+	}
+
+	conn, rtt, err := stats.dialBurst(context.Background(), dial)
+	assert.NoError(t, err)
+	assert.Equal(t, 4, dials)
+	assert.Same(t, conns[0], conn)
+
+	b := stats.takeBurst()
+	assert.Equal(t, uint(4), b.size)
+	assert.Equal(t, uint(1), b.failures)
+	assert.Equal(t, nanoToMillisecond(rtt.Nanoseconds()), b.min)
+	assert.Less(t, b.min, b.median)
+	assert.Zero(t, stats.takeBurst().size)
+
+	// the median is the one of the connections that succeeded
+	stats.userInput.BurstMedian = true
+	dials, conns = 0, nil
+	_, rtt, err = stats.dialBurst(context.Background(), dial)
+	assert.NoError(t, err)
+	assert.Equal(t, stats.burst.median, nanoToMillisecond(rtt.Nanoseconds()))
+}
+
+func TestDialBurstFailed(t *testing.T) {
+	stats := createTestStats(t)
+	stats.userInput.Burst = 3
+
+	var dials int
+	_, _, err := stats.dialBurst(context.Background(), func() (net.Conn, error) {
+		dials++
+		return nil, syscall.ECONNREFUSED
+	})
+	assert.ErrorIs(t, err, syscall.ECONNREFUSED)
+	assert.Equal(t, 3, dials)
+	assert.Equal(t, uint(3), stats.takeBurst().failures)
+
+	// a broken proxy fails the whole burst at once
+	dials = 0
+	_, _, err = stats.dialBurst(context.Background(), func() (net.Conn, error) {
+		dials++
+		return nil, proxyError{err: errors.New("the proxy answered 502 Bad Gateway")}
+	})
+	assert.Error(t, err)
+	assert.Equal(t, 1, dials)
+}
+
+func TestBurst(t *testing.T) {
+	srv := testServerListen(t)
+	t.Cleanup(func() {
+		if err := srv.Close(); err != nil {
+			t.Errorf("srv close: %v", err)
+		}
+	})
+
+	for _, concurrent := range []bool{false, true} {
+		var results []Result
+		p, err := New(Options{
+			Printer:               &dummyPrinter{},
+			Hostname:              "127.0.0.1",
+			Port:                  12345,
+			IntervalBetweenProbes: 2 * time.Millisecond,
+			Timeout:               time.Second,
+			ProbesBeforeQuit:      2,
+			Burst:                 3,
+			BurstConcurrent:       concurrent,
+			Hooks: Hooks{
+				OnProbe: func(r Result) { results = append(results, r) },
+			},
+		})
+		assert.NoError(t, err)
+
+		p.Run()
+		p.Shutdown()
+
+		if assert.Len(t, results, 2) {
+			for _, r := range results {
+				assert.True(t, r.Success)
+				assert.Equal(t, r.BurstMin, r.RTT)
+				assert.LessOrEqual(t, r.BurstMin, r.BurstMedian)
+				assert.LessOrEqual(t, r.BurstMedian, r.BurstMax)
+			}
+		}
+		assert.Zero(t, p.Statistics().BurstFailures)
+		assert.True(t, p.Statistics().BurstRttResults.HasResults)
+	}
+
+	_, err := New(Options{Hostname: "127.0.0.1", Port: 12345, BurstMedian: true})
+	assert.Error(t, err)
+	_, err = New(Options{Hostname: "127.0.0.1", Port: 12345, Burst: 3, BurstConcurrent: true, TFO: true})
+	assert.Error(t, err)
+}
+
+func TestDialConcurrently(t *testing.T) {
+	stats := createTestStats(t)
+	stats.userInput.Burst = 3
+	stats.userInput.BurstConcurrent = true
+
+	// every connection waits for the others to start
+	var started sync.WaitGroup
+	started.Add(3)
+	var open atomic.Int32
+	dial := func() (net.Conn, error) {
+		started.Done()
+		started.Wait()
+		if open.Add(1) > 2 {
+			return nil, syscall.ECONNREFUSED
+		}
+		client, server := net.Pipe()
+		server.Close()
+		return client, nil
+	}
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		conn, _, err := stats.dialBurst(context.Background(), dial)
+		assert.NoError(t, err)
+		assert.NotNil(t, conn)
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("the connections weren't made at the same time")
+	}
+
+	b := stats.takeBurst()
+	assert.Equal(t, uint(3), b.size)
+	assert.Equal(t, uint(1), b.failures)
+	assert.LessOrEqual(t, b.min, b.max)
+	assert.True(t, stats.statistics().BurstRttResults.HasResults)
+}
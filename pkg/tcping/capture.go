@@ -0,0 +1,225 @@
+package tcping
+
+import (
+	"encoding/binary"
+	"fmt"
+	"net/netip"
+	"sync"
+	"time"
+)
+
+// the TCP flags of the handshake
+const (
+	tcpFlagSYN = 0x02
+	tcpFlagACK = 0x10
+)
+
+// captureSnaplen is the most bytes captured of a packet.
+const captureSnaplen = 65535
+
+// protocolTCP is the protocol number of TCP, to parse the captured packets.
+const protocolTCP = 6
+
+// handshakeWait is how long the captured handshake of a successful
+// probe is waited for, as the capture is read on its own goroutine.
+const handshakeWait = 100 * time.Millisecond
+
+// flowExpiry is how long the handshakes that were never
+// waited for, e.g. of the failed probes, are kept.
+const flowExpiry = time.Minute
+
+// wirePacket is a TCP segment to or from the port of the target,
+// timestamped by the kernel when it was captured.
+type wirePacket struct {
+	time     time.Time
+	src, dst netip.AddrPort
+	flags    uint8
+	// outbound is set for the segments sent to the port of the target.
+	outbound bool
+	// data is the IP packet.
+	data []byte
+}
+
+// parseSegment parses the IP packet of a TCP segment.
+// It reports false if it's not one.
+func parseSegment(data []byte, port uint16) (wirePacket, bool) {
+	var p wirePacket
+	if len(data) == 0 {
+		return p, false
+	}
+
+	var src, dst netip.Addr
+	var tcp []byte
+	switch data[0] >> 4 {
+	case 4:
+		ihl := int(data[0]&0x0f) * 4
+		if len(data) < 20 || ihl < 20 || len(data) < ihl+20 || data[9] != protocolTCP {
+			return p, false
+		}
+		src = netip.AddrFrom4([4]byte(data[12:16]))
+		dst = netip.AddrFrom4([4]byte(data[16:20]))
+		tcp = data[ihl:]
+	case 6:
+		// the probes don't send extension headers
+		if len(data) < 60 || data[6] != protocolTCP {
+			return p, false
+		}
+		src = netip.AddrFrom16([16]byte(data[8:24]))
+		dst = netip.AddrFrom16([16]byte(data[24:40]))
+		tcp = data[40:]
+	default:
+		return p, false
+	}
+
+	p.src = netip.AddrPortFrom(src, binary.BigEndian.Uint16(tcp[0:2]))
+	p.dst = netip.AddrPortFrom(dst, binary.BigEndian.Uint16(tcp[2:4]))
+	p.flags = tcp[13]
+	p.outbound = p.dst.Port() == port
+	p.data = data
+
+	return p, true
+}
+
+// flowKey tells the connections of the probes apart.
+type flowKey struct {
+	localPort uint16
+	remote    netip.Addr
+}
+
+// flowTimes are the times the segments of a handshake were captured.
+type flowTimes struct {
+	syn, synAck, ack time.Time
+	// done is closed once the ACK was captured.
+	done chan struct{}
+}
+
+// handshakes collects the handshakes of the captured connections
+// until the probes pick them up, see Options.Handshake.
+// It's safe for concurrent use.
+type handshakes struct {
+	mu    sync.Mutex
+	flows map[flowKey]*flowTimes
+}
+
+func newHandshakes() *handshakes {
+	return &handshakes{flows: make(map[flowKey]*flowTimes)}
+}
+
+// flow returns the times of the connection, adding it if it's new.
+func (h *handshakes) flow(key flowKey) *flowTimes {
+	f, ok := h.flows[key]
+	if !ok {
+		f = &flowTimes{done: make(chan struct{})}
+		h.flows[key] = f
+	}
+	return f
+}
+
+// add records the segment if it's part of a handshake.
+// It is meant to be called with every captured segment.
+func (h *handshakes) add(p wirePacket) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if p.outbound {
+		key := flowKey{localPort: p.src.Port(), remote: p.dst.Addr()}
+		switch {
+		case p.flags&tcpFlagSYN != 0:
+			for k, f := range h.flows {
+				if !f.syn.IsZero() && p.time.Sub(f.syn) > flowExpiry {
+					delete(h.flows, k)
+				}
+			}
+
+			// the SYN-ACK answers the last SYN, if it was retransmitted
+			f := h.flow(key)
+			f.syn = p.time
+		case p.flags&tcpFlagACK != 0:
+			if f, ok := h.flows[key]; ok && !f.synAck.IsZero() && f.ack.IsZero() {
+				f.ack = p.time
+				close(f.done)
+			}
+		}
+		return
+	}
+
+	key := flowKey{localPort: p.dst.Port(), remote: p.src.Addr()}
+	if p.flags&(tcpFlagSYN|tcpFlagACK) == tcpFlagSYN|tcpFlagACK {
+		if f, ok := h.flows[key]; ok && !f.syn.IsZero() && f.synAck.IsZero() {
+			f.synAck = p.time
+		}
+	}
+}
+
+// wait waits for the handshake of the connection from the local port to
+// the remote address and returns its timing, with the RTT of the probe in
+// milliseconds. It reports false if it wasn't captured in time.
+func (h *handshakes) wait(localPort uint16, remote netip.Addr, rtt float32) (Handshake, bool) {
+	key := flowKey{localPort: localPort, remote: remote.Unmap().WithZone("")}
+
+	h.mu.Lock()
+	f := h.flow(key)
+	h.mu.Unlock()
+
+	timer := time.NewTimer(handshakeWait)
+	defer timer.Stop()
+
+	select {
+	case <-f.done:
+	case <-timer.C:
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	delete(h.flows, key)
+
+	if f.ack.IsZero() {
+		return Handshake{}, false
+	}
+
+	network := nanoToMillisecond(f.synAck.Sub(f.syn).Nanoseconds())
+	return Handshake{
+		Network: network,
+		Stack:   rtt - network,
+		ACK:     nanoToMillisecond(f.ack.Sub(f.synAck).Nanoseconds()),
+	}, true
+}
+
+// openCapture starts capturing the probes for Options.Handshake and
+// Options.Pcap. If it can't, it fails with Options.Pcap, otherwise the
+// probes are timed as usual and why is printed when they start.
+func (tcpStats *stats) openCapture() error {
+	var handlers []func(wirePacket)
+	if tcpStats.userInput.Handshake {
+		tcpStats.userInput.handshakes = newHandshakes()
+		handlers = append(handlers, tcpStats.userInput.handshakes.add)
+	}
+	if tcpStats.userInput.Pcap != nil {
+		tcpStats.userInput.pcapFlows = &pcapFlows{w: tcpStats.userInput.Pcap}
+		handlers = append(handlers, tcpStats.userInput.pcapFlows.add)
+	}
+
+	var c *capture
+	err := tcpStats.inNetns(func() error {
+		var err error
+		c, err = openCapture(tcpStats.userInput.Port, func(p wirePacket) {
+			for _, handle := range handlers {
+				handle(p)
+			}
+		})
+		return err
+	})
+	if err != nil {
+		tcpStats.userInput.handshakes = nil
+		tcpStats.userInput.pcapFlows = nil
+		if tcpStats.userInput.Pcap != nil {
+			return fmt.Errorf("unable to capture the probes: %w", err)
+		}
+
+		tcpStats.userInput.captureErr = err
+		return nil
+	}
+
+	tcpStats.userInput.capture = c
+	return nil
+}
@@ -0,0 +1,187 @@
+//go:build linux
+
+package tcping
+
+import (
+	"encoding/binary"
+	"errors"
+	"os"
+	"syscall"
+	"time"
+
+	"golang.org/x/net/bpf"
+	"golang.org/x/sys/unix"
+)
+
+// captureSupported reports whether the probes can be captured on this platform.
+const captureSupported = true
+
+// capture captures the TCP segments to and from the port of
+// the target on all the interfaces, with a packet socket.
+type capture struct {
+	f *os.File
+}
+
+// openCapture starts capturing the segments to and from the port,
+// passing them to handle on its own goroutine until it's closed.
+// It needs CAP_NET_RAW.
+func openCapture(port uint16, handle func(wirePacket)) (*capture, error) {
+	// the socket gets no packet before it's bound,
+	// so that they're all filtered
+	fd, err := unix.Socket(unix.AF_PACKET, unix.SOCK_DGRAM|unix.SOCK_NONBLOCK|unix.SOCK_CLOEXEC, 0)
+	if err != nil {
+		return nil, os.NewSyscallError("socket", err)
+	}
+
+	if err := setupCapture(fd, port); err != nil {
+		unix.Close(fd)
+		return nil, err
+	}
+
+	c := &capture{f: os.NewFile(uintptr(fd), "capture")}
+	go c.read(port, handle)
+
+	return c, nil
+}
+
+// setupCapture filters the segments of the port and binds the socket.
+func setupCapture(fd int, port uint16) error {
+	filter, err := captureFilter(port)
+	if err != nil {
+		return err
+	}
+	prog := unix.SockFprog{Len: uint16(len(filter)), Filter: &filter[0]}
+	if err := unix.SetsockoptSockFprog(fd, unix.SOL_SOCKET, unix.SO_ATTACH_FILTER, &prog); err != nil {
+		return os.NewSyscallError("setsockopt", err)
+	}
+
+	if err := unix.SetsockoptInt(fd, unix.SOL_SOCKET, unix.SO_TIMESTAMPNS, 1); err != nil {
+		return os.NewSyscallError("setsockopt", err)
+	}
+
+	sa := &unix.SockaddrLinklayer{Protocol: htons(unix.ETH_P_ALL)}
+	return os.NewSyscallError("bind", unix.Bind(fd, sa))
+}
+
+// captureFilter only passes the TCP segments to and from the port. The
+// packet socket strips the link layer header, the packets start with the
+// IP header.
+func captureFilter(port uint16) ([]unix.SockFilter, error) {
+	const drop, accept = 0, captureSnaplen
+	raw, err := bpf.Assemble([]bpf.Instruction{
+		bpf.LoadAbsolute{Off: 0, Size: 1},
+		bpf.ALUOpConstant{Op: bpf.ALUOpAnd, Val: 0xf0},
+		bpf.JumpIf{Cond: bpf.JumpEqual, Val: 0x60, SkipTrue: 11},
+		bpf.JumpIf{Cond: bpf.JumpEqual, Val: 0x40, SkipFalse: 9},
+
+		// IPv4, the unfragmented TCP segments
+		bpf.LoadAbsolute{Off: 9, Size: 1},
+		bpf.JumpIf{Cond: bpf.JumpEqual, Val: protocolTCP, SkipFalse: 7},
+		bpf.LoadAbsolute{Off: 6, Size: 2},
+		bpf.JumpIf{Cond: bpf.JumpBitsSet, Val: 0x1fff, SkipTrue: 5},
+		bpf.LoadMemShift{Off: 0},
+		bpf.LoadIndirect{Off: 0, Size: 2},
+		bpf.JumpIf{Cond: bpf.JumpEqual, Val: uint32(port), SkipTrue: 9},
+		bpf.LoadIndirect{Off: 2, Size: 2},
+		bpf.JumpIf{Cond: bpf.JumpEqual, Val: uint32(port), SkipTrue: 7},
+		bpf.RetConstant{Val: drop},
+
+		// IPv6, without extension headers
+		bpf.LoadAbsolute{Off: 6, Size: 1},
+		bpf.JumpIf{Cond: bpf.JumpEqual, Val: protocolTCP, SkipFalse: 5},
+		bpf.LoadAbsolute{Off: 40, Size: 2},
+		bpf.JumpIf{Cond: bpf.JumpEqual, Val: uint32(port), SkipTrue: 2},
+		bpf.LoadAbsolute{Off: 42, Size: 2},
+		bpf.JumpIf{Cond: bpf.JumpEqual, Val: uint32(port), SkipFalse: 1},
+		bpf.RetConstant{Val: accept},
+		bpf.RetConstant{Val: drop},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	filter := make([]unix.SockFilter, len(raw))
+	for i, ins := range raw {
+		filter[i] = unix.SockFilter{Code: ins.Op, Jt: ins.Jt, Jf: ins.Jf, K: ins.K}
+	}
+	return filter, nil
+}
+
+// read reads the captured segments until the capture is closed.
+func (c *capture) read(port uint16, handle func(wirePacket)) {
+	raw, err := c.f.SyscallConn()
+	if err != nil {
+		return
+	}
+
+	buf := make([]byte, captureSnaplen)
+	oob := make([]byte, unix.CmsgSpace(16))
+	for {
+		var n, oobn int
+		var from unix.Sockaddr
+		var recvErr error
+		err := raw.Read(func(fd uintptr) bool {
+			n, oobn, _, from, recvErr = unix.Recvmsg(int(fd), buf, oob, 0)
+			return !errors.Is(recvErr, syscall.EAGAIN)
+		})
+		if err != nil {
+			return
+		}
+		if recvErr != nil {
+			continue
+		}
+
+		p, ok := parseSegment(buf[:n], port)
+		if !ok {
+			continue
+		}
+
+		// the segments over the loopback are captured twice, the copy of
+		// the sender is kept for the outbound ones and the one of the
+		// receiver for the inbound ones, like on the other interfaces
+		if ll, ok := from.(*unix.SockaddrLinklayer); ok {
+			if p.outbound != (ll.Pkttype == unix.PACKET_OUTGOING) {
+				continue
+			}
+		}
+
+		p.time = captureTime(oob[:oobn])
+		p.data = append([]byte(nil), p.data...)
+		handle(p)
+	}
+}
+
+// captureTime returns the time the kernel captured the
+// packet at, or now if it's not in the control messages.
+func captureTime(oob []byte) time.Time {
+	msgs, err := unix.ParseSocketControlMessage(oob)
+	if err != nil {
+		return time.Now()
+	}
+
+	for _, m := range msgs {
+		if m.Header.Level != unix.SOL_SOCKET || m.Header.Type != unix.SO_TIMESTAMPNS {
+			continue
+		}
+
+		// a struct timespec of two longs
+		switch len(m.Data) {
+		case 16:
+			return time.Unix(int64(binary.NativeEndian.Uint64(m.Data)), int64(binary.NativeEndian.Uint64(m.Data[8:])))
+		case 8:
+			return time.Unix(int64(int32(binary.NativeEndian.Uint32(m.Data))), int64(int32(binary.NativeEndian.Uint32(m.Data[4:]))))
+		}
+	}
+
+	return time.Now()
+}
+
+// close stops the capture.
+func (c *capture) close() error {
+	return c.f.Close()
+}
+
+// htons converts the protocol to the network byte order of the packet sockets.
+func htons(v uint16) uint16 {
+	return v<<8 | v>>8
+}
@@ -0,0 +1,108 @@
+//go:build linux
+
+package tcping
+
+import (
+	"bytes"
+	"errors"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHandshake(t *testing.T) {
+	srv := testServerListen(t)
+	t.Cleanup(func() {
+		if err := srv.Close(); err != nil {
+			t.Errorf("srv close: %v", err)
+		}
+	})
+
+	var results []Result
+	p, err := New(Options{
+		Printer:               &dummyPrinter{},
+		Hostname:              "127.0.0.1",
+		Port:                  12345,
+		IntervalBetweenProbes: 2 * time.Millisecond,
+		Timeout:               time.Second,
+		ProbesBeforeQuit:      2,
+		Handshake:             true,
+		Hooks: Hooks{
+			OnProbe: func(r Result) { results = append(results, r) },
+		},
+	})
+	assert.NoError(t, err)
+	if errors.Is(p.stats.userInput.captureErr, syscall.EPERM) {
+		t.Skip("capturing the handshakes needs CAP_NET_RAW")
+	}
+	assert.NoError(t, p.stats.userInput.captureErr)
+
+	p.Run()
+	p.Shutdown()
+
+	if assert.Len(t, results, 2) {
+		for _, r := range results {
+			if assert.NotNil(t, r.Handshake) {
+				assert.Positive(t, r.Handshake.Network)
+				assert.InDelta(t, r.RTT, r.Handshake.Network+r.Handshake.Stack, 0.001)
+			}
+		}
+	}
+
+	assert.True(t, p.Statistics().NetworkRttResults.HasResults)
+
+	_, err = New(Options{
+		Printer:    &dummyPrinter{},
+		Hostname:   "127.0.0.1",
+		Port:       12345,
+		Persistent: true,
+		Handshake:  true,
+	})
+	assert.EqualError(t, err, "the handshake breakdown needs a new connection for every probe")
+}
+
+func TestPcap(t *testing.T) {
+	srv := testServerListen(t)
+	t.Cleanup(func() {
+		if err := srv.Close(); err != nil {
+			t.Errorf("srv close: %v", err)
+		}
+	})
+
+	var buf bytes.Buffer
+	pw, err := NewPcapWriter(&buf)
+	assert.NoError(t, err)
+
+	p, err := New(Options{
+		Printer:               &dummyPrinter{},
+		Hostname:              "127.0.0.1",
+		Port:                  12345,
+		IntervalBetweenProbes: 10 * time.Millisecond,
+		Timeout:               time.Second,
+		ProbesBeforeQuit:      2,
+		Pcap:                  pw,
+	})
+	if errors.Is(err, syscall.EPERM) {
+		t.Skip("capturing the probes needs CAP_NET_RAW")
+	}
+	assert.NoError(t, err)
+
+	p.Run()
+	time.Sleep(handshakeWait)
+	p.Shutdown()
+
+	// the capture may still be writing
+	pw.mu.Lock()
+	captured := bytes.Clone(buf.Bytes())
+	pw.mu.Unlock()
+
+	var comments []string
+	for _, packet := range readPcapng(t, captured) {
+		if packet.comment != "" {
+			comments = append(comments, packet.comment)
+		}
+	}
+	assert.Equal(t, []string{"tcping seq=1 to 127.0.0.1:12345", "tcping seq=2 to 127.0.0.1:12345"}, comments)
+}
@@ -0,0 +1,18 @@
+//go:build !linux
+
+package tcping
+
+import "errors"
+
+// captureSupported reports whether the probes can be captured on this platform.
+const captureSupported = false
+
+type capture struct{}
+
+func openCapture(_ uint16, _ func(wirePacket)) (*capture, error) {
+	return nil, errors.New("capturing the probes is only supported on Linux")
+}
+
+func (c *capture) close() error {
+	return nil
+}
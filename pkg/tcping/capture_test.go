@@ -0,0 +1,101 @@
+package tcping
+
+import (
+	"encoding/binary"
+	"net/netip"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testSegment returns the IP packet of a TCP segment without options.
+func testSegment(src, dst netip.AddrPort, flags uint8) []byte {
+	tcp := make([]byte, 20)
+	binary.BigEndian.PutUint16(tcp[0:2], src.Port())
+	binary.BigEndian.PutUint16(tcp[2:4], dst.Port())
+	tcp[12] = 5 << 4
+	tcp[13] = flags
+
+	if src.Addr().Is4() {
+		ip := make([]byte, 20)
+		ip[0] = 0x45
+		ip[9] = protocolTCP
+		copy(ip[12:16], src.Addr().AsSlice())
+		copy(ip[16:20], dst.Addr().AsSlice())
+		return append(ip, tcp...)
+	}
+
+	ip := make([]byte, 40)
+	ip[0] = 0x60
+	ip[6] = protocolTCP
+	copy(ip[8:24], src.Addr().AsSlice())
+	copy(ip[24:40], dst.Addr().AsSlice())
+	return append(ip, tcp...)
+}
+
+func TestParseSegment(t *testing.T) {
+	tests := []struct {
+		name     string
+		src, dst string
+		outbound bool
+	}{
+		{name: "IPv4 outbound", src: "192.0.2.1:40000", dst: "198.51.100.1:443", outbound: true},
+		{name: "IPv4 inbound", src: "198.51.100.1:443", dst: "192.0.2.1:40000"},
+		{name: "IPv6 outbound", src: "[2001:db8::1]:40000", dst: "[2001:db8::2]:443", outbound: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			src, dst := netip.MustParseAddrPort(tt.src), netip.MustParseAddrPort(tt.dst)
+			p, ok := parseSegment(testSegment(src, dst, tcpFlagSYN), 443)
+			require.True(t, ok)
+			assert.Equal(t, src, p.src)
+			assert.Equal(t, dst, p.dst)
+			assert.Equal(t, uint8(tcpFlagSYN), p.flags)
+			assert.Equal(t, tt.outbound, p.outbound)
+		})
+	}
+
+	udp := testSegment(netip.MustParseAddrPort("192.0.2.1:40000"), netip.MustParseAddrPort("198.51.100.1:443"), 0)
+	udp[9] = 17
+	_, ok := parseSegment(udp, 443)
+	assert.False(t, ok)
+
+	_, ok = parseSegment([]byte{0x45, 0}, 443)
+	assert.False(t, ok)
+}
+
+func TestHandshakes(t *testing.T) {
+	local := netip.MustParseAddrPort("192.0.2.1:40000")
+	remote := netip.MustParseAddrPort("198.51.100.1:443")
+	start := time.Now()
+
+	segment := func(flags uint8, outbound bool, after time.Duration) wirePacket {
+		src, dst := local, remote
+		if !outbound {
+			src, dst = remote, local
+		}
+		p, ok := parseSegment(testSegment(src, dst, flags), remote.Port())
+		require.True(t, ok)
+		p.time = start.Add(after)
+		return p
+	}
+
+	h := newHandshakes()
+	h.add(segment(tcpFlagSYN, true, 0))
+	// the SYN-ACK answers the retransmitted SYN
+	h.add(segment(tcpFlagSYN, true, time.Second))
+	h.add(segment(tcpFlagSYN|tcpFlagACK, false, time.Second+20*time.Millisecond))
+	h.add(segment(tcpFlagACK, true, time.Second+21*time.Millisecond))
+
+	hs, ok := h.wait(local.Port(), netip.MustParseAddr("::ffff:198.51.100.1"), 25)
+	require.True(t, ok)
+	assert.Equal(t, Handshake{Network: 20, Stack: 5, ACK: 1}, hs)
+	assert.Empty(t, h.flows)
+
+	// the handshake of another port isn't captured
+	_, ok = h.wait(local.Port()+1, remote.Addr(), 25)
+	assert.False(t, ok)
+}
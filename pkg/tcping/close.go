@@ -0,0 +1,61 @@
+package tcping
+
+import (
+	"errors"
+	"io"
+	"net"
+	"os"
+	"syscall"
+	"time"
+)
+
+// PeerClose is how the peer ended a successful connection
+// within Options.CloseWait.
+type PeerClose string
+
+const (
+	// PeerCloseFIN means the peer closed the connection gracefully.
+	PeerCloseFIN PeerClose = "fin"
+	// PeerCloseRST means the peer reset the connection it had accepted,
+	// e.g. a load balancer without any healthy backend.
+	PeerCloseRST PeerClose = "rst"
+	// PeerKeptOpen means the connection was still open at the end of the wait.
+	PeerKeptOpen PeerClose = "open"
+)
+
+// waitPeerClose reads from the connection until the peer closes it
+// or the wait is over, and returns how it ended and when.
+// Whatever the peer sends meanwhile is discarded.
+func waitPeerClose(conn net.Conn, wait time.Duration) (PeerClose, time.Duration) {
+	start := time.Now()
+	conn.SetReadDeadline(start.Add(wait))
+
+	buf := make([]byte, 4096)
+	for {
+		_, err := conn.Read(buf)
+		switch {
+		case err == nil:
+			continue
+		case errors.Is(err, io.EOF):
+			return PeerCloseFIN, time.Since(start)
+		case errors.Is(err, os.ErrDeadlineExceeded):
+			return PeerKeptOpen, wait
+		case errors.Is(err, syscall.ECONNRESET), errors.Is(err, syscall.ECONNABORTED):
+			return PeerCloseRST, time.Since(start)
+		default:
+			return "", time.Since(start)
+		}
+	}
+}
+
+// recordPeerClose counts how the peer ended the connection.
+func (tcpStats *stats) recordPeerClose(peerClose PeerClose) {
+	if peerClose == "" {
+		return
+	}
+
+	if tcpStats.peerCloses == nil {
+		tcpStats.peerCloses = make(map[PeerClose]uint)
+	}
+	tcpStats.peerCloses[peerClose] += 1
+}
@@ -0,0 +1,84 @@
+package tcping
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWaitPeerClose(t *testing.T) {
+	srv, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	t.Cleanup(func() { srv.Close() })
+
+	// the consecutive connections are closed, reset, then kept open
+	go func() {
+		for i := 0; ; i++ {
+			c, err := srv.Accept()
+			if err != nil {
+				return
+			}
+
+			switch i {
+			case 0:
+				c.Write([]byte("bye\r\n"))
+				c.Close()
+			case 1:
+				c.(*net.TCPConn).SetLinger(0)
+				c.Close()
+			default:
+				t.Cleanup(func() { c.Close() })
+			}
+		}
+	}()
+
+	for _, want := range []PeerClose{PeerCloseFIN, PeerCloseRST, PeerKeptOpen} {
+		conn, err := net.Dial("tcp", srv.Addr().String())
+		if !assert.NoError(t, err) {
+			continue
+		}
+
+		got, after := waitPeerClose(conn, 200*time.Millisecond)
+		assert.Equal(t, want, got)
+		if want == PeerKeptOpen {
+			assert.Equal(t, 200*time.Millisecond, after)
+		} else {
+			assert.Less(t, after, 200*time.Millisecond)
+		}
+		conn.Close()
+	}
+}
+
+func TestRecordPeerClose(t *testing.T) {
+	printer := &eventPrinter{}
+	stats := createTestStats(t)
+	stats.printer = printer
+
+	now := time.Now()
+	stats.peerClose, stats.peerCloseAfter = PeerCloseRST, time.Millisecond
+	stats.handleConnSuccess(10, now)
+	stats.peerClose, stats.peerCloseAfter = PeerCloseFIN, 2*time.Millisecond
+	stats.handleConnSuccess(10, now)
+	stats.handleConnSuccess(10, now)
+
+	assert.Equal(t, map[PeerClose]uint{PeerCloseRST: 1, PeerCloseFIN: 1}, stats.statistics().PeerCloses)
+	assert.Equal(t, "fin 1, rst 1", formatCounts(stats.peerCloses))
+	if assert.Len(t, printer.probes, 3) {
+		assert.Equal(t, []string{"127.0.0.1 reset the connection after 1.000 ms"}, probeDetails(printer.probes[0]))
+		assert.Equal(t, []string{"127.0.0.1 closed the connection after 2.000 ms"}, probeDetails(printer.probes[1]))
+		assert.Empty(t, probeDetails(printer.probes[2]))
+	}
+
+	_, err := New(Options{
+		Printer:               &dummyPrinter{},
+		Hostname:              "127.0.0.1",
+		Port:                  12345,
+		IntervalBetweenProbes: time.Second,
+		CloseWait:             time.Second,
+	})
+	assert.EqualError(t, err, "the close wait must be shorter than the interval between probes")
+}
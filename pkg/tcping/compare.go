@@ -0,0 +1,96 @@
+package tcping
+
+import (
+	"context"
+	"net/netip"
+	"slices"
+	"sync"
+	"time"
+)
+
+// compareInterval is the interval between the probes of [CompareAddrs].
+const compareInterval = 100 * time.Millisecond
+
+// CompareAddrs probes every address of the hostname in parallel the given
+// number of times, without printing, notifying or reporting the results,
+// and returns their statistics ordered from the lowest average RTT.
+// The addresses that didn't answer come last, in the order of the resolver.
+//
+// Only the connections are compared: the probes aren't captured, timed on
+// the wire or checked, by Options.SuccessCheck or Options.Prober. They still
+// go through Options.Proxy and Options.Jump, as the addresses might only be
+// reachable through them, and it's the RTT of that path that's compared.
+func CompareAddrs(ctx context.Context, opts Options, probes uint) ([]Statistics, error) {
+	addrs, err := ResolveAll(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	opts.Printer = discardPrinter{}
+	opts.Results = nil
+	opts.Notifiers = nil
+	opts.Hooks = Hooks{}
+	opts.Bell = ""
+	opts.Persistent = false
+	opts.CompareICMP = false
+	opts.PathMTU = false
+	opts.IPLookup = nil
+	opts.ReverseDNS = false
+	opts.Warmup = 0
+	opts.Pcap = nil
+	opts.Handshake = false
+	opts.EBPF = false
+	opts.SuccessCheck = nil
+	opts.Prober = nil
+	opts.ProbesBeforeQuit = probes
+	opts.IntervalBetweenProbes = compareInterval
+	if opts.Timeout == 0 {
+		opts.Timeout = time.Second
+	}
+
+	pingers := make([]*Pinger, len(addrs))
+	for i, addr := range addrs {
+		addrOpts := opts
+		addrOpts.Hosts = map[string][]netip.Addr{opts.Hostname: {addr}}
+
+		if pingers[i], err = New(addrOpts); err != nil {
+			return nil, err
+		}
+	}
+
+	var wg sync.WaitGroup
+	for _, pinger := range pingers {
+		wg.Add(1)
+		go func(pinger *Pinger) {
+			defer wg.Done()
+			pinger.RunContext(ctx)
+			pinger.Shutdown()
+		}(pinger)
+	}
+	wg.Wait()
+
+	stats := make([]Statistics, len(pingers))
+	for i, pinger := range pingers {
+		stats[i] = pinger.Statistics()
+	}
+
+	slices.SortStableFunc(stats, func(a, b Statistics) int {
+		switch {
+		case a.RttResults.HasResults && b.RttResults.HasResults:
+			if a.RttResults.Average < b.RttResults.Average {
+				return -1
+			} else if a.RttResults.Average > b.RttResults.Average {
+				return 1
+			}
+			return 0
+		case a.RttResults.HasResults:
+			return -1
+		case b.RttResults.HasResults:
+			return 1
+		default:
+			return 0
+		}
+	})
+
+	return stats, nil
+}
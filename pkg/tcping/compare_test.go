@@ -0,0 +1,53 @@
+package tcping
+
+import (
+	"bytes"
+	"context"
+	"net"
+	"net/netip"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCompareAddrs(t *testing.T) {
+	srv, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	t.Cleanup(func() { srv.Close() })
+
+	// only the second address is listened on
+	closed := netip.MustParseAddr("127.0.0.2")
+	listening := netip.MustParseAddr("127.0.0.1")
+	var capture bytes.Buffer
+	pcap, err := NewPcapWriter(&capture)
+	if err != nil {
+		t.Fatalf("pcap: %v", err)
+	}
+	captured := capture.Len()
+
+	checked := false
+	stats, err := CompareAddrs(context.Background(), Options{
+		Printer:  &dummyPrinter{},
+		Hostname: "www.example.test",
+		Port:     uint16(srv.Addr().(*net.TCPAddr).Port),
+		Hosts:    map[string][]netip.Addr{"www.example.test": {closed, listening}},
+		Pcap:     pcap,
+		SuccessCheck: func(ctx context.Context, r Result) error {
+			checked = true
+			return nil
+		},
+	}, 2)
+	assert.NoError(t, err)
+	// the comparison isn't captured or checked
+	assert.Equal(t, captured, capture.Len())
+	assert.False(t, checked)
+	if assert.Len(t, stats, 2) {
+		assert.Equal(t, listening, stats[0].IP)
+		assert.Equal(t, uint(2), stats[0].TotalSuccessfulProbes)
+		assert.True(t, stats[0].RttResults.HasResults)
+		assert.Equal(t, closed, stats[1].IP)
+		assert.Equal(t, uint(2), stats[1].TotalUnsuccessfulProbes)
+	}
+}
@@ -1,4 +1,4 @@
-package main
+package tcping
 
 import (
 	"fmt"
@@ -98,31 +98,36 @@ CREATE TABLE %s (
 	total_duration) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?);`
 )
 
+// NewDatabasePrinter returns a printer that saves the statistics
+// to the sqlite database at dbPath, in a new table for every run.
+func NewDatabasePrinter(hostname string, port uint16, dbPath string) (Printer, error) {
+	return newDb(hostname, port, dbPath)
+}
+
 // newDb creates a newDb with the given path and returns a pointer to the `database` struct
-func newDb(args []string, dbPath string) *database {
-	tableName := newTableName(args)
+func newDb(hostname string, port uint16, dbPath string) (*database, error) {
+	tableName := newTableName(hostname, port)
 	tableSchema := fmt.Sprintf(tableSchema, tableName)
 
 	conn, err := sqlite.OpenConn(dbPath, sqlite.OpenCreate, sqlite.OpenReadWrite)
 	if err != nil {
-		colorRed("\nError while creating the database %q: %s\n", dbPath, err)
-		os.Exit(1)
+		return nil, fmt.Errorf("error while creating the database %q: %w", dbPath, err)
 	}
 
 	err = sqlitex.Execute(conn, tableSchema, &sqlitex.ExecOptions{})
 	if err != nil {
 		// 	// TODO: add better err messege
-		colorRed("\nError while writing to the database %q \nerr: %s\n", dbPath, err)
-		os.Exit(1)
+		conn.Close()
+		return nil, fmt.Errorf("error while writing to the database %q: %w", dbPath, err)
 	}
-	return &database{conn, dbPath, tableName}
+	return &database{conn, dbPath, tableName}, nil
 }
 
 // newTableName will return correctly formatted table name
 // formatting the table name as "example_com_port_hour_minute_sec_day_month_year"
 // table name can't have '.' and can't start with numbers
-func newTableName(args []string) string {
-	tableName := fmt.Sprintf("%s_%s_%s", strings.ReplaceAll(args[0], ".", "_"), args[1], time.Now().Format("15_04_05_01_02_2006"))
+func newTableName(hostname string, port uint16) string {
+	tableName := fmt.Sprintf("%s_%d_%s", strings.ReplaceAll(hostname, ".", "_"), port, time.Now().Format("15_04_05_01_02_2006"))
 
 	if unicode.IsNumber(rune(tableName[0])) {
 		tableName = "_" + tableName
@@ -132,9 +137,9 @@ func newTableName(args []string) string {
 }
 
 // saveStats saves stats to the database with proper formatting
-func (db *database) saveStats(stat stats) error {
-	totalPackets := stat.totalSuccessfulProbes + stat.totalUnsuccessfulProbes
-	packetLoss := (float32(stat.totalUnsuccessfulProbes) / float32(totalPackets)) * 100
+func (db *database) saveStats(stat Statistics) error {
+	totalPackets := stat.TotalSuccessfulProbes + stat.TotalUnsuccessfulProbes
+	packetLoss := (float32(stat.TotalUnsuccessfulProbes) / float32(totalPackets)) * 100
 	if math.IsNaN(float64(packetLoss)) {
 		packetLoss = 0
 	}
@@ -142,14 +147,14 @@ func (db *database) saveStats(stat stats) error {
 	// If the time is zero, that means it never failed.
 	// In this case, the time should be empty instead of "0001-01-01 00:00:00".
 	// Rather, it should be left empty.
-	lastSuccessfulProbe := stat.lastSuccessfulProbe.Format(timeFormat)
+	lastSuccessfulProbe := stat.LastSuccessfulProbe.Format(timeFormat)
 	var neverSucceedProbe, neverFailedProbe bool
-	if stat.lastSuccessfulProbe.IsZero() {
+	if stat.LastSuccessfulProbe.IsZero() {
 		lastSuccessfulProbe = ""
 		neverSucceedProbe = true
 	}
-	lastUnsuccessfulProbe := stat.lastUnsuccessfulProbe.Format(timeFormat)
-	if stat.lastUnsuccessfulProbe.IsZero() {
+	lastUnsuccessfulProbe := stat.LastUnsuccessfulProbe.Format(timeFormat)
+	if stat.LastUnsuccessfulProbe.IsZero() {
 		lastUnsuccessfulProbe = ""
 		neverFailedProbe = true
 	}
@@ -160,54 +165,54 @@ func (db *database) saveStats(stat stats) error {
 	longestUptimeDuration = "0s"
 	longestDowntimeDuration = "0s"
 
-	if !stat.longestUptime.start.IsZero() {
-		longestUptimeDuration = stat.longestUptime.duration.String()
-		longestUptimeStart = stat.longestUptime.start.Format(timeFormat)
-		longestUptimeEnd = stat.longestUptime.end.Format(timeFormat)
+	if !stat.LongestUptime.Start.IsZero() {
+		longestUptimeDuration = stat.LongestUptime.Duration.String()
+		longestUptimeStart = stat.LongestUptime.Start.Format(timeFormat)
+		longestUptimeEnd = stat.LongestUptime.End.Format(timeFormat)
 	}
 
-	if !stat.longestDowntime.start.IsZero() {
-		longestDowntimeDuration = stat.longestDowntime.duration.String()
-		longestDowntimeStart = stat.longestDowntime.start.Format(timeFormat)
-		longestDowntimeEnd = stat.longestDowntime.end.Format(timeFormat)
+	if !stat.LongestDowntime.Start.IsZero() {
+		longestDowntimeDuration = stat.LongestDowntime.Duration.String()
+		longestDowntimeStart = stat.LongestDowntime.Start.Format(timeFormat)
+		longestDowntimeEnd = stat.LongestDowntime.End.Format(timeFormat)
 	}
 
 	var totalDuration string
-	if stat.endTime.IsZero() {
-		totalDuration = time.Since(stat.startTime).String()
+	if stat.EndTime.IsZero() {
+		totalDuration = time.Since(stat.StartTime).String()
 	} else {
-		totalDuration = stat.endTime.Sub(stat.startTime).String()
+		totalDuration = stat.EndTime.Sub(stat.StartTime).String()
 	}
 
 	// data
 	args := []interface{}{
 		eventTypeStatistics,
 		time.Now().Format(timeFormat),
-		stat.userInput.ip.String(),
-		stat.userInput.hostname,
-		stat.userInput.port,
-		stat.retriedHostnameLookups,
-		stat.totalSuccessfulProbes,
-		stat.totalUnsuccessfulProbes,
+		stat.IP.String(),
+		stat.Hostname,
+		stat.Port,
+		stat.RetriedHostnameLookups,
+		stat.TotalSuccessfulProbes,
+		stat.TotalUnsuccessfulProbes,
 		neverSucceedProbe,
 		neverFailedProbe,
 		lastSuccessfulProbe,
 		lastUnsuccessfulProbe,
 		totalPackets,
 		packetLoss,
-		stat.totalUptime.String(),
-		stat.totalDowntime.String(),
+		stat.TotalUptime.String(),
+		stat.TotalDowntime.String(),
 		longestUptimeDuration,
 		longestUptimeStart,
 		longestUptimeEnd,
 		longestDowntimeDuration,
 		longestDowntimeStart,
 		longestDowntimeEnd,
-		fmt.Sprintf("%.3f", stat.rttResults.min),
-		fmt.Sprintf("%.3f", stat.rttResults.average),
-		fmt.Sprintf("%.3f", stat.rttResults.max),
-		stat.startTime.Format(timeFormat),
-		stat.endTime.Format(timeFormat),
+		fmt.Sprintf("%.3f", stat.RttResults.Min),
+		fmt.Sprintf("%.3f", stat.RttResults.Average),
+		fmt.Sprintf("%.3f", stat.RttResults.Max),
+		stat.StartTime.Format(timeFormat),
+		stat.EndTime.Format(timeFormat),
 		totalDuration,
 	}
 
@@ -220,7 +225,7 @@ func (db *database) saveStats(stat stats) error {
 
 // saveHostNameChang saves the hostname changes
 // in multiple rows with event_type = eventTypeHostnameChange
-func (db *database) saveHostNameChange(h []hostnameChange) error {
+func (db *database) saveHostNameChange(h []HostnameChange) error {
 	// %s will be replaced by the table name
 	schema := `INSERT INTO %s
 	(event_type, hostname_changed_to, hostname_change_time)
@@ -240,43 +245,42 @@ func (db *database) saveHostNameChange(h []hostnameChange) error {
 	return nil
 }
 
-// printStart will let the user know the program is running by
+// PrintStart will let the user know the program is running by
 // printing a msg with the hostname, and port number to stdout
-func (db *database) printStart(hostname string, port uint16) {
+func (db *database) PrintStart(hostname string, port uint16) {
 	fmt.Printf("TCPinging %s on port %d\n", hostname, port)
 }
 
-// printStatistics saves the statistics to the given database
-// calls stat.printer.printError() on err
-func (db *database) printStatistics(stat stats) {
+// PrintStatistics saves the statistics to the given database
+// calls db.PrintError() on err
+func (db *database) PrintStatistics(stat Statistics) {
 	err := db.saveStats(stat)
 	if err != nil {
-		db.printError("\nError while writing stats to the database %q\nerr: %s", db.dbPath, err)
+		db.PrintError("\nError while writing stats to the database %q\nerr: %s", db.dbPath, err)
 	}
 
 	// Hostname changes should be written during the final call.
 	// If the endTime is 0, it indicates that this is not the last call.
-	if !stat.endTime.IsZero() {
-		err = db.saveHostNameChange(stat.hostnameChanges)
+	if !stat.EndTime.IsZero() {
+		err = db.saveHostNameChange(stat.HostnameChanges)
 		if err != nil {
-			db.printError("\nError while writing hostname changes to the database %q\nerr: %s", db.dbPath, err)
+			db.PrintError("\nError while writing hostname changes to the database %q\nerr: %s", db.dbPath, err)
 		}
 
 	}
 
-	colorYellow("\nStatistics for %q have been saved to %q in the table %q\n", stat.userInput.hostname, db.dbPath, db.tableName)
+	colorYellow("\nStatistics for %q have been saved to %q in the table %q\n", stat.Hostname, db.dbPath, db.tableName)
 }
 
-// printError prints the err to the stderr and exits with status code 1
-func (db *database) printError(format string, args ...any) {
+// PrintError prints the err to the stderr and exits with status code 1
+func (db *database) PrintError(format string, args ...any) {
 	fmt.Fprintf(os.Stderr, format, args...)
 	os.Exit(1)
 }
 
-// Satisfying the "printer" interface.
-func (db *database) printProbeSuccess(hostname, ip string, port uint16, streak uint, rtt float32) {}
-func (db *database) printProbeFail(hostname, ip string, port uint16, streak uint)                 {}
-func (db *database) printRetryingToResolve(hostname string)                                       {}
-func (db *database) printTotalDownTime(downtime time.Duration)                                    {}
-func (db *database) printVersion()                                                                {}
-func (db *database) printInfo(format string, args ...any)                                         {}
+// Satisfying the "Printer" interface.
+func (db *database) PrintProbe(r Result)                    {}
+func (db *database) PrintStateChange(c StateChange)         {}
+func (db *database) PrintRetryingToResolve(hostname string) {}
+func (db *database) PrintVersion()                          {}
+func (db *database) PrintInfo(format string, args ...any)   {}
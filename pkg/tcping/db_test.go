@@ -1,4 +1,4 @@
-package main
+package tcping
 
 import (
 	"fmt"
@@ -13,12 +13,12 @@ import (
 )
 
 func TestNewDBTableCreation(t *testing.T) {
-	arg := []string{"localhost", "8001"}
-	db := newDb(arg, ":memory:")
+	db, err := newDb("localhost", 8001, ":memory:")
+	isNil(t, err)
 	defer db.conn.Close()
 
 	query := "SELECT name FROM sqlite_master WHERE type='table';"
-	err := sqlitex.Execute(db.conn, query, &sqlitex.ExecOptions{
+	err = sqlitex.Execute(db.conn, query, &sqlitex.ExecOptions{
 		ResultFunc: func(stmt *sqlite.Stmt) error {
 			Equals(t, stmt.ColumnCount(), 1)
 			Equals(t, stmt.ColumnText(0), db.tableName)
@@ -31,13 +31,13 @@ func TestNewDBTableCreation(t *testing.T) {
 
 func TestDbSaveStats(t *testing.T) {
 	// There are many fields, so many things could go wrong; that's why this elaborate test.
-	arg := []string{"localhost", "8001"}
-	db := newDb(arg, ":memory:")
+	db, err := newDb("localhost", 8001, ":memory:")
+	isNil(t, err)
 	t.Log(db.tableName)
 	defer db.conn.Close()
 
 	stat := mockStats()
-	err := db.saveStats(stat)
+	err = db.saveStats(stat)
 	isNil(t, err)
 
 	query := `SELECT
@@ -155,56 +155,56 @@ FROM ` + fmt.Sprintf("%s WHERE event_type = '%s'", db.tableName, eventTypeStatis
 	})
 	isNil(t, err)
 
-	stat.rttResults.min = toFixedFloat(stat.rttResults.min, 3)
-	stat.rttResults.average = toFixedFloat(stat.rttResults.average, 3)
-	stat.rttResults.max = toFixedFloat(stat.rttResults.max, 3)
+	stat.RttResults.Min = toFixedFloat(stat.RttResults.Min, 3)
+	stat.RttResults.Average = toFixedFloat(stat.RttResults.Average, 3)
+	stat.RttResults.Max = toFixedFloat(stat.RttResults.Max, 3)
 
 	t.Log("the line number will tell you where the error happend")
-	Equals(t, addr, stat.userInput.ip.String())
-	Equals(t, hostname, stat.userInput.hostname)
-	Equals(t, totalUnsuccessfulProbes, stat.totalUnsuccessfulProbes)
-	Equals(t, totalSuccessfulProbes, stat.totalSuccessfulProbes)
-	Equals(t, port, strconv.Itoa(int(stat.userInput.port)))
-
-	Equals(t, hostNameResolveTries, int(stat.retriedHostnameLookups))
-	packetLoss := (float32(stat.totalUnsuccessfulProbes) / float32(stat.totalSuccessfulProbes+stat.totalUnsuccessfulProbes)) * 100
+	Equals(t, addr, stat.IP.String())
+	Equals(t, hostname, stat.Hostname)
+	Equals(t, totalUnsuccessfulProbes, stat.TotalUnsuccessfulProbes)
+	Equals(t, totalSuccessfulProbes, stat.TotalSuccessfulProbes)
+	Equals(t, port, strconv.Itoa(int(stat.Port)))
+
+	Equals(t, hostNameResolveTries, int(stat.RetriedHostnameLookups))
+	packetLoss := (float32(stat.TotalUnsuccessfulProbes) / float32(stat.TotalSuccessfulProbes+stat.TotalUnsuccessfulProbes)) * 100
 	Equals(t, totalPacketsLoss, packetLoss)
 
-	Equals(t, neverSucceedProbe, stat.lastSuccessfulProbe.IsZero())
-	Equals(t, neverFailedProbe, stat.lastUnsuccessfulProbe.IsZero())
+	Equals(t, neverSucceedProbe, stat.LastSuccessfulProbe.IsZero())
+	Equals(t, neverFailedProbe, stat.LastUnsuccessfulProbe.IsZero())
 
-	Equals(t, lastSuccessfulProbe.Format(timeFormat), stat.lastSuccessfulProbe.Format(timeFormat))
+	Equals(t, lastSuccessfulProbe.Format(timeFormat), stat.LastSuccessfulProbe.Format(timeFormat))
 
-	Equals(t, lMin, stat.rttResults.min)
-	Equals(t, lAvg, stat.rttResults.average)
-	Equals(t, lMax, stat.rttResults.max)
-	Equals(t, startTimestamp.Format(timeFormat), stat.startTime.Format(timeFormat))
-	Equals(t, endTimestamp.Format(timeFormat), stat.endTime.Format(timeFormat))
+	Equals(t, lMin, stat.RttResults.Min)
+	Equals(t, lAvg, stat.RttResults.Average)
+	Equals(t, lMax, stat.RttResults.Max)
+	Equals(t, startTimestamp.Format(timeFormat), stat.StartTime.Format(timeFormat))
+	Equals(t, endTimestamp.Format(timeFormat), stat.EndTime.Format(timeFormat))
 
-	actualDuration := stat.endTime.Sub(stat.startTime).String()
+	actualDuration := stat.EndTime.Sub(stat.StartTime).String()
 	Equals(t, totalDuration, actualDuration)
-	Equals(t, totalUptime, stat.totalUptime.String())
-	Equals(t, totalDowntime, stat.totalDowntime.String())
-	Equals(t, totalPackets, stat.totalSuccessfulProbes+stat.totalUnsuccessfulProbes)
+	Equals(t, totalUptime, stat.TotalUptime.String())
+	Equals(t, totalDowntime, stat.TotalDowntime.String())
+	Equals(t, totalPackets, stat.TotalSuccessfulProbes+stat.TotalUnsuccessfulProbes)
 
-	Equals(t, longestUptime, stat.longestUptime.duration.String())
-	Equals(t, longestUptimeStart, stat.longestUptime.start.Format(timeFormat))
-	Equals(t, longestUptimeEnd, stat.longestUptime.end.Format(timeFormat))
+	Equals(t, longestUptime, stat.LongestUptime.Duration.String())
+	Equals(t, longestUptimeStart, stat.LongestUptime.Start.Format(timeFormat))
+	Equals(t, longestUptimeEnd, stat.LongestUptime.End.Format(timeFormat))
 
-	Equals(t, longestDowntime, stat.longestDowntime.duration.String())
-	Equals(t, longestDowntimeStart, stat.longestDowntime.start.Format(timeFormat))
-	Equals(t, longestDowntimeEnd, stat.longestDowntime.end.Format(timeFormat))
+	Equals(t, longestDowntime, stat.LongestDowntime.Duration.String())
+	Equals(t, longestDowntimeStart, stat.LongestDowntime.Start.Format(timeFormat))
+	Equals(t, longestDowntimeEnd, stat.LongestDowntime.End.Format(timeFormat))
 
 }
 
 func TestSaveHostname(t *testing.T) {
 	// There are many fields, so many things could go wrong; that's why this elaborate test.
-	arg := []string{"localhost", "8001"}
-	db := newDb(arg, ":memory:")
+	db, err := newDb("localhost", 8001, ":memory:")
+	isNil(t, err)
 	defer db.conn.Close()
 	stat := mockStats()
 
-	err := db.saveHostNameChange(stat.hostnameChanges)
+	err = db.saveHostNameChange(stat.HostnameChanges)
 	isNil(t, err)
 
 	// testing the host names if they are properly written
@@ -217,7 +217,7 @@ func TestSaveHostname(t *testing.T) {
 		ResultFunc: func(stmt *sqlite.Stmt) error {
 			hostName := stmt.ColumnText(0)
 			cTime := stmt.ColumnText(1)
-			actualHost := stat.hostnameChanges[idx]
+			actualHost := stat.HostnameChanges[idx]
 			idx++
 			Equals(t, hostName, actualHost.Addr.String())
 			Equals(t, cTime, actualHost.When.Format(timeFormat))
@@ -226,19 +226,19 @@ func TestSaveHostname(t *testing.T) {
 		}})
 	isNil(t, err)
 
-	Equals(t, idx, len(stat.hostnameChanges))
+	Equals(t, idx, len(stat.HostnameChanges))
 }
 
-func hostNameChange() []hostnameChange {
+func hostNameChange() []HostnameChange {
 	ipAddresses := []string{
 		"192.168.1.1",
 		"10.0.0.1",
 		"172.16.0.1",
 		"2001:0db8:85a3:0000:0000:8a2e:0370:7334",
 	}
-	var hostNames []hostnameChange
+	var hostNames []HostnameChange
 	for i, ip := range ipAddresses {
-		host := hostnameChange{
+		host := HostnameChange{
 			Addr: netip.MustParseAddr(ip),
 			When: time.Now().Add(time.Duration(i) * time.Minute),
 		}
@@ -247,39 +247,37 @@ func hostNameChange() []hostnameChange {
 	return hostNames
 }
 
-func mockStats() stats {
-	stat := stats{
-		startTime:           time.Now(),
-		endTime:             time.Now().Add(10 * time.Minute),
-		lastSuccessfulProbe: time.Now().Add(1 * time.Minute),
-		// lastUnsuccessfulProbe is left with the default value "0" to simulate no probe failed
-		retriedHostnameLookups: 10,
-		longestUptime: longestTime{
-			start:    time.Now().Add(20 * time.Second),
-			end:      time.Now().Add(80 * time.Second),
-			duration: time.Minute,
-		},
-		longestDowntime: longestTime{
-			start:    time.Now().Add(20 * time.Second),
-			end:      time.Now().Add(140 * time.Second),
-			duration: time.Minute * 2,
+func mockStats() Statistics {
+	stat := Statistics{
+		StartTime:           time.Now(),
+		EndTime:             time.Now().Add(10 * time.Minute),
+		LastSuccessfulProbe: time.Now().Add(1 * time.Minute),
+		// LastUnsuccessfulProbe is left with the default value "0" to simulate no probe failed
+		RetriedHostnameLookups: 10,
+		LongestUptime: LongestTime{
+			Start:    time.Now().Add(20 * time.Second),
+			End:      time.Now().Add(80 * time.Second),
+			Duration: time.Minute,
 		},
-		userInput: userInput{
-			ip:       netip.MustParseAddr("192.168.1.1"),
-			hostname: "example.com",
-			port:     1234,
+		LongestDowntime: LongestTime{
+			Start:    time.Now().Add(20 * time.Second),
+			End:      time.Now().Add(140 * time.Second),
+			Duration: time.Minute * 2,
 		},
-		totalUptime:             time.Second * 32,
-		totalDowntime:           time.Second * 60,
-		totalSuccessfulProbes:   201,
-		totalUnsuccessfulProbes: 123,
-		rttResults: rttResult{
-			min:     2.832,
-			average: 3.8123,
-			max:     4.0932,
+		IP:                      netip.MustParseAddr("192.168.1.1"),
+		Hostname:                "example.com",
+		Port:                    1234,
+		TotalUptime:             time.Second * 32,
+		TotalDowntime:           time.Second * 60,
+		TotalSuccessfulProbes:   201,
+		TotalUnsuccessfulProbes: 123,
+		RttResults: RttResult{
+			Min:     2.832,
+			Average: 3.8123,
+			Max:     4.0932,
 		},
 
-		hostnameChanges: hostNameChange(),
+		HostnameChanges: hostNameChange(),
 	}
 
 	return stat
@@ -0,0 +1,44 @@
+package tcping
+
+import "time"
+
+// isDegraded reports whether the RTT in milliseconds is above Options.RTTThreshold.
+func (tcpStats *stats) isDegraded(rtt float32) bool {
+	threshold := tcpStats.userInput.RTTThreshold
+	return threshold > 0 && float64(rtt) > float64(threshold)/float64(time.Millisecond)
+}
+
+// recordDegraded counts the degraded probe and, with Options.NotifyDegraded,
+// notifies about the target entering or leaving the degraded state.
+func (tcpStats *stats) recordDegraded(degraded bool, rtt float32, connTime time.Time) {
+	if degraded {
+		tcpStats.degradedProbes += 1
+	}
+
+	if degraded == tcpStats.degraded {
+		return
+	}
+
+	change := StateChange{
+		When:     connTime,
+		Hostname: tcpStats.userInput.Hostname,
+		IP:       tcpStats.userInput.ip.String(),
+		Port:     tcpStats.userInput.Port,
+		RTT:      rtt,
+		Up:       !degraded,
+		Degraded: true,
+	}
+
+	if degraded {
+		tcpStats.startOfDegradation = connTime
+	} else {
+		change.Downtime = connTime.Sub(tcpStats.startOfDegradation)
+		tcpStats.startOfDegradation = time.Time{}
+	}
+	tcpStats.degraded = degraded
+
+	if tcpStats.userInput.NotifyDegraded {
+		tcpStats.notifyStateChange(change)
+		tcpStats.ringBell(BellOnChange)
+	}
+}
@@ -0,0 +1,119 @@
+package tcping
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"net"
+	"strings"
+	"time"
+
+	"golang.org/x/net/dns/dnsmessage"
+)
+
+// DNSConfig is the query sent by the dns prober. See ProberConfig.DNS.
+type DNSConfig struct {
+	// Name is looked up, e.g. example.com.
+	Name string
+	// Type of the records, e.g. AAAA. Defaults to A.
+	Type string
+	// UDP sends the query over UDP to the address of the
+	// target instead of the connection of the probe.
+	UDP bool
+}
+
+// dnsTypes are the types of records the dns prober can query.
+var dnsTypes = map[string]dnsmessage.Type{
+	"A":     dnsmessage.TypeA,
+	"NS":    dnsmessage.TypeNS,
+	"CNAME": dnsmessage.TypeCNAME,
+	"SOA":   dnsmessage.TypeSOA,
+	"PTR":   dnsmessage.TypePTR,
+	"MX":    dnsmessage.TypeMX,
+	"TXT":   dnsmessage.TypeTXT,
+	"AAAA":  dnsmessage.TypeAAAA,
+	"SRV":   dnsmessage.TypeSRV,
+}
+
+func init() {
+	RegisterProber("dns", func(cfg ProberConfig) (Prober, error) {
+		return newDNSProber(cfg.DNS)
+	})
+}
+
+// dnsProber checks that the target answers
+// queries, acting as a DNS server.
+type dnsProber struct {
+	name  dnsmessage.Name
+	qtype dnsmessage.Type
+	udp   bool
+}
+
+func newDNSProber(cfg DNSConfig) (*dnsProber, error) {
+	if cfg.Name == "" {
+		return nil, errors.New("the dns prober needs the name to look up")
+	}
+
+	name, err := dnsmessage.NewName(strings.TrimSuffix(cfg.Name, ".") + ".")
+	if err != nil {
+		return nil, fmt.Errorf("invalid name %q: %w", cfg.Name, err)
+	}
+
+	qtype := dnsmessage.TypeA
+	if cfg.Type != "" {
+		var ok bool
+		if qtype, ok = dnsTypes[strings.ToUpper(cfg.Type)]; !ok {
+			return nil, fmt.Errorf("unsupported record type %q", cfg.Type)
+		}
+	}
+
+	return &dnsProber{name: name, qtype: qtype, udp: cfg.UDP}, nil
+}
+
+func (p *dnsProber) Probe(conn net.Conn, timeout time.Duration) error {
+	if p.udp {
+		d := net.Dialer{Timeout: timeout}
+		udpConn, err := d.Dial("udp", conn.RemoteAddr().String())
+		if err != nil {
+			return err
+		}
+		defer udpConn.Close()
+
+		conn = udpConn
+	}
+
+	if timeout > 0 {
+		conn.SetDeadline(time.Now().Add(timeout))
+	}
+
+	id := uint16(rand.Intn(1 << 16))
+	query, err := (&dnsmessage.Message{
+		Header:    dnsmessage.Header{ID: id, RecursionDesired: true},
+		Questions: []dnsmessage.Question{{Name: p.name, Type: p.qtype, Class: dnsmessage.ClassINET}},
+	}).Pack()
+	if err != nil {
+		return err
+	}
+
+	answer, err := exchange(conn, query)
+	if err != nil {
+		return fmt.Errorf("no answer to the query: %w", err)
+	}
+
+	var header dnsmessage.Header
+	var parser dnsmessage.Parser
+	if header, err = parser.Start(answer); err != nil {
+		return fmt.Errorf("invalid answer: %w", err)
+	}
+
+	if header.ID != id || !header.Response {
+		return errors.New("unexpected answer from the name server")
+	}
+
+	// a name that doesn't exist is still answered
+	if header.RCode != dnsmessage.RCodeSuccess && header.RCode != dnsmessage.RCodeNameError {
+		return fmt.Errorf("the name server answered with %s", header.RCode)
+	}
+
+	return nil
+}
@@ -0,0 +1,133 @@
+package tcping
+
+import (
+	"encoding/binary"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/net/dns/dnsmessage"
+)
+
+// dnsAnswer answers the query with the given code.
+func dnsAnswer(t *testing.T, query []byte, rcode dnsmessage.RCode) []byte {
+	var msg dnsmessage.Message
+	if err := msg.Unpack(query); err != nil {
+		t.Errorf("unpack: %v", err)
+		return nil
+	}
+
+	msg.Header.Response = true
+	msg.Header.RCode = rcode
+	answer, err := msg.Pack()
+	if err != nil {
+		t.Errorf("pack: %v", err)
+	}
+
+	return answer
+}
+
+// serveDNS answers the queries over TCP and UDP on the same port.
+func serveDNS(t *testing.T, rcode dnsmessage.RCode) string {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	t.Cleanup(func() { ln.Close() })
+
+	pc, err := net.ListenPacket("udp", ln.Addr().String())
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	t.Cleanup(func() { pc.Close() })
+
+	go func() {
+		for {
+			c, err := ln.Accept()
+			if err != nil {
+				return
+			}
+
+			var length uint16
+			if binary.Read(c, binary.BigEndian, &length) == nil {
+				query := make([]byte, length)
+				if _, err := io.ReadFull(c, query); err == nil {
+					answer := dnsAnswer(t, query, rcode)
+					binary.Write(c, binary.BigEndian, uint16(len(answer)))
+					c.Write(answer)
+				}
+			}
+			c.Close()
+		}
+	}()
+
+	go func() {
+		buf := make([]byte, 512)
+		for {
+			n, addr, err := pc.ReadFrom(buf)
+			if err != nil {
+				return
+			}
+
+			pc.WriteTo(dnsAnswer(t, buf[:n], rcode), addr)
+		}
+	}()
+
+	return ln.Addr().String()
+}
+
+func TestDNSProber(t *testing.T) {
+	tests := []struct {
+		name    string
+		rcode   dnsmessage.RCode
+		cfg     DNSConfig
+		wantErr string
+	}{
+		{
+			name: "tcp",
+			cfg:  DNSConfig{Name: "example.com", Type: "aaaa"},
+		},
+		{
+			name: "udp",
+			cfg:  DNSConfig{Name: "example.com", UDP: true},
+		},
+		{
+			name:  "nxdomain",
+			rcode: dnsmessage.RCodeNameError,
+			cfg:   DNSConfig{Name: "missing.example.com"},
+		},
+		{
+			name:    "servfail",
+			rcode:   dnsmessage.RCodeServerFailure,
+			cfg:     DNSConfig{Name: "example.com", UDP: true},
+			wantErr: "the name server answered with RCodeServerFailure",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conn, err := net.Dial("tcp", serveDNS(t, tt.rcode))
+			if err != nil {
+				t.Fatalf("dial: %v", err)
+			}
+			defer conn.Close()
+
+			prober, err := NewProber("dns", ProberConfig{DNS: tt.cfg})
+			assert.NoError(t, err)
+
+			err = prober.Probe(conn, time.Second)
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+
+	_, err := NewProber("dns", ProberConfig{})
+	assert.Error(t, err)
+
+	_, err = NewProber("dns", ProberConfig{DNS: DNSConfig{Name: "example.com", Type: "AXFR"}})
+	assert.EqualError(t, err, `unsupported record type "AXFR"`)
+}
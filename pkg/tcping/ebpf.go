@@ -0,0 +1,14 @@
+package tcping
+
+// openConnectTracer starts timing the probes with eBPF, see Options.EBPF.
+// If it can't, the probes are timed as usual and why is printed when
+// they start.
+func (tcpStats *stats) openConnectTracer() {
+	t, err := openConnectTracer(tcpStats.userInput.Port)
+	if err != nil {
+		tcpStats.userInput.tracerErr = err
+		return
+	}
+
+	tcpStats.userInput.connectTracer = t
+}
@@ -0,0 +1,177 @@
+//go:build linux
+
+package tcping
+
+import (
+	"errors"
+
+	"github.com/cilium/ebpf"
+	"github.com/cilium/ebpf/asm"
+	"github.com/cilium/ebpf/link"
+	"github.com/cilium/ebpf/rlimit"
+)
+
+// ebpfSupported reports whether the probes can be timed with eBPF on this platform.
+const ebpfSupported = true
+
+// the fields of the sock:inet_sock_set_state tracepoint, as
+// in /sys/kernel/tracing/events/sock/inet_sock_set_state/format
+const (
+	stateOffSkaddr   = 8
+	stateOffOldstate = 16
+	stateOffNewstate = 20
+	stateOffSport    = 24
+	stateOffDport    = 26
+	stateOffProtocol = 30
+)
+
+// connectTracerEntries is the size of the maps of the connections.
+// They're LRU maps, the connections that were never picked up go first.
+const connectTracerEntries = 1024
+
+// connectTracer times the handshakes of the connections to the port of the
+// target in the kernel, from the SYN sent by tcp_v4_connect or tcp_v6_connect
+// to the SYN-ACK establishing the connection, see Options.EBPF.
+type connectTracer struct {
+	starts *ebpf.Map // starts are the times of the SYNs by socket.
+	times  *ebpf.Map // times are the durations of the handshakes in ns by local port.
+	prog   *ebpf.Program
+	link   link.Link
+}
+
+// openConnectTracer loads the eBPF program timing the handshakes of the
+// connections to the port and attaches it to the state changes of the
+// sockets. It needs CAP_BPF and CAP_PERFMON, or CAP_SYS_ADMIN, and tracefs.
+func openConnectTracer(port uint16) (*connectTracer, error) {
+	// the maps are charged to the memlock limit before Linux 5.11
+	_ = rlimit.RemoveMemlock()
+
+	t := &connectTracer{}
+	var err error
+	t.starts, err = ebpf.NewMap(&ebpf.MapSpec{
+		Type:       ebpf.LRUHash,
+		KeySize:    8,
+		ValueSize:  8,
+		MaxEntries: connectTracerEntries,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	t.times, err = ebpf.NewMap(&ebpf.MapSpec{
+		Type:       ebpf.LRUHash,
+		KeySize:    4,
+		ValueSize:  8,
+		MaxEntries: connectTracerEntries,
+	})
+	if err != nil {
+		t.close()
+		return nil, err
+	}
+
+	t.prog, err = ebpf.NewProgram(&ebpf.ProgramSpec{
+		Type:         ebpf.TracePoint,
+		License:      "MIT",
+		Instructions: connectTracerProgram(port, t.starts.FD(), t.times.FD()),
+	})
+	if err != nil {
+		t.close()
+		return nil, err
+	}
+
+	t.link, err = link.Tracepoint("sock", "inet_sock_set_state", t.prog, nil)
+	if err != nil {
+		t.close()
+		return nil, err
+	}
+
+	return t, nil
+}
+
+// connectTracerProgram records the time a socket connecting to the port
+// enters SYN_SENT and, once it's established, how long the handshake took
+// by its local port.
+func connectTracerProgram(port uint16, starts, times int) asm.Instructions {
+	return asm.Instructions{
+		asm.Mov.Reg(asm.R6, asm.R1),
+		asm.LoadMem(asm.R2, asm.R6, stateOffDport, asm.Half),
+		asm.JNE.Imm(asm.R2, int32(port), "exit"),
+		asm.LoadMem(asm.R2, asm.R6, stateOffProtocol, asm.Half),
+		asm.JNE.Imm(asm.R2, protocolTCP, "exit"),
+		asm.LoadMem(asm.R7, asm.R6, stateOffOldstate, asm.Word),
+		asm.LoadMem(asm.R8, asm.R6, stateOffNewstate, asm.Word),
+
+		// the socket is the key of the start of its handshake
+		asm.LoadMem(asm.R2, asm.R6, stateOffSkaddr, asm.DWord),
+		asm.StoreMem(asm.RFP, -8, asm.R2, asm.DWord),
+		asm.JNE.Imm(asm.R8, tcpSynSent, "handshake_done"),
+
+		asm.FnKtimeGetNs.Call(),
+		asm.StoreMem(asm.RFP, -16, asm.R0, asm.DWord),
+		asm.LoadMapPtr(asm.R1, starts),
+		asm.Mov.Reg(asm.R2, asm.RFP),
+		asm.Add.Imm(asm.R2, -8),
+		asm.Mov.Reg(asm.R3, asm.RFP),
+		asm.Add.Imm(asm.R3, -16),
+		asm.Mov.Imm(asm.R4, int32(ebpf.UpdateAny)),
+		asm.FnMapUpdateElem.Call(),
+		asm.Ja.Label("exit"),
+
+		// the handshake ended, established or not
+		asm.JNE.Imm(asm.R7, tcpSynSent, "exit").WithSymbol("handshake_done"),
+		asm.LoadMapPtr(asm.R1, starts),
+		asm.Mov.Reg(asm.R2, asm.RFP),
+		asm.Add.Imm(asm.R2, -8),
+		asm.FnMapLookupElem.Call(),
+		asm.JEq.Imm(asm.R0, 0, "exit"),
+		asm.LoadMem(asm.R9, asm.R0, 0, asm.DWord),
+		asm.LoadMapPtr(asm.R1, starts),
+		asm.Mov.Reg(asm.R2, asm.RFP),
+		asm.Add.Imm(asm.R2, -8),
+		asm.FnMapDeleteElem.Call(),
+		asm.JNE.Imm(asm.R8, tcpEstablished, "exit"),
+
+		asm.FnKtimeGetNs.Call(),
+		asm.Sub.Reg(asm.R0, asm.R9),
+		asm.StoreMem(asm.RFP, -16, asm.R0, asm.DWord),
+		asm.LoadMem(asm.R2, asm.R6, stateOffSport, asm.Half),
+		asm.StoreMem(asm.RFP, -24, asm.R2, asm.Word),
+		asm.LoadMapPtr(asm.R1, times),
+		asm.Mov.Reg(asm.R2, asm.RFP),
+		asm.Add.Imm(asm.R2, -24),
+		asm.Mov.Reg(asm.R3, asm.RFP),
+		asm.Add.Imm(asm.R3, -16),
+		asm.Mov.Imm(asm.R4, int32(ebpf.UpdateAny)),
+		asm.FnMapUpdateElem.Call(),
+
+		asm.Mov.Imm(asm.R0, 0).WithSymbol("exit"),
+		asm.Return(),
+	}
+}
+
+// connectTime returns the time the handshake of the connection from
+// the local port took in the kernel, in milliseconds. The connection
+// is established by the time connect returns, so it's already known.
+func (t *connectTracer) connectTime(localPort uint16) (float32, bool) {
+	key := uint32(localPort)
+	var ns uint64
+	if err := t.times.Lookup(&key, &ns); err != nil {
+		return 0, false
+	}
+
+	// LRU maps can't pop their elements before Linux 5.14
+	t.times.Delete(&key)
+	return nanoToMillisecond(int64(ns)), true
+}
+
+// close detaches the program and releases the maps.
+func (t *connectTracer) close() error {
+	var errs []error
+	if t.link != nil {
+		errs = append(errs, t.link.Close())
+	}
+
+	// the maps and the program can be closed while nil
+	errs = append(errs, t.prog.Close(), t.times.Close(), t.starts.Close())
+	return errors.Join(errs...)
+}
@@ -0,0 +1,56 @@
+package tcping
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEBPF(t *testing.T) {
+	srv := testServerListen(t)
+	t.Cleanup(func() {
+		if err := srv.Close(); err != nil {
+			t.Errorf("srv close: %v", err)
+		}
+	})
+
+	var results []Result
+	p, err := New(Options{
+		Printer:               &dummyPrinter{},
+		Hostname:              "127.0.0.1",
+		Port:                  12345,
+		IntervalBetweenProbes: 2 * time.Millisecond,
+		Timeout:               time.Second,
+		ProbesBeforeQuit:      2,
+		EBPF:                  true,
+		Hooks: Hooks{
+			OnProbe: func(r Result) { results = append(results, r) },
+		},
+	})
+	assert.NoError(t, err)
+	if err := p.stats.userInput.tracerErr; err != nil {
+		t.Skipf("unable to load the eBPF program: %s", err)
+	}
+
+	p.Run()
+	p.Shutdown()
+
+	if assert.Len(t, results, 2) {
+		for _, r := range results {
+			assert.Positive(t, r.RTT)
+			assert.Greater(t, r.UserRTT, r.RTT)
+		}
+	}
+
+	assert.True(t, p.Statistics().UserRttResults.HasResults)
+
+	_, err = New(Options{
+		Printer:    &dummyPrinter{},
+		Hostname:   "127.0.0.1",
+		Port:       12345,
+		Persistent: true,
+		EBPF:       true,
+	})
+	assert.EqualError(t, err, "the eBPF timing needs a new connection for every probe")
+}
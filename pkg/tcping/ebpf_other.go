@@ -0,0 +1,22 @@
+//go:build !linux
+
+package tcping
+
+import "errors"
+
+// ebpfSupported reports whether the probes can be timed with eBPF on this platform.
+const ebpfSupported = false
+
+type connectTracer struct{}
+
+func openConnectTracer(_ uint16) (*connectTracer, error) {
+	return nil, errors.New("eBPF is only supported on Linux")
+}
+
+func (t *connectTracer) connectTime(_ uint16) (float32, bool) {
+	return 0, false
+}
+
+func (t *connectTracer) close() error {
+	return nil
+}
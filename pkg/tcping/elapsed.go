@@ -0,0 +1,68 @@
+package tcping
+
+import (
+	"fmt"
+	"io"
+	"sync"
+	"time"
+)
+
+// elapsedWriter prefixes every line written to w with the time
+// elapsed since start, e.g. [+00:02:13], see PrinterConfig.Elapsed.
+// It's safe for concurrent use.
+type elapsedWriter struct {
+	mu    sync.Mutex
+	w     io.Writer
+	start time.Time
+	now   func() time.Time
+	// midLine is false until the first printable byte of a line is written.
+	midLine bool
+	// escape is true within an ANSI escape sequence, e.g. a color,
+	// which is written before the prefix, so that it's colored like the line.
+	escape bool
+}
+
+func newElapsedWriter(w io.Writer) *elapsedWriter {
+	return &elapsedWriter{w: w, start: time.Now(), now: time.Now}
+}
+
+func (e *elapsedWriter) Write(b []byte) (int, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	var buf []byte
+	for _, c := range b {
+		switch {
+		case e.midLine, c == '\n':
+			// the empty lines aren't prefixed
+		case c == '\x1b':
+			e.escape = true
+		case e.escape:
+			// the sequence ends with its first letter, after the '['
+			e.escape = c < 0x40 || c > 0x7e || c == '['
+		default:
+			buf = append(buf, formatElapsed(e.now().Sub(e.start))...)
+			e.midLine = true
+		}
+
+		buf = append(buf, c)
+		if c == '\n' {
+			e.midLine = false
+		}
+	}
+
+	if _, err := e.w.Write(buf); err != nil {
+		return 0, err
+	}
+	return len(b), nil
+}
+
+// formatElapsed formats the elapsed time as the prefix of the lines.
+func formatElapsed(d time.Duration) string {
+	d = d.Truncate(time.Second)
+	h := d / time.Hour
+	m := (d % time.Hour) / time.Minute
+	s := (d % time.Minute) / time.Second
+
+	return fmt.Sprintf("[+%02d:%02d:%02d] ", h, m, s)
+}
@@ -0,0 +1,31 @@
+package tcping
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFormatElapsed(t *testing.T) {
+	assert.Equal(t, "[+00:00:00] ", formatElapsed(999*time.Millisecond))
+	assert.Equal(t, "[+00:02:13] ", formatElapsed(2*time.Minute+13*time.Second))
+	assert.Equal(t, "[+101:00:05] ", formatElapsed(101*time.Hour+5*time.Second))
+}
+
+func TestElapsedWriter(t *testing.T) {
+	var out strings.Builder
+	start := time.Now()
+	now := start.Add(2*time.Minute + 13*time.Second)
+	w := &elapsedWriter{w: &out, start: start, now: func() time.Time { return now }}
+
+	// the colors are written before the prefix and the empty lines aren't prefixed
+	fmt.Fprint(w, "\x1b[92mReply\n\x1b[0m")
+	fmt.Fprint(w, "\x1b[33m\n--- statistics ")
+	fmt.Fprint(w, "---\n\x1b[0m")
+
+	assert.Equal(t, "\x1b[92m[+00:02:13] Reply\n\x1b[0m"+
+		"\x1b[33m\n[+00:02:13] --- statistics ---\n\x1b[0m", out.String())
+}
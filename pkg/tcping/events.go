@@ -0,0 +1,83 @@
+package tcping
+
+import (
+	"fmt"
+	"net/netip"
+)
+
+// printProbe passes the probe to the printers, without the details
+// that are always measured but only printed on demand.
+func (tcpStats *stats) printProbe(r Result) {
+	if !tcpStats.userInput.ShowLocalAddr {
+		r.LocalAddr = netip.AddrPort{}
+	}
+	if !tcpStats.userInput.SmoothedRTT {
+		r.SmoothedRTT = 0
+	}
+	if tcpStats.userInput.prober == nil {
+		r.ResponseTime = 0
+	}
+
+	tcpStats.print(func(printer Printer) { printer.PrintProbe(r) })
+}
+
+// printStateChange passes the state change to the printers.
+func (tcpStats *stats) printStateChange(change StateChange) {
+	tcpStats.print(func(printer Printer) { printer.PrintStateChange(change) })
+}
+
+// isRecovery reports whether the state change is the target
+// coming back up after an outage, the one the printers print.
+func (c StateChange) isRecovery() bool {
+	return c.Up && !c.Degraded && !c.Trend && !c.Outlier
+}
+
+// probeDetails returns the messages about the details of the successful
+// probe that the printers print like PrintInfo after it, in order.
+// Result.TCPInfo, Result.LocalAddr and Result.SmoothedRTT are left to
+// the printers, which have a format of their own for them.
+func probeDetails(r Result) []string {
+	if !r.Success {
+		return nil
+	}
+
+	var details []string
+	if r.UserRTT > 0 {
+		details = append(details, fmt.Sprintf("userspace rtt=%.3f ms (%+.3f ms over the kernel)", r.UserRTT, r.UserRTT-r.RTT))
+	}
+	if r.TunnelSetup > 0 {
+		details = append(details, fmt.Sprintf("SSH tunnel set up in %.3f ms", r.TunnelSetup))
+	}
+	if r.Goodput > 0 {
+		details = append(details, fmt.Sprintf("Goodput of %.3f Mbit/s", r.Goodput))
+	}
+	if r.Burst > 0 {
+		details = append(details, fmt.Sprintf("Burst of %d connections: min %.3f ms, median %.3f ms, max %.3f ms, %d failed",
+			r.Burst, r.BurstMin, r.BurstMedian, r.BurstMax, r.BurstFailures))
+	}
+	if h := r.Handshake; h != nil {
+		details = append(details, fmt.Sprintf("wire rtt=%.3f ms (%+.3f ms in the local stack), ack after %.3f ms", h.Network, h.Stack, h.ACK))
+	}
+
+	ms := nanoToMillisecond(r.PeerCloseAfter.Nanoseconds())
+	switch r.PeerClose {
+	case PeerCloseFIN:
+		details = append(details, fmt.Sprintf("%s closed the connection after %.3f ms", r.IP, ms))
+	case PeerCloseRST:
+		details = append(details, fmt.Sprintf("%s reset the connection after %.3f ms", r.IP, ms))
+	case PeerKeptOpen:
+		details = append(details, fmt.Sprintf("%s kept the connection open for %s", r.IP, r.PeerCloseAfter))
+	}
+
+	if r.Outlier {
+		details = append(details, fmt.Sprintf("Outlier: %.3f ms is far above the previous RTTs", r.RTT))
+	}
+	if r.Attempts > 1 {
+		details = append(details, fmt.Sprintf("Connected to %s on attempt %d", r.IP, r.Attempts))
+	}
+	if r.ResponseTime > 0 {
+		details = append(details, fmt.Sprintf("Response from %s on port %d in %.3f ms", r.IP, r.Port, r.ResponseTime))
+	}
+
+	return details
+}
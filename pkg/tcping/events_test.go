@@ -0,0 +1,84 @@
+package tcping
+
+import (
+	"net/netip"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// eventPrinter records the probes and the state changes it gets,
+// and the rest of the calls as info messages.
+type eventPrinter struct {
+	infoPrinter
+	probes  []Result
+	changes []StateChange
+}
+
+func (p *eventPrinter) PrintProbe(r Result) {
+	p.probes = append(p.probes, r)
+}
+
+func (p *eventPrinter) PrintStateChange(c StateChange) {
+	p.changes = append(p.changes, c)
+}
+
+func TestPrintProbe(t *testing.T) {
+	printer := &eventPrinter{}
+
+	stats := createTestStats(t)
+	stats.printer = printer
+	stats.userInput.RTTThreshold = 150 * time.Millisecond
+	stats.userInput.SmoothedRTT = true
+
+	start := time.Now()
+	stats.localAddr = netip.MustParseAddrPort("127.0.0.1:50000")
+	stats.handleConnSuccess(200, start)
+	stats.handleConnError(start.Add(time.Second), syscall.ECONNREFUSED)
+	stats.userInput.ShowLocalAddr = true
+	stats.localAddr = netip.MustParseAddrPort("127.0.0.1:50001")
+	stats.handleConnSuccess(20, start.Add(2*time.Second))
+	stats.printer.PrintInfo("done")
+
+	if assert.Len(t, printer.probes, 3) {
+		assert.True(t, printer.probes[0].Degraded)
+		assert.Equal(t, float32(200), printer.probes[0].SmoothedRTT)
+		// only printed with Options.ShowLocalAddr
+		assert.False(t, printer.probes[0].LocalAddr.IsValid())
+
+		assert.False(t, printer.probes[1].Success)
+		assert.Equal(t, FailureRefused, printer.probes[1].FailureReason)
+		assert.ErrorIs(t, printer.probes[1].Err, syscall.ECONNREFUSED)
+
+		assert.Equal(t, uint(3), printer.probes[2].Seq)
+		assert.Equal(t, "127.0.0.1:50001", printer.probes[2].LocalAddr.String())
+	}
+	if assert.Len(t, printer.changes, 2) {
+		assert.False(t, printer.changes[0].Up)
+		assert.True(t, printer.changes[1].Up)
+		assert.Equal(t, time.Second, printer.changes[1].Downtime)
+		assert.True(t, printer.changes[1].isRecovery())
+	}
+	// only the calls that aren't about a probe
+	assert.Equal(t, []string{"done"}, printer.lines)
+}
+
+func TestProbeDetails(t *testing.T) {
+	ip := netip.MustParseAddr("127.0.0.1")
+
+	assert.Empty(t, probeDetails(Result{IP: ip, Attempts: 2}))
+	assert.Equal(t, []string{
+		"Goodput of 12.500 Mbit/s",
+		"127.0.0.1 closed the connection after 1.500 ms",
+		"Connected to 127.0.0.1 on attempt 2",
+	}, probeDetails(Result{
+		IP:             ip,
+		Success:        true,
+		Goodput:        12.5,
+		PeerClose:      PeerCloseFIN,
+		PeerCloseAfter: 1500 * time.Microsecond,
+		Attempts:       2,
+	}))
+}
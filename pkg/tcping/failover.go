@@ -0,0 +1,33 @@
+package tcping
+
+import "slices"
+
+// failover probes the next address of the hostname once the current one
+// failed Options.Failover probes in a row, so that a broken address isn't
+// probed until the hostname is resolved again, which may return it again.
+func (tcpStats *stats) failover() {
+	if tcpStats.addrFailures < tcpStats.userInput.Failover {
+		return
+	}
+
+	addrs := lookupHosts(tcpStats.userInput.Hosts, tcpStats.userInput.Hostname)
+	if addrs == nil {
+		addrs = tcpStats.resolvedAddrs
+	}
+	addrs = filterAddrs(tcpStats.userInput.Options, addrs)
+	if len(addrs) < 2 {
+		return
+	}
+
+	// the address might not be among them anymore, then the first one is next
+	current := tcpStats.userInput.ip
+	next := addrs[(slices.Index(addrs, current)+1)%len(addrs)]
+
+	tcpStats.log().Info("failing over to the next address", "previous", current, "ip", next,
+		"failed_probes", tcpStats.addrFailures)
+	tcpStats.printer.PrintInfo("Failing over from %s to %s after %d failed probes",
+		current, next, tcpStats.addrFailures)
+
+	tcpStats.failovers += 1
+	tcpStats.updateIP(next)
+}
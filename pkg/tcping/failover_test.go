@@ -0,0 +1,53 @@
+package tcping
+
+import (
+	"net/netip"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFailover(t *testing.T) {
+	first := netip.MustParseAddr("192.0.2.1")
+	second := netip.MustParseAddr("192.0.2.2")
+
+	stats := createTestStats(t)
+	stats.userInput.Hostname = "www.example.test"
+	stats.userInput.Hosts = map[string][]netip.Addr{"www.example.test": {first, second}}
+	stats.userInput.Failover = 2
+	stats.userInput.ip = first
+
+	now := time.Now()
+	stats.handleConnError(now, nil)
+	stats.failover()
+	assert.Equal(t, first, stats.userInput.ip)
+
+	stats.handleConnError(now, nil)
+	stats.failover()
+	assert.Equal(t, second, stats.userInput.ip)
+	assert.Zero(t, stats.addrFailures)
+
+	// a success keeps the address
+	stats.handleConnError(now, nil)
+	stats.handleConnSuccess(10, now)
+	stats.handleConnError(now, nil)
+	stats.failover()
+	assert.Equal(t, second, stats.userInput.ip)
+
+	// the first address is next to the last one
+	stats.handleConnError(now, nil)
+	stats.failover()
+	assert.Equal(t, first, stats.userInput.ip)
+	assert.Equal(t, uint(2), stats.statistics().Failovers)
+}
+
+func TestFailoverSingleAddress(t *testing.T) {
+	stats := createTestStats(t)
+	stats.userInput.Failover = 1
+
+	stats.handleConnError(time.Now(), nil)
+	stats.failover()
+	assert.Equal(t, netip.MustParseAddr("127.0.0.1"), stats.userInput.ip)
+	assert.Zero(t, stats.failovers)
+}
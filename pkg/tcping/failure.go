@@ -0,0 +1,95 @@
+package tcping
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"slices"
+	"strings"
+	"syscall"
+)
+
+// FailureReason is the category of the error of a failed probe.
+type FailureReason string
+
+const (
+	// FailureRefused means the target actively refused the connection.
+	FailureRefused FailureReason = "refused"
+	// FailureTimeout means nothing answered within the timeout.
+	FailureTimeout FailureReason = "timeout"
+	// FailureUnreachable means a router or the OS reported
+	// that the network or the host is unreachable.
+	FailureUnreachable FailureReason = "unreachable"
+	// FailureReset means the connection was reset or aborted.
+	FailureReset FailureReason = "reset"
+	// FailurePermission means the OS, e.g. a local firewall, denied the connection.
+	FailurePermission FailureReason = "permission"
+	// FailureProxy means the proxy of Options.Proxy or the SSH tunnel of
+	// Options.Jump failed, e.g. it was unreachable or it refused to open
+	// the tunnel.
+	FailureProxy FailureReason = "proxy"
+	// FailureProxyAuth means the proxy of Options.Proxy or the SSH
+	// server of Options.Jump rejected the credentials or required some.
+	FailureProxyAuth FailureReason = "proxy-auth"
+	// FailureOther is any other error, e.g. a prober whose check failed.
+	FailureOther FailureReason = "other"
+)
+
+// classifyFailure returns the category of the error of a failed probe.
+func classifyFailure(err error) FailureReason {
+	var netErr net.Error
+	var replayed replayedFailure
+	var proxyErr proxyError
+	switch {
+	case errors.As(err, &replayed):
+		return FailureReason(replayed)
+	case errors.As(err, &proxyErr) && proxyErr.auth:
+		return FailureProxyAuth
+	case errors.As(err, &proxyErr):
+		return FailureProxy
+	case errors.Is(err, syscall.ECONNREFUSED):
+		return FailureRefused
+	case errors.Is(err, syscall.ENETUNREACH), errors.Is(err, syscall.EHOSTUNREACH),
+		errors.Is(err, syscall.ENETDOWN), errors.Is(err, syscall.EHOSTDOWN):
+		return FailureUnreachable
+	case errors.Is(err, syscall.ECONNRESET), errors.Is(err, syscall.ECONNABORTED),
+		errors.Is(err, syscall.EPIPE):
+		return FailureReset
+	case errors.Is(err, syscall.EACCES), errors.Is(err, syscall.EPERM):
+		return FailurePermission
+	case errors.Is(err, os.ErrDeadlineExceeded), errors.Is(err, syscall.ETIMEDOUT),
+		errors.As(err, &netErr) && netErr.Timeout():
+		return FailureTimeout
+	}
+
+	return FailureOther
+}
+
+// recordFailure counts the reason of the failed probe and returns it.
+func (tcpStats *stats) recordFailure(err error) FailureReason {
+	reason := classifyFailure(err)
+	if tcpStats.failureReasons == nil {
+		tcpStats.failureReasons = make(map[FailureReason]uint)
+	}
+	tcpStats.failureReasons[reason] += 1
+
+	return reason
+}
+
+// formatCounts lists the counts of the failure reasons or of the
+// peer closes, e.g. "refused 3, timeout 1", sorted by key.
+func formatCounts[K ~string](counts map[K]uint) string {
+	keys := make([]K, 0, len(counts))
+	for key := range counts {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+
+	list := make([]string, 0, len(keys))
+	for _, key := range keys {
+		list = append(list, fmt.Sprintf("%s %d", key, counts[key]))
+	}
+
+	return strings.Join(list, ", ")
+}
@@ -0,0 +1,58 @@
+package tcping
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClassifyFailure(t *testing.T) {
+	dialErr := func(err error) error {
+		return &net.OpError{Op: "dial", Net: "tcp", Err: os.NewSyscallError("connect", err)}
+	}
+
+	tests := []struct {
+		err  error
+		want FailureReason
+	}{
+		{dialErr(syscall.ECONNREFUSED), FailureRefused},
+		{dialErr(syscall.EHOSTUNREACH), FailureUnreachable},
+		{dialErr(syscall.ENETUNREACH), FailureUnreachable},
+		{dialErr(syscall.ECONNRESET), FailureReset},
+		{dialErr(syscall.EACCES), FailurePermission},
+		{dialErr(syscall.ETIMEDOUT), FailureTimeout},
+		{fmt.Errorf("TLS handshake failed: %w", os.ErrDeadlineExceeded), FailureTimeout},
+		{proxyError{err: dialErr(syscall.ECONNREFUSED)}, FailureProxy},
+		{proxyError{err: proxyAuthError{status: "407 Proxy Authentication Required"}, auth: true}, FailureProxyAuth},
+		{errors.New(`unexpected status "503 Service Unavailable"`), FailureOther},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, classifyFailure(tt.err), tt.err.Error())
+	}
+}
+
+func TestFailureReasons(t *testing.T) {
+	stats := createTestStats(t)
+	stats.ticker = time.NewTicker(time.Nanosecond)
+
+	var results []Result
+	stats.userInput.Hooks.OnProbe = func(r Result) { results = append(results, r) }
+
+	// nothing listens on the port
+	tcping(context.Background(), stats)
+	tcping(context.Background(), stats)
+
+	if assert.Len(t, results, 2) {
+		assert.Equal(t, FailureRefused, results[1].FailureReason)
+	}
+	assert.Equal(t, map[FailureReason]uint{FailureRefused: 2}, stats.statistics().FailureReasons)
+	assert.Equal(t, "refused 2", formatCounts(stats.failureReasons))
+}
@@ -0,0 +1,115 @@
+package tcping
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"time"
+)
+
+// goodputChunk is the size of the writes of the payload of Options.GoodputSize.
+const goodputChunk = 32 * 1024
+
+// measureGoodput streams the Options.GoodputSize bytes of the payload over
+// the connection within the timeout and records the goodput, which is
+// printed with the probe. With Options.GoodputEcho, the payload is read
+// back as it's sent. Otherwise the connection is half-closed once it's
+// sent, and the peer must close it once it has read everything, like the
+// discard services do, so that the time covers the whole transfer and not
+// only the filling of the send buffer.
+func (tcpStats *stats) measureGoodput(conn net.Conn) error {
+	size := tcpStats.userInput.GoodputSize
+	if timeout := tcpStats.userInput.Timeout; timeout > 0 {
+		conn.SetDeadline(time.Now().Add(timeout))
+		defer conn.SetDeadline(time.Time{})
+	}
+
+	start := time.Now()
+	var err error
+	if tcpStats.userInput.GoodputEcho {
+		err = streamEcho(conn, size)
+	} else {
+		err = streamDiscard(conn, size)
+	}
+	if err != nil {
+		tcpStats.printer.PrintError("Streaming %d bytes to %s on port %d failed: %s",
+			size, tcpStats.userInput.ip, tcpStats.userInput.Port, err)
+		return err
+	}
+
+	elapsed := time.Since(start)
+	tcpStats.goodput = float32(float64(size) * 8 / elapsed.Seconds() / 1e6)
+	tcpStats.goodputs.add(tcpStats.goodput)
+	tcpStats.log().Debug("streamed the payload", "bytes", size, "elapsed", elapsed, "mbps", tcpStats.goodput)
+
+	return nil
+}
+
+// writePayload writes size zeros to the connection.
+func writePayload(conn net.Conn, size int64) error {
+	chunk := make([]byte, min(size, goodputChunk))
+	for size > 0 {
+		n, err := conn.Write(chunk[:min(size, int64(len(chunk)))])
+		size -= int64(n)
+		if err != nil {
+			return fmt.Errorf("unable to send the payload: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// streamEcho sends the payload and reads it back at the same time.
+func streamEcho(conn net.Conn, size int64) error {
+	written := make(chan error, 1)
+	go func() {
+		written <- writePayload(conn, size)
+	}()
+
+	n, err := io.CopyN(io.Discard, conn, size)
+	if err != nil {
+		// unblock the writes
+		conn.SetDeadline(time.Now())
+	}
+	if writeErr := <-written; writeErr != nil && err == nil {
+		return writeErr
+	}
+	if errors.Is(err, io.EOF) {
+		return fmt.Errorf("the peer closed the connection after echoing %d of %d bytes", n, size)
+	}
+	if err != nil {
+		return fmt.Errorf("unable to read the echo: %w", err)
+	}
+
+	return nil
+}
+
+// streamDiscard sends the payload, half-closes the connection
+// and waits for the peer to close it.
+func streamDiscard(conn net.Conn, size int64) error {
+	if err := writePayload(conn, size); err != nil {
+		return err
+	}
+
+	closer, ok := conn.(interface{ CloseWrite() error })
+	if !ok {
+		return errors.New("the connection can't be half-closed, use the echo")
+	}
+	if err := closer.CloseWrite(); err != nil {
+		return fmt.Errorf("unable to end the payload: %w", err)
+	}
+
+	if _, err := io.Copy(io.Discard, conn); err != nil {
+		return fmt.Errorf("the peer didn't close the connection: %w", err)
+	}
+
+	return nil
+}
+
+// takeGoodput returns the goodput of the probe, if it was measured, and resets it.
+func (tcpStats *stats) takeGoodput() float32 {
+	goodput := tcpStats.goodput
+	tcpStats.goodput = 0
+	return goodput
+}
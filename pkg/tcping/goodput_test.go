@@ -0,0 +1,91 @@
+package tcping
+
+import (
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// testStreamServer starts a server handling every connection with serve
+// and returns its port.
+func testStreamServer(t *testing.T, serve func(conn net.Conn)) uint16 {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	t.Cleanup(func() { ln.Close() })
+
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				serve(conn)
+			}()
+		}
+	}()
+
+	return uint16(ln.Addr().(*net.TCPAddr).Port)
+}
+
+func TestGoodput(t *testing.T) {
+	discard := func(conn net.Conn) { io.Copy(io.Discard, conn) }
+	echo := func(conn net.Conn) { io.Copy(conn, conn) }
+	// reads the payload but never closes the connection
+	stuck := func(conn net.Conn) {
+		io.Copy(io.Discard, conn)
+		time.Sleep(time.Second)
+	}
+
+	for _, tt := range []struct {
+		name   string
+		serve  func(conn net.Conn)
+		echo   bool
+		reason FailureReason
+	}{
+		{"discard", discard, false, ""},
+		{"echo", echo, true, ""},
+		{"stuck", stuck, false, FailureTimeout},
+		{"not echoing", discard, true, FailureTimeout},
+	} {
+		var results []Result
+		p, err := New(Options{
+			Printer:               &dummyPrinter{},
+			Hostname:              "127.0.0.1",
+			Port:                  testStreamServer(t, tt.serve),
+			IntervalBetweenProbes: 2 * time.Millisecond,
+			Timeout:               200 * time.Millisecond,
+			ProbesBeforeQuit:      2,
+			GoodputSize:           1 << 20,
+			GoodputEcho:           tt.echo,
+			Hooks: Hooks{
+				OnProbe: func(r Result) { results = append(results, r) },
+			},
+		})
+		assert.NoError(t, err)
+
+		p.Run()
+		p.Shutdown()
+
+		if !assert.Len(t, results, 2, tt.name) {
+			continue
+		}
+		for _, r := range results {
+			assert.Equal(t, tt.reason == "", r.Success, tt.name)
+			assert.Equal(t, tt.reason, r.FailureReason, tt.name)
+			assert.Equal(t, tt.reason == "", r.Goodput > 0, tt.name)
+		}
+		assert.Equal(t, tt.reason == "", p.Statistics().GoodputResults.HasResults, tt.name)
+	}
+
+	_, err := New(Options{Hostname: "127.0.0.1", Port: 12345, GoodputEcho: true})
+	assert.Error(t, err)
+	_, err = New(Options{Hostname: "127.0.0.1", Port: 12345, GoodputSize: 1 << 20, Send: []byte("PING\r\n")})
+	assert.Error(t, err)
+}
@@ -0,0 +1,168 @@
+package tcping
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// HTTPConfig is the request sent by the http prober
+// and the response it expects. See ProberConfig.HTTP.
+type HTTPConfig struct {
+	// Method defaults to GET.
+	Method string
+	// Path defaults to /.
+	Path string
+	// Header is sent with the request. Host defaults to the hostname
+	// of the target, followed by the port unless it's 80 or 443.
+	Header http.Header
+	// ExpectStatus lists the accepted status codes, 200-399 by default.
+	ExpectStatus []StatusRange
+	// ExpectBody must match the first 4 KiB of the body, if it's not nil.
+	ExpectBody *regexp.Regexp
+}
+
+// StatusRange is an inclusive range of HTTP status codes.
+type StatusRange struct {
+	Min, Max int
+}
+
+// ParseStatusRanges parses a comma-separated list of status
+// codes and ranges of status codes, e.g. "200-299,301".
+func ParseStatusRanges(s string) ([]StatusRange, error) {
+	var ranges []StatusRange
+	for _, part := range strings.Split(s, ",") {
+		first, last, isRange := strings.Cut(strings.TrimSpace(part), "-")
+		if !isRange {
+			last = first
+		}
+
+		lo, err := strconv.Atoi(first)
+		if err != nil {
+			return nil, fmt.Errorf("invalid status code %q", first)
+		}
+		hi, err := strconv.Atoi(last)
+		if err != nil {
+			return nil, fmt.Errorf("invalid status code %q", last)
+		}
+		if lo < 100 || hi > 999 || lo > hi {
+			return nil, fmt.Errorf("invalid status range %q", part)
+		}
+
+		ranges = append(ranges, StatusRange{Min: lo, Max: hi})
+	}
+
+	return ranges, nil
+}
+
+func init() {
+	RegisterProber("http", func(cfg ProberConfig) (Prober, error) {
+		return newHTTPProber(cfg), nil
+	})
+}
+
+// httpProber sends a request over the connection of
+// the probe and checks the status and the body.
+type httpProber struct {
+	hostname string
+	HTTPConfig
+}
+
+func newHTTPProber(cfg ProberConfig) *httpProber {
+	p := &httpProber{hostname: cfg.Hostname, HTTPConfig: cfg.HTTP}
+	if p.Method == "" {
+		p.Method = http.MethodGet
+	}
+	if p.Path == "" {
+		p.Path = "/"
+	}
+	if len(p.ExpectStatus) == 0 {
+		p.ExpectStatus = []StatusRange{{Min: 200, Max: 399}}
+	}
+
+	return p
+}
+
+func (p *httpProber) Probe(conn net.Conn, timeout time.Duration) error {
+	if timeout > 0 {
+		conn.SetDeadline(time.Now().Add(timeout))
+	}
+
+	req, err := newHTTPRequest(p.Method, p.hostname, p.Path, p.Header, conn)
+	if err != nil {
+		return err
+	}
+	req.Close = true
+
+	if err := req.Write(conn); err != nil {
+		return fmt.Errorf("unable to send the request: %w", err)
+	}
+
+	resp, err := http.ReadResponse(bufio.NewReader(conn), req)
+	if err != nil {
+		return fmt.Errorf("unable to read the response: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if !p.statusExpected(resp.StatusCode) {
+		return fmt.Errorf("unexpected status %q", resp.Status)
+	}
+
+	if p.ExpectBody != nil {
+		body, err := io.ReadAll(io.LimitReader(resp.Body, maxResponseSize))
+		if err != nil {
+			return fmt.Errorf("unable to read the body: %w", err)
+		}
+
+		if !p.ExpectBody.Match(body) {
+			return fmt.Errorf("the body %s doesn't match %s", quoteResponse(body), p.ExpectBody)
+		}
+	}
+
+	return nil
+}
+
+// newHTTPRequest returns a request to the target with the given headers.
+// Host defaults to the hostname followed by the port of the connection,
+// unless it's the default one.
+func newHTTPRequest(method, hostname, path string, header http.Header, conn net.Conn) (*http.Request, error) {
+	host := hostname
+	if strings.Contains(host, ":") {
+		host = "[" + host + "]"
+	}
+	if _, port, err := net.SplitHostPort(conn.RemoteAddr().String()); err == nil && port != "80" && port != "443" {
+		host = net.JoinHostPort(hostname, port)
+	}
+
+	req, err := http.NewRequest(method, "http://"+host+path, nil)
+	if err != nil {
+		return nil, err
+	}
+	if header != nil {
+		req.Header = header.Clone()
+	}
+	if host := req.Header.Get("Host"); host != "" {
+		req.Host = host
+	}
+	if req.Header.Get("User-Agent") == "" {
+		req.Header.Set("User-Agent", "tcping")
+	}
+
+	return req, nil
+}
+
+func (p *httpProber) statusExpected(code int) bool {
+	for _, r := range p.ExpectStatus {
+		if code >= r.Min && code <= r.Max {
+			return true
+		}
+	}
+
+	return false
+}
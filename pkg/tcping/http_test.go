@@ -0,0 +1,93 @@
+package tcping
+
+import (
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseStatusRanges(t *testing.T) {
+	ranges, err := ParseStatusRanges("200-299, 301")
+	assert.NoError(t, err)
+	assert.Equal(t, []StatusRange{{Min: 200, Max: 299}, {Min: 301, Max: 301}}, ranges)
+
+	for _, s := range []string{"", "2xx", "299-200", "200-", "42"} {
+		_, err := ParseStatusRanges(s)
+		assert.Error(t, err, s)
+	}
+}
+
+func TestHTTPProber(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Host != "www.example.com":
+			w.WriteHeader(http.StatusMisdirectedRequest)
+		case r.Method == http.MethodHead:
+			w.WriteHeader(http.StatusNoContent)
+		case r.URL.Path == "/health":
+			w.Write([]byte(`{"status":"ok"}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	host := http.Header{"Host": {"www.example.com"}}
+	tests := []struct {
+		name    string
+		cfg     HTTPConfig
+		wantErr string
+	}{
+		{
+			name: "default",
+			cfg:  HTTPConfig{Path: "/health", Header: host},
+		},
+		{
+			name: "method",
+			cfg:  HTTPConfig{Method: http.MethodHead, Header: host, ExpectStatus: []StatusRange{{Min: 204, Max: 204}}},
+		},
+		{
+			name:    "status",
+			cfg:     HTTPConfig{Path: "/missing", Header: host},
+			wantErr: `unexpected status "404 Not Found"`,
+		},
+		{
+			name:    "host",
+			cfg:     HTTPConfig{Path: "/health"},
+			wantErr: `unexpected status "421 Misdirected Request"`,
+		},
+		{
+			name: "body",
+			cfg:  HTTPConfig{Path: "/health", Header: host, ExpectBody: regexp.MustCompile(`"status":"ok"`)},
+		},
+		{
+			name:    "body mismatch",
+			cfg:     HTTPConfig{Path: "/health", Header: host, ExpectBody: regexp.MustCompile(`"status":"degraded"`)},
+			wantErr: `the body "{\"status\":\"ok\"}" doesn't match "status":"degraded"`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conn, err := net.Dial("tcp", srv.Listener.Addr().String())
+			if err != nil {
+				t.Fatalf("dial: %v", err)
+			}
+			defer conn.Close()
+
+			prober, err := NewProber("http", ProberConfig{Hostname: "127.0.0.1", HTTP: tt.cfg})
+			assert.NoError(t, err)
+
+			err = prober.Probe(conn, time.Second)
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,134 @@
+package tcping
+
+import (
+	"net"
+	"net/netip"
+	"os"
+	"time"
+
+	"golang.org/x/net/icmp"
+	"golang.org/x/net/ipv4"
+	"golang.org/x/net/ipv6"
+)
+
+// the protocol numbers of ICMP, to parse the replies
+const (
+	protocolICMP     = 1
+	protocolICMPIPv6 = 58
+)
+
+// icmpReply is the result of an ICMP echo sent with Options.CompareICMP.
+type icmpReply struct {
+	rtt float32
+	err error
+}
+
+// listenICMP opens a socket for ICMP echoes. Unprivileged ICMP sockets
+// are preferred, raw ones are the fallback when they're not allowed,
+// e.g. by net.ipv4.ping_group_range on Linux.
+func listenICMP(v6 bool) (conn *icmp.PacketConn, raw bool, err error) {
+	network, rawNetwork, address := "udp4", "ip4:icmp", "0.0.0.0"
+	if v6 {
+		network, rawNetwork, address = "udp6", "ip6:ipv6-icmp", "::"
+	}
+
+	if conn, err = icmp.ListenPacket(network, address); err == nil {
+		return conn, false, nil
+	}
+
+	conn, err = icmp.ListenPacket(rawNetwork, address)
+	return conn, true, err
+}
+
+// startICMPEcho sends an ICMP echo to the target alongside the probe.
+// The reply, or its absence, is received from the returned channel.
+func (tcpStats *stats) startICMPEcho() <-chan icmpReply {
+	tcpStats.icmpSeq++
+	seq := int(tcpStats.icmpSeq)
+
+	timeout := tcpStats.userInput.Timeout
+	if timeout <= 0 {
+		timeout = tcpStats.userInput.IntervalBetweenProbes
+	}
+
+	done := make(chan icmpReply, 1)
+	go func(ip netip.Addr) {
+		rtt, err := pingICMP(ip, seq, timeout)
+		done <- icmpReply{rtt: rtt, err: err}
+	}(tcpStats.userInput.ip)
+
+	return done
+}
+
+// recordICMPEcho waits for the ICMP echo sent alongside the
+// probe and prints its RTT next to the one of the probe.
+func (tcpStats *stats) recordICMPEcho(done <-chan icmpReply) {
+	reply := <-done
+	if reply.err != nil {
+		tcpStats.icmpLost += 1
+		tcpStats.printer.PrintInfo("No ICMP echo reply from %s: %s", tcpStats.userInput.ip, reply.err)
+		return
+	}
+
+	tcpStats.icmpRtt.add(reply.rtt)
+	tcpStats.printer.PrintInfo("ICMP echo reply from %s in %.3f ms", tcpStats.userInput.ip, reply.rtt)
+}
+
+// pingICMP sends an ICMP echo to the IP and returns the RTT of its reply.
+func pingICMP(ip netip.Addr, seq int, timeout time.Duration) (float32, error) {
+	ip = ip.Unmap()
+	conn, raw, err := listenICMP(ip.Is6())
+	if err != nil {
+		return 0, err
+	}
+	defer conn.Close()
+
+	var echoType, replyType icmp.Type = ipv4.ICMPTypeEcho, ipv4.ICMPTypeEchoReply
+	proto := protocolICMP
+	if ip.Is6() {
+		echoType, replyType = ipv6.ICMPTypeEchoRequest, ipv6.ICMPTypeEchoReply
+		proto = protocolICMPIPv6
+	}
+
+	// the kernel sets the ID of unprivileged sockets
+	// and only passes them their own replies
+	id := os.Getpid() & 0xffff
+	msg, err := (&icmp.Message{
+		Type: echoType,
+		Body: &icmp.Echo{ID: id, Seq: seq, Data: []byte("tcping")},
+	}).Marshal(nil)
+	if err != nil {
+		return 0, err
+	}
+
+	var dst net.Addr = &net.IPAddr{IP: ip.AsSlice(), Zone: ip.Zone()}
+	if !raw {
+		dst = &net.UDPAddr{IP: ip.AsSlice(), Zone: ip.Zone()}
+	}
+
+	start := time.Now()
+	conn.SetDeadline(start.Add(timeout))
+	if _, err := conn.WriteTo(msg, dst); err != nil {
+		return 0, err
+	}
+
+	buf := make([]byte, 1500)
+	for {
+		n, _, err := conn.ReadFrom(buf)
+		if err != nil {
+			return 0, err
+		}
+
+		reply, err := icmp.ParseMessage(proto, buf[:n])
+		if err != nil || reply.Type != replyType {
+			continue
+		}
+
+		echo, ok := reply.Body.(*icmp.Echo)
+		if !ok || echo.Seq != seq || (raw && echo.ID != id) {
+			continue
+		}
+
+		return nanoToMillisecond(time.Since(start).Nanoseconds()), nil
+	}
+}
@@ -0,0 +1,53 @@
+package tcping
+
+import (
+	"net"
+	"net/netip"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPingICMP(t *testing.T) {
+	conn, _, err := listenICMP(false)
+	if err != nil {
+		t.Skipf("ICMP sockets aren't allowed: %v", err)
+	}
+	conn.Close()
+
+	rtt, err := pingICMP(netip.MustParseAddr("127.0.0.1"), 1, time.Second)
+	assert.NoError(t, err)
+	assert.NotZero(t, rtt)
+}
+
+func TestCompareICMP(t *testing.T) {
+	conn, _, err := listenICMP(false)
+	if err != nil {
+		t.Skipf("ICMP sockets aren't allowed: %v", err)
+	}
+	conn.Close()
+
+	srv, err := net.Listen("tcp", "127.0.0.1:12345")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	t.Cleanup(func() { srv.Close() })
+
+	p, err := New(Options{
+		Printer:               &dummyPrinter{},
+		Hostname:              "127.0.0.1",
+		Port:                  12345,
+		IntervalBetweenProbes: 2 * time.Millisecond,
+		Timeout:               time.Second,
+		ProbesBeforeQuit:      2,
+		CompareICMP:           true,
+	})
+	assert.NoError(t, err)
+
+	p.Run()
+
+	s := p.Statistics()
+	assert.True(t, s.ICMPRttResults.HasResults)
+	assert.Zero(t, s.ICMPLostEchoes)
+}
@@ -0,0 +1,123 @@
+package tcping
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"net"
+	"net/netip"
+	"slices"
+)
+
+// policies picking the address to probe, as accepted by Options.IPSelect.
+const (
+	// IPSelectFirst picks the first address, in the order of the resolver.
+	IPSelectFirst = "first"
+	// IPSelectRandom picks any of the addresses.
+	IPSelectRandom = "random"
+	// IPSelectRoundRobin picks the next address every time the hostname
+	// is resolved, e.g. with Options.ResolveInterval.
+	IPSelectRoundRobin = "round-robin"
+	// IPSelectFastest connects to all the addresses at once
+	// and picks the one that answers first.
+	IPSelectFastest = "fastest"
+)
+
+// filterAddrs returns the addresses of the family asked for with
+// Options.UseIPv4 and Options.UseIPv6, without duplicates.
+// IPv4-mapped IPv6 addresses are IPv4 ones.
+func filterAddrs(opts Options, ipAddrs []netip.Addr) []netip.Addr {
+	var ipList []netip.Addr
+	for _, ip := range ipAddrs {
+		ip = ip.Unmap()
+		if (opts.UseIPv4 && !ip.Is4()) || (opts.UseIPv6 && !ip.Is6()) || slices.Contains(ipList, ip) {
+			continue
+		}
+		ipList = append(ipList, ip)
+	}
+
+	return ipList
+}
+
+// selectResolvedIP returns the address to probe among the resolved
+// addresses, picked as asked for with Options.IPSelect.
+func selectResolvedIP(ctx context.Context, tcpStats *stats, ipAddrs []netip.Addr) (netip.Addr, error) {
+	ipList := filterAddrs(tcpStats.userInput.Options, ipAddrs)
+	if len(ipList) == 0 {
+		switch {
+		case tcpStats.userInput.UseIPv4:
+			return netip.Addr{}, fmt.Errorf("failed to find IPv4 address for %s", tcpStats.userInput.Hostname)
+		case tcpStats.userInput.UseIPv6:
+			return netip.Addr{}, fmt.Errorf("failed to find IPv6 address for %s", tcpStats.userInput.Hostname)
+		default:
+			return netip.Addr{}, fmt.Errorf("failed to find an address for %s", tcpStats.userInput.Hostname)
+		}
+	}
+
+	policy := tcpStats.userInput.IPSelect
+	if policy == "" {
+		policy = IPSelectFirst
+	}
+
+	var ip netip.Addr
+	switch policy {
+	case IPSelectRandom:
+		ip = ipList[rand.Intn(len(ipList))]
+	case IPSelectRoundRobin:
+		ip = ipList[tcpStats.nextAddr%uint(len(ipList))]
+		tcpStats.nextAddr++
+	case IPSelectFastest:
+		ip = tcpStats.fastestAddr(ctx, ipList)
+	default:
+		ip = ipList[0]
+	}
+
+	tcpStats.log().Info("selected the address to probe", "ip", ip, "candidates", len(ipList),
+		"ipv4_only", tcpStats.userInput.UseIPv4, "ipv6_only", tcpStats.userInput.UseIPv6, "policy", policy)
+
+	return ip, nil
+}
+
+// fastestAddr connects to all the addresses at once and returns the
+// one that answers first, or the first one if none of them answers.
+func (tcpStats *stats) fastestAddr(ctx context.Context, ipList []netip.Addr) netip.Addr {
+	if len(ipList) == 1 {
+		return ipList[0]
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	dialer := net.Dialer{Timeout: tcpStats.userInput.Timeout}
+	if tcpStats.userInput.networkInterface.use {
+		dialer = tcpStats.userInput.networkInterface.dialer
+	}
+	dialer.Control = tcpStats.control
+
+	// the addresses that didn't answer are sent as invalid ones
+	answered := make(chan netip.Addr, len(ipList))
+	for _, ip := range ipList {
+		go func(ip netip.Addr) {
+			var conn net.Conn
+			err := tcpStats.inNetns(func() (err error) {
+				conn, err = dialer.DialContext(ctx, "tcp", netip.AddrPortFrom(ip, tcpStats.userInput.Port).String())
+				return err
+			})
+			if err != nil {
+				answered <- netip.Addr{}
+				return
+			}
+			conn.Close()
+			answered <- ip
+		}(ip)
+	}
+
+	for range ipList {
+		if ip := <-answered; ip.IsValid() {
+			return ip
+		}
+	}
+
+	tcpStats.log().Debug("none of the addresses answered, picking the first one", "candidates", len(ipList))
+	return ipList[0]
+}
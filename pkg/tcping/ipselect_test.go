@@ -0,0 +1,84 @@
+package tcping
+
+import (
+	"context"
+	"net"
+	"net/netip"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFilterAddrs(t *testing.T) {
+	v4 := netip.MustParseAddr("192.0.2.1")
+	mapped := netip.MustParseAddr("::ffff:192.0.2.2")
+	v6 := netip.MustParseAddr("2001:db8::1")
+	addrs := []netip.Addr{v4, mapped, v6, v4}
+
+	assert.Equal(t, []netip.Addr{v4, mapped.Unmap(), v6}, filterAddrs(Options{}, addrs))
+	assert.Equal(t, []netip.Addr{v4, mapped.Unmap()}, filterAddrs(Options{UseIPv4: true}, addrs))
+	// the IPv4-mapped addresses aren't IPv6 ones
+	assert.Equal(t, []netip.Addr{v6}, filterAddrs(Options{UseIPv6: true}, addrs))
+	assert.Empty(t, filterAddrs(Options{UseIPv6: true}, []netip.Addr{mapped}))
+}
+
+func TestSelectResolvedIPPolicies(t *testing.T) {
+	addrs := []netip.Addr{
+		netip.MustParseAddr("192.0.2.1"),
+		netip.MustParseAddr("192.0.2.2"),
+		netip.MustParseAddr("192.0.2.3"),
+	}
+
+	stats := createTestStats(t)
+	for i := 0; i < 2; i++ {
+		ip, err := selectResolvedIP(context.Background(), stats, addrs)
+		assert.NoError(t, err)
+		assert.Equal(t, addrs[0], ip)
+	}
+
+	stats.userInput.IPSelect = IPSelectRoundRobin
+	for _, want := range []netip.Addr{addrs[0], addrs[1], addrs[2], addrs[0]} {
+		ip, err := selectResolvedIP(context.Background(), stats, addrs)
+		assert.NoError(t, err)
+		assert.Equal(t, want, ip)
+	}
+
+	stats.userInput.IPSelect = IPSelectRandom
+	ip, err := selectResolvedIP(context.Background(), stats, addrs)
+	assert.NoError(t, err)
+	assert.Contains(t, addrs, ip)
+
+	stats.userInput.UseIPv6 = true
+	_, err = selectResolvedIP(context.Background(), stats, addrs)
+	assert.Error(t, err)
+}
+
+func TestSelectFastestIP(t *testing.T) {
+	srv, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	t.Cleanup(func() { srv.Close() })
+
+	stats := createTestStats(t)
+	stats.userInput.IPSelect = IPSelectFastest
+	stats.userInput.Port = uint16(srv.Addr().(*net.TCPAddr).Port)
+
+	// only the second address is listened on
+	listening := netip.MustParseAddr("127.0.0.1")
+	ip, err := selectResolvedIP(context.Background(), stats, []netip.Addr{netip.MustParseAddr("127.0.0.2"), listening})
+	assert.NoError(t, err)
+	assert.Equal(t, listening, ip)
+
+	// none of them answers
+	srv.Close()
+	first := netip.MustParseAddr("127.0.0.2")
+	ip, err = selectResolvedIP(context.Background(), stats, []netip.Addr{first, listening})
+	assert.NoError(t, err)
+	assert.Equal(t, first, ip)
+}
+
+func TestInvalidIPSelect(t *testing.T) {
+	_, err := New(Options{Printer: &dummyPrinter{}, Hostname: "127.0.0.1", Port: 80, IPSelect: "nearest"})
+	assert.Error(t, err)
+}
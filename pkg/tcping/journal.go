@@ -0,0 +1,336 @@
+package tcping
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"net/netip"
+	"os"
+	"strings"
+	"time"
+)
+
+// syslog priorities understood by systemd-journald
+// at the beginning of the lines written to the stdout.
+const (
+	journalErr     = "<3>"
+	journalWarning = "<4>"
+	journalNotice  = "<5>"
+	journalInfo    = "<6>"
+)
+
+type journalPrinter struct {
+	w io.Writer
+	// start is when the printer was created, with PrinterConfig.Elapsed.
+	start time.Time
+}
+
+// NewJournalPrinter returns a printer for running under systemd.
+// It prints plain messages without colors, each line prefixed
+// with its priority, so that the journal can filter them.
+func NewJournalPrinter() Printer {
+	return &journalPrinter{w: os.Stdout}
+}
+
+func (p *journalPrinter) print(priority, format string, args ...any) {
+	if !p.start.IsZero() {
+		// after the priority, so that the journal still finds it
+		priority += formatElapsed(time.Since(p.start))
+	}
+	fmt.Fprintf(p.w, priority+format+"\n", args...)
+}
+
+func (p *journalPrinter) PrintStart(hostname string, port uint16) {
+	p.print(journalInfo, "TCPinging %s on port %d", hostname, port)
+}
+
+// PrintStartService logs the first message with the name of the service of the port.
+func (p *journalPrinter) PrintStartService(hostname string, port uint16, service string) {
+	p.print(journalInfo, "TCPinging %s on port %s", hostname, formatPort(port, service))
+}
+
+// PrintProbe logs the probe with its sequence number, followed by
+// why it failed or the details of the successful probe. The failed
+// and the degraded probes are logged as warnings.
+func (p *journalPrinter) PrintProbe(r Result) {
+	if !r.Success {
+		p.print(journalWarning, "%s", formatProbe(r))
+		if r.Err != nil {
+			p.print(journalWarning, "%s: %s", r.FailureReason, r.Err)
+		}
+		return
+	}
+
+	if r.Degraded {
+		p.print(journalWarning, "%s", formatProbe(r))
+	} else {
+		p.print(journalInfo, "%s", formatProbe(r))
+	}
+
+	if info := r.TCPInfo; info != nil {
+		p.print(journalInfo, "kernel srtt=%.3f ms rttvar=%.3f ms (%+.3f ms in userspace) %s",
+			info.SRTT, info.RTTVar, r.RTT-info.SRTT, formatTCPInfo(*info))
+	}
+	if r.LocalAddr.IsValid() {
+		p.print(journalInfo, "Connected from %s", r.LocalAddr)
+	}
+	if r.SmoothedRTT > 0 {
+		p.print(journalInfo, "smoothed rtt=%.3f ms", r.SmoothedRTT)
+	}
+	for _, detail := range probeDetails(r) {
+		p.print(journalInfo, "%s", detail)
+	}
+}
+
+// PrintStateChange logs the downtime when the target is back up.
+func (p *journalPrinter) PrintStateChange(c StateChange) {
+	if c.isRecovery() {
+		p.print(journalNotice, "No response received for %s", DurationToString(c.Downtime))
+	}
+}
+
+func (p *journalPrinter) PrintRetryingToResolve(hostname string) {
+	p.print(journalNotice, "retrying to resolve %s", hostname)
+}
+
+// PrintStatistics prints the same statistics as the plain printer,
+// one line per entry, all with the info priority.
+func (p *journalPrinter) PrintStatistics(s Statistics) {
+	totalPackets := s.TotalSuccessfulProbes + s.TotalUnsuccessfulProbes
+	packetLoss := (float32(s.TotalUnsuccessfulProbes) / float32(totalPackets)) * 100
+
+	if math.IsNaN(float64(packetLoss)) {
+		packetLoss = 0
+	}
+
+	if !s.IsIP {
+		p.print(journalInfo, "--- %s (%s) TCPing statistics ---", s.Hostname, s.IP)
+	} else {
+		p.print(journalInfo, "--- %s TCPing statistics ---", s.Hostname)
+	}
+	if len(s.PTRNames) > 0 {
+		p.print(journalInfo, "reverse DNS: %s", strings.Join(s.PTRNames, ", "))
+	}
+	p.print(journalInfo, "%d probes transmitted on port %s | %d received, %.2f%% packet loss",
+		totalPackets, formatPort(s.Port, s.Service), s.TotalSuccessfulProbes, packetLoss)
+	p.print(journalInfo, "successful probes:   %d", s.TotalSuccessfulProbes)
+	p.print(journalInfo, "unsuccessful probes: %d", s.TotalUnsuccessfulProbes)
+	if len(s.FailureReasons) > 0 {
+		p.print(journalInfo, "failure reasons: %s", formatCounts(s.FailureReasons))
+	}
+	if s.PeerCloses[PeerCloseRST] > 0 {
+		p.print(journalWarning, "connections ended by the peer: %s", formatCounts(s.PeerCloses))
+	} else if len(s.PeerCloses) > 0 {
+		p.print(journalInfo, "connections ended by the peer: %s", formatCounts(s.PeerCloses))
+	}
+	if s.RetriedProbes > 0 {
+		p.print(journalInfo, "probes that succeeded on a retry: %d", s.RetriedProbes)
+	}
+	if s.BurstFailures > 0 {
+		p.print(journalInfo, "failed connections of the bursts: %d", s.BurstFailures)
+	}
+
+	if s.LastSuccessfulProbe.IsZero() {
+		p.print(journalInfo, "last successful probe:   Never succeeded")
+	} else {
+		p.print(journalInfo, "last successful probe:   %v", s.LastSuccessfulProbe.Format(timeFormat))
+	}
+
+	if s.LastUnsuccessfulProbe.IsZero() {
+		p.print(journalInfo, "last unsuccessful probe: Never failed")
+	} else {
+		p.print(journalInfo, "last unsuccessful probe: %v", s.LastUnsuccessfulProbe.Format(timeFormat))
+	}
+
+	p.print(journalInfo, "total uptime:   %s", DurationToString(s.TotalUptime))
+	p.print(journalInfo, "total downtime: %s", DurationToString(s.TotalDowntime))
+
+	if s.TotalUptime+s.TotalDowntime > 0 {
+		p.print(journalInfo, "availability: %.3f%%", s.Availability)
+	}
+
+	for _, ip := range s.PerIP {
+		if ip.RttResults.HasResults {
+			p.print(journalInfo, "statistics of %s: %d probes, %d failed, rtt min/avg/max %.3f/%.3f/%.3f ms",
+				ip.IP, ip.SuccessfulProbes+ip.UnsuccessfulProbes, ip.UnsuccessfulProbes,
+				ip.RttResults.Min, ip.RttResults.Average, ip.RttResults.Max)
+		} else {
+			p.print(journalInfo, "statistics of %s: %d probes, %d failed",
+				ip.IP, ip.SuccessfulProbes+ip.UnsuccessfulProbes, ip.UnsuccessfulProbes)
+		}
+	}
+
+	for _, w := range s.AvailabilityWindows {
+		p.print(journalInfo, "availability from %v: %.3f%%, %d probes, %d failed, rtt avg %.3f ms",
+			w.Start.Format(timeFormat), w.Availability(),
+			w.SuccessfulProbes+w.UnsuccessfulProbes, w.UnsuccessfulProbes, w.AverageRTT)
+	}
+
+	if s.LongestUptime.Duration != 0 {
+		p.print(journalInfo, "longest consecutive uptime:   %v from %v to %v",
+			DurationToString(s.LongestUptime.Duration),
+			s.LongestUptime.Start.Format(timeFormat),
+			s.LongestUptime.End.Format(timeFormat))
+	}
+
+	if s.LongestDowntime.Duration != 0 {
+		p.print(journalInfo, "longest consecutive downtime: %v from %v to %v",
+			DurationToString(s.LongestDowntime.Duration),
+			s.LongestDowntime.Start.Format(timeFormat),
+			s.LongestDowntime.End.Format(timeFormat))
+	}
+
+	if !s.IsIP {
+		p.print(journalInfo, "retried to resolve hostname %d times", s.RetriedHostnameLookups)
+
+		if s.DNSFailures > 0 {
+			p.print(journalWarning, "DNS failures: %d, last at %v",
+				s.DNSFailures, s.LastDNSFailure.Format(timeFormat))
+		}
+
+		if s.Failovers > 0 {
+			p.print(journalWarning, "failed over to the next address %d times", s.Failovers)
+		}
+
+		for i := 0; i < len(s.HostnameChanges)-1; i++ {
+			p.print(journalInfo, "IP address changed from %s to %s at %v",
+				s.HostnameChanges[i].Addr,
+				s.HostnameChanges[i+1].Addr,
+				s.HostnameChanges[i+1].When.Format(timeFormat))
+		}
+	}
+
+	if s.RttResults.HasResults {
+		p.print(journalInfo, "rtt min/avg/max: %.3f/%.3f/%.3f ms",
+			s.RttResults.Min, s.RttResults.Average, s.RttResults.Max)
+		p.print(journalInfo, "rtt median/trimmed mean/stddev: %.3f/%.3f/%.3f ms",
+			s.RttResults.Median, s.RttResults.TrimmedMean, s.RttResults.StdDev)
+		p.print(journalInfo, "smoothed rtt: %.3f ms", s.SmoothedRTT)
+	}
+
+	if s.Outliers > 0 {
+		p.print(journalInfo, "outliers: %d", s.Outliers)
+	}
+	if s.LatencyTrends > 0 {
+		p.print(journalInfo, "latency trends: %d", s.LatencyTrends)
+	}
+
+	if s.DegradedProbes > 0 {
+		p.print(journalInfo, "degraded probes: %d", s.DegradedProbes)
+	}
+
+	if s.KernelRttResults.HasResults {
+		p.print(journalInfo, "kernel srtt min/avg/max: %.3f/%.3f/%.3f ms",
+			s.KernelRttResults.Min, s.KernelRttResults.Average, s.KernelRttResults.Max)
+	}
+	if s.Retransmits > 0 {
+		p.print(journalInfo, "retransmitted segments: %d", s.Retransmits)
+	}
+
+	if s.NetworkRttResults.HasResults {
+		p.print(journalInfo, "wire rtt min/avg/max: %.3f/%.3f/%.3f ms",
+			s.NetworkRttResults.Min, s.NetworkRttResults.Average, s.NetworkRttResults.Max)
+	}
+
+	if s.UserRttResults.HasResults {
+		p.print(journalInfo, "userspace rtt min/avg/max: %.3f/%.3f/%.3f ms",
+			s.UserRttResults.Min, s.UserRttResults.Average, s.UserRttResults.Max)
+	}
+
+	if s.TunnelSetupResults.HasResults {
+		p.print(journalInfo, "tunnel setup min/avg/max: %.3f/%.3f/%.3f ms",
+			s.TunnelSetupResults.Min, s.TunnelSetupResults.Average, s.TunnelSetupResults.Max)
+	}
+
+	if s.BurstRttResults.HasResults {
+		p.print(journalInfo, "burst rtt min/avg/max: %.3f/%.3f/%.3f ms",
+			s.BurstRttResults.Min, s.BurstRttResults.Average, s.BurstRttResults.Max)
+	}
+
+	if s.ResponseTimeResults.HasResults {
+		p.print(journalInfo, "response time min/avg/max: %.3f/%.3f/%.3f ms",
+			s.ResponseTimeResults.Min, s.ResponseTimeResults.Average, s.ResponseTimeResults.Max)
+	}
+
+	if s.GoodputResults.HasResults {
+		p.print(journalInfo, "goodput min/avg/max: %.3f/%.3f/%.3f Mbit/s",
+			s.GoodputResults.Min, s.GoodputResults.Average, s.GoodputResults.Max)
+	}
+
+	if s.TFORttResults.HasResults || s.RegularRttResults.HasResults {
+		p.print(journalInfo, "TCP Fast Open accepted on %d of %d successful probes",
+			s.TFOAcceptedProbes, s.TotalSuccessfulProbes)
+	}
+
+	if s.TFORttResults.HasResults {
+		p.print(journalInfo, "fast open rtt min/avg/max: %.3f/%.3f/%.3f ms",
+			s.TFORttResults.Min, s.TFORttResults.Average, s.TFORttResults.Max)
+	}
+
+	if s.RegularRttResults.HasResults {
+		p.print(journalInfo, "regular rtt min/avg/max: %.3f/%.3f/%.3f ms",
+			s.RegularRttResults.Min, s.RegularRttResults.Average, s.RegularRttResults.Max)
+	}
+
+	if !s.CertExpiry.IsZero() {
+		p.print(journalInfo, "certificate chain expires on %s, in %d days",
+			s.CertExpiry.Format(timeFormat), certDaysRemaining(s.CertExpiry))
+	}
+
+	if s.MPTCPProbes > 0 {
+		p.print(journalInfo, "MPTCP negotiated on %d of %d successful probes",
+			s.MPTCPProbes, s.TotalSuccessfulProbes)
+	}
+
+	if s.ICMPRttResults.HasResults {
+		p.print(journalInfo, "icmp rtt min/avg/max: %.3f/%.3f/%.3f ms",
+			s.ICMPRttResults.Min, s.ICMPRttResults.Average, s.ICMPRttResults.Max)
+	}
+
+	if s.ICMPLostEchoes > 0 {
+		p.print(journalInfo, "ICMP echoes lost: %d", s.ICMPLostEchoes)
+	}
+
+	if s.PathMTU > 0 {
+		p.print(journalInfo, "path MTU: %d bytes", s.PathMTU)
+	}
+
+	if !s.IsIP && s.ResolveTimeResults.HasResults {
+		p.print(journalInfo, "hostname resolution min/avg/max: %.3f/%.3f/%.3f ms",
+			s.ResolveTimeResults.Min, s.ResolveTimeResults.Average, s.ResolveTimeResults.Max)
+	}
+
+	p.print(journalInfo, "TCPing started at: %v", s.StartTime.Format(timeFormat))
+
+	if !s.EndTime.IsZero() {
+		p.print(journalInfo, "TCPing ended at:   %v", s.EndTime.Format(timeFormat))
+	}
+
+	durationTime := time.Time{}.Add(s.TotalDowntime + s.TotalUptime)
+	p.print(journalInfo, "duration (HH:MM:SS): %v", durationTime.Format(hourFormat))
+}
+
+func (p *journalPrinter) PrintVersion() {
+	p.print(journalInfo, "TCPING version %s", Version)
+}
+
+func (p *journalPrinter) PrintHop(hop Hop) {
+	if !hop.Addr.IsValid() || hop.Unreachable {
+		p.print(journalWarning, "%s", formatHop(hop))
+		return
+	}
+
+	p.print(journalInfo, "%s", formatHop(hop))
+}
+
+func (p *journalPrinter) PrintResolved(hostname string, addrs []netip.Addr, selected netip.Addr, resolveTime float32) {
+	p.print(journalInfo, "Resolved %s in %.3f ms to %s", hostname, resolveTime, formatResolvedAddrs(addrs, selected))
+}
+
+func (p *journalPrinter) PrintInfo(format string, args ...any) {
+	p.print(journalInfo, format, args...)
+}
+
+func (p *journalPrinter) PrintError(format string, args ...any) {
+	p.print(journalErr, format, args...)
+}
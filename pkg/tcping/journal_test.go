@@ -0,0 +1,85 @@
+package tcping
+
+import (
+	"net/netip"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestJournalPrinter(t *testing.T) {
+	var out strings.Builder
+	p := &journalPrinter{w: &out}
+
+	ip := netip.MustParseAddr("93.184.216.34")
+	p.PrintProbe(Result{
+		Hostname: "example.com",
+		IP:       ip,
+		Port:     443,
+		Streak:   1,
+		RTT:      12.5,
+		TCPInfo:  &TCPInfo{SRTT: 12, RTTVar: 6, Retransmits: 1, Cwnd: 10, DeliveryRate: 2.5},
+		Success:  true,
+	})
+	p.PrintProbe(Result{IP: ip, Port: 443, Streak: 2, FailureReason: FailureRefused, Err: syscall.ECONNREFUSED})
+	p.PrintError("failed: %s", "reason")
+	p.PrintResolved("example.com", []netip.Addr{
+		netip.MustParseAddr("93.184.216.34"),
+		netip.MustParseAddr("93.184.216.35"),
+	}, netip.MustParseAddr("93.184.216.35"), 1.5)
+
+	assert.Equal(t, "<6>Reply from example.com (93.184.216.34) on port 443 TCP_conn=1 time=12.500 ms\n"+
+		"<6>kernel srtt=12.000 ms rttvar=6.000 ms (+0.500 ms in userspace) retransmits=1 cwnd=10 delivery_rate=2.500 Mbit/s\n"+
+		"<4>No reply from 93.184.216.34 on port 443 TCP_conn=2\n"+
+		"<4>refused: connection refused\n"+
+		"<3>failed: reason\n"+
+		"<6>Resolved example.com in 1.500 ms to 93.184.216.34, 93.184.216.35 (selected)\n", out.String())
+}
+
+func TestJournalPrinterStatistics(t *testing.T) {
+	var out strings.Builder
+	p := &journalPrinter{w: &out}
+
+	p.PrintStatistics(Statistics{
+		StartTime:               time.Now(),
+		Hostname:                "example.com",
+		IP:                      netip.MustParseAddr("93.184.216.34"),
+		Port:                    443,
+		TotalSuccessfulProbes:   3,
+		TotalUnsuccessfulProbes: 1,
+	})
+
+	lines := strings.Split(strings.TrimSuffix(out.String(), "\n"), "\n")
+	for _, line := range lines {
+		assert.True(t, strings.HasPrefix(line, journalInfo), "missing priority: %q", line)
+		assert.NotContains(t, line, "\x1b", "colors should not be used")
+	}
+	assert.Contains(t, out.String(), "<6>4 probes transmitted on port 443 | 3 received, 25.00% packet loss\n")
+}
+
+func TestSequenceNumbers(t *testing.T) {
+	var out strings.Builder
+	stats := createTestStats(t)
+	stats.printer = &journalPrinter{w: &out}
+	stats.userInput.RTTThreshold = 150 * time.Millisecond
+
+	var results []Result
+	stats.userInput.Hooks.OnProbe = func(r Result) { results = append(results, r) }
+
+	start := time.Now()
+	stats.handleConnSuccess(20, start)
+	stats.handleConnError(start.Add(time.Second), nil)
+	stats.handleConnSuccess(200, start.Add(2*time.Second))
+
+	if assert.Len(t, results, 3) {
+		for i, r := range results {
+			assert.Equal(t, uint(i+1), r.Seq)
+		}
+	}
+	assert.Contains(t, out.String(), "<6>Reply from 127.0.0.1 on port 12345 seq=1 TCP_conn=1 time=20.000 ms\n")
+	assert.Contains(t, out.String(), "<4>No reply from 127.0.0.1 on port 12345 seq=2 TCP_conn=1\n")
+	assert.Contains(t, out.String(), "<4>Degraded reply from 127.0.0.1 on port 12345 seq=3 TCP_conn=1 time=200.000 ms\n")
+}
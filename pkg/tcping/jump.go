@@ -0,0 +1,154 @@
+package tcping
+
+import (
+	"context"
+	"errors"
+	"net"
+	"strings"
+	"syscall"
+	"time"
+
+	"golang.org/x/crypto/ssh"
+)
+
+// JumpHost is the SSH server the probes are tunneled through, see Options.Jump.
+type JumpHost struct {
+	// Addr is the host:port of the SSH server.
+	Addr string
+	// Config authenticates tcping to the server and verifies its host key.
+	Config *ssh.ClientConfig
+}
+
+// jumpTargetError is the reason the jump host gave for not reaching
+// the target, e.g. "Connection refused" from OpenSSH. It unwraps to
+// the matching errno, so that the probe fails with the same reason
+// as if it had connected itself.
+type jumpTargetError struct {
+	err   *ssh.OpenChannelError
+	errno syscall.Errno
+}
+
+func (e jumpTargetError) Error() string {
+	return "the jump host couldn't connect: " + e.err.Message
+}
+
+func (e jumpTargetError) Unwrap() []error {
+	if e.errno == 0 {
+		return []error{e.err}
+	}
+	return []error{e.err, e.errno}
+}
+
+// newJumpTargetError guesses the errno from the message of the jump host.
+func newJumpTargetError(err *ssh.OpenChannelError) jumpTargetError {
+	msg := strings.ToLower(err.Message)
+	switch {
+	case strings.Contains(msg, "refused"):
+		return jumpTargetError{err, syscall.ECONNREFUSED}
+	case strings.Contains(msg, "timed out"):
+		return jumpTargetError{err, syscall.ETIMEDOUT}
+	case strings.Contains(msg, "no route"), strings.Contains(msg, "unreachable"):
+		return jumpTargetError{err, syscall.EHOSTUNREACH}
+	}
+	return jumpTargetError{err: err}
+}
+
+// openJump sets up the SSH tunnel to the jump host of Options.Jump, unless
+// it's already up, within the timeout of the probes. The time it took is
+// reported with the next successful probe.
+func (tcpStats *stats) openJump(ctx context.Context, dialer net.Dialer) error {
+	jump := tcpStats.userInput.Jump
+	if jump == nil || tcpStats.jumpClient != nil {
+		return nil
+	}
+
+	start := time.Now()
+	if dialer.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, dialer.Timeout)
+		defer cancel()
+	}
+
+	var conn net.Conn
+	err := tcpStats.inNetns(func() (err error) {
+		conn, err = dialer.DialContext(ctx, "tcp", jump.Addr)
+		return err
+	})
+	if err != nil {
+		return proxyError{err: err}
+	}
+
+	// the handshake and the authentication stop with the probe
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+	stop := context.AfterFunc(ctx, func() {
+		conn.SetDeadline(time.Now())
+	})
+	defer stop()
+
+	c, chans, reqs, err := ssh.NewClientConn(conn, jump.Addr, jump.Config)
+	if err != nil {
+		conn.Close()
+		return proxyError{err: err, auth: strings.Contains(err.Error(), "unable to authenticate")}
+	}
+	client := ssh.NewClient(c, chans, reqs)
+
+	if !stop() {
+		client.Close()
+		return ctx.Err()
+	}
+	conn.SetDeadline(time.Time{})
+
+	tcpStats.jumpClient = client
+	tcpStats.tunnelSetup = nanoToMillisecond(time.Since(start).Nanoseconds())
+	tcpStats.tunnelSetups.add(tcpStats.tunnelSetup)
+	tcpStats.log().Info("set up the SSH tunnel", "jump", jump.Addr, "setup_ms", tcpStats.tunnelSetup)
+
+	return nil
+}
+
+// dialJump connects to the address from the jump host through a
+// direct-tcpip channel of the tunnel. If the tunnel is broken,
+// it's set up again with the next probe.
+func (tcpStats *stats) dialJump(ctx context.Context, timeout time.Duration, address string) (net.Conn, error) {
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	conn, err := tcpStats.jumpClient.DialContext(ctx, "tcp", address)
+	if err == nil {
+		return conn, nil
+	}
+
+	var chanErr *ssh.OpenChannelError
+	switch {
+	case errors.As(err, &chanErr):
+		return nil, newJumpTargetError(chanErr)
+	case ctx.Err() != nil:
+		return nil, err
+	}
+
+	tcpStats.closeJump()
+	return nil, proxyError{err: err}
+}
+
+// closeJump closes the SSH tunnel, if any.
+func (tcpStats *stats) closeJump() {
+	if tcpStats.jumpClient == nil {
+		return
+	}
+
+	tcpStats.jumpClient.Close()
+	tcpStats.jumpClient = nil
+}
+
+// takeTunnelSetup returns the time the SSH tunnel took
+// to set up for the probe, if it did, and resets it.
+func (tcpStats *stats) takeTunnelSetup() float32 {
+	setup := tcpStats.tunnelSetup
+	tcpStats.tunnelSetup = 0
+	return setup
+}
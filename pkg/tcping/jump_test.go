@@ -0,0 +1,175 @@
+package tcping
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/crypto/ssh"
+)
+
+// testSSHServer starts an SSH server taking the password "secret" and
+// opening the direct-tcpip channels like OpenSSH. It returns its address
+// and its host key.
+func testSSHServer(t *testing.T) (string, ssh.PublicKey) {
+	_, priv, err := ed25519.GenerateKey(rand.Reader)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	signer, err := ssh.NewSignerFromKey(priv)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+
+	config := &ssh.ServerConfig{
+		PasswordCallback: func(_ ssh.ConnMetadata, password []byte) (*ssh.Permissions, error) {
+			if string(password) != "secret" {
+				return nil, fmt.Errorf("wrong password")
+			}
+			return nil, nil
+		},
+	}
+	config.AddHostKey(signer)
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	t.Cleanup(func() { ln.Close() })
+
+	serve := func(conn net.Conn) {
+		_, chans, reqs, err := ssh.NewServerConn(conn, config)
+		if err != nil {
+			return
+		}
+		go ssh.DiscardRequests(reqs)
+
+		for newChan := range chans {
+			var req struct {
+				Host       string
+				Port       uint32
+				OriginHost string
+				OriginPort uint32
+			}
+			if newChan.ChannelType() != "direct-tcpip" || ssh.Unmarshal(newChan.ExtraData(), &req) != nil {
+				newChan.Reject(ssh.UnknownChannelType, "unknown channel type")
+				continue
+			}
+
+			target, err := net.Dial("tcp", net.JoinHostPort(req.Host, fmt.Sprint(req.Port)))
+			if err != nil {
+				newChan.Reject(ssh.ConnectionFailed, "connect failed (Connection refused)")
+				continue
+			}
+
+			ch, reqs, err := newChan.Accept()
+			if err != nil {
+				target.Close()
+				continue
+			}
+			go ssh.DiscardRequests(reqs)
+			go func() {
+				defer ch.Close()
+				defer target.Close()
+				go io.Copy(target, ch)
+				io.Copy(ch, target)
+			}()
+		}
+	}
+
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go serve(conn)
+		}
+	}()
+
+	return ln.Addr().String(), signer.PublicKey()
+}
+
+func TestJump(t *testing.T) {
+	srv := testServerListen(t)
+	t.Cleanup(func() {
+		if err := srv.Close(); err != nil {
+			t.Errorf("srv close: %v", err)
+		}
+	})
+
+	addr, hostKey := testSSHServer(t)
+	jump := func(password string) *JumpHost {
+		return &JumpHost{
+			Addr: addr,
+			Config: &ssh.ClientConfig{
+				User:            "tcping",
+				Auth:            []ssh.AuthMethod{ssh.Password(password)},
+				HostKeyCallback: ssh.FixedHostKey(hostKey),
+			},
+		}
+	}
+
+	for _, tt := range []struct {
+		jump   *JumpHost
+		port   uint16
+		reason FailureReason
+	}{
+		{jump("secret"), 12345, ""},
+		{jump("secret"), 1, FailureRefused},
+		{jump("wrong"), 12345, FailureProxyAuth},
+		{&JumpHost{Addr: "127.0.0.1:1", Config: jump("secret").Config}, 12345, FailureProxy},
+	} {
+		var results []Result
+		p, err := New(Options{
+			Printer:               &dummyPrinter{},
+			Hostname:              "127.0.0.1",
+			Port:                  tt.port,
+			IntervalBetweenProbes: 2 * time.Millisecond,
+			Timeout:               time.Second,
+			ProbesBeforeQuit:      2,
+			Jump:                  tt.jump,
+			Hooks: Hooks{
+				OnProbe: func(r Result) { results = append(results, r) },
+			},
+		})
+		assert.NoError(t, err)
+
+		p.Run()
+		p.Shutdown()
+
+		if !assert.Len(t, results, 2) {
+			continue
+		}
+		for _, r := range results {
+			assert.Equal(t, tt.reason == "", r.Success, tt.jump.Addr)
+			assert.Equal(t, tt.reason, r.FailureReason, tt.jump.Addr)
+		}
+
+		// the tunnel is set up once
+		if tt.reason == "" || tt.reason == FailureRefused {
+			assert.Positive(t, results[0].TunnelSetup)
+			assert.Zero(t, results[1].TunnelSetup)
+			assert.Equal(t, results[0].TunnelSetup, p.Statistics().TunnelSetupResults.Max)
+		}
+	}
+}
+
+func TestJumpTargetError(t *testing.T) {
+	for msg, want := range map[string]FailureReason{
+		"connect failed (Connection refused)":      FailureRefused,
+		"connect failed (Connection timed out)":    FailureTimeout,
+		"connect failed (No route to host)":        FailureUnreachable,
+		"administratively prohibited: open failed": FailureOther,
+	} {
+		err := newJumpTargetError(&ssh.OpenChannelError{Reason: ssh.ConnectionFailed, Message: msg})
+		assert.Equal(t, want, classifyFailure(err), msg)
+		assert.True(t, strings.HasSuffix(err.Error(), msg))
+	}
+}
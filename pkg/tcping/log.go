@@ -0,0 +1,25 @@
+package tcping
+
+import (
+	"context"
+	"log/slog"
+)
+
+// discardLogger drops the logs when Options.Logger isn't set.
+var discardLogger = slog.New(discardHandler{})
+
+type discardHandler struct{}
+
+func (discardHandler) Enabled(context.Context, slog.Level) bool  { return false }
+func (discardHandler) Handle(context.Context, slog.Record) error { return nil }
+func (h discardHandler) WithAttrs([]slog.Attr) slog.Handler      { return h }
+func (h discardHandler) WithGroup(string) slog.Handler           { return h }
+
+// log returns the logger of the pinger.
+func (tcpStats *stats) log() *slog.Logger {
+	if logger := tcpStats.userInput.Logger; logger != nil {
+		return logger
+	}
+
+	return discardLogger
+}
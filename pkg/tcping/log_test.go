@@ -0,0 +1,31 @@
+package tcping
+
+import (
+	"bytes"
+	"log/slog"
+	"net/netip"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLogger(t *testing.T) {
+	var logs bytes.Buffer
+	_, err := New(Options{
+		Printer:               &dummyPrinter{},
+		Hostname:              "www.example.test",
+		Port:                  12345,
+		IntervalBetweenProbes: time.Second,
+		UseIPv4:               true,
+		Hosts: map[string][]netip.Addr{
+			"www.example.test": {netip.MustParseAddr("::1"), netip.MustParseAddr("127.0.0.1")},
+		},
+		Logger: slog.New(slog.NewTextHandler(&logs, &slog.HandlerOptions{Level: slog.LevelDebug})),
+	})
+	assert.NoError(t, err)
+
+	assert.Contains(t, logs.String(), `msg="resolving the hostname"`)
+	assert.Contains(t, logs.String(), "static=true")
+	assert.Contains(t, logs.String(), "ip=127.0.0.1 candidates=1 ipv4_only=true")
+}
@@ -0,0 +1,171 @@
+package tcping
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/netip"
+	"strings"
+
+	"github.com/oschwald/maxminddb-golang"
+)
+
+// IPInfo describes who announces an IP and where, see Options.IPLookup.
+type IPInfo struct {
+	// ASN is the number of the autonomous system announcing the IP,
+	// 0 when unknown.
+	ASN uint
+	// Org is the organization of the autonomous system.
+	Org string
+	// Country is the ISO 3166-1 code of the country of the IP, e.g. NL.
+	Country string
+}
+
+// String formats the info like "AS13335 Cloudflare, Inc., US".
+func (info IPInfo) String() string {
+	var parts []string
+	if info.ASN != 0 {
+		parts = append(parts, fmt.Sprintf("AS%d", info.ASN))
+	}
+	if info.Org != "" {
+		parts = append(parts, info.Org)
+	}
+
+	s := strings.Join(parts, " ")
+	if info.Country != "" {
+		if s != "" {
+			s += ", "
+		}
+		s += info.Country
+	}
+
+	if s == "" {
+		return "unknown"
+	}
+
+	return s
+}
+
+// IPLookup finds the info of the probed IP, see [OpenMMDB].
+type IPLookup interface {
+	LookupIP(ip netip.Addr) (IPInfo, error)
+}
+
+// IPInfoPrinter is implemented by the printers that print the
+// info found with Options.IPLookup. The other printers get it
+// with PrintInfo instead.
+type IPInfoPrinter interface {
+	// PrintIPInfo is called after PrintStart and
+	// whenever the probed IP changes.
+	PrintIPInfo(ip netip.Addr, info IPInfo)
+}
+
+// MMDB looks up the IPs in offline MaxMind DB files, e.g. GeoLite2-ASN
+// and GeoLite2-Country. The fields found in every file are merged.
+type MMDB struct {
+	readers []*maxminddb.Reader
+}
+
+// mmdbRecord holds the fields of the MaxMind databases
+// and the ones of the IPinfo databases, their fallback.
+type mmdbRecord struct {
+	ASN     uint   `maxminddb:"autonomous_system_number"`
+	Org     string `maxminddb:"autonomous_system_organization"`
+	Country struct {
+		ISOCode string `maxminddb:"iso_code"`
+	} `maxminddb:"country"`
+
+	IPinfoASN     string `maxminddb:"asn"`
+	IPinfoOrg     string `maxminddb:"as_name"`
+	IPinfoCountry string `maxminddb:"country_code"`
+}
+
+// OpenMMDB opens the MaxMind DB files at the paths.
+// The MMDB should be closed once it isn't used anymore.
+func OpenMMDB(paths ...string) (*MMDB, error) {
+	if len(paths) == 0 {
+		return nil, errors.New("no MMDB file to open")
+	}
+
+	db := &MMDB{}
+	for _, path := range paths {
+		r, err := maxminddb.Open(path)
+		if err != nil {
+			db.Close()
+			return nil, fmt.Errorf("unable to open %s: %w", path, err)
+		}
+		db.readers = append(db.readers, r)
+	}
+
+	return db, nil
+}
+
+// LookupIP merges the records of the IP found in the files.
+// An IP that's in none of them has an empty info.
+func (db *MMDB) LookupIP(ip netip.Addr) (IPInfo, error) {
+	var info IPInfo
+	for _, r := range db.readers {
+		var record mmdbRecord
+		if err := r.Lookup(net.IP(ip.Unmap().AsSlice()), &record); err != nil {
+			return info, err
+		}
+
+		if info.ASN == 0 {
+			info.ASN = record.ASN
+			if info.ASN == 0 {
+				fmt.Sscanf(record.IPinfoASN, "AS%d", &info.ASN)
+			}
+		}
+
+		if info.Org == "" {
+			info.Org = record.Org
+			if info.Org == "" {
+				info.Org = record.IPinfoOrg
+			}
+		}
+
+		if info.Country == "" {
+			info.Country = record.Country.ISOCode
+			if info.Country == "" {
+				info.Country = record.IPinfoCountry
+			}
+		}
+	}
+
+	return info, nil
+}
+
+// Close closes the files.
+func (db *MMDB) Close() error {
+	var errs []error
+	for _, r := range db.readers {
+		errs = append(errs, r.Close())
+	}
+	db.readers = nil
+
+	return errors.Join(errs...)
+}
+
+// lookupIP prints the info of the probed IP when it changed.
+func (tcpStats *stats) lookupIP() {
+	ip := tcpStats.userInput.ip
+	if ip == tcpStats.lookedUpIP {
+		return
+	}
+	tcpStats.lookedUpIP = ip
+
+	info, err := tcpStats.userInput.IPLookup.LookupIP(ip)
+	if err != nil {
+		tcpStats.printer.PrintError("Unable to look up %s: %s", ip, err)
+		return
+	}
+
+	tcpStats.print(func(printer Printer) {
+		if p, ok := printer.(IPInfoPrinter); ok {
+			p.PrintIPInfo(ip, info)
+			return
+		}
+
+		printer.PrintInfo("%s belongs to %s", ip, info)
+	})
+}
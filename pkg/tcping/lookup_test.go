@@ -0,0 +1,59 @@
+package tcping
+
+import (
+	"net/netip"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIPInfoString(t *testing.T) {
+	tests := []struct {
+		info IPInfo
+		want string
+	}{
+		{IPInfo{ASN: 13335, Org: "Cloudflare, Inc.", Country: "US"}, "AS13335 Cloudflare, Inc., US"},
+		{IPInfo{ASN: 64500}, "AS64500"},
+		{IPInfo{Country: "NL"}, "NL"},
+		{IPInfo{}, "unknown"},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, tt.info.String())
+	}
+}
+
+func TestOpenMMDB(t *testing.T) {
+	_, err := OpenMMDB()
+	assert.Error(t, err)
+
+	_, err = OpenMMDB("testdata/missing.mmdb")
+	assert.Error(t, err)
+}
+
+// countingLookup counts the lookups of every IP.
+type countingLookup map[netip.Addr]int
+
+func (l countingLookup) LookupIP(ip netip.Addr) (IPInfo, error) {
+	l[ip]++
+	return IPInfo{ASN: 64500, Country: "NL"}, nil
+}
+
+func TestLookupIPOnce(t *testing.T) {
+	lookup := countingLookup{}
+	p, err := New(Options{
+		Printer:               &dummyPrinter{},
+		Hostname:              "127.0.0.1",
+		Port:                  12345,
+		IntervalBetweenProbes: 2 * time.Millisecond,
+		Timeout:               time.Millisecond,
+		ProbesBeforeQuit:      3,
+		IPLookup:              lookup,
+	})
+	assert.NoError(t, err)
+
+	p.Run()
+
+	assert.Equal(t, countingLookup{netip.MustParseAddr("127.0.0.1"): 1}, lookup)
+}
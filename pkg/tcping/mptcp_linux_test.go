@@ -0,0 +1,86 @@
+package tcping
+
+import (
+	"context"
+	"net"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// runMPTCPProbes runs the probes requesting MPTCP against port 12345.
+func runMPTCPProbes(t *testing.T) ([]Result, Statistics) {
+	var results []Result
+	p, err := New(Options{
+		Printer:               &dummyPrinter{},
+		Hostname:              "127.0.0.1",
+		Port:                  12345,
+		IntervalBetweenProbes: 2 * time.Millisecond,
+		Timeout:               time.Second,
+		ProbesBeforeQuit:      2,
+		MPTCP:                 true,
+		Hooks: Hooks{
+			OnProbe: func(r Result) { results = append(results, r) },
+		},
+	})
+	assert.NoError(t, err)
+
+	p.Run()
+
+	return results, p.Statistics()
+}
+
+func TestMPTCPFallback(t *testing.T) {
+	srv := testServerListen(t)
+	t.Cleanup(func() { srv.Close() })
+
+	results, s := runMPTCPProbes(t)
+
+	if assert.Len(t, results, 2) {
+		for _, r := range results {
+			assert.True(t, r.Success)
+			assert.False(t, r.MPTCP)
+		}
+	}
+
+	assert.Equal(t, uint(0), s.MPTCPProbes)
+}
+
+func TestMPTCPNegotiated(t *testing.T) {
+	enabled, err := os.ReadFile("/proc/sys/net/mptcp/enabled")
+	if err != nil || strings.TrimSpace(string(enabled)) != "1" {
+		t.Skip("MPTCP is not enabled")
+	}
+
+	var lc net.ListenConfig
+	lc.SetMultipathTCP(true)
+	srv, err := lc.Listen(context.Background(), "tcp", "127.0.0.1:12345")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	t.Cleanup(func() { srv.Close() })
+
+	go func() {
+		for {
+			c, err := srv.Accept()
+			if err != nil {
+				return
+			}
+
+			c.Close()
+		}
+	}()
+
+	results, s := runMPTCPProbes(t)
+
+	if assert.Len(t, results, 2) {
+		for _, r := range results {
+			assert.True(t, r.MPTCP)
+		}
+	}
+
+	assert.Equal(t, uint(2), s.MPTCPProbes)
+}
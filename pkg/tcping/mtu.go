@@ -0,0 +1,34 @@
+package tcping
+
+// checkPathMTU probes the path MTU to the target once, after the first
+// successful connection, and reports it. A path MTU below the one of the
+// route that the routers never reported means that the larger packets
+// are silently dropped, i.e. path MTU discovery is blackholed.
+func (tcpStats *stats) checkPathMTU() {
+	tcpStats.mtuProbed = true
+	ip := tcpStats.userInput.ip
+
+	timeout := tcpStats.userInput.Timeout
+	if timeout <= 0 {
+		timeout = tcpStats.userInput.IntervalBetweenProbes
+	}
+
+	pathMTU, upper, err := probePathMTU(ip, timeout)
+	if err != nil {
+		tcpStats.printer.PrintError("Unable to probe the path MTU to %s: %s", ip, err)
+		return
+	}
+	tcpStats.pathMTU = pathMTU
+
+	if pathMTU >= upper {
+		tcpStats.printer.PrintInfo("Path MTU to %s is %d bytes", ip, pathMTU)
+		return
+	}
+
+	tcpStats.printer.PrintInfo("Path MTU to %s is %d bytes, below the MTU of %d bytes of the route", ip, pathMTU, upper)
+
+	// the kernel lowers the MTU of the route when the routers report it
+	if current, err := routeMTU(ip.Unmap()); err == nil && current > pathMTU {
+		tcpStats.printer.PrintError("Packets larger than %d bytes to %s are dropped without ICMP errors, path MTU discovery is blackholed", pathMTU, ip)
+	}
+}
@@ -0,0 +1,212 @@
+//go:build linux
+
+package tcping
+
+import (
+	"errors"
+	"net"
+	"net/netip"
+	"os"
+	"syscall"
+	"time"
+
+	"golang.org/x/net/icmp"
+	"golang.org/x/net/ipv4"
+	"golang.org/x/net/ipv6"
+	"golang.org/x/sys/unix"
+)
+
+// mtuSupported reports whether the path MTU can be probed on this platform.
+const mtuSupported = true
+
+// routeMTU returns the MTU the kernel knows for the route to the IP,
+// lowered by the ICMP errors of the routers asking for fragmentation.
+func routeMTU(ip netip.Addr) (int, error) {
+	conn, err := net.DialUDP("udp", nil, net.UDPAddrFromAddrPort(netip.AddrPortFrom(ip, 9)))
+	if err != nil {
+		return 0, err
+	}
+	defer conn.Close()
+
+	raw, err := conn.SyscallConn()
+	if err != nil {
+		return 0, err
+	}
+
+	var mtu int
+	var sockErr error
+	err = raw.Control(func(fd uintptr) {
+		if ip.Is6() {
+			mtu, sockErr = unix.GetsockoptInt(int(fd), unix.IPPROTO_IPV6, unix.IPV6_MTU)
+		} else {
+			mtu, sockErr = unix.GetsockoptInt(int(fd), unix.IPPROTO_IP, unix.IP_MTU)
+		}
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return mtu, sockErr
+}
+
+// dfPinger sends ICMP echoes that mustn't be fragmented.
+type dfPinger struct {
+	conn    net.PacketConn
+	raw     bool
+	ip      netip.Addr
+	timeout time.Duration
+	seq     int
+}
+
+// newDFPinger opens an ICMP socket setting the DF bit and ignoring the
+// MTU known for the route, so that larger packets can still be probed.
+// Unprivileged ICMP sockets are preferred, like with Options.CompareICMP.
+func newDFPinger(ip netip.Addr, timeout time.Duration) (*dfPinger, error) {
+	family, proto := unix.AF_INET, unix.IPPROTO_ICMP
+	if ip.Is6() {
+		family, proto = unix.AF_INET6, unix.IPPROTO_ICMPV6
+	}
+
+	raw := false
+	fd, err := unix.Socket(family, unix.SOCK_DGRAM|unix.SOCK_CLOEXEC, proto)
+	if err != nil {
+		raw = true
+		fd, err = unix.Socket(family, unix.SOCK_RAW|unix.SOCK_CLOEXEC, proto)
+	}
+	if err != nil {
+		return nil, os.NewSyscallError("socket", err)
+	}
+
+	if ip.Is6() {
+		err = unix.SetsockoptInt(fd, unix.IPPROTO_IPV6, unix.IPV6_MTU_DISCOVER, unix.IPV6_PMTUDISC_PROBE)
+		if err == nil {
+			err = unix.SetsockoptInt(fd, unix.IPPROTO_IPV6, unix.IPV6_DONTFRAG, 1)
+		}
+	} else {
+		err = unix.SetsockoptInt(fd, unix.IPPROTO_IP, unix.IP_MTU_DISCOVER, unix.IP_PMTUDISC_PROBE)
+	}
+	if err != nil {
+		unix.Close(fd)
+		return nil, os.NewSyscallError("setsockopt", err)
+	}
+
+	f := os.NewFile(uintptr(fd), "icmp")
+	defer f.Close()
+
+	conn, err := net.FilePacketConn(f)
+	if err != nil {
+		return nil, err
+	}
+
+	return &dfPinger{conn: conn, raw: raw, ip: ip, timeout: timeout}, nil
+}
+
+// ping reports whether an echo of the given size,
+// headers included, was answered within the timeout.
+func (p *dfPinger) ping(size int) (bool, error) {
+	header, proto := ipv4.HeaderLen, protocolICMP
+	var echoType, replyType icmp.Type = ipv4.ICMPTypeEcho, ipv4.ICMPTypeEchoReply
+	if p.ip.Is6() {
+		header, proto = ipv6.HeaderLen, protocolICMPIPv6
+		echoType, replyType = ipv6.ICMPTypeEchoRequest, ipv6.ICMPTypeEchoReply
+	}
+
+	p.seq++
+	id := os.Getpid() & 0xffff
+	msg, err := (&icmp.Message{
+		Type: echoType,
+		Body: &icmp.Echo{ID: id, Seq: p.seq, Data: make([]byte, size-header-8)},
+	}).Marshal(nil)
+	if err != nil {
+		return false, err
+	}
+
+	var dst net.Addr = &net.UDPAddr{IP: p.ip.AsSlice(), Zone: p.ip.Zone()}
+	if p.raw {
+		dst = &net.IPAddr{IP: p.ip.AsSlice(), Zone: p.ip.Zone()}
+	}
+
+	p.conn.SetDeadline(time.Now().Add(p.timeout))
+	if _, err := p.conn.WriteTo(msg, dst); err != nil {
+		// larger than the MTU of the interface
+		if errors.Is(err, syscall.EMSGSIZE) {
+			return false, nil
+		}
+		return false, err
+	}
+
+	buf := make([]byte, size+header)
+	for {
+		n, _, err := p.conn.ReadFrom(buf)
+		if errors.Is(err, os.ErrDeadlineExceeded) {
+			return false, nil
+		}
+		if err != nil {
+			return false, err
+		}
+
+		reply, err := icmp.ParseMessage(proto, buf[:n])
+		if err != nil || reply.Type != replyType {
+			continue
+		}
+
+		echo, ok := reply.Body.(*icmp.Echo)
+		if ok && echo.Seq == p.seq && (!p.raw || echo.ID == id) {
+			return true, nil
+		}
+	}
+}
+
+// probePathMTU estimates the path MTU to the IP by searching the largest
+// ICMP echo that isn't fragmented and still answered, up to the MTU of
+// the route. It returns the MTU of the route as well.
+func probePathMTU(ip netip.Addr, timeout time.Duration) (int, int, error) {
+	ip = ip.Unmap()
+	upper, err := routeMTU(ip)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	// the largest IPv4 packet, e.g. for the loopback interface
+	hi := min(upper, 65535)
+
+	// every link must carry these
+	lo := 576
+	if ip.Is6() {
+		lo = 1280
+	}
+
+	p, err := newDFPinger(ip, timeout)
+	if err != nil {
+		return 0, upper, err
+	}
+	defer p.conn.Close()
+
+	if ok, err := p.ping(lo); err != nil || !ok {
+		if err == nil {
+			err = errors.New("the target doesn't answer ICMP echoes")
+		}
+		return 0, upper, err
+	}
+
+	if ok, err := p.ping(hi); err != nil || ok {
+		return hi, upper, err
+	}
+
+	// lo is answered and hi isn't
+	for hi-lo > 1 {
+		mid := (lo + hi) / 2
+		ok, err := p.ping(mid)
+		if err != nil {
+			return 0, upper, err
+		}
+
+		if ok {
+			lo = mid
+		} else {
+			hi = mid
+		}
+	}
+
+	return lo, upper, nil
+}
@@ -0,0 +1,28 @@
+//go:build linux
+
+package tcping
+
+import (
+	"net/netip"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProbePathMTU(t *testing.T) {
+	ip := netip.MustParseAddr("127.0.0.1")
+	p, err := newDFPinger(ip, time.Second)
+	if err != nil {
+		t.Skipf("ICMP sockets aren't allowed: %v", err)
+	}
+	p.conn.Close()
+
+	upper, err := routeMTU(ip)
+	assert.NoError(t, err)
+
+	pathMTU, routeMTU, err := probePathMTU(ip, time.Second)
+	assert.NoError(t, err)
+	assert.Equal(t, upper, routeMTU)
+	assert.Equal(t, min(upper, 65535), pathMTU)
+}
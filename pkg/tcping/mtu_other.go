@@ -0,0 +1,20 @@
+//go:build !linux
+
+package tcping
+
+import (
+	"errors"
+	"net/netip"
+	"time"
+)
+
+// mtuSupported reports whether the path MTU can be probed on this platform.
+const mtuSupported = false
+
+func probePathMTU(_ netip.Addr, _ time.Duration) (int, int, error) {
+	return 0, 0, errors.New("probing the path MTU is only supported on Linux")
+}
+
+func routeMTU(_ netip.Addr) (int, error) {
+	return 0, errors.New("probing the path MTU is only supported on Linux")
+}
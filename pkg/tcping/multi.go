@@ -0,0 +1,86 @@
+package tcping
+
+import "slices"
+
+// multiPrinter is the printer returned by [NewMultiPrinter].
+type multiPrinter []Printer
+
+// NewMultiPrinter returns a printer that prints every message with
+// all the given printers in turn, e.g. to print to the console and
+// to save the statistics to a database at the same time.
+//
+// Every printer gets the calls of the optional printer interfaces,
+// e.g. [ResolvePrinter], that it implements, and their fallback
+// to the methods of [Printer] otherwise.
+func NewMultiPrinter(printers ...Printer) Printer {
+	if len(printers) == 1 {
+		return printers[0]
+	}
+
+	return multiPrinter(slices.Clone(printers))
+}
+
+// eachPrinter calls fn with every printer of p if it's
+// a [NewMultiPrinter] printer, and with p otherwise.
+func eachPrinter(p Printer, fn func(Printer)) {
+	m, ok := p.(multiPrinter)
+	if !ok {
+		fn(p)
+		return
+	}
+
+	for _, p := range m {
+		eachPrinter(p, fn)
+	}
+}
+
+func (m multiPrinter) PrintStart(hostname string, port uint16) {
+	for _, p := range m {
+		p.PrintStart(hostname, port)
+	}
+}
+
+func (m multiPrinter) PrintProbe(r Result) {
+	for _, p := range m {
+		p.PrintProbe(r)
+	}
+}
+
+func (m multiPrinter) PrintStateChange(c StateChange) {
+	for _, p := range m {
+		p.PrintStateChange(c)
+	}
+}
+
+func (m multiPrinter) PrintRetryingToResolve(hostname string) {
+	for _, p := range m {
+		p.PrintRetryingToResolve(hostname)
+	}
+}
+
+func (m multiPrinter) PrintStatistics(s Statistics) {
+	for _, p := range m {
+		p.PrintStatistics(s)
+	}
+}
+
+func (m multiPrinter) PrintVersion() {
+	for _, p := range m {
+		p.PrintVersion()
+	}
+}
+
+func (m multiPrinter) PrintInfo(format string, args ...any) {
+	for _, p := range m {
+		p.PrintInfo(format, args...)
+	}
+}
+
+// PrintError prints the error with every printer.
+// As the database printer exits after printing an error,
+// it should be the last one.
+func (m multiPrinter) PrintError(format string, args ...any) {
+	for _, p := range m {
+		p.PrintError(format, args...)
+	}
+}
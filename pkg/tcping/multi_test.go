@@ -0,0 +1,58 @@
+package tcping
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// infoPrinter only implements the methods of [Printer],
+// and records the probes and the info messages.
+type infoPrinter struct {
+	dummyPrinter
+	lines []string
+}
+
+func (p *infoPrinter) PrintProbe(r Result) {
+	p.lines = append(p.lines, fmt.Sprintf("reply from %s time=%.3f", r.IP, r.RTT))
+}
+
+func (p *infoPrinter) PrintInfo(format string, args ...any) {
+	p.lines = append(p.lines, fmt.Sprintf(format, args...))
+}
+
+func TestMultiPrinter(t *testing.T) {
+	var journal, json strings.Builder
+	info := &infoPrinter{}
+
+	stats := createTestStats(t)
+	stats.printer = NewMultiPrinter(&journalPrinter{w: &journal}, newJSONPrinter(&json, false), info)
+	stats.userInput.RTTThreshold = 150 * time.Millisecond
+
+	stats.handleConnSuccess(200, time.Now())
+	stats.printer.PrintInfo("done")
+
+	assert.Equal(t, "<4>Degraded reply from 127.0.0.1 on port 12345 seq=1 TCP_conn=1 time=200.000 ms\n<6>done\n", journal.String())
+	assert.Contains(t, json.String(), `"type":"probe"`)
+	assert.Contains(t, json.String(), `"seq":1`)
+	assert.Contains(t, json.String(), `"message":"done"`)
+
+	assert.Equal(t, []string{
+		"reply from 127.0.0.1 time=200.000",
+		"done",
+	}, info.lines)
+}
+
+func TestNewMultiPrinter(t *testing.T) {
+	p := &dummyPrinter{}
+	assert.Same(t, p, NewMultiPrinter(p))
+
+	var visited []Printer
+	eachPrinter(NewMultiPrinter(p, NewMultiPrinter(p, p)), func(p Printer) {
+		visited = append(visited, p)
+	})
+	assert.Equal(t, []Printer{p, p, p}, visited)
+}
@@ -0,0 +1,62 @@
+//go:build linux
+
+package tcping
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"golang.org/x/sys/unix"
+)
+
+// netnsSupported reports whether the probes can be sent
+// from another network namespace on this platform.
+const netnsSupported = true
+
+// openNetns opens the network namespace named like with `ip netns`,
+// or given by its path, e.g. /proc/PID/ns/net.
+func openNetns(netns string) (*os.File, error) {
+	path := netns
+	if !strings.ContainsRune(netns, '/') {
+		path = filepath.Join("/var/run/netns", netns)
+	}
+
+	return os.Open(path)
+}
+
+// inNetns calls fn from within the network namespace, so that the sockets
+// it creates belong to it. fn runs on its own goroutine, locked to a thread
+// that enters the namespace, as the namespace is a property of the thread.
+func inNetns(netns *os.File, fn func() error) error {
+	done := make(chan error, 1)
+	go func() {
+		runtime.LockOSThread()
+
+		host, err := os.Open("/proc/thread-self/ns/net")
+		if err != nil {
+			runtime.UnlockOSThread()
+			done <- err
+			return
+		}
+		defer host.Close()
+
+		if err := unix.Setns(int(netns.Fd()), unix.CLONE_NEWNET); err != nil {
+			runtime.UnlockOSThread()
+			done <- err
+			return
+		}
+
+		err = fn()
+
+		// a thread that can't go back is left locked, so that
+		// it's terminated along with the goroutine
+		if unix.Setns(int(host.Fd()), unix.CLONE_NEWNET) == nil {
+			runtime.UnlockOSThread()
+		}
+		done <- err
+	}()
+
+	return <-done
+}
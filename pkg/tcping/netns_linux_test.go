@@ -0,0 +1,95 @@
+package tcping
+
+import (
+	"errors"
+	"net"
+	"os"
+	"runtime"
+	"syscall"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/sys/unix"
+)
+
+// newTestNetns creates an empty network namespace, or skips
+// the test without the privileges to create it.
+func newTestNetns(t *testing.T) *os.File {
+	type result struct {
+		f   *os.File
+		err error
+	}
+
+	created := make(chan result, 1)
+	go func() {
+		// the thread is never unlocked, so that it's
+		// terminated with the goroutine, in the new namespace
+		runtime.LockOSThread()
+		if err := unix.Unshare(unix.CLONE_NEWNET); err != nil {
+			created <- result{err: err}
+			return
+		}
+		f, err := os.Open("/proc/thread-self/ns/net")
+		created <- result{f, err}
+	}()
+
+	r := <-created
+	if errors.Is(r.err, syscall.EPERM) {
+		t.Skip("creating a network namespace needs CAP_SYS_ADMIN")
+	}
+	if r.err != nil {
+		t.Fatalf("new netns: %v", r.err)
+	}
+	t.Cleanup(func() { r.f.Close() })
+
+	return r.f
+}
+
+// inode returns the inode of the file, which identifies a namespace.
+func inode(t *testing.T, path string) uint64 {
+	var st unix.Stat_t
+	if err := unix.Stat(path, &st); err != nil {
+		t.Fatalf("stat: %v", err)
+	}
+	return st.Ino
+}
+
+func TestInNetns(t *testing.T) {
+	netns := newTestNetns(t)
+
+	srv := testServerListen(t)
+	t.Cleanup(func() { srv.Close() })
+
+	var st unix.Stat_t
+	if err := unix.Fstat(int(netns.Fd()), &st); err != nil {
+		t.Fatalf("fstat: %v", err)
+	}
+
+	var inside uint64
+	err := inNetns(netns, func() error {
+		inside = inode(t, "/proc/thread-self/ns/net")
+
+		// the loopback interface of the new namespace is down
+		_, err := net.Dial("tcp", "127.0.0.1:12345")
+		return err
+	})
+	assert.Error(t, err)
+	assert.Equal(t, st.Ino, inside)
+
+	// the host's namespace is unchanged
+	conn, err := net.Dial("tcp", "127.0.0.1:12345")
+	if assert.NoError(t, err) {
+		conn.Close()
+	}
+}
+
+func TestOpenNetns(t *testing.T) {
+	f, err := openNetns("/proc/self/ns/net")
+	if assert.NoError(t, err) {
+		f.Close()
+	}
+
+	_, err = openNetns("nonexistent")
+	assert.ErrorIs(t, err, os.ErrNotExist)
+	assert.ErrorContains(t, err, "/var/run/netns/nonexistent")
+}
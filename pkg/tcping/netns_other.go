@@ -0,0 +1,20 @@
+//go:build !linux
+
+package tcping
+
+import (
+	"errors"
+	"os"
+)
+
+// netnsSupported reports whether the probes can be sent
+// from another network namespace on this platform.
+const netnsSupported = false
+
+func openNetns(_ string) (*os.File, error) {
+	return nil, errors.New("network namespaces are only supported on Linux")
+}
+
+func inNetns(_ *os.File, fn func() error) error {
+	return fn()
+}
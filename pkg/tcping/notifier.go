@@ -0,0 +1,140 @@
+package tcping
+
+import (
+	"fmt"
+	"os"
+	"time"
+)
+
+// Notifier is a set of methods for notifiers to implement.
+//
+// Notifiers alert the user about the target going down or
+// coming back up through channels other than the printer.
+// They should never block the probes for a noticeable amount of time.
+type Notifier interface {
+	// Notify is called when the target changes its state.
+	Notify(change StateChange)
+}
+
+// ProbeNotifier is implemented by the notifiers that need to know
+// about every probe, e.g. to alert on high latency or long downtimes.
+type ProbeNotifier interface {
+	NotifyProbe(r Result)
+}
+
+// StatisticsNotifier is implemented by the notifiers that
+// should be informed about the final statistics on exit.
+//
+// NotifyStatistics is called synchronously from [Pinger.Shutdown],
+// so it's also a good place to wait for the notifications
+// that are still on their way.
+type StatisticsNotifier interface {
+	NotifyStatistics(s Statistics)
+}
+
+// StateChange holds the information about the target
+// going down or coming back up.
+type StateChange struct {
+	When     time.Time
+	Hostname string
+	IP       string
+	// Downtime is how long the target was unreachable, degraded,
+	// trending up, or answering with outliers.
+	// It is only set when the target came back up.
+	Downtime time.Duration
+	// RTT of the probe that brought the target back up,
+	// or that degraded it, or that made its RTTs trend up or was an outlier.
+	RTT  float32
+	Port uint16
+	Up   bool
+	// Degraded is true if the target crossed Options.RTTThreshold instead
+	// of going down or coming back up, see Options.NotifyDegraded.
+	Degraded bool
+	// Trend is true if the RTTs of the target started or stopped trending
+	// up instead, see Options.NotifyTrend.
+	Trend bool
+	// Outlier is true if the probes started or stopped being outliers
+	// instead, see Options.NotifyOutliers.
+	Outlier bool
+}
+
+// Target returns a human-readable representation of the probed target.
+func (c StateChange) Target() string {
+	if c.Hostname == "" || c.Hostname == c.IP {
+		return fmt.Sprintf("%s on port %d", c.IP, c.Port)
+	}
+
+	return fmt.Sprintf("%s (%s) on port %d", c.Hostname, c.IP, c.Port)
+}
+
+// Summary returns a short human-readable description of the state change.
+func (c StateChange) Summary() string {
+	if c.Outlier {
+		if c.Up {
+			return fmt.Sprintf("%s is back to its usual RTTs after %s of outliers", c.Target(), DurationToString(c.Downtime))
+		}
+
+		return fmt.Sprintf("%s has outlier RTTs, a probe took %.3f ms", c.Target(), c.RTT)
+	}
+
+	if c.Trend {
+		if c.Up {
+			return fmt.Sprintf("%s is no longer trending up after %s", c.Target(), DurationToString(c.Downtime))
+		}
+
+		return fmt.Sprintf("%s is trending up, a probe took %.3f ms", c.Target(), c.RTT)
+	}
+
+	if c.Degraded {
+		if c.Up {
+			return fmt.Sprintf("%s is responsive again after %s of degradation", c.Target(), DurationToString(c.Downtime))
+		}
+
+		return fmt.Sprintf("%s is degraded, a probe took %.3f ms", c.Target(), c.RTT)
+	}
+
+	if c.Up {
+		return fmt.Sprintf("%s is reachable again after %s of downtime", c.Target(), DurationToString(c.Downtime))
+	}
+
+	return fmt.Sprintf("%s is not responding", c.Target())
+}
+
+// notifyStateChange passes the state change to the printer, the hook and all registered notifiers.
+func (tcpStats *stats) notifyStateChange(change StateChange) {
+	tcpStats.printStateChange(change)
+
+	if hook := tcpStats.userInput.Hooks.OnStateChange; hook != nil {
+		hook(change)
+	}
+
+	for _, n := range tcpStats.notifiers {
+		n.Notify(change)
+	}
+}
+
+// notifyProbe passes the probe to the notifiers interested in it.
+func (tcpStats *stats) notifyProbe(r Result) {
+	for _, n := range tcpStats.notifiers {
+		if pn, ok := n.(ProbeNotifier); ok {
+			pn.NotifyProbe(r)
+		}
+	}
+}
+
+// events that can ring the terminal bell, as accepted by Options.Bell.
+const (
+	BellOnFail    = "fail"
+	BellOnSuccess = "success"
+	BellOnChange  = "change"
+)
+
+// ringBell rings the terminal bell if the user asked for it on the given event.
+//
+// The bell is written to the stderr, so that it doesn't
+// end up in the middle of the JSON output.
+func (tcpStats *stats) ringBell(event string) {
+	if tcpStats.userInput.Bell == event {
+		fmt.Fprint(os.Stderr, "\a")
+	}
+}
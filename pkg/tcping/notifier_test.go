@@ -0,0 +1,133 @@
+package tcping
+
+import (
+	"io"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStateChangeSummary(t *testing.T) {
+	tests := []struct {
+		name   string
+		change StateChange
+		want   string
+	}{
+		{
+			name:   "hostname down",
+			change: StateChange{Hostname: "example.com", IP: "93.184.216.34", Port: 443},
+			want:   "example.com (93.184.216.34) on port 443 is not responding",
+		},
+		{
+			name:   "ip down",
+			change: StateChange{Hostname: "127.0.0.1", IP: "127.0.0.1", Port: 80},
+			want:   "127.0.0.1 on port 80 is not responding",
+		},
+		{
+			name: "hostname up",
+			change: StateChange{
+				Hostname: "example.com",
+				IP:       "93.184.216.34",
+				Port:     443,
+				Downtime: time.Minute + 5*time.Second,
+				Up:       true,
+			},
+			want: "example.com (93.184.216.34) on port 443 is reachable again after 1 minute 5 seconds of downtime",
+		},
+		{
+			name:   "degraded",
+			change: StateChange{IP: "127.0.0.1", Port: 80, RTT: 200, Degraded: true},
+			want:   "127.0.0.1 on port 80 is degraded, a probe took 200.000 ms",
+		},
+		{
+			name: "no longer degraded",
+			change: StateChange{
+				IP:       "127.0.0.1",
+				Port:     80,
+				Downtime: 5 * time.Second,
+				Up:       true,
+				Degraded: true,
+			},
+			want: "127.0.0.1 on port 80 is responsive again after 5 seconds of degradation",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.change.Summary())
+		})
+	}
+}
+
+// recordingNotifier keeps the state changes it's notified about.
+type recordingNotifier struct {
+	changes []StateChange
+}
+
+func (n *recordingNotifier) Notify(change StateChange) {
+	n.changes = append(n.changes, change)
+}
+
+func TestNotifyStateChange(t *testing.T) {
+	stats := createTestStats(t)
+	stats.userInput.Hostname = "localhost"
+	n := &recordingNotifier{}
+	stats.notifiers = []Notifier{n}
+
+	start := time.Now()
+	stats.handleConnSuccess(10, start)
+	stats.handleConnError(start.Add(time.Second), nil)
+	stats.handleConnError(start.Add(2*time.Second), nil)
+	stats.handleConnSuccess(12, start.Add(3*time.Second))
+	stats.handleConnSuccess(11, start.Add(4*time.Second))
+
+	if assert.Len(t, n.changes, 2) {
+		assert.False(t, n.changes[0].Up)
+		assert.Equal(t, start.Add(time.Second), n.changes[0].When)
+		assert.Equal(t, "localhost", n.changes[0].Hostname)
+		assert.Equal(t, "127.0.0.1", n.changes[0].IP)
+		assert.Equal(t, uint16(12345), n.changes[0].Port)
+
+		assert.True(t, n.changes[1].Up)
+		assert.Equal(t, 2*time.Second, n.changes[1].Downtime)
+		assert.Equal(t, float32(12), n.changes[1].RTT)
+	}
+}
+
+func TestRingBell(t *testing.T) {
+	stderr := os.Stderr
+	r, w, err := os.Pipe()
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	os.Stderr = w
+	t.Cleanup(func() { os.Stderr = stderr })
+
+	stats := createTestStats(t)
+	stats.userInput.Bell = BellOnChange
+
+	start := time.Now()
+	stats.handleConnSuccess(10, start)
+	stats.handleConnError(start.Add(time.Second), nil)
+	stats.handleConnError(start.Add(2*time.Second), nil)
+	stats.handleConnSuccess(12, start.Add(3*time.Second))
+
+	// rung once when going down and once when coming back up
+	stats.userInput.Bell = BellOnFail
+	stats.handleConnError(start.Add(4*time.Second), nil)
+	stats.handleConnError(start.Add(5*time.Second), nil)
+	stats.handleConnSuccess(12, start.Add(6*time.Second))
+
+	// rung on each of the two failures
+	stats.userInput.Bell = ""
+	stats.handleConnError(start.Add(7*time.Second), nil)
+
+	w.Close()
+	out, err := io.ReadAll(r)
+	assert.NoError(t, err)
+	assert.Equal(t, "\a\a\a\a", string(out))
+
+	_, err = New(Options{Hostname: "127.0.0.1", Port: 12345, Bell: "always"})
+	assert.Error(t, err)
+}
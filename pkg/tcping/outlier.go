@@ -0,0 +1,76 @@
+package tcping
+
+import (
+	"math"
+	"time"
+)
+
+// outlierBaseline returns the mean and the standard deviation of the RTTs
+// the next one is compared to, the last Options.OutlierWindow ones or all
+// the previous ones, and whether there are enough of them.
+func (tcpStats *stats) outlierBaseline() (float64, float64, bool) {
+	if tcpStats.userInput.OutlierWindow == 0 {
+		mean, stdDev := tcpStats.rtt.mean()
+		return mean, stdDev, tcpStats.rtt.count >= minOutlierSamples
+	}
+
+	rtts := tcpStats.outlierRtts
+	if len(rtts) < minOutlierSamples {
+		return 0, 0, false
+	}
+
+	var sum, squaresSum float64
+	for _, rtt := range rtts {
+		sum += float64(rtt)
+		squaresSum += float64(rtt) * float64(rtt)
+	}
+	n := float64(len(rtts))
+	mean := sum / n
+
+	return mean, math.Sqrt(max(squaresSum/n-mean*mean, 0)), true
+}
+
+// addOutlierRtt slides the window of Options.OutlierWindow over the RTT.
+func (tcpStats *stats) addOutlierRtt(rtt float32) {
+	window := int(tcpStats.userInput.OutlierWindow)
+	if window == 0 {
+		return
+	}
+
+	tcpStats.outlierRtts = append(tcpStats.outlierRtts, rtt)
+	if len(tcpStats.outlierRtts) > window {
+		tcpStats.outlierRtts = tcpStats.outlierRtts[1:]
+	}
+}
+
+// recordOutlier notifies, with Options.NotifyOutliers, about the first
+// outlier of a row and about the next probe that isn't one, like when
+// the target goes down and up.
+func (tcpStats *stats) recordOutlier(outlier bool, rtt float32, connTime time.Time) {
+	if outlier == tcpStats.outlying {
+		return
+	}
+
+	change := StateChange{
+		When:     connTime,
+		Hostname: tcpStats.userInput.Hostname,
+		IP:       tcpStats.userInput.ip.String(),
+		Port:     tcpStats.userInput.Port,
+		RTT:      rtt,
+		Up:       !outlier,
+		Outlier:  true,
+	}
+
+	if outlier {
+		tcpStats.startOfOutliers = connTime
+	} else {
+		change.Downtime = connTime.Sub(tcpStats.startOfOutliers)
+		tcpStats.startOfOutliers = time.Time{}
+	}
+	tcpStats.outlying = outlier
+
+	if tcpStats.userInput.NotifyOutliers {
+		tcpStats.notifyStateChange(change)
+		tcpStats.ringBell(BellOnChange)
+	}
+}
@@ -0,0 +1,91 @@
+package tcping
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOutlierWindow(t *testing.T) {
+	stats := createTestStats(t)
+	stats.userInput.OutlierStdDevs = 3
+	stats.userInput.OutlierWindow = minOutlierSamples
+
+	// the path got slower, the old RTTs don't count anymore
+	for i := 0; i < minOutlierSamples; i++ {
+		stats.recordRtt(float32(10 + i%2))
+	}
+	for i := 0; i < minOutlierSamples; i++ {
+		stats.recordRtt(float32(100 + i%2))
+	}
+
+	assert.Len(t, stats.outlierRtts, minOutlierSamples)
+	assert.False(t, stats.recordRtt(101))
+	assert.True(t, stats.recordRtt(150))
+
+	// against all the previous RTTs, the outlier would go unnoticed
+	stats.userInput.OutlierWindow = 0
+	assert.False(t, stats.recordRtt(150))
+}
+
+func TestNotifyOutliers(t *testing.T) {
+	stats := createTestStats(t)
+	stats.userInput.OutlierStdDevs = 3
+	stats.userInput.NotifyOutliers = true
+
+	var results []Result
+	var changes []StateChange
+	stats.userInput.Hooks.OnProbe = func(r Result) { results = append(results, r) }
+	stats.userInput.Hooks.OnStateChange = func(change StateChange) { changes = append(changes, change) }
+
+	start := time.Now()
+	rtts := []float32{10, 11, 10, 11, 10, 11, 10, 11, 10, 11, 300, 400, 11}
+	for i, rtt := range rtts {
+		stats.handleConnSuccess(rtt, start.Add(time.Duration(i)*time.Second))
+	}
+
+	if assert.Len(t, results, len(rtts)) {
+		assert.True(t, results[10].Outlier)
+		assert.True(t, results[11].Outlier)
+		assert.False(t, results[12].Outlier)
+	}
+	if assert.Len(t, changes, 2) {
+		assert.True(t, changes[0].Outlier)
+		assert.False(t, changes[0].Up)
+		assert.Equal(t, float32(300), changes[0].RTT)
+		assert.True(t, changes[1].Outlier)
+		assert.True(t, changes[1].Up)
+		assert.Equal(t, 2*time.Second, changes[1].Downtime)
+	}
+	assert.Equal(t, uint(2), stats.statistics().Outliers)
+}
+
+func TestOutliersThenDown(t *testing.T) {
+	stats := createTestStats(t)
+	stats.userInput.OutlierStdDevs = 3
+	stats.userInput.NotifyOutliers = true
+
+	var changes []StateChange
+	stats.userInput.Hooks.OnStateChange = func(change StateChange) { changes = append(changes, change) }
+
+	start := time.Now()
+	rtts := []float32{10, 11, 10, 11, 10, 11, 10, 11, 10, 11, 300}
+	for i, rtt := range rtts {
+		stats.handleConnSuccess(rtt, start.Add(time.Duration(i)*time.Second))
+	}
+	stats.handleConnError(start.Add(time.Duration(len(rtts))*time.Second), nil)
+
+	// the outliers end before the target goes down
+	if assert.Len(t, changes, 3) {
+		assert.True(t, changes[0].Outlier)
+		assert.False(t, changes[0].Up)
+		assert.True(t, changes[1].Outlier)
+		assert.True(t, changes[1].Up)
+		assert.Equal(t, time.Second, changes[1].Downtime)
+		assert.False(t, changes[2].Outlier)
+		assert.False(t, changes[2].Up)
+	}
+	assert.False(t, stats.outlying)
+	assert.True(t, stats.startOfOutliers.IsZero())
+}
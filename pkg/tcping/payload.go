@@ -0,0 +1,88 @@
+package tcping
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"regexp"
+	"time"
+)
+
+const (
+	// maxResponseSize limits how much of a response is matched.
+	maxResponseSize = 4096
+	// maxShownResponse limits how much of a mismatching response is printed.
+	maxShownResponse = 64
+)
+
+// exchangeStep sends a payload, if any, and reads
+// the response until it matches, if anything is expected.
+type exchangeStep struct {
+	send   []byte
+	expect *regexp.Regexp
+}
+
+// exchangeProber runs the steps one after another within the timeout,
+// e.g. to answer the greeting of the server and check the response.
+// It is the [Prober] of Options.Send and Options.Expect.
+type exchangeProber []exchangeStep
+
+func (p exchangeProber) Probe(conn net.Conn, timeout time.Duration) error {
+	if timeout > 0 {
+		conn.SetDeadline(time.Now().Add(timeout))
+	}
+
+	for _, step := range p {
+		if err := step.exchange(conn); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (s exchangeStep) exchange(conn net.Conn) error {
+	if len(s.send) > 0 {
+		if _, err := conn.Write(s.send); err != nil {
+			return fmt.Errorf("unable to send the payload: %w", err)
+		}
+	}
+
+	if s.expect == nil {
+		return nil
+	}
+
+	var response []byte
+	buf := make([]byte, 512)
+	for len(response) < maxResponseSize {
+		n, err := conn.Read(buf)
+		response = append(response, buf[:n]...)
+		if s.expect.Match(response) {
+			return nil
+		}
+
+		if errors.Is(err, io.EOF) || errors.Is(err, os.ErrDeadlineExceeded) {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("unable to read the response: %w", err)
+		}
+	}
+
+	if len(response) == 0 {
+		return fmt.Errorf("no response matching %s", s.expect)
+	}
+
+	return fmt.Errorf("the response %s doesn't match %s", quoteResponse(response), s.expect)
+}
+
+// quoteResponse quotes the beginning of the response for printing.
+func quoteResponse(response []byte) string {
+	if len(response) > maxShownResponse {
+		return fmt.Sprintf("%q...", response[:maxShownResponse])
+	}
+
+	return fmt.Sprintf("%q", response)
+}
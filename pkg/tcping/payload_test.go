@@ -0,0 +1,97 @@
+package tcping
+
+import (
+	"bufio"
+	"net"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// servePONG starts a server on port 12345 that answers
+// PING with +PONG and everything else with -ERR.
+func servePONG(t *testing.T) {
+	srv, err := net.Listen("tcp", "127.0.0.1:12345")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	t.Cleanup(func() { srv.Close() })
+
+	go func() {
+		for {
+			c, err := srv.Accept()
+			if err != nil {
+				return
+			}
+
+			go func() {
+				defer c.Close()
+
+				line, err := bufio.NewReader(c).ReadString('\n')
+				if err != nil {
+					return
+				}
+
+				if line == "PING\r\n" {
+					c.Write([]byte("+PONG\r\n"))
+				} else {
+					c.Write([]byte("-ERR unknown command\r\n"))
+				}
+			}()
+		}
+	}()
+}
+
+// runPayloadProbe runs a single probe with the payload and returns its result.
+func runPayloadProbe(t *testing.T, send string, expect *regexp.Regexp) Result {
+	var result Result
+	p, err := New(Options{
+		Printer:               &dummyPrinter{},
+		Hostname:              "127.0.0.1",
+		Port:                  12345,
+		IntervalBetweenProbes: 2 * time.Millisecond,
+		Timeout:               time.Second,
+		ProbesBeforeQuit:      1,
+		Send:                  []byte(send),
+		Expect:                expect,
+		Hooks: Hooks{
+			OnProbe: func(r Result) { result = r },
+		},
+	})
+	assert.NoError(t, err)
+
+	p.Run()
+
+	return result
+}
+
+func TestPayload(t *testing.T) {
+	servePONG(t)
+
+	pong := regexp.MustCompile(`^\+PONG`)
+
+	assert.True(t, runPayloadProbe(t, "PING\r\n", pong).Success)
+	assert.False(t, runPayloadProbe(t, "QUIT\r\n", pong).Success)
+	// nothing is sent, so the server never answers
+	assert.False(t, runPayloadProbe(t, "", pong).Success)
+	// nothing is expected
+	assert.True(t, runPayloadProbe(t, "QUIT\r\n", nil).Success)
+}
+
+func TestExchangeProberError(t *testing.T) {
+	servePONG(t)
+
+	conn, err := net.Dial("tcp", "127.0.0.1:12345")
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	defer conn.Close()
+
+	err = exchangeProber{{
+		send:   []byte("QUIT\r\n"),
+		expect: regexp.MustCompile(`^\+PONG`),
+	}}.Probe(conn, time.Second)
+	assert.EqualError(t, err, `the response "-ERR unknown command\r\n" doesn't match ^\+PONG`)
+}
@@ -0,0 +1,186 @@
+package tcping
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net/netip"
+	"sync"
+	"sync/atomic"
+)
+
+// the pcapng blocks written by [PcapWriter]
+const (
+	pcapngSectionHeader  = 0x0a0d0d0a
+	pcapngInterface      = 0x00000001
+	pcapngEnhancedPacket = 0x00000006
+	pcapngByteOrderMagic = 0x1a2b3c4d
+)
+
+// the pcapng options written by [PcapWriter]
+const (
+	pcapngOptEnd     = 0
+	pcapngOptComment = 1
+	pcapngOptIfName  = 2
+	pcapngOptTsResol = 9
+)
+
+// linkTypeRaw is the link type of the packets starting with the IP header.
+const linkTypeRaw = 101
+
+// PcapWriter writes the segments of the probes captured with Options.Pcap
+// in the pcapng format, for Wireshark. The first segment sent by every
+// probe is commented with its sequence number and its target, e.g.
+// "tcping seq=3 to 192.0.2.1:443", to find it from the output of tcping.
+// It's safe for concurrent use, the pingers can share it.
+type PcapWriter struct {
+	mu  sync.Mutex
+	w   io.Writer
+	err error
+}
+
+// NewPcapWriter writes the header of the capture to w
+// and returns a PcapWriter appending the segments to it.
+func NewPcapWriter(w io.Writer) (*PcapWriter, error) {
+	pw := &PcapWriter{w: w}
+
+	// the section has an unknown length
+	shb := binary.LittleEndian.AppendUint32(nil, pcapngByteOrderMagic)
+	shb = binary.LittleEndian.AppendUint16(shb, 1)
+	shb = binary.LittleEndian.AppendUint16(shb, 0)
+	shb = binary.LittleEndian.AppendUint64(shb, ^uint64(0))
+	if err := pw.writeBlock(pcapngSectionHeader, shb); err != nil {
+		return nil, err
+	}
+
+	// the timestamps are in nanoseconds
+	idb := binary.LittleEndian.AppendUint16(nil, linkTypeRaw)
+	idb = binary.LittleEndian.AppendUint16(idb, 0)
+	idb = binary.LittleEndian.AppendUint32(idb, captureSnaplen)
+	idb = appendPcapngOption(idb, pcapngOptIfName, []byte("tcping"))
+	idb = appendPcapngOption(idb, pcapngOptTsResol, []byte{9})
+	idb = appendPcapngOption(idb, pcapngOptEnd, nil)
+	if err := pw.writeBlock(pcapngInterface, idb); err != nil {
+		return nil, err
+	}
+
+	return pw, nil
+}
+
+// Err returns the first error writing the segments, if any.
+func (pw *PcapWriter) Err() error {
+	pw.mu.Lock()
+	defer pw.mu.Unlock()
+
+	return pw.err
+}
+
+// write writes the segment right away, so that it isn't lost
+// if tcping is killed. The first error is kept for Err, the
+// next segments are dropped.
+func (pw *PcapWriter) write(p wirePacket, comment string) {
+	pw.mu.Lock()
+	defer pw.mu.Unlock()
+
+	if pw.err != nil {
+		return
+	}
+
+	ts := uint64(p.time.UnixNano())
+	epb := binary.LittleEndian.AppendUint32(nil, 0)
+	epb = binary.LittleEndian.AppendUint32(epb, uint32(ts>>32))
+	epb = binary.LittleEndian.AppendUint32(epb, uint32(ts))
+	epb = binary.LittleEndian.AppendUint32(epb, uint32(len(p.data)))
+	epb = binary.LittleEndian.AppendUint32(epb, uint32(len(p.data)))
+	epb = append(epb, p.data...)
+	epb = appendPadding(epb)
+	if comment != "" {
+		epb = appendPcapngOption(epb, pcapngOptComment, []byte(comment))
+		epb = appendPcapngOption(epb, pcapngOptEnd, nil)
+	}
+
+	pw.err = pw.writeBlock(pcapngEnhancedPacket, epb)
+}
+
+// writeBlock writes a block with the given body, padded to 32 bits.
+func (pw *PcapWriter) writeBlock(blockType uint32, body []byte) error {
+	length := uint32(12 + len(body))
+	block := binary.LittleEndian.AppendUint32(nil, blockType)
+	block = binary.LittleEndian.AppendUint32(block, length)
+	block = append(block, body...)
+	block = binary.LittleEndian.AppendUint32(block, length)
+
+	_, err := pw.w.Write(block)
+	return err
+}
+
+// appendPcapngOption appends the option, padded to 32 bits.
+func appendPcapngOption(b []byte, code uint16, value []byte) []byte {
+	b = binary.LittleEndian.AppendUint16(b, code)
+	b = binary.LittleEndian.AppendUint16(b, uint16(len(value)))
+	return appendPadding(append(b, value...))
+}
+
+// appendPadding pads b to 32 bits.
+func appendPadding(b []byte) []byte {
+	for len(b)%4 != 0 {
+		b = append(b, 0)
+	}
+	return b
+}
+
+// pcapProbe is the probe in flight, whose segments are written to the pcap file.
+type pcapProbe struct {
+	seq  uint
+	addr netip.AddrPort
+}
+
+// pcapFlows writes the segments of the probes of a pinger to the pcap
+// file, the others to the port of its target are left out. The
+// probes set themselves as they start, on their own goroutine.
+type pcapFlows struct {
+	w     *PcapWriter
+	probe atomic.Pointer[pcapProbe]
+	// tagged is the last probe whose first segment was commented,
+	// it's only used by the goroutine of the capture.
+	tagged uint
+}
+
+// add writes the segment to the pcap file if it's one of the probes.
+// It is meant to be called with every captured segment.
+func (f *pcapFlows) add(p wirePacket) {
+	probe := f.probe.Load()
+	if probe == nil {
+		return
+	}
+
+	remote := p.dst
+	if !p.outbound {
+		remote = p.src
+	}
+	if remote.Addr() != probe.addr.Addr() {
+		return
+	}
+
+	comment := ""
+	if p.outbound && probe.seq != f.tagged {
+		f.tagged = probe.seq
+		comment = fmt.Sprintf("tcping seq=%d to %s", probe.seq, probe.addr)
+	}
+
+	f.w.write(p, comment)
+}
+
+// setPcapProbe tags the segments sent from now on with
+// the sequence number of the probe about to be sent.
+func (tcpStats *stats) setPcapProbe() {
+	if tcpStats.userInput.pcapFlows == nil {
+		return
+	}
+
+	ip := tcpStats.userInput.ip.Unmap().WithZone("")
+	tcpStats.userInput.pcapFlows.probe.Store(&pcapProbe{
+		seq:  tcpStats.seq() + 1,
+		addr: netip.AddrPortFrom(ip, tcpStats.userInput.Port),
+	})
+}
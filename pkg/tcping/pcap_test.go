@@ -0,0 +1,109 @@
+package tcping
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"net/netip"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// pcapngPacket is an enhanced packet block read back by readPcapng.
+type pcapngPacket struct {
+	time    time.Time
+	data    []byte
+	comment string
+}
+
+// readPcapng reads the packets of a pcapng file written by [PcapWriter].
+func readPcapng(t *testing.T, b []byte) []pcapngPacket {
+	t.Helper()
+
+	var packets []pcapngPacket
+	for len(b) > 0 {
+		require.GreaterOrEqual(t, len(b), 12)
+		blockType := binary.LittleEndian.Uint32(b)
+		length := binary.LittleEndian.Uint32(b[4:])
+		require.Zero(t, length%4)
+		require.Equal(t, length, binary.LittleEndian.Uint32(b[length-4:]))
+		body := b[8 : length-4]
+		b = b[length:]
+
+		if blockType != pcapngEnhancedPacket {
+			continue
+		}
+
+		ts := uint64(binary.LittleEndian.Uint32(body[4:]))<<32 | uint64(binary.LittleEndian.Uint32(body[8:]))
+		n := binary.LittleEndian.Uint32(body[12:])
+		p := pcapngPacket{time: time.Unix(0, int64(ts)), data: body[20 : 20+n]}
+		for opts := body[20+(n+3)/4*4:]; len(opts) >= 4; {
+			code, size := binary.LittleEndian.Uint16(opts), binary.LittleEndian.Uint16(opts[2:])
+			if code == pcapngOptComment {
+				p.comment = string(opts[4 : 4+size])
+			}
+			opts = opts[4+(size+3)/4*4:]
+		}
+		packets = append(packets, p)
+	}
+
+	return packets
+}
+
+func TestPcapWriter(t *testing.T) {
+	var buf bytes.Buffer
+	pw, err := NewPcapWriter(&buf)
+	require.NoError(t, err)
+	assert.Equal(t, []byte{0x0a, 0x0d, 0x0d, 0x0a}, buf.Bytes()[:4])
+
+	local := netip.MustParseAddrPort("192.0.2.1:40000")
+	target := netip.MustParseAddrPort("198.51.100.1:443")
+	other := netip.MustParseAddrPort("198.51.100.2:443")
+	start := time.Unix(1700000000, 123456789)
+
+	segment := func(src, dst netip.AddrPort, flags uint8) wirePacket {
+		p, ok := parseSegment(testSegment(src, dst, flags), 443)
+		require.True(t, ok)
+		p.time = start
+		return p
+	}
+
+	f := &pcapFlows{w: pw}
+	// nothing is written before the first probe
+	f.add(segment(local, target, tcpFlagSYN))
+
+	f.probe.Store(&pcapProbe{seq: 1, addr: target})
+	f.add(segment(local, target, tcpFlagSYN))
+	f.add(segment(target, local, tcpFlagSYN|tcpFlagACK))
+	f.add(segment(local, target, tcpFlagACK))
+	// the segments of the other connections to the port are left out
+	f.add(segment(local, other, tcpFlagSYN))
+
+	f.probe.Store(&pcapProbe{seq: 2, addr: target})
+	f.add(segment(local, target, tcpFlagSYN))
+	require.NoError(t, pw.Err())
+
+	packets := readPcapng(t, buf.Bytes())
+	if assert.Len(t, packets, 4) {
+		assert.Equal(t, "tcping seq=1 to 198.51.100.1:443", packets[0].comment)
+		assert.Empty(t, packets[1].comment)
+		assert.Empty(t, packets[2].comment)
+		assert.Equal(t, "tcping seq=2 to 198.51.100.1:443", packets[3].comment)
+		assert.True(t, packets[0].time.Equal(start))
+		assert.Equal(t, testSegment(local, target, tcpFlagSYN), packets[0].data)
+	}
+}
+
+type failingWriter struct{}
+
+func (failingWriter) Write([]byte) (int, error) {
+	return 0, errors.New("disk full")
+}
+
+func TestPcapWriterError(t *testing.T) {
+	_, err := NewPcapWriter(failingWriter{})
+	assert.EqualError(t, err, "disk full")
+}
@@ -0,0 +1,54 @@
+package tcping
+
+import "net/netip"
+
+// IPStatistics are the probes of one of the addresses of the hostname,
+// kept apart so that the RTTs of different backends aren't mixed.
+type IPStatistics struct {
+	IP                 netip.Addr
+	SuccessfulProbes   uint
+	UnsuccessfulProbes uint
+	RttResults         RttResult
+}
+
+// ipStats are the probes of one of the probed addresses.
+type ipStats struct {
+	ip           netip.Addr
+	successful   uint
+	unsuccessful uint
+	rtt          rttStats
+}
+
+// addrStats returns the statistics of the probed address,
+// starting them if it's probed for the first time.
+func (tcpStats *stats) addrStats() *ipStats {
+	ip := tcpStats.userInput.ip
+	for i := range tcpStats.perIP {
+		if tcpStats.perIP[i].ip == ip {
+			return &tcpStats.perIP[i]
+		}
+	}
+
+	tcpStats.perIP = append(tcpStats.perIP, ipStats{ip: ip})
+	return &tcpStats.perIP[len(tcpStats.perIP)-1]
+}
+
+// ipStatistics returns the statistics of every probed address, in the
+// order they were first probed, nil if a single address was probed.
+func (tcpStats *stats) ipStatistics() []IPStatistics {
+	if len(tcpStats.perIP) < 2 {
+		return nil
+	}
+
+	perIP := make([]IPStatistics, len(tcpStats.perIP))
+	for i, s := range tcpStats.perIP {
+		perIP[i] = IPStatistics{
+			IP:                 s.ip,
+			SuccessfulProbes:   s.successful,
+			UnsuccessfulProbes: s.unsuccessful,
+			RttResults:         s.rtt.results(),
+		}
+	}
+
+	return perIP
+}
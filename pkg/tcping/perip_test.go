@@ -0,0 +1,44 @@
+package tcping
+
+import (
+	"net/netip"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPerIPStatistics(t *testing.T) {
+	first := netip.MustParseAddr("192.0.2.1")
+	second := netip.MustParseAddr("192.0.2.2")
+
+	stats := createTestStats(t)
+	stats.userInput.ip = first
+	now := time.Now()
+	stats.handleConnSuccess(10, now)
+	stats.handleConnSuccess(20, now)
+
+	// a single address isn't reported apart
+	assert.Nil(t, stats.statistics().PerIP)
+
+	stats.updateIP(second)
+	stats.handleConnError(now, nil)
+	stats.handleConnSuccess(100, now)
+
+	s := stats.statistics()
+	if assert.Len(t, s.PerIP, 2) {
+		assert.Equal(t, first, s.PerIP[0].IP)
+		assert.Equal(t, uint(2), s.PerIP[0].SuccessfulProbes)
+		assert.Equal(t, float32(15), s.PerIP[0].RttResults.Average)
+		assert.Equal(t, second, s.PerIP[1].IP)
+		assert.Equal(t, uint(1), s.PerIP[1].SuccessfulProbes)
+		assert.Equal(t, uint(1), s.PerIP[1].UnsuccessfulProbes)
+		assert.Equal(t, float32(100), s.PerIP[1].RttResults.Max)
+	}
+
+	data := NewStatisticsJSONData(s)
+	if assert.Len(t, data.PerIP, 2) {
+		assert.Equal(t, "192.0.2.1", data.PerIP[0].IP)
+		assert.Equal(t, "15.000", data.PerIP[0].LatencyAvg)
+	}
+}
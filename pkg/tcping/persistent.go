@@ -0,0 +1,104 @@
+package tcping
+
+import (
+	"errors"
+	"io"
+	"net"
+	"time"
+)
+
+// persistentConn is the connection kept open with Options.Persistent.
+type persistentConn struct {
+	conn *net.TCPConn
+	// dropped receives the error that ended the connection.
+	dropped chan error
+	// retransmits are the retransmissions of TCP_INFO already reported.
+	retransmits uint32
+}
+
+// keepOpen keeps the connection of the successful probe open,
+// so that the next probes check it instead of connecting again.
+func (tcpStats *stats) keepOpen(conn net.Conn) {
+	tcpConn := conn.(*net.TCPConn)
+
+	// the kernel sends a keepalive whenever the connection is idle
+	// for an interval and drops it if they're unanswered for the timeout
+	tcpConn.SetKeepAlive(true)
+	tcpConn.SetKeepAlivePeriod(tcpStats.userInput.IntervalBetweenProbes)
+	if err := setUserTimeout(tcpConn, tcpStats.userInput.Timeout); err != nil {
+		tcpStats.printer.PrintError("Unable to set the user timeout: %s", err)
+	}
+	tcpStats.log().Debug("keeping the connection open", "local", tcpConn.LocalAddr(),
+		"keepalive_period", tcpStats.userInput.IntervalBetweenProbes, "user_timeout", tcpStats.userInput.Timeout)
+
+	p := &persistentConn{conn: tcpConn, dropped: make(chan error, 1)}
+	if info, err := readTCPInfo(tcpConn); err == nil {
+		p.retransmits = info.Retransmits
+	}
+	go p.watch()
+
+	tcpStats.persistent = p
+}
+
+// watch discards what the server sends until the connection ends.
+func (p *persistentConn) watch() {
+	buf := make([]byte, 512)
+	for {
+		if _, err := p.conn.Read(buf); err != nil {
+			p.dropped <- err
+			return
+		}
+	}
+}
+
+// probePersistent checks the open connection instead of connecting again.
+// The RTT of the probe is the kernel's smoothed RTT of the connection.
+func probePersistent(tcpStats *stats) {
+	p := tcpStats.persistent
+	probeTime := time.Now()
+
+	var err error
+	select {
+	case err = <-p.dropped:
+	default:
+	}
+
+	var info TCPInfo
+	if err == nil {
+		info, err = readTCPInfo(p.conn)
+	}
+
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			err = errors.New("closed by the server")
+		}
+
+		tcpStats.printer.PrintError("The connection to %s was dropped: %s", tcpStats.userInput.ip, err)
+		tcpStats.closePersistent()
+		tcpStats.handleConnError(probeTime, err)
+		return
+	}
+
+	// the connection counts the retransmissions since it was opened
+	retransmits := info.Retransmits
+	info.Retransmits -= p.retransmits
+	p.retransmits = retransmits
+
+	if tcpStats.userInput.TCPInfo {
+		tcpStats.tcpInfo = &info
+	}
+
+	tcpStats.localAddr = localAddrOf(p.conn)
+	tcpStats.handleConnSuccess(info.SRTT, probeTime)
+}
+
+// closePersistent closes the open connection, if any,
+// so that the next probe connects again.
+func (tcpStats *stats) closePersistent() {
+	if tcpStats.persistent == nil {
+		return
+	}
+
+	tcpStats.persistent.conn.Close()
+	tcpStats.persistent = nil
+}
@@ -0,0 +1,33 @@
+//go:build linux
+
+package tcping
+
+import (
+	"net"
+	"time"
+
+	"golang.org/x/sys/unix"
+)
+
+// persistentSupported reports whether the connections can be kept open on this platform.
+const persistentSupported = true
+
+// setUserTimeout makes the kernel drop the connection when the
+// sent data or keepalives stay unacknowledged for the timeout.
+// 0 keeps the default of the system.
+func setUserTimeout(conn *net.TCPConn, timeout time.Duration) error {
+	raw, err := conn.SyscallConn()
+	if err != nil {
+		return err
+	}
+
+	var sockErr error
+	err = raw.Control(func(fd uintptr) {
+		sockErr = unix.SetsockoptInt(int(fd), unix.IPPROTO_TCP, unix.TCP_USER_TIMEOUT, int(timeout.Milliseconds()))
+	})
+	if err != nil {
+		return err
+	}
+
+	return sockErr
+}
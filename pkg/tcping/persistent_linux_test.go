@@ -0,0 +1,77 @@
+package tcping
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPersistent(t *testing.T) {
+	srv, err := net.Listen("tcp", "127.0.0.1:12345")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	t.Cleanup(func() { srv.Close() })
+
+	accepted := make(chan net.Conn, 10)
+	go func() {
+		for {
+			c, err := srv.Accept()
+			if err != nil {
+				return
+			}
+
+			accepted <- c
+		}
+	}()
+
+	var results []Result
+	p, err := New(Options{
+		Printer:               &dummyPrinter{},
+		Hostname:              "127.0.0.1",
+		Port:                  12345,
+		IntervalBetweenProbes: 10 * time.Millisecond,
+		Timeout:               time.Second,
+		ProbesBeforeQuit:      4,
+		Persistent:            true,
+		Hooks: Hooks{
+			OnProbe: func(r Result) {
+				results = append(results, r)
+
+				// the server drops the connection after the second probe
+				if len(results) == 2 {
+					(<-accepted).Close()
+					time.Sleep(50 * time.Millisecond)
+				}
+			},
+		},
+	})
+	assert.NoError(t, err)
+
+	p.Run()
+
+	if assert.Len(t, results, 4) {
+		assert.True(t, results[0].Success)
+		assert.True(t, results[1].Success)
+		assert.False(t, results[2].Success)
+		assert.True(t, results[3].Success)
+	}
+
+	// one connection until the drop and another one after it
+	assert.Len(t, accepted, 1)
+	assert.Nil(t, p.stats.persistent)
+}
+
+func TestPersistentWithTFO(t *testing.T) {
+	_, err := New(Options{
+		Printer:               &dummyPrinter{},
+		Hostname:              "127.0.0.1",
+		Port:                  12345,
+		IntervalBetweenProbes: time.Second,
+		Persistent:            true,
+		TFO:                   true,
+	})
+	assert.Error(t, err)
+}
@@ -0,0 +1,16 @@
+//go:build !linux
+
+package tcping
+
+import (
+	"errors"
+	"net"
+	"time"
+)
+
+// persistentSupported reports whether the connections can be kept open on this platform.
+const persistentSupported = false
+
+func setUserTimeout(_ *net.TCPConn, _ time.Duration) error {
+	return errors.New("TCP_USER_TIMEOUT is only supported on Linux")
+}
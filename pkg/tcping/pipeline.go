@@ -0,0 +1,100 @@
+package tcping
+
+// eventsBuffer is the number of printer events that can be queued
+// before the probing has to wait for the printer to catch up.
+const eventsBuffer = 256
+
+// printEvent is a call to the printer, queued by the probing
+// goroutine and made by the printing one.
+type printEvent func(p Printer)
+
+// queuePrinter is the printer of a [Pinger] while it's probing.
+// Instead of printing, it queues the calls as events, so that a slow
+// printer, e.g. a database, doesn't delay the probes.
+type queuePrinter struct {
+	events chan<- printEvent
+}
+
+func (e queuePrinter) PrintStart(hostname string, port uint16) {
+	e.events <- func(p Printer) { p.PrintStart(hostname, port) }
+}
+
+func (e queuePrinter) PrintProbe(r Result) {
+	e.events <- func(p Printer) { p.PrintProbe(r) }
+}
+
+func (e queuePrinter) PrintStateChange(c StateChange) {
+	e.events <- func(p Printer) { p.PrintStateChange(c) }
+}
+
+func (e queuePrinter) PrintRetryingToResolve(hostname string) {
+	e.events <- func(p Printer) { p.PrintRetryingToResolve(hostname) }
+}
+
+func (e queuePrinter) PrintStatistics(s Statistics) {
+	e.events <- func(p Printer) { p.PrintStatistics(s) }
+}
+
+func (e queuePrinter) PrintVersion() {
+	e.events <- func(p Printer) { p.PrintVersion() }
+}
+
+func (e queuePrinter) PrintInfo(format string, args ...any) {
+	e.events <- func(p Printer) { p.PrintInfo(format, args...) }
+}
+
+func (e queuePrinter) PrintError(format string, args ...any) {
+	e.events <- func(p Printer) { p.PrintError(format, args...) }
+}
+
+// print makes the calls to the printer that depend on the optional
+// printer interfaces, e.g. [ResolvePrinter], with the actual printer.
+// The event mustn't read the stats, as they keep changing while it's queued.
+//
+// With a [NewMultiPrinter] printer, the event is made with each of its
+// printers, so that every one of them gets the calls it implements.
+func (tcpStats *stats) print(event printEvent) {
+	fanOut := func(p Printer) { eachPrinter(p, event) }
+	if e, ok := tcpStats.printer.(queuePrinter); ok {
+		e.events <- fanOut
+		return
+	}
+
+	fanOut(tcpStats.printer)
+}
+
+// startPrinting makes the printer print the events
+// of the pinger on its own goroutine.
+func (p *Pinger) startPrinting() {
+	events := make(chan printEvent, eventsBuffer)
+	p.events = events
+	p.stats.printer = queuePrinter{events}
+
+	go func() {
+		for event := range events {
+			if event == nil {
+				return
+			}
+			event(p.printer)
+		}
+	}()
+}
+
+// stopPrinting prints the queued events and stops the printing
+// goroutine, the pinger prints with the actual printer afterwards.
+func (p *Pinger) stopPrinting() {
+	if _, ok := p.stats.printer.(queuePrinter); !ok {
+		return
+	}
+
+	p.flush()
+	p.stats.printer = p.printer
+	p.events <- nil
+}
+
+// flush waits until the queued events are printed.
+func (p *Pinger) flush() {
+	printed := make(chan struct{})
+	p.events <- func(Printer) { close(printed) }
+	<-printed
+}
@@ -0,0 +1,94 @@
+package tcping
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// statsPrinter signals the statistics it prints.
+type statsPrinter struct {
+	dummyPrinter
+	printed chan Statistics
+}
+
+func (sp *statsPrinter) PrintStatistics(s Statistics) {
+	sp.printed <- s
+}
+
+func TestRequestStatisticsBetweenProbes(t *testing.T) {
+	srv, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	t.Cleanup(func() { srv.Close() })
+
+	printer := &statsPrinter{printed: make(chan Statistics, 1)}
+	p, err := New(Options{
+		Printer:               printer,
+		Hostname:              "127.0.0.1",
+		Port:                  uint16(srv.Addr().(*net.TCPAddr).Port),
+		IntervalBetweenProbes: time.Hour,
+		Timeout:               time.Second,
+	})
+	assert.NoError(t, err)
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		p.Run()
+	}()
+
+	// the statistics are printed without waiting for the next probe
+	assert.Eventually(t, func() bool { return p.Statistics().TotalSuccessfulProbes == 1 },
+		time.Second, time.Millisecond)
+	p.RequestStatistics()
+	select {
+	case s := <-printer.printed:
+		assert.Equal(t, uint(1), s.TotalSuccessfulProbes)
+	case <-time.After(time.Second):
+		t.Fatal("the statistics weren't printed")
+	}
+
+	p.Stop()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Run didn't return")
+	}
+}
+
+func TestRunContextCancelsTheProbe(t *testing.T) {
+	p, err := New(Options{
+		Printer:               &dummyPrinter{},
+		Hostname:              "127.0.0.1",
+		Port:                  12345,
+		IntervalBetweenProbes: time.Hour,
+		Timeout:               time.Hour,
+		Retries:               1 << 30,
+	})
+	assert.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		p.RunContext(ctx)
+	}()
+
+	// the refused connection is retried until the probe is cancelled
+	time.Sleep(50 * time.Millisecond)
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("RunContext didn't return")
+	}
+
+	// the interrupted probe isn't counted
+	s := p.Statistics()
+	assert.Zero(t, s.TotalSuccessfulProbes+s.TotalUnsuccessfulProbes)
+}
@@ -0,0 +1,149 @@
+package tcping
+
+import (
+	"crypto/tls"
+	"fmt"
+	"net"
+	"regexp"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Prober checks the service behind the connection of a successful
+// probe, e.g. by speaking its protocol. See Options.Prober.
+type Prober interface {
+	// Probe returns an error if the service doesn't answer as
+	// expected within the timeout, 0 meaning no timeout.
+	// The connection is closed afterwards.
+	Probe(conn net.Conn, timeout time.Duration) error
+}
+
+// ProberConfig holds the settings a [ProberFactory] may need.
+// Probers ignore the settings that don't apply to them.
+type ProberConfig struct {
+	// Hostname of the target.
+	Hostname string
+	// Service is the name of the service to check,
+	// e.g. for the health checks of gRPC.
+	Service string
+	// HTTP is the request of the http prober and the response it expects.
+	// The path and the header also apply to the upgrade of the ws prober.
+	HTTP HTTPConfig
+	// DNS is the query sent by the dns prober.
+	DNS DNSConfig
+	// WebSocketPing sends a ping after the upgrade of
+	// the ws prober and waits for the server's pong.
+	WebSocketPing bool
+	// TLSConfig speaks the protocol over TLS with the given config,
+	// nil meaning plaintext. ServerName defaults to Hostname.
+	TLSConfig *tls.Config
+}
+
+// ProberFactory creates a new [Prober] from the given config.
+type ProberFactory func(cfg ProberConfig) (Prober, error)
+
+var (
+	probersMu sync.RWMutex
+	probers   = make(map[string]ProberFactory)
+)
+
+func init() {
+	// a server requiring authentication is still up
+	registerExchangeProber("redis", exchangeStep{
+		send:   []byte("PING\r\n"),
+		expect: regexp.MustCompile(`^(\+PONG|-NOAUTH)`),
+	})
+	// SSLRequest, answered with S or N before any authentication
+	registerExchangeProber("postgres", exchangeStep{
+		send:   []byte{0, 0, 0, 8, 0x04, 0xd2, 0x16, 0x2f},
+		expect: regexp.MustCompile(`^[SN]`),
+	})
+	// the initial handshake packet of protocol version 10
+	registerExchangeProber("mysql", exchangeStep{
+		expect: regexp.MustCompile(`(?s)^.{3}\x00\x0a`),
+	})
+	registerExchangeProber("smtp",
+		exchangeStep{expect: regexp.MustCompile(`(?m)^220 `)},
+		exchangeStep{send: []byte("EHLO tcping\r\n"), expect: regexp.MustCompile(`(?m)^250 `)},
+		exchangeStep{send: []byte("QUIT\r\n")},
+	)
+	registerExchangeProber("imap", exchangeStep{
+		expect: regexp.MustCompile(`^\* (OK|PREAUTH)`),
+	})
+	registerExchangeProber("pop3", exchangeStep{
+		expect: regexp.MustCompile(`^\+OK`),
+	})
+}
+
+// runProber checks the service behind the connection and records
+// how long it took to answer, which is printed with the probe.
+func (tcpStats *stats) runProber(conn net.Conn) error {
+	start := time.Now()
+	if err := tcpStats.userInput.prober.Probe(conn, tcpStats.userInput.Timeout); err != nil {
+		err = tcpStats.clientAuthError(err)
+		tcpStats.printer.PrintError("Probe to %s on port %d failed: %s",
+			tcpStats.userInput.ip, tcpStats.userInput.Port, err)
+		return err
+	}
+
+	responseTime := nanoToMillisecond(time.Since(start).Nanoseconds())
+	tcpStats.responseTimes.add(responseTime)
+	tcpStats.lastResponseTime = responseTime
+
+	return nil
+}
+
+// registerExchangeProber registers a prober running the given steps.
+func registerExchangeProber(name string, steps ...exchangeStep) {
+	RegisterProber(name, func(_ ProberConfig) (Prober, error) {
+		return exchangeProber(steps), nil
+	})
+}
+
+// RegisterProber makes a prober available by the provided name,
+// so that it can be selected with [NewProber].
+//
+// If RegisterProber is called twice with the
+// same name or if factory is nil, it panics.
+func RegisterProber(name string, factory ProberFactory) {
+	probersMu.Lock()
+	defer probersMu.Unlock()
+
+	if factory == nil {
+		panic("tcping: RegisterProber factory is nil")
+	}
+
+	if _, dup := probers[name]; dup {
+		panic("tcping: RegisterProber called twice for prober " + name)
+	}
+
+	probers[name] = factory
+}
+
+// NewProber creates the prober registered under the given name.
+func NewProber(name string, cfg ProberConfig) (Prober, error) {
+	probersMu.RLock()
+	factory, ok := probers[name]
+	probersMu.RUnlock()
+
+	if !ok {
+		return nil, fmt.Errorf("unknown prober %q", name)
+	}
+
+	return factory(cfg)
+}
+
+// Probers returns a sorted list of the names of the registered probers.
+func Probers() []string {
+	probersMu.RLock()
+	defer probersMu.RUnlock()
+
+	names := make([]string, 0, len(probers))
+	for name := range probers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
@@ -0,0 +1,168 @@
+package tcping
+
+import (
+	"bufio"
+	"io"
+	"net"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProbers(t *testing.T) {
+	assert.Equal(t, []string{"dns", "http", "imap", "mysql", "pop3", "postgres", "redis", "smtp", "ws", "wss"}, Probers())
+
+	_, err := NewProber("unknown", ProberConfig{})
+	assert.EqualError(t, err, `unknown prober "unknown"`)
+
+	assert.Panics(t, func() {
+		RegisterProber("redis", func(_ ProberConfig) (Prober, error) {
+			return exchangeProber{}, nil
+		})
+	})
+	assert.Panics(t, func() {
+		RegisterProber("nil", nil)
+	})
+}
+
+// probeFakeServer runs the prober against a server
+// handled by serve and returns the result of the probe.
+func probeFakeServer(t *testing.T, name string, serve func(c net.Conn)) error {
+	client, server := net.Pipe()
+	defer client.Close()
+
+	go func() {
+		defer server.Close()
+		serve(server)
+	}()
+
+	prober, err := NewProber(name, ProberConfig{})
+	assert.NoError(t, err)
+
+	return prober.Probe(client, time.Second)
+}
+
+func TestExchangeProbers(t *testing.T) {
+	tests := []struct {
+		name    string
+		serve   func(c net.Conn)
+		wantErr bool
+	}{
+		{
+			name: "redis",
+			serve: func(c net.Conn) {
+				bufio.NewReader(c).ReadString('\n')
+				c.Write([]byte("-NOAUTH Authentication required.\r\n"))
+			},
+		},
+		{
+			name: "redis",
+			serve: func(c net.Conn) {
+				bufio.NewReader(c).ReadString('\n')
+				c.Write([]byte("-LOADING Redis is loading the dataset in memory\r\n"))
+			},
+			wantErr: true,
+		},
+		{
+			name: "postgres",
+			serve: func(c net.Conn) {
+				io.ReadFull(c, make([]byte, 8))
+				c.Write([]byte("N"))
+			},
+		},
+		{
+			name: "mysql",
+			serve: func(c net.Conn) {
+				c.Write([]byte("\x4a\x00\x00\x00\x0a8.0.36\x00"))
+			},
+		},
+		{
+			name: "mysql",
+			serve: func(c net.Conn) {
+				c.Write([]byte("\x17\x00\x00\x00\xffj\x04Host is blocked"))
+			},
+			wantErr: true,
+		},
+		{
+			name: "smtp",
+			serve: func(c net.Conn) {
+				r := bufio.NewReader(c)
+				c.Write([]byte("220-mail.example.com ESMTP\r\n220 ready\r\n"))
+				r.ReadString('\n')
+				c.Write([]byte("250-mail.example.com\r\n250 SMTPUTF8\r\n"))
+				r.ReadString('\n')
+			},
+		},
+		{
+			name: "smtp",
+			serve: func(c net.Conn) {
+				c.Write([]byte("554 no SMTP service here\r\n"))
+			},
+			wantErr: true,
+		},
+		{
+			name: "imap",
+			serve: func(c net.Conn) {
+				c.Write([]byte("* OK IMAP4rev1 Service Ready\r\n"))
+			},
+		},
+		{
+			name: "pop3",
+			serve: func(c net.Conn) {
+				c.Write([]byte("+OK POP3 server ready\r\n"))
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := probeFakeServer(t, tt.name, tt.serve)
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestProberResponseTime(t *testing.T) {
+	servePONG(t)
+
+	prober, err := NewProber("redis", ProberConfig{})
+	assert.NoError(t, err)
+
+	var results []Result
+	p, err := New(Options{
+		Printer:               &dummyPrinter{},
+		Hostname:              "127.0.0.1",
+		Port:                  12345,
+		IntervalBetweenProbes: 2 * time.Millisecond,
+		Timeout:               time.Second,
+		ProbesBeforeQuit:      2,
+		Prober:                prober,
+		Hooks: Hooks{
+			OnProbe: func(r Result) { results = append(results, r) },
+		},
+	})
+	assert.NoError(t, err)
+
+	p.Run()
+
+	if assert.Len(t, results, 2) {
+		assert.True(t, results[0].Success)
+		assert.NotZero(t, results[0].ResponseTime)
+	}
+	assert.True(t, p.Statistics().ResponseTimeResults.HasResults)
+
+	_, err = New(Options{
+		Printer:               &dummyPrinter{},
+		Hostname:              "127.0.0.1",
+		Port:                  12345,
+		IntervalBetweenProbes: time.Second,
+		Prober:                prober,
+		Expect:                regexp.MustCompile(`^\+PONG`),
+	})
+	assert.Error(t, err)
+}
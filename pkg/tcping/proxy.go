@@ -0,0 +1,199 @@
+package tcping
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/net/proxy"
+)
+
+// proxyError is an error of the proxy rather than of the target,
+// its probes fail with FailureProxy, which tells it apart, or with
+// FailureProxyAuth if the proxy didn't take the credentials.
+type proxyError struct {
+	err  error
+	auth bool
+}
+
+func (e proxyError) Error() string {
+	return e.err.Error()
+}
+
+func (e proxyError) Unwrap() error {
+	return e.err
+}
+
+// checkProxy checks that the scheme of the proxy is supported.
+func checkProxy(u *url.URL) error {
+	switch u.Scheme {
+	case "http", "https", "socks5", "socks5h":
+		return nil
+	}
+
+	return fmt.Errorf("unsupported proxy scheme %q, use http, https or socks5", u.Scheme)
+}
+
+// proxyAddr returns the address of the proxy, with the default port of its scheme.
+func proxyAddr(u *url.URL) string {
+	if u.Port() != "" {
+		return u.Host
+	}
+
+	port := 80
+	switch u.Scheme {
+	case "https":
+		port = 443
+	case "socks5", "socks5h":
+		port = 1080
+	}
+
+	return net.JoinHostPort(u.Hostname(), strconv.Itoa(port))
+}
+
+// dialProxy connects to the target through the proxy of Options.Proxy,
+// the dialer connecting to the proxy. The target is resolved by the proxy.
+func (tcpStats *stats) dialProxy(ctx context.Context, dialer net.Dialer) (net.Conn, error) {
+	u := tcpStats.userInput.Proxy
+	target := net.JoinHostPort(tcpStats.userInput.Hostname, strconv.Itoa(int(tcpStats.userInput.Port)))
+
+	if dialer.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, dialer.Timeout)
+		defer cancel()
+	}
+
+	if u.Scheme == "socks5" || u.Scheme == "socks5h" {
+		d, err := proxy.FromURL(u, &dialer)
+		if err != nil {
+			return nil, proxyError{err: err}
+		}
+
+		conn, err := d.(proxy.ContextDialer).DialContext(ctx, "tcp", target)
+		if err != nil {
+			// the SOCKS5 client only tells its authentication errors by their message
+			return nil, proxyError{err: err, auth: strings.Contains(err.Error(), "authentication")}
+		}
+		return conn, nil
+	}
+
+	conn, err := dialer.DialContext(ctx, "tcp", proxyAddr(u))
+	if err != nil {
+		return nil, proxyError{err: err}
+	}
+
+	conn, err = connectProxy(ctx, conn, u, target)
+	if err != nil {
+		var authErr proxyAuthError
+		return nil, proxyError{err: err, auth: errors.As(err, &authErr)}
+	}
+
+	return conn, nil
+}
+
+// connectProxy asks the HTTP proxy on the connection for a tunnel to the target.
+func connectProxy(ctx context.Context, conn net.Conn, u *url.URL, target string) (net.Conn, error) {
+	// the exchange with the proxy stops with the probe
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+	stop := context.AfterFunc(ctx, func() {
+		conn.SetDeadline(time.Now())
+	})
+	defer stop()
+
+	tunnel := conn
+	if u.Scheme == "https" {
+		tlsConn := tls.Client(conn, &tls.Config{ServerName: u.Hostname()})
+		if err := tlsConn.HandshakeContext(ctx); err != nil {
+			conn.Close()
+			return nil, err
+		}
+		tunnel = tlsConn
+	}
+
+	req := &http.Request{
+		Method: http.MethodConnect,
+		URL:    &url.URL{Opaque: target},
+		Host:   target,
+		Header: make(http.Header),
+	}
+	// the credentials are only encrypted with an https proxy
+	if u.User != nil {
+		password, _ := u.User.Password()
+		credentials := base64.StdEncoding.EncodeToString([]byte(u.User.Username() + ":" + password))
+		req.Header.Set("Proxy-Authorization", "Basic "+credentials)
+	}
+	if err := req.Write(tunnel); err != nil {
+		tunnel.Close()
+		return nil, err
+	}
+
+	br := bufio.NewReader(tunnel)
+	resp, err := http.ReadResponse(br, req)
+	if err != nil {
+		tunnel.Close()
+		return nil, err
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode == http.StatusProxyAuthRequired {
+		tunnel.Close()
+		return nil, proxyAuthError{status: resp.Status, sent: u.User != nil}
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		tunnel.Close()
+		return nil, errors.New("the proxy answered " + resp.Status)
+	}
+
+	if !stop() {
+		tunnel.Close()
+		return nil, ctx.Err()
+	}
+	conn.SetDeadline(time.Time{})
+
+	// the server may have spoken first, e.g. with a banner
+	if br.Buffered() > 0 {
+		return &bufferedConn{Conn: tunnel, r: br}, nil
+	}
+
+	return tunnel, nil
+}
+
+// proxyAuthError is the answer of an HTTP proxy that
+// didn't take the credentials or that asks for some.
+type proxyAuthError struct {
+	status string
+	sent   bool
+}
+
+func (e proxyAuthError) Error() string {
+	if e.sent {
+		return "the proxy rejected the credentials: " + e.status
+	}
+	return "the proxy requires credentials: " + e.status
+}
+
+// bufferedConn is a connection whose first bytes were
+// read ahead while reading the answer of the proxy.
+type bufferedConn struct {
+	net.Conn
+	r *bufio.Reader
+}
+
+func (c *bufferedConn) Read(b []byte) (int, error) {
+	if c.r.Buffered() > 0 {
+		return c.r.Read(b)
+	}
+	return c.Conn.Read(b)
+}
@@ -0,0 +1,242 @@
+package tcping
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/binary"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// testConnectProxy starts an HTTP proxy opening the CONNECT tunnels,
+// unless status isn't 200. If user is set, it requires its credentials
+// with Basic authentication. It records the targets of the tunnels.
+func testConnectProxy(t *testing.T, status int, user *url.Userinfo) (*url.URL, *[]string) {
+	var targets []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		targets = append(targets, r.Host)
+		if user != nil {
+			password, _ := user.Password()
+			want := "Basic " + base64.StdEncoding.EncodeToString([]byte(user.Username()+":"+password))
+			if r.Header.Get("Proxy-Authorization") != want {
+				http.Error(w, "", http.StatusProxyAuthRequired)
+				return
+			}
+		}
+		if r.Method != http.MethodConnect || status != http.StatusOK {
+			http.Error(w, http.StatusText(status), status)
+			return
+		}
+
+		target, err := net.Dial("tcp", r.Host)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadGateway)
+			return
+		}
+		defer target.Close()
+
+		w.WriteHeader(http.StatusOK)
+		conn, rw, err := http.NewResponseController(w).Hijack()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		rw.Flush()
+
+		go io.Copy(target, conn)
+		io.Copy(conn, target)
+	}))
+	t.Cleanup(srv.Close)
+
+	u, err := url.Parse(srv.URL)
+	assert.NoError(t, err)
+	return u, &targets
+}
+
+func TestProxy(t *testing.T) {
+	srv := testServerListen(t)
+	t.Cleanup(func() {
+		if err := srv.Close(); err != nil {
+			t.Errorf("srv close: %v", err)
+		}
+	})
+
+	proxyURL, targets := testConnectProxy(t, http.StatusOK, nil)
+	forbidden, _ := testConnectProxy(t, http.StatusForbidden, nil)
+	down := &url.URL{Scheme: "http", Host: "127.0.0.1:1"}
+
+	user := url.UserPassword("tcping", "secret")
+	authURL, _ := testConnectProxy(t, http.StatusOK, user)
+	withUser := func(u *url.URL, user *url.Userinfo) *url.URL {
+		return &url.URL{Scheme: u.Scheme, Host: u.Host, User: user}
+	}
+
+	socksURL := testSOCKS5Proxy(t, user)
+
+	for _, tt := range []struct {
+		proxy  *url.URL
+		reason FailureReason
+	}{
+		{proxyURL, ""},
+		{forbidden, FailureProxy},
+		{down, FailureProxy},
+		{withUser(authURL, user), ""},
+		{withUser(authURL, url.UserPassword("tcping", "wrong")), FailureProxyAuth},
+		{authURL, FailureProxyAuth},
+		{withUser(socksURL, user), ""},
+		{withUser(socksURL, url.UserPassword("tcping", "wrong")), FailureProxyAuth},
+		{socksURL, FailureProxyAuth},
+	} {
+		var results []Result
+		p, err := New(Options{
+			Printer:               &dummyPrinter{},
+			Hostname:              "127.0.0.1",
+			Port:                  12345,
+			IntervalBetweenProbes: 2 * time.Millisecond,
+			Timeout:               time.Second,
+			ProbesBeforeQuit:      1,
+			Proxy:                 tt.proxy,
+			Hooks: Hooks{
+				OnProbe: func(r Result) { results = append(results, r) },
+			},
+		})
+		assert.NoError(t, err)
+
+		p.Run()
+		p.Shutdown()
+
+		if assert.Len(t, results, 1, tt.proxy.String()) {
+			assert.Equal(t, tt.reason == "", results[0].Success, tt.proxy.String())
+			assert.Equal(t, tt.reason, results[0].FailureReason, tt.proxy.String())
+		}
+	}
+
+	assert.Equal(t, []string{"127.0.0.1:12345"}, *targets)
+
+	_, err := New(Options{
+		Printer:  &dummyPrinter{},
+		Hostname: "127.0.0.1",
+		Port:     12345,
+		Proxy:    &url.URL{Scheme: "ftp", Host: "127.0.0.1:21"},
+	})
+	assert.EqualError(t, err, `unsupported proxy scheme "ftp", use http, https or socks5`)
+
+	_, err = New(Options{
+		Printer:  &dummyPrinter{},
+		Hostname: "127.0.0.1",
+		Port:     12345,
+		Proxy:    proxyURL,
+		TCPInfo:  true,
+	})
+	assert.EqualError(t, err, "the connections through a proxy or a jump host can't be inspected")
+}
+
+// testSOCKS5Proxy starts a SOCKS5 proxy requiring the credentials of
+// the user with the username/password method. It only takes IPv4 targets.
+func testSOCKS5Proxy(t *testing.T, user *url.Userinfo) *url.URL {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	t.Cleanup(func() { ln.Close() })
+
+	password, _ := user.Password()
+	serve := func(conn net.Conn) {
+		defer conn.Close()
+		r := bufio.NewReader(conn)
+
+		// the greeting, only the username/password method is accepted
+		greeting := make([]byte, 2)
+		io.ReadFull(r, greeting)
+		methods := make([]byte, greeting[1])
+		io.ReadFull(r, methods)
+		if !bytes.Contains(methods, []byte{0x02}) {
+			conn.Write([]byte{0x05, 0xff})
+			return
+		}
+		conn.Write([]byte{0x05, 0x02})
+
+		auth := make([]byte, 2)
+		io.ReadFull(r, auth)
+		username := make([]byte, auth[1])
+		io.ReadFull(r, username)
+		n, _ := r.ReadByte()
+		pass := make([]byte, n)
+		io.ReadFull(r, pass)
+		if string(username) != user.Username() || string(pass) != password {
+			conn.Write([]byte{0x01, 0x01})
+			return
+		}
+		conn.Write([]byte{0x01, 0x00})
+
+		// the CONNECT request to an IPv4 address
+		req := make([]byte, 10)
+		io.ReadFull(r, req)
+		target, err := net.Dial("tcp", netip.AddrPortFrom(netip.AddrFrom4([4]byte(req[4:8])), binary.BigEndian.Uint16(req[8:])).String())
+		if err != nil {
+			conn.Write([]byte{0x05, 0x05, 0x00, 0x01, 0, 0, 0, 0, 0, 0})
+			return
+		}
+		defer target.Close()
+		conn.Write([]byte{0x05, 0x00, 0x00, 0x01, 0, 0, 0, 0, 0, 0})
+
+		go io.Copy(target, r)
+		io.Copy(conn, target)
+	}
+
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go serve(conn)
+		}
+	}()
+
+	return &url.URL{Scheme: "socks5", Host: ln.Addr().String()}
+}
+
+func TestConnectProxyReadAhead(t *testing.T) {
+	client, server := net.Pipe()
+	defer client.Close()
+
+	// the server speaks first, its banner comes with the answer of the proxy
+	go func() {
+		defer server.Close()
+		http.ReadRequest(bufio.NewReader(server))
+		server.Write([]byte("HTTP/1.1 200 Connection established\r\n\r\nSSH-2.0-OpenSSH_9.6\r\n"))
+	}()
+
+	conn, err := connectProxy(context.Background(), client, &url.URL{Scheme: "http", Host: "proxy"}, "192.0.2.1:22")
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	banner, err := bufio.NewReader(conn).ReadString('\n')
+	assert.NoError(t, err)
+	assert.Equal(t, "SSH-2.0-OpenSSH_9.6\r\n", banner)
+}
+
+func TestProxyAddr(t *testing.T) {
+	for rawURL, want := range map[string]string{
+		"http://proxy":           "proxy:80",
+		"https://proxy":          "proxy:443",
+		"socks5://[2001:db8::1]": "[2001:db8::1]:1080",
+		"http://proxy:3128":      "proxy:3128",
+	} {
+		u, err := url.Parse(rawURL)
+		assert.NoError(t, err)
+		assert.Equal(t, want, proxyAddr(u), rawURL)
+	}
+}
@@ -0,0 +1,44 @@
+package tcping
+
+import (
+	"context"
+	"errors"
+	"net"
+	"strings"
+)
+
+// lookupPTR prints the names of the probed IP from
+// its PTR records when the IP changed.
+func (tcpStats *stats) lookupPTR() {
+	ip := tcpStats.userInput.ip
+	if ip == tcpStats.ptrIP {
+		return
+	}
+	tcpStats.ptrIP = ip
+	tcpStats.ptrNames = nil
+
+	ctx, cancel := context.WithTimeout(context.Background(), dnsTimeout)
+	defer cancel()
+
+	resolver := tcpStats.userInput.Resolver
+	if resolver == nil {
+		resolver = net.DefaultResolver
+	}
+
+	names, err := resolver.LookupAddr(ctx, ip.Unmap().String())
+	var dnsErr *net.DNSError
+	if errors.As(err, &dnsErr) && dnsErr.IsNotFound {
+		tcpStats.printer.PrintInfo("%s has no reverse DNS record", ip)
+		return
+	}
+	if err != nil {
+		tcpStats.printer.PrintError("Unable to resolve the reverse DNS of %s: %s", ip, err)
+		return
+	}
+
+	for _, name := range names {
+		tcpStats.ptrNames = append(tcpStats.ptrNames, strings.TrimSuffix(name, "."))
+	}
+
+	tcpStats.printer.PrintInfo("Reverse DNS of %s is %s", ip, strings.Join(tcpStats.ptrNames, ", "))
+}
@@ -0,0 +1,38 @@
+package tcping
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/net/dns/dnsmessage"
+)
+
+func TestReverseDNS(t *testing.T) {
+	resolver := serveTestDNSAnswers(t, func(q dnsmessage.Question) []dnsmessage.Resource {
+		if q.Type != dnsmessage.TypePTR || q.Name.String() != "3.0.0.127.in-addr.arpa." {
+			return nil
+		}
+
+		return []dnsmessage.Resource{{
+			Header: dnsmessage.ResourceHeader{Name: q.Name, Type: q.Type, Class: q.Class, TTL: 300},
+			Body:   &dnsmessage.PTRResource{PTR: dnsmessage.MustNewName("edge-3.example.test.")},
+		}}
+	})
+
+	p, err := New(Options{
+		Printer:               &dummyPrinter{},
+		Hostname:              "127.0.0.3",
+		Port:                  12345,
+		IntervalBetweenProbes: 2 * time.Millisecond,
+		Timeout:               time.Millisecond,
+		ProbesBeforeQuit:      1,
+		Resolver:              resolver,
+		ReverseDNS:            true,
+	})
+	assert.NoError(t, err)
+
+	p.Run()
+
+	assert.Equal(t, []string{"edge-3.example.test"}, p.Statistics().PTRNames)
+}
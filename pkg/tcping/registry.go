@@ -0,0 +1,122 @@
+package tcping
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/gookit/color"
+)
+
+// PrinterConfig holds the settings a [PrinterFactory] may need.
+// Printers ignore the settings that don't apply to them.
+type PrinterConfig struct {
+	// Hostname and Port of the target.
+	Hostname string
+	// DBPath is the path of the sqlite database used by the "database" printer.
+	DBPath string
+	Port   uint16
+	// PrettyJSON indents the output of the "json" printer.
+	PrettyJSON bool
+	// Elapsed prefixes the lines of the "plain" and the "journal" printers
+	// with the time elapsed since the printer was created, e.g. [+00:02:13].
+	Elapsed bool
+	// Output is where the "json" and the "journal" printers write,
+	// the stdout if nil. The "plain" printer only writes to the stdout.
+	Output io.Writer
+}
+
+// PrinterFactory creates a new [Printer] from the given config.
+type PrinterFactory func(cfg PrinterConfig) (Printer, error)
+
+var (
+	printersMu sync.RWMutex
+	printers   = make(map[string]PrinterFactory)
+)
+
+func init() {
+	RegisterPrinter("plain", func(cfg PrinterConfig) (Printer, error) {
+		if cfg.Output != nil {
+			return nil, errors.New("the plain printer only writes to the stdout")
+		}
+		if cfg.Elapsed {
+			// the plain printer prints with the colors' output
+			color.SetOutput(newElapsedWriter(os.Stdout))
+		}
+		return NewPlainPrinter(), nil
+	})
+	RegisterPrinter("json", func(cfg PrinterConfig) (Printer, error) {
+		if cfg.Output != nil {
+			return newJSONPrinter(cfg.Output, cfg.PrettyJSON), nil
+		}
+		return NewJSONPrinter(cfg.PrettyJSON), nil
+	})
+	RegisterPrinter("journal", func(cfg PrinterConfig) (Printer, error) {
+		p := &journalPrinter{w: cfg.Output}
+		if p.w == nil {
+			p.w = os.Stdout
+		}
+		if cfg.Elapsed {
+			p.start = time.Now()
+		}
+		return p, nil
+	})
+	RegisterPrinter("database", func(cfg PrinterConfig) (Printer, error) {
+		if cfg.DBPath == "" {
+			return nil, errors.New("the database printer requires a database path")
+		}
+		return NewDatabasePrinter(cfg.Hostname, cfg.Port, cfg.DBPath)
+	})
+}
+
+// RegisterPrinter makes a printer available by the provided name,
+// so that it can be selected with [NewPrinter].
+//
+// It is meant to be called from the init function of the package
+// implementing the printer. If RegisterPrinter is called twice
+// with the same name or if factory is nil, it panics.
+func RegisterPrinter(name string, factory PrinterFactory) {
+	printersMu.Lock()
+	defer printersMu.Unlock()
+
+	if factory == nil {
+		panic("tcping: RegisterPrinter factory is nil")
+	}
+
+	if _, dup := printers[name]; dup {
+		panic("tcping: RegisterPrinter called twice for printer " + name)
+	}
+
+	printers[name] = factory
+}
+
+// NewPrinter creates the printer registered under the given name.
+func NewPrinter(name string, cfg PrinterConfig) (Printer, error) {
+	printersMu.RLock()
+	factory, ok := printers[name]
+	printersMu.RUnlock()
+
+	if !ok {
+		return nil, fmt.Errorf("unknown printer %q", name)
+	}
+
+	return factory(cfg)
+}
+
+// Printers returns a sorted list of the names of the registered printers.
+func Printers() []string {
+	printersMu.RLock()
+	defer printersMu.RUnlock()
+
+	names := make([]string, 0, len(printers))
+	for name := range printers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
@@ -0,0 +1,55 @@
+package tcping
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRegisterPrinter(t *testing.T) {
+	RegisterPrinter("dummy", func(_ PrinterConfig) (Printer, error) {
+		return &dummyPrinter{}, nil
+	})
+	t.Cleanup(func() {
+		printersMu.Lock()
+		delete(printers, "dummy")
+		printersMu.Unlock()
+	})
+
+	assert.Equal(t, []string{"database", "dummy", "journal", "json", "plain"}, Printers())
+
+	p, err := NewPrinter("dummy", PrinterConfig{})
+	assert.NoError(t, err)
+	assert.IsType(t, &dummyPrinter{}, p)
+
+	assert.Panics(t, func() {
+		RegisterPrinter("dummy", func(_ PrinterConfig) (Printer, error) {
+			return &dummyPrinter{}, nil
+		})
+	})
+	assert.Panics(t, func() {
+		RegisterPrinter("nil", nil)
+	})
+}
+
+func TestNewPrinter(t *testing.T) {
+	_, err := NewPrinter("unknown", PrinterConfig{})
+	assert.EqualError(t, err, `unknown printer "unknown"`)
+
+	_, err = NewPrinter("database", PrinterConfig{Hostname: "localhost", Port: 8001})
+	assert.Error(t, err)
+
+	p, err := NewPrinter("json", PrinterConfig{PrettyJSON: true})
+	assert.NoError(t, err)
+	assert.IsType(t, &jsonPrinter{}, p)
+
+	var out strings.Builder
+	p, err = NewPrinter("journal", PrinterConfig{Output: &out})
+	assert.NoError(t, err)
+	p.PrintInfo("to %s", "the output")
+	assert.Equal(t, "<6>to the output\n", out.String())
+
+	_, err = NewPrinter("plain", PrinterConfig{Output: &out})
+	assert.EqualError(t, err, "the plain printer only writes to the stdout")
+}
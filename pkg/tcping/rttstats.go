@@ -0,0 +1,201 @@
+package tcping
+
+import (
+	"math"
+	"slices"
+)
+
+// defaultRecentRtts is the number of recent RTTs kept
+// when Options.RecentRTTs isn't set.
+const defaultRecentRtts = 10000
+
+// rttSketchAccuracy is the relative accuracy of the median and the trimmed
+// mean once the RTTs don't all fit in the recent ones anymore.
+const rttSketchAccuracy = 0.01
+
+var (
+	rttSketchGamma    = (1 + rttSketchAccuracy) / (1 - rttSketchAccuracy)
+	rttSketchLogGamma = math.Log(rttSketchGamma)
+)
+
+// rttStats aggregates RTTs, or any durations in milliseconds, in bounded
+// memory. The count, the sum, the min and the max are exact. The median
+// and the trimmed mean are exact as long as all the RTTs fit in the recent
+// ones, and approximated by a logarithmic sketch after that.
+// The zero value is ready to use.
+type rttStats struct {
+	count      uint
+	sum        float64
+	squaresSum float64
+	min        float32
+	max        float32
+	// buckets count the RTTs by the power of rttSketchGamma they round up to,
+	// zeros counts the ones that can't be bucketed.
+	buckets map[int]uint
+	zeros   uint
+	// recent is a ring buffer of the last RTTs, next is where the next one goes.
+	recent []float32
+	next   int
+	// keep is the size of recent, defaultRecentRtts if 0.
+	keep uint
+}
+
+// add aggregates the RTT.
+func (r *rttStats) add(rtt float32) {
+	if r.count == 0 || rtt < r.min {
+		r.min = rtt
+	}
+	if rtt > r.max {
+		r.max = rtt
+	}
+	r.count += 1
+	r.sum += float64(rtt)
+	r.squaresSum += float64(rtt) * float64(rtt)
+
+	if rtt > 0 {
+		if r.buckets == nil {
+			r.buckets = make(map[int]uint)
+		}
+		r.buckets[int(math.Ceil(math.Log(float64(rtt))/rttSketchLogGamma))] += 1
+	} else {
+		r.zeros += 1
+	}
+
+	keep := int(r.keep)
+	if keep == 0 {
+		keep = defaultRecentRtts
+	}
+	if len(r.recent) < keep {
+		r.recent = append(r.recent, rtt)
+		return
+	}
+	r.recent[r.next] = rtt
+	r.next = (r.next + 1) % keep
+}
+
+// mean returns the average of the RTTs and their standard deviation.
+func (r *rttStats) mean() (float64, float64) {
+	n := float64(r.count)
+	mean := r.sum / n
+
+	return mean, math.Sqrt(max(r.squaresSum/n-mean*mean, 0))
+}
+
+// results calculates min, avg, max and the other stats of the RTTs.
+func (r *rttStats) results() RttResult {
+	if r.count == 0 {
+		return RttResult{}
+	}
+
+	mean, stdDev := r.mean()
+	result := RttResult{
+		HasResults: true,
+		Min:        r.min,
+		Max:        r.max,
+		Average:    float32(mean),
+		StdDev:     float32(stdDev),
+	}
+
+	if r.count == uint(len(r.recent)) {
+		result.Median, result.TrimmedMean = calcMedianTrimmedMean(r.recent)
+	} else {
+		result.Median, result.TrimmedMean = r.sketchMedianTrimmedMean()
+	}
+
+	return result
+}
+
+// sketchMedianTrimmedMean approximates the median and the trimmed mean,
+// see calcMedianTrimmedMean, from the buckets.
+func (r *rttStats) sketchMedianTrimmedMean() (float32, float32) {
+	type bucket struct {
+		value float64
+		count uint
+	}
+
+	keys := make([]int, 0, len(r.buckets))
+	for k := range r.buckets {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+
+	sorted := []bucket{{0, r.zeros}}
+	for _, k := range keys {
+		// the middle of the bucket, in relative terms
+		value := 2 * math.Pow(rttSketchGamma, float64(k)) / (rttSketchGamma + 1)
+		sorted = append(sorted, bucket{value, r.buckets[k]})
+	}
+
+	// valueAt returns the value of the RTT with the given rank
+	valueAt := func(rank uint) float64 {
+		var seen uint
+		for _, b := range sorted {
+			seen += b.count
+			if rank < seen {
+				return b.value
+			}
+		}
+		return sorted[len(sorted)-1].value
+	}
+
+	n := r.count
+	median := valueAt(n / 2)
+	if n%2 == 0 {
+		median = (valueAt(n/2-1) + median) / 2
+	}
+
+	trim := n / 10
+	var sum float64
+	var seen uint
+	for _, b := range sorted {
+		// the ranks of the bucket within [trim, n-trim)
+		from, to := max(seen, trim), min(seen+b.count, n-trim)
+		if to > from {
+			sum += b.value * float64(to-from)
+		}
+		seen += b.count
+	}
+
+	return float32(median), float32(sum / float64(n-2*trim))
+}
+
+// keepRecentRtts sets how many recent RTTs of every kind are kept.
+func (tcpStats *stats) keepRecentRtts(keep uint) {
+	for _, r := range []*rttStats{
+		&tcpStats.rtt,
+		&tcpStats.kernelRtt,
+		&tcpStats.networkRtt,
+		&tcpStats.userRtts,
+		&tcpStats.tunnelSetups,
+		&tcpStats.burstRtts,
+		&tcpStats.tfoRtt,
+		&tcpStats.regularRtt,
+		&tcpStats.resolveTimes,
+		&tcpStats.responseTimes,
+		&tcpStats.goodputs,
+		&tcpStats.icmpRtt,
+	} {
+		r.keep = keep
+	}
+}
+
+// calcMedianTrimmedMean calculates the median of the RTTs and their
+// average without the lowest and the highest tenth of them.
+func calcMedianTrimmedMean(timeArr []float32) (float32, float32) {
+	sorted := slices.Clone(timeArr)
+	slices.Sort(sorted)
+
+	n := len(sorted)
+	median := sorted[n/2]
+	if n%2 == 0 {
+		median = (sorted[n/2-1] + sorted[n/2]) / 2
+	}
+
+	trim := n / 10
+	var sum float32
+	for _, rtt := range sorted[trim : n-trim] {
+		sum += rtt
+	}
+
+	return median, sum / float32(n-2*trim)
+}
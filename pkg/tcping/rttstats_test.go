@@ -0,0 +1,39 @@
+package tcping
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRttStats(t *testing.T) {
+	var r rttStats
+	for _, rtt := range []float32{3, 1, 2, 4, 5, 6, 7, 8, 9, 3000} {
+		r.add(rtt)
+	}
+	res := r.results()
+
+	assert.Equal(t, float32(1), res.Min)
+	assert.Equal(t, float32(3000), res.Max)
+	assert.InDelta(t, 304.5, res.Average, 0.001)
+	assert.Equal(t, float32(5.5), res.Median)
+	// the 1 and the 3000 are trimmed
+	assert.Equal(t, float32(5.5), res.TrimmedMean)
+	assert.InDelta(t, 898.5, res.StdDev, 0.1)
+}
+
+func TestRttStatsSketch(t *testing.T) {
+	r := rttStats{keep: 100}
+	for i := 1; i <= 1000; i++ {
+		r.add(float32(i))
+	}
+	res := r.results()
+
+	assert.Len(t, r.recent, 100)
+	assert.Equal(t, uint(1000), r.count)
+	assert.Equal(t, float32(1), res.Min)
+	assert.Equal(t, float32(1000), res.Max)
+	assert.Equal(t, float32(500.5), res.Average)
+	assert.InEpsilon(t, 500.5, res.Median, rttSketchAccuracy)
+	assert.InEpsilon(t, 500.5, res.TrimmedMean, rttSketchAccuracy)
+}
@@ -0,0 +1,96 @@
+package tcping
+
+import (
+	"encoding/csv"
+	"io"
+	"net/netip"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// sampleSuccess is the outcome of the successful samples,
+// the failed ones have their FailureReason instead.
+const sampleSuccess = "success"
+
+// sampleHeader is the first row of the samples files.
+var sampleHeader = []string{"timestamp", "ip", "port", "outcome", "rtt_ms"}
+
+// Sample is the raw data of a single probe.
+type Sample struct {
+	Time    time.Time
+	IP      netip.Addr
+	Port    uint16
+	Success bool
+	// FailureReason is only set for the failed probes.
+	FailureReason FailureReason
+	// RTT in milliseconds, only set for the successful probes.
+	RTT float32
+}
+
+// SampleFromResult returns the raw data of the probe.
+func SampleFromResult(r Result) Sample {
+	return Sample{
+		Time:          r.Time,
+		IP:            r.IP,
+		Port:          r.Port,
+		Success:       r.Success,
+		FailureReason: r.FailureReason,
+		RTT:           r.RTT,
+	}
+}
+
+// SampleWriter writes the samples as CSV rows of the timestamp, the IP,
+// the port, the outcome and the RTT, e.g. for offline analysis.
+// They can be read back with [ReadSamples].
+// It's safe for concurrent use.
+type SampleWriter struct {
+	mu sync.Mutex
+	w  *csv.Writer
+}
+
+// NewSampleWriter writes the header of the samples to w
+// and returns a SampleWriter appending the samples to it.
+func NewSampleWriter(w io.Writer) (*SampleWriter, error) {
+	sw := &SampleWriter{w: csv.NewWriter(w)}
+	if err := sw.writeRow(sampleHeader); err != nil {
+		return nil, err
+	}
+
+	return sw, nil
+}
+
+// Write writes the sample right away,
+// so that it isn't lost if tcping is killed.
+func (sw *SampleWriter) Write(s Sample) error {
+	outcome, rtt := sampleSuccess, strconv.FormatFloat(float64(s.RTT), 'f', 3, 32)
+	if !s.Success {
+		outcome, rtt = string(s.FailureReason), ""
+		if outcome == "" {
+			outcome = string(FailureOther)
+		}
+	}
+
+	ip := ""
+	if s.IP.IsValid() {
+		ip = s.IP.String()
+	}
+
+	return sw.writeRow([]string{
+		s.Time.Format(time.RFC3339Nano),
+		ip,
+		strconv.FormatUint(uint64(s.Port), 10),
+		outcome,
+		rtt,
+	})
+}
+
+func (sw *SampleWriter) writeRow(row []string) error {
+	sw.mu.Lock()
+	defer sw.mu.Unlock()
+
+	sw.w.Write(row)
+	sw.w.Flush()
+
+	return sw.w.Error()
+}
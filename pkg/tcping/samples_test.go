@@ -0,0 +1,27 @@
+package tcping
+
+import (
+	"bytes"
+	"net/netip"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSampleWriter(t *testing.T) {
+	var buf bytes.Buffer
+	w, err := NewSampleWriter(&buf)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	when := time.Date(2023, 9, 10, 12, 30, 0, 0, time.UTC)
+	ip := netip.MustParseAddr("127.0.0.1")
+	assert.NoError(t, w.Write(SampleFromResult(Result{Time: when, IP: ip, RTT: 1.5, Success: true})))
+	assert.NoError(t, w.Write(SampleFromResult(Result{Time: when.Add(time.Second), IP: ip, FailureReason: FailureRefused})))
+
+	assert.Equal(t, "timestamp,ip,port,outcome,rtt_ms\n"+
+		"2023-09-10T12:30:00Z,127.0.0.1,0,success,1.500\n"+
+		"2023-09-10T12:30:01Z,127.0.0.1,0,refused,\n", buf.String())
+}
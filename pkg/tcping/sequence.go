@@ -0,0 +1,37 @@
+package tcping
+
+import "fmt"
+
+// seq returns the sequence number of the last probe, starting at 1.
+func (tcpStats *stats) seq() uint {
+	return tcpStats.totalSuccessfulProbes + tcpStats.totalUnsuccessfulProbes
+}
+
+// formatSeq formats the sequence number for the probe lines,
+// the probes printed without one have none.
+func formatSeq(seq uint) string {
+	if seq == 0 {
+		return ""
+	}
+
+	return fmt.Sprintf(" seq=%d", seq)
+}
+
+// formatProbe formats the line of the probe with its sequence number,
+// so that gaps and reordering are detectable once the output is shipped
+// elsewhere, e.g. "Reply from 127.0.0.1 on port 80 seq=1 TCP_conn=1 time=0.123 ms".
+func formatProbe(r Result) string {
+	target := r.IP.String()
+	if r.Hostname != "" {
+		target = fmt.Sprintf("%s (%s)", r.Hostname, r.IP)
+	}
+
+	switch {
+	case !r.Success:
+		return fmt.Sprintf("No reply from %s on port %d%s TCP_conn=%d", target, r.Port, formatSeq(r.Seq), r.Streak)
+	case r.Degraded:
+		return fmt.Sprintf("Degraded reply from %s on port %d%s TCP_conn=%d time=%.3f ms", target, r.Port, formatSeq(r.Seq), r.Streak, r.RTT)
+	default:
+		return fmt.Sprintf("Reply from %s on port %d%s TCP_conn=%d time=%.3f ms", target, r.Port, formatSeq(r.Seq), r.Streak, r.RTT)
+	}
+}
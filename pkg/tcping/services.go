@@ -0,0 +1,130 @@
+package tcping
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// wellKnownServices are the names of the TCP ports
+// printed when Options.Services doesn't name them.
+var wellKnownServices = map[uint16]string{
+	21:    "ftp",
+	22:    "ssh",
+	23:    "telnet",
+	25:    "smtp",
+	53:    "domain",
+	80:    "http",
+	110:   "pop3",
+	111:   "sunrpc",
+	143:   "imap",
+	179:   "bgp",
+	389:   "ldap",
+	443:   "https",
+	445:   "microsoft-ds",
+	465:   "submissions",
+	587:   "submission",
+	636:   "ldaps",
+	853:   "domain-s",
+	873:   "rsync",
+	993:   "imaps",
+	995:   "pop3s",
+	1433:  "ms-sql-s",
+	1883:  "mqtt",
+	2049:  "nfs",
+	2181:  "zookeeper",
+	2379:  "etcd-client",
+	3306:  "mysql",
+	3389:  "ms-wbt-server",
+	5060:  "sip",
+	5432:  "postgresql",
+	5671:  "amqps",
+	5672:  "amqp",
+	5900:  "vnc",
+	6379:  "redis",
+	8080:  "http-alt",
+	8443:  "https-alt",
+	8883:  "secure-mqtt",
+	9092:  "kafka",
+	9200:  "elasticsearch",
+	11211: "memcache",
+	27017: "mongodb",
+}
+
+// ServicePrinter is implemented by the printers that print the name of
+// the service of the port, see Options.Services, e.g. 443 (https).
+// The other printers get PrintStart instead.
+type ServicePrinter interface {
+	// PrintStartService is called instead of PrintStart
+	// when the port has a name.
+	PrintStartService(hostname string, port uint16, service string)
+}
+
+// ReadServices reads the names of the TCP ports in the format of
+// /etc/services, e.g. "billing 8081/tcp", for Options.Services.
+// The aliases and the ports of the other protocols are ignored.
+func ReadServices(r io.Reader) (map[uint16]string, error) {
+	services := make(map[uint16]string)
+
+	scanner := bufio.NewScanner(r)
+	for n := 1; scanner.Scan(); n++ {
+		line, _, _ := strings.Cut(scanner.Text(), "#")
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		if len(fields) < 2 {
+			return nil, fmt.Errorf("line %d: no port for %s", n, fields[0])
+		}
+
+		portNumber, proto, ok := strings.Cut(fields[1], "/")
+		if !ok {
+			return nil, fmt.Errorf("line %d: no protocol in %s", n, fields[1])
+		}
+		port, err := strconv.ParseUint(portNumber, 10, 16)
+		if err != nil || port == 0 {
+			return nil, fmt.Errorf("line %d: invalid port %s", n, portNumber)
+		}
+
+		// the first name of a port wins, like in /etc/services
+		if _, dup := services[uint16(port)]; proto == "tcp" && !dup {
+			services[uint16(port)] = fields[0]
+		}
+	}
+
+	return services, scanner.Err()
+}
+
+// service returns the name of the service of the port, if it has one.
+func (u userInput) service() string {
+	if name, ok := u.Services[u.Port]; ok {
+		return name
+	}
+	return wellKnownServices[u.Port]
+}
+
+// printStart prints the first message, with the name of the service of the port.
+func (tcpStats *stats) printStart() {
+	hostname := tcpStats.userInput.Hostname
+	port := tcpStats.userInput.Port
+	service := tcpStats.userInput.service()
+
+	tcpStats.print(func(printer Printer) {
+		if p, ok := printer.(ServicePrinter); ok && service != "" {
+			p.PrintStartService(hostname, port, service)
+			return
+		}
+
+		printer.PrintStart(hostname, port)
+	})
+}
+
+// formatPort formats the port for the output, with the name of its service.
+func formatPort(port uint16, service string) string {
+	if service == "" {
+		return strconv.Itoa(int(port))
+	}
+	return fmt.Sprintf("%d (%s)", port, service)
+}
@@ -0,0 +1,46 @@
+package tcping
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReadServices(t *testing.T) {
+	services, err := ReadServices(strings.NewReader(`# internal services
+billing     8081/tcp    invoices # the aliases are ignored
+billing-udp 8081/udp
+metrics     9100/tcp
+exporter    9100/tcp
+`))
+	assert.NoError(t, err)
+	assert.Equal(t, map[uint16]string{8081: "billing", 9100: "metrics"}, services)
+
+	_, err = ReadServices(strings.NewReader("billing 8081\n"))
+	assert.ErrorContains(t, err, "line 1: no protocol")
+
+	_, err = ReadServices(strings.NewReader("\nbilling 99999/tcp\n"))
+	assert.ErrorContains(t, err, "line 2: invalid port")
+}
+
+func TestService(t *testing.T) {
+	var out strings.Builder
+	stats := createTestStats(t)
+	stats.printer = &journalPrinter{w: &out}
+
+	// the ports of the user override the well-known ones
+	stats.userInput.Services = map[uint16]string{12345: "billing"}
+	assert.Equal(t, "billing", stats.userInput.service())
+	stats.userInput.Port = 443
+	assert.Equal(t, "https", stats.userInput.service())
+	stats.userInput.Port = 8081
+	assert.Empty(t, stats.userInput.service())
+
+	stats.userInput.Hostname = "example.com"
+	stats.printStart()
+	stats.userInput.Port = 443
+	stats.printStart()
+	assert.Equal(t, "<6>TCPinging example.com on port 8081\n"+
+		"<6>TCPinging example.com on port 443 (https)\n", out.String())
+}
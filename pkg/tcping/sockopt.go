@@ -0,0 +1,70 @@
+package tcping
+
+import (
+	"math"
+	"net"
+	"syscall"
+)
+
+// control sets the options of the socket of a probe before it connects,
+// it's the Control of the dialers of the probes.
+func (tcpStats *stats) control(_, _ string, c syscall.RawConn) error {
+	mark, device := tcpStats.userInput.Mark, tcpStats.userInput.BindDevice
+	if mark == 0 && device == "" {
+		return nil
+	}
+
+	var sockErr error
+	err := c.Control(func(fd uintptr) {
+		if mark != 0 {
+			if sockErr = setMark(fd, mark); sockErr != nil {
+				return
+			}
+		}
+		if device != "" {
+			sockErr = bindToDevice(fd, device)
+		}
+	})
+	if err != nil {
+		return err
+	}
+
+	return sockErr
+}
+
+// inNetns calls fn from within Options.Netns, if set, so that the
+// sockets it creates send the probes from there.
+func (tcpStats *stats) inNetns(fn func() error) error {
+	if tcpStats.userInput.netns == nil {
+		return fn()
+	}
+
+	return inNetns(tcpStats.userInput.netns, fn)
+}
+
+// setSocketOptions applies Options.Nagle and Options.Linger
+// to the connection of a successful probe.
+//
+// Unlike the options set before connecting, they're set on the
+// connection, as Go sets TCP_NODELAY once it's established.
+func (tcpStats *stats) setSocketOptions(conn net.Conn) error {
+	tcpConn, ok := conn.(*net.TCPConn)
+	if !ok {
+		return nil
+	}
+
+	if tcpStats.userInput.Nagle {
+		if err := tcpConn.SetNoDelay(false); err != nil {
+			return err
+		}
+	}
+
+	switch linger := tcpStats.userInput.Linger; {
+	case linger < 0:
+		return tcpConn.SetLinger(0)
+	case linger > 0:
+		return tcpConn.SetLinger(int(math.Ceil(linger.Seconds())))
+	}
+
+	return nil
+}
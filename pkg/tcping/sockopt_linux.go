@@ -0,0 +1,19 @@
+//go:build linux
+
+package tcping
+
+import "golang.org/x/sys/unix"
+
+// socketOptionsSupported reports whether SO_MARK
+// and SO_BINDTODEVICE can be set on this platform.
+const socketOptionsSupported = true
+
+// setMark sets SO_MARK on the socket, see Options.Mark.
+func setMark(fd uintptr, mark uint32) error {
+	return unix.SetsockoptInt(int(fd), unix.SOL_SOCKET, unix.SO_MARK, int(mark))
+}
+
+// bindToDevice sets SO_BINDTODEVICE on the socket, see Options.BindDevice.
+func bindToDevice(fd uintptr, device string) error {
+	return unix.BindToDevice(int(fd), device)
+}
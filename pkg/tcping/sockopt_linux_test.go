@@ -0,0 +1,142 @@
+package tcping
+
+import (
+	"errors"
+	"net"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/sys/unix"
+)
+
+// sockopts reads TCP_NODELAY and SO_LINGER from the connection.
+func sockopts(t *testing.T, conn net.Conn) (int, *unix.Linger) {
+	raw, err := conn.(*net.TCPConn).SyscallConn()
+	if err != nil {
+		t.Fatalf("syscall conn: %v", err)
+	}
+
+	var noDelay int
+	var linger *unix.Linger
+	var sockErr error
+	raw.Control(func(fd uintptr) {
+		if noDelay, sockErr = unix.GetsockoptInt(int(fd), unix.IPPROTO_TCP, unix.TCP_NODELAY); sockErr != nil {
+			return
+		}
+		linger, sockErr = unix.GetsockoptLinger(int(fd), syscall.SOL_SOCKET, syscall.SO_LINGER)
+	})
+	if sockErr != nil {
+		t.Fatalf("getsockopt: %v", sockErr)
+	}
+
+	return noDelay, linger
+}
+
+func TestSetSocketOptions(t *testing.T) {
+	srv := testServerListen(t)
+	t.Cleanup(func() { srv.Close() })
+
+	tests := []struct {
+		name        string
+		nagle       bool
+		linger      time.Duration
+		wantNoDelay int
+		wantLinger  unix.Linger
+	}{
+		{name: "defaults", wantNoDelay: 1},
+		{name: "nagle", nagle: true},
+		{name: "linger", linger: 1500 * time.Millisecond, wantNoDelay: 1, wantLinger: unix.Linger{Onoff: 1, Linger: 2}},
+		{name: "reset", linger: -1, wantNoDelay: 1, wantLinger: unix.Linger{Onoff: 1}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conn, err := net.Dial("tcp", "127.0.0.1:12345")
+			if err != nil {
+				t.Fatalf("dial: %v", err)
+			}
+			defer conn.Close()
+
+			stats := createTestStats(t)
+			stats.userInput.Nagle = tt.nagle
+			stats.userInput.Linger = tt.linger
+			assert.NoError(t, stats.setSocketOptions(conn))
+
+			noDelay, linger := sockopts(t, conn)
+			assert.Equal(t, tt.wantNoDelay, noDelay)
+			assert.Equal(t, tt.wantLinger, *linger)
+		})
+	}
+}
+
+func TestMark(t *testing.T) {
+	srv := testServerListen(t)
+	t.Cleanup(func() { srv.Close() })
+
+	stats := createTestStats(t)
+	stats.userInput.Mark = 42
+
+	d := net.Dialer{Control: stats.control}
+	conn, err := d.Dial("tcp", "127.0.0.1:12345")
+	if errors.Is(err, syscall.EPERM) {
+		t.Skip("setting SO_MARK needs CAP_NET_ADMIN")
+	}
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	defer conn.Close()
+
+	raw, err := conn.(*net.TCPConn).SyscallConn()
+	if err != nil {
+		t.Fatalf("syscall conn: %v", err)
+	}
+
+	var mark int
+	var sockErr error
+	raw.Control(func(fd uintptr) {
+		mark, sockErr = unix.GetsockoptInt(int(fd), unix.SOL_SOCKET, unix.SO_MARK)
+	})
+	assert.NoError(t, sockErr)
+	assert.Equal(t, 42, mark)
+}
+
+func TestBindDevice(t *testing.T) {
+	srv := testServerListen(t)
+	t.Cleanup(func() { srv.Close() })
+
+	stats := createTestStats(t)
+	stats.userInput.BindDevice = "lo"
+
+	d := net.Dialer{Control: stats.control}
+	conn, err := d.Dial("tcp", "127.0.0.1:12345")
+	if errors.Is(err, syscall.EPERM) {
+		t.Skip("binding to a device needs CAP_NET_RAW")
+	}
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	defer conn.Close()
+
+	raw, err := conn.(*net.TCPConn).SyscallConn()
+	if err != nil {
+		t.Fatalf("syscall conn: %v", err)
+	}
+
+	var device string
+	var sockErr error
+	raw.Control(func(fd uintptr) {
+		device, sockErr = unix.GetsockoptString(int(fd), unix.SOL_SOCKET, unix.SO_BINDTODEVICE)
+	})
+	assert.NoError(t, sockErr)
+	assert.Equal(t, "lo", device)
+
+	_, err = New(Options{
+		Printer:    &dummyPrinter{},
+		Hostname:   "127.0.0.1",
+		Port:       12345,
+		BindDevice: "nonexistent0",
+	})
+	assert.EqualError(t, err, "interface nonexistent0 not found")
+}
@@ -0,0 +1,17 @@
+//go:build !linux
+
+package tcping
+
+import "errors"
+
+// socketOptionsSupported reports whether SO_MARK
+// and SO_BINDTODEVICE can be set on this platform.
+const socketOptionsSupported = false
+
+func setMark(_ uintptr, _ uint32) error {
+	return errors.New("SO_MARK is only supported on Linux")
+}
+
+func bindToDevice(_ uintptr, _ string) error {
+	return errors.New("SO_BINDTODEVICE is only supported on Linux")
+}
@@ -0,0 +1,1274 @@
+package tcping
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"net/netip"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/gookit/color"
+)
+
+const (
+	timeFormat = "2006-01-02 15:04:05"
+	hourFormat = "15:04:05"
+)
+
+var (
+	colorYellow      = color.Yellow.Printf
+	colorGreen       = color.Green.Printf
+	colorRed         = color.Red.Printf
+	colorCyan        = color.Cyan.Printf
+	colorLightYellow = color.LightYellow.Printf
+	colorLightBlue   = color.FgLightBlue.Printf
+	colorLightGreen  = color.LightGreen.Printf
+	colorLightCyan   = color.LightCyan.Printf
+)
+
+type planePrinter struct{}
+
+// NewPlainPrinter returns a printer that prints
+// colored human-readable messages to the stdout.
+func NewPlainPrinter() Printer {
+	return &planePrinter{}
+}
+
+func (p *planePrinter) PrintStart(hostname string, port uint16) {
+	colorLightCyan("TCPinging %s on port %d\n", hostname, port)
+}
+
+// PrintStartService prints the first message with the name of the service of the port.
+func (p *planePrinter) PrintStartService(hostname string, port uint16, service string) {
+	colorLightCyan("TCPinging %s on port %s\n", hostname, formatPort(port, service))
+}
+
+func (p *planePrinter) PrintStatistics(s Statistics) {
+	totalPackets := s.TotalSuccessfulProbes + s.TotalUnsuccessfulProbes
+	packetLoss := (float32(s.TotalUnsuccessfulProbes) / float32(totalPackets)) * 100
+
+	if math.IsNaN(float64(packetLoss)) {
+		packetLoss = 0
+	}
+
+	/* general stats */
+	if !s.IsIP {
+		colorYellow("\n--- %s (%s) TCPing statistics ---\n", s.Hostname, s.IP)
+	} else {
+		colorYellow("\n--- %s TCPing statistics ---\n", s.Hostname)
+	}
+	if len(s.PTRNames) > 0 {
+		colorYellow("reverse DNS: ")
+		colorLightBlue("%s\n", strings.Join(s.PTRNames, ", "))
+	}
+	colorYellow("%d probes transmitted on port %s | ", totalPackets, formatPort(s.Port, s.Service))
+	colorYellow("%d received, ", s.TotalSuccessfulProbes)
+
+	/* packet loss stats */
+	if packetLoss == 0 {
+		colorGreen("%.2f%%", packetLoss)
+	} else if packetLoss > 0 && packetLoss <= 30 {
+		colorLightYellow("%.2f%%", packetLoss)
+	} else {
+		colorRed("%.2f%%", packetLoss)
+	}
+
+	colorYellow(" packet loss\n")
+
+	/* successful packet stats */
+	colorYellow("successful probes:   ")
+	colorGreen("%d\n", s.TotalSuccessfulProbes)
+
+	/* unsuccessful packet stats */
+	colorYellow("unsuccessful probes: ")
+	colorRed("%d\n", s.TotalUnsuccessfulProbes)
+
+	if len(s.FailureReasons) > 0 {
+		colorYellow("failure reasons: ")
+		colorRed("%s\n", formatCounts(s.FailureReasons))
+	}
+
+	if len(s.PeerCloses) > 0 {
+		colorYellow("connections ended by the peer: ")
+		colorLightYellow("%s\n", formatCounts(s.PeerCloses))
+	}
+
+	if s.RetriedProbes > 0 {
+		colorYellow("probes that succeeded on a retry: ")
+		colorLightYellow("%d\n", s.RetriedProbes)
+	}
+
+	if s.BurstFailures > 0 {
+		colorYellow("failed connections of the bursts: ")
+		colorLightYellow("%d\n", s.BurstFailures)
+	}
+
+	colorYellow("last successful probe:   ")
+	if s.LastSuccessfulProbe.IsZero() {
+		colorRed("Never succeeded\n")
+	} else {
+		colorGreen("%v\n", s.LastSuccessfulProbe.Format(timeFormat))
+	}
+
+	colorYellow("last unsuccessful probe: ")
+	if s.LastUnsuccessfulProbe.IsZero() {
+		colorGreen("Never failed\n")
+	} else {
+		colorRed("%v\n", s.LastUnsuccessfulProbe.Format(timeFormat))
+	}
+
+	/* uptime and downtime stats */
+	colorYellow("total uptime: ")
+	colorGreen("  %s\n", DurationToString(s.TotalUptime))
+	colorYellow("total downtime: ")
+	colorRed("%s\n", DurationToString(s.TotalDowntime))
+
+	/* availability stats */
+	if s.TotalUptime+s.TotalDowntime > 0 {
+		colorYellow("availability: ")
+		printAvailability(s.Availability)
+		colorYellow("\n")
+	}
+
+	if len(s.AvailabilityWindows) > 0 {
+		colorYellow("availability per window:\n")
+		colorYellow("  %-19s  %8s  %8s  %12s  %s\n", "start", "probes", "failed", "avg rtt", "availability")
+		for _, w := range s.AvailabilityWindows {
+			colorLightBlue("  %s  ", w.Start.Format(timeFormat))
+			colorYellow("%8d  ", w.SuccessfulProbes+w.UnsuccessfulProbes)
+			if w.UnsuccessfulProbes == 0 {
+				colorGreen("%8d  ", w.UnsuccessfulProbes)
+			} else {
+				colorRed("%8d  ", w.UnsuccessfulProbes)
+			}
+			if w.SuccessfulProbes == 0 {
+				colorYellow("%12s  ", "-")
+			} else {
+				colorCyan("%9.3f ms  ", w.AverageRTT)
+			}
+			printAvailability(w.Availability())
+			colorYellow("\n")
+		}
+	}
+
+	/* longest uptime stats */
+	if s.LongestUptime.Duration != 0 {
+		uptime := DurationToString(s.LongestUptime.Duration)
+
+		colorYellow("longest consecutive uptime:   ")
+		colorGreen("%v ", uptime)
+		colorYellow("from ")
+		colorLightBlue("%v ", s.LongestUptime.Start.Format(timeFormat))
+		colorYellow("to ")
+		colorLightBlue("%v\n", s.LongestUptime.End.Format(timeFormat))
+	}
+
+	/* longest downtime stats */
+	if s.LongestDowntime.Duration != 0 {
+		downtime := DurationToString(s.LongestDowntime.Duration)
+
+		colorYellow("longest consecutive downtime: ")
+		colorRed("%v ", downtime)
+		colorYellow("from ")
+		colorLightBlue("%v ", s.LongestDowntime.Start.Format(timeFormat))
+		colorYellow("to ")
+		colorLightBlue("%v\n", s.LongestDowntime.End.Format(timeFormat))
+	}
+
+	/* resolve retry stats */
+	if !s.IsIP {
+		colorYellow("retried to resolve hostname ")
+		colorRed("%d ", s.RetriedHostnameLookups)
+		colorYellow("times\n")
+
+		if s.DNSFailures > 0 {
+			colorYellow("DNS failures: ")
+			colorRed("%d", s.DNSFailures)
+			colorYellow(", last at ")
+			colorLightBlue("%v\n", s.LastDNSFailure.Format(timeFormat))
+		}
+
+		if s.Failovers > 0 {
+			colorYellow("failed over to the next address ")
+			colorRed("%d ", s.Failovers)
+			colorYellow("times\n")
+		}
+
+		if len(s.HostnameChanges) >= 2 {
+			colorYellow("IP address changes:\n")
+			for i := 0; i < len(s.HostnameChanges)-1; i++ {
+				colorYellow("  from ")
+				colorRed(s.HostnameChanges[i].Addr.String())
+				colorYellow(" to ")
+				colorGreen(s.HostnameChanges[i+1].Addr.String())
+				colorYellow(" at ")
+				colorLightBlue("%v\n", s.HostnameChanges[i+1].When.Format(timeFormat))
+			}
+		}
+	}
+
+	if s.RttResults.HasResults {
+		colorYellow("rtt ")
+		colorGreen("min")
+		colorYellow("/")
+		colorCyan("avg")
+		colorYellow("/")
+		colorRed("max: ")
+		colorGreen("%.3f", s.RttResults.Min)
+		colorYellow("/")
+		colorCyan("%.3f", s.RttResults.Average)
+		colorYellow("/")
+		colorRed("%.3f", s.RttResults.Max)
+		colorYellow(" ms\n")
+
+		colorYellow("rtt median/trimmed mean/stddev: ")
+		colorCyan("%.3f", s.RttResults.Median)
+		colorYellow("/")
+		colorCyan("%.3f", s.RttResults.TrimmedMean)
+		colorYellow("/")
+		colorCyan("%.3f", s.RttResults.StdDev)
+		colorYellow(" ms\n")
+
+		colorYellow("smoothed rtt: ")
+		colorCyan("%.3f", s.SmoothedRTT)
+		colorYellow(" ms\n")
+	}
+
+	if len(s.PerIP) > 0 {
+		printPerIP(s.PerIP)
+	}
+
+	if s.Outliers > 0 {
+		colorYellow("outliers: ")
+		colorRed("%d\n", s.Outliers)
+	}
+
+	if s.LatencyTrends > 0 {
+		colorYellow("latency trends: ")
+		colorRed("%d\n", s.LatencyTrends)
+	}
+
+	if s.DegradedProbes > 0 {
+		colorYellow("degraded probes: ")
+		colorRed("%d\n", s.DegradedProbes)
+	}
+
+	if s.KernelRttResults.HasResults {
+		printMinAvgMax("kernel srtt", s.KernelRttResults)
+	}
+
+	if s.Retransmits > 0 {
+		colorYellow("retransmitted segments: ")
+		colorRed("%d\n", s.Retransmits)
+	}
+
+	if s.NetworkRttResults.HasResults {
+		printMinAvgMax("wire rtt", s.NetworkRttResults)
+	}
+
+	if s.UserRttResults.HasResults {
+		printMinAvgMax("userspace rtt", s.UserRttResults)
+	}
+
+	if s.TunnelSetupResults.HasResults {
+		printMinAvgMax("tunnel setup", s.TunnelSetupResults)
+	}
+
+	if s.BurstRttResults.HasResults {
+		printMinAvgMax("burst rtt", s.BurstRttResults)
+	}
+
+	if s.ResponseTimeResults.HasResults {
+		printMinAvgMax("response time", s.ResponseTimeResults)
+	}
+
+	if s.GoodputResults.HasResults {
+		printMinAvgMaxIn("goodput", s.GoodputResults, "Mbit/s")
+	}
+
+	if s.TFORttResults.HasResults || s.RegularRttResults.HasResults {
+		colorYellow("TCP Fast Open accepted on ")
+		colorGreen("%d", s.TFOAcceptedProbes)
+		colorYellow(" of ")
+		colorLightBlue("%d", s.TotalSuccessfulProbes)
+		colorYellow(" successful probes\n")
+
+		if s.TFORttResults.HasResults {
+			printMinAvgMax("fast open rtt", s.TFORttResults)
+		}
+
+		if s.RegularRttResults.HasResults {
+			printMinAvgMax("regular rtt", s.RegularRttResults)
+		}
+	}
+
+	if !s.CertExpiry.IsZero() {
+		colorYellow("certificate chain expires on ")
+		colorLightBlue(s.CertExpiry.Format(timeFormat))
+		colorYellow(", in ")
+		colorRed("%d", certDaysRemaining(s.CertExpiry))
+		colorYellow(" days\n")
+	}
+
+	if s.MPTCPProbes > 0 {
+		colorYellow("MPTCP negotiated on ")
+		colorGreen("%d", s.MPTCPProbes)
+		colorYellow(" of ")
+		colorLightBlue("%d", s.TotalSuccessfulProbes)
+		colorYellow(" successful probes\n")
+	}
+
+	if s.ICMPRttResults.HasResults {
+		printMinAvgMax("icmp rtt", s.ICMPRttResults)
+	}
+
+	if s.ICMPLostEchoes > 0 {
+		colorYellow("ICMP echoes lost: ")
+		colorRed("%d\n", s.ICMPLostEchoes)
+	}
+
+	if s.PathMTU > 0 {
+		colorYellow("path MTU: ")
+		colorGreen("%d", s.PathMTU)
+		colorYellow(" bytes\n")
+	}
+
+	if !s.IsIP && s.ResolveTimeResults.HasResults {
+		printMinAvgMax("hostname resolution", s.ResolveTimeResults)
+	}
+
+	colorYellow("--------------------------------------\n")
+	colorYellow("TCPing started at: %v\n", s.StartTime.Format(timeFormat))
+
+	/* If the program was not terminated, no need to show the end time */
+	if !s.EndTime.IsZero() {
+		colorYellow("TCPing ended at:   %v\n", s.EndTime.Format(timeFormat))
+	}
+
+	durationTime := time.Time{}.Add(s.TotalDowntime + s.TotalUptime)
+	colorYellow("duration (HH:MM:SS): %v\n\n", durationTime.Format(hourFormat))
+}
+
+// printMinAvgMax prints a min/avg/max line of the statistics.
+func printMinAvgMax(label string, r RttResult) {
+	printMinAvgMaxIn(label, r, "ms")
+}
+
+// printMinAvgMaxIn prints a min/avg/max line of the statistics in the unit.
+func printMinAvgMaxIn(label string, r RttResult, unit string) {
+	colorYellow("%s ", label)
+	colorGreen("min")
+	colorYellow("/")
+	colorCyan("avg")
+	colorYellow("/")
+	colorRed("max: ")
+	colorGreen("%.3f", r.Min)
+	colorYellow("/")
+	colorCyan("%.3f", r.Average)
+	colorYellow("/")
+	colorRed("%.3f", r.Max)
+	colorYellow(" %s\n", unit)
+}
+
+// printPerIP prints the probes of every probed address,
+// so that the RTTs of different backends can be told apart.
+func printPerIP(perIP []IPStatistics) {
+	width := len("address")
+	for _, ip := range perIP {
+		width = max(width, len(ip.IP.String()))
+	}
+
+	colorYellow("statistics per address:\n")
+	colorYellow("  %-*s  %8s  %8s  %s\n", width, "address", "probes", "failed", "rtt min/avg/max")
+	for _, ip := range perIP {
+		colorLightBlue("  %-*s  ", width, ip.IP)
+		colorYellow("%8d  ", ip.SuccessfulProbes+ip.UnsuccessfulProbes)
+		if ip.UnsuccessfulProbes == 0 {
+			colorGreen("%8d  ", ip.UnsuccessfulProbes)
+		} else {
+			colorRed("%8d  ", ip.UnsuccessfulProbes)
+		}
+		if r := ip.RttResults; r.HasResults {
+			colorCyan("%.3f/%.3f/%.3f ms\n", r.Min, r.Average, r.Max)
+		} else {
+			colorYellow("-\n")
+		}
+	}
+}
+
+// printAvailability prints the availability percentage
+// colored like the packet loss.
+func printAvailability(availability float64) {
+	switch {
+	case availability == 100:
+		colorGreen("%.3f%%", availability)
+	case availability >= 70:
+		colorLightYellow("%.3f%%", availability)
+	default:
+		colorRed("%.3f%%", availability)
+	}
+}
+
+// PrintProbe prints the probe with its sequence number,
+// followed by why it failed or the details of the successful probe.
+func (p *planePrinter) PrintProbe(r Result) {
+	switch {
+	case !r.Success:
+		colorRed("%s\n", formatProbe(r))
+		if r.Err != nil {
+			colorRed("  %s: %s\n", r.FailureReason, r.Err)
+		}
+		return
+	case r.Degraded:
+		colorLightYellow("%s\n", formatProbe(r))
+	default:
+		colorLightGreen("%s\n", formatProbe(r))
+	}
+
+	if info := r.TCPInfo; info != nil {
+		colorLightBlue("  kernel srtt=%.3f ms rttvar=%.3f ms (%+.3f ms in userspace) %s\n",
+			info.SRTT, info.RTTVar, r.RTT-info.SRTT, formatTCPInfo(*info))
+	}
+	if r.LocalAddr.IsValid() {
+		colorLightBlue("  from %s\n", r.LocalAddr)
+	}
+	if r.SmoothedRTT > 0 {
+		colorLightBlue("  smoothed rtt=%.3f ms\n", r.SmoothedRTT)
+	}
+	for _, detail := range probeDetails(r) {
+		p.PrintInfo("%s", detail)
+	}
+}
+
+// PrintStateChange prints the downtime when the target is back up.
+func (p *planePrinter) PrintStateChange(c StateChange) {
+	if c.isRecovery() {
+		colorYellow("No response received for %s\n", DurationToString(c.Downtime))
+	}
+}
+
+func (p *planePrinter) PrintRetryingToResolve(hostname string) {
+	colorLightYellow("retrying to resolve %s\n", hostname)
+}
+
+func (p *planePrinter) PrintResolved(hostname string, addrs []netip.Addr, selected netip.Addr, resolveTime float32) {
+	colorLightBlue("Resolved %s in %.3f ms to ", hostname, resolveTime)
+	for i, addr := range addrs {
+		if i > 0 {
+			colorLightBlue(", ")
+		}
+
+		if addr.Unmap() == selected {
+			colorGreen("%s (selected)", addr.Unmap())
+		} else {
+			colorLightBlue("%s", addr.Unmap())
+		}
+	}
+	colorLightBlue("\n")
+}
+
+func (p *planePrinter) PrintIPInfo(ip netip.Addr, info IPInfo) {
+	colorLightBlue("%s belongs to ", ip)
+	colorGreen("%s\n", info)
+}
+
+func (p *planePrinter) PrintHop(hop Hop) {
+	switch {
+	case hop.Reached:
+		colorGreen("%s\n", formatHop(hop))
+	case hop.Unreachable, !hop.Addr.IsValid():
+		colorRed("%s\n", formatHop(hop))
+	default:
+		colorLightBlue("%s\n", formatHop(hop))
+	}
+}
+
+func (p *planePrinter) PrintInfo(format string, args ...any) {
+	colorLightBlue(format+"\n", args...)
+}
+
+func (p *planePrinter) PrintError(format string, args ...any) {
+	colorRed(format+"\n", args...)
+}
+
+func (p *planePrinter) PrintVersion() {
+	colorGreen("TCPING version %s\n", Version)
+}
+
+type jsonPrinter struct {
+	e *json.Encoder
+}
+
+// NewJSONPrinter returns a printer that prints every message
+// as a JSON object to the stdout, indented if withIndent is set.
+func NewJSONPrinter(withIndent bool) Printer {
+	return newJSONPrinter(os.Stdout, withIndent)
+}
+
+func newJSONPrinter(w io.Writer, withIndent bool) *jsonPrinter {
+	encoder := json.NewEncoder(w)
+	if withIndent {
+		encoder.SetIndent("", "\t")
+	}
+	return &jsonPrinter{e: encoder}
+}
+
+// print is a little helper method for p.e.Encode.
+// at also sets data.Timestamp to Now().
+func (p *jsonPrinter) print(data JSONData) {
+	data.Timestamp = time.Now()
+	p.e.Encode(data)
+}
+
+// JSONEventType is a special type, each for each method
+// in the printer interface so that automatic tools
+// can understand what kind of an event they've received.
+type JSONEventType string
+
+const (
+	// startEvent is an event type for [PrintStart] method.
+	startEvent JSONEventType = "start"
+	// probeEvent is an event type for [PrintProbe] method.
+	probeEvent JSONEventType = "probe"
+	// retryEvent is an event type for [PrintRetryingToResolve] method.
+	retryEvent JSONEventType = "retry"
+	// tcpInfoEvent is an event type for [PrintProbe] method, with the tcp_info of the probe.
+	tcpInfoEvent JSONEventType = "tcpinfo"
+	// hopEvent is an event type for [PrintHop] method.
+	hopEvent JSONEventType = "hop"
+	// smoothedRTTEvent is an event type for [PrintProbe] method, with the smoothed RTT.
+	smoothedRTTEvent JSONEventType = "smoothed_rtt"
+	// failureEvent is an event type for [PrintProbe] method, with why the probe failed.
+	failureEvent JSONEventType = "failure"
+	// localAddrEvent is an event type for [PrintProbe] method, with the local address.
+	localAddrEvent JSONEventType = "local_addr"
+	// ipInfoEvent is an event type for [PrintIPInfo] method.
+	ipInfoEvent JSONEventType = "ipinfo"
+	// resolvedEvent is an event type for [PrintResolved] method.
+	resolvedEvent JSONEventType = "resolved"
+	// retrySuccessEvent is an event type for [PrintStateChange] method.
+	retrySuccessEvent JSONEventType = "retry-success"
+	// statisticsEvent is a event type for [PrintStatistics] method.
+	statisticsEvent JSONEventType = "statistics"
+	// infoEvent is a event type for [PrintInfo] method.
+	infoEvent JSONEventType = "info"
+	// versionEvent is a event type for [PrintVersion] method.
+	versionEvent JSONEventType = "version"
+	// errorEvent is a event type for [PrintError] method.
+	errorEvent JSONEventType = "error"
+)
+
+// JSONData contains all possible fields for JSON output.
+// Because one event usually contains only a subset of fields,
+// other fields will be omitted in the output.
+type JSONData struct {
+	// Type is a mandatory field that specifies type of a message/event.
+	Type JSONEventType `json:"type"`
+	// Message contains a human-readable message.
+	Message string `json:"message"`
+	// Timestamp contains data when a message was sent.
+	Timestamp time.Time `json:"timestamp"`
+
+	// Optional fields below
+
+	// Seq is the sequence number of the probe event.
+	Seq                  uint             `json:"seq,omitempty"`
+	Addr                 string           `json:"addr,omitempty"`
+	Hostname             string           `json:"hostname,omitempty"`
+	HostnameResolveTries uint             `json:"hostname_resolve_tries,omitempty"`
+	HostnameChanges      []HostnameChange `json:"hostname_changes,omitempty"`
+	IsIP                 *bool            `json:"is_ip,omitempty"`
+	Port                 uint16           `json:"port,omitempty"`
+	Rtt                  float32          `json:"time,omitempty"`
+	// Service is the name of the service of the port for the start and the stats events.
+	Service string `json:"service,omitempty"`
+	// SRTT and RTTVar in ms are the kernel's view of the connection for the tcpinfo event.
+	SRTT   float32 `json:"srtt,omitempty"`
+	RTTVar float32 `json:"rttvar,omitempty"`
+	// Retransmits, Cwnd and DeliveryRate in Mbit/s are the rest of it.
+	Retransmits  uint32  `json:"retransmits,omitempty"`
+	Cwnd         uint32  `json:"cwnd,omitempty"`
+	DeliveryRate float32 `json:"delivery_rate,omitempty"`
+	// TTL, Reached, Open and Unreachable describe the hop of the hop event,
+	// see [Hop]. Its address and RTT are in Addr and Rtt.
+	TTL         int  `json:"ttl,omitempty"`
+	Reached     bool `json:"reached,omitempty"`
+	Open        bool `json:"open,omitempty"`
+	Unreachable bool `json:"unreachable,omitempty"`
+	// ResolvedAddrs are all the addresses the hostname was resolved to.
+	ResolvedAddrs []string `json:"resolved_addrs,omitempty"`
+	// Reason is the category of the error of the failure event.
+	Reason FailureReason `json:"reason,omitempty"`
+	// FailureReasons are the numbers of failed probes per reason for the stats event.
+	FailureReasons map[FailureReason]uint `json:"failure_reasons,omitempty"`
+	// PeerCloses are the numbers of successful probes per way the peer ended them for the stats event.
+	PeerCloses map[PeerClose]uint `json:"peer_closes,omitempty"`
+	// RetriedProbes is the number of successful probes that needed retries for the stats event.
+	RetriedProbes uint `json:"retried_probes,omitempty"`
+	// BurstFailures is the number of failed connections of the successful bursts for the stats event.
+	BurstFailures uint `json:"burst_failures,omitempty"`
+	// LocalAddr is the address and the port of the local_addr event.
+	LocalAddr string `json:"local_addr,omitempty"`
+	// ASN, Org and Country describe the address of the ipinfo event, see [IPInfo].
+	ASN     uint   `json:"asn,omitempty"`
+	Org     string `json:"org,omitempty"`
+	Country string `json:"country,omitempty"`
+	// PTRNames are the names of the PTR records of the address for the stats event.
+	PTRNames []string `json:"ptr,omitempty"`
+	// ResolveTime in ms for the resolved event.
+	ResolveTime float32 `json:"resolve_time,omitempty"`
+
+	// Success is a special field from probe messages, containing information
+	// whether request was successful or not.
+	// It's a pointer on purpose, otherwise success=false will be omitted,
+	// but we still need to omit it for non-probe messages.
+	Success *bool `json:"success,omitempty"`
+
+	// Latency in ms for a successful probe messages.
+	Latency float32 `json:"latency,omitempty"`
+
+	// LatencyMin is a latency stat for the stats event.
+	//
+	// It's a string on purpose, as we'd like to have exactly
+	// 3 decimal places without doing extra math.
+	LatencyMin string `json:"latency_min,omitempty"`
+	// LatencyAvg is a latency stat for the stats event.
+	//
+	// It's a string on purpose, as we'd like to have exactly
+	// 3 decimal places without doing extra math.
+	LatencyAvg string `json:"latency_avg,omitempty"`
+	// LatencyMax is a latency stat for the stats event.
+	//
+	// It's a string on purpose, as we'd like to have exactly
+	// 3 decimal places without doing extra math.
+	LatencyMax string `json:"latency_max,omitempty"`
+	// LatencyMedian, LatencyTrimmedMean and LatencyStdDev are the
+	// outlier-resistant latency stats, as strings like the others.
+	LatencyMedian      string `json:"latency_median,omitempty"`
+	LatencyTrimmedMean string `json:"latency_trimmed_mean,omitempty"`
+	LatencyStdDev      string `json:"latency_stddev,omitempty"`
+	// SmoothedRTT in ms for the smoothed_rtt and the stats events.
+	SmoothedRTT float32 `json:"smoothed_rtt,omitempty"`
+	// Outliers is the number of probes flagged as outliers for the stats event.
+	Outliers uint `json:"outliers,omitempty"`
+	// LatencyTrends is the number of times the RTTs started trending up for the stats event.
+	LatencyTrends uint `json:"latency_trends,omitempty"`
+	// Degraded is set for the probes above the RTT threshold.
+	Degraded bool `json:"degraded,omitempty"`
+	// DegradedProbes is the number of probes above the RTT threshold for the stats event.
+	DegradedProbes uint `json:"degraded_probes,omitempty"`
+
+	// KernelSRTTMin, KernelSRTTAvg and KernelSRTTMax are the smoothed RTT
+	// stats in ms measured by the kernel, as strings like the latency.
+	KernelSRTTMin string `json:"kernel_srtt_min,omitempty"`
+	KernelSRTTAvg string `json:"kernel_srtt_avg,omitempty"`
+	KernelSRTTMax string `json:"kernel_srtt_max,omitempty"`
+	// RetransmittedSegments is the number of segments retransmitted for the stats event.
+	RetransmittedSegments uint `json:"retransmitted_segments,omitempty"`
+
+	// WireRTTMin, WireRTTAvg and WireRTTMax are the stats in ms of the times
+	// from the SYN to the SYN-ACK on the wire, as strings like the latency.
+	WireRTTMin string `json:"wire_rtt_min,omitempty"`
+	WireRTTAvg string `json:"wire_rtt_avg,omitempty"`
+	WireRTTMax string `json:"wire_rtt_max,omitempty"`
+
+	// UserRTTMin, UserRTTAvg and UserRTTMax are the stats in ms of the RTTs
+	// measured by tcping when they were measured in the kernel with eBPF,
+	// as strings like the latency.
+	UserRTTMin string `json:"userspace_rtt_min,omitempty"`
+	UserRTTAvg string `json:"userspace_rtt_avg,omitempty"`
+	UserRTTMax string `json:"userspace_rtt_max,omitempty"`
+
+	// TunnelSetupMin, TunnelSetupAvg and TunnelSetupMax are the stats in ms
+	// of the times the SSH tunnel took to set up, as strings like the latency.
+	TunnelSetupMin string `json:"tunnel_setup_min,omitempty"`
+	TunnelSetupAvg string `json:"tunnel_setup_avg,omitempty"`
+	TunnelSetupMax string `json:"tunnel_setup_max,omitempty"`
+
+	// BurstRTTMin, BurstRTTAvg and BurstRTTMax are the stats in ms of the
+	// RTTs of all the connections of the bursts, as strings like the latency.
+	BurstRTTMin string `json:"burst_rtt_min,omitempty"`
+	BurstRTTAvg string `json:"burst_rtt_avg,omitempty"`
+	BurstRTTMax string `json:"burst_rtt_max,omitempty"`
+
+	// ResponseTimeMin, ResponseTimeAvg and ResponseTimeMax are the stats in ms
+	// of the time the service took to answer, as strings like the latency.
+	ResponseTimeMin string `json:"response_time_min,omitempty"`
+	ResponseTimeAvg string `json:"response_time_avg,omitempty"`
+	ResponseTimeMax string `json:"response_time_max,omitempty"`
+
+	// GoodputMin, GoodputAvg and GoodputMax are the stats in Mbit/s of the
+	// goodputs of the payload, as strings like the latency.
+	GoodputMin string `json:"goodput_min,omitempty"`
+	GoodputAvg string `json:"goodput_avg,omitempty"`
+	GoodputMax string `json:"goodput_max,omitempty"`
+
+	// TFOAcceptedProbes is the number of probes whose data in the SYN was accepted,
+	// see Statistics.TFOAcceptedProbes. The RTT stats of these probes and of the
+	// other ones are strings like the latency.
+	TFOAcceptedProbes uint   `json:"tfo_accepted_probes,omitempty"`
+	TFORttMin         string `json:"tfo_rtt_min,omitempty"`
+	TFORttAvg         string `json:"tfo_rtt_avg,omitempty"`
+	TFORttMax         string `json:"tfo_rtt_max,omitempty"`
+	RegularRttMin     string `json:"regular_rtt_min,omitempty"`
+	RegularRttAvg     string `json:"regular_rtt_avg,omitempty"`
+	RegularRttMax     string `json:"regular_rtt_max,omitempty"`
+
+	// CertExpiry and CertDaysRemaining tell when the certificate chain
+	// expires, see Statistics.CertExpiry.
+	CertExpiry        *time.Time `json:"cert_expiry,omitempty"`
+	CertDaysRemaining *int       `json:"cert_days_remaining,omitempty"`
+
+	// MPTCPProbes is the number of probes that negotiated Multipath TCP.
+	MPTCPProbes uint `json:"mptcp_probes,omitempty"`
+
+	// ICMPRttMin, ICMPRttAvg and ICMPRttMax are the RTT stats in ms of the
+	// ICMP echoes, as strings like the latency, and ICMPLostEchoes the
+	// number of unanswered ones.
+	ICMPRttMin     string `json:"icmp_rtt_min,omitempty"`
+	ICMPRttAvg     string `json:"icmp_rtt_avg,omitempty"`
+	ICMPRttMax     string `json:"icmp_rtt_max,omitempty"`
+	ICMPLostEchoes uint   `json:"icmp_lost_echoes,omitempty"`
+
+	// PathMTU is the path MTU to the target in bytes.
+	PathMTU int `json:"path_mtu,omitempty"`
+
+	// ResolveTimeMin, ResolveTimeAvg and ResolveTimeMax are the hostname
+	// resolution time stats in ms for the stats event, as strings like the latency.
+	ResolveTimeMin string `json:"resolve_time_min,omitempty"`
+	ResolveTimeAvg string `json:"resolve_time_avg,omitempty"`
+	ResolveTimeMax string `json:"resolve_time_max,omitempty"`
+
+	// TotalDuration is a total amount of seconds that program was running.
+	//
+	// It's a string on purpose, as we'd like to have exactly
+	// 3 decimal places without doing extra math.
+	TotalDuration string `json:"total_duration,omitempty"`
+	// StartTimestamp is used as a start time of TotalDuration for stats messages.
+	StartTimestamp *time.Time `json:"start_timestamp,omitempty"`
+	// EndTimestamp is used as an end of TotalDuration for stats messages.
+	EndTimestamp *time.Time `json:"end_timestamp,omitempty"`
+
+	LastSuccessfulProbe   *time.Time `json:"last_successful_probe,omitempty"`
+	LastUnsuccessfulProbe *time.Time `json:"last_unsuccessful_probe,omitempty"`
+	// DNSFailures and LastDNSFailure are the failed resolutions while probing.
+	DNSFailures    uint       `json:"dns_failures,omitempty"`
+	LastDNSFailure *time.Time `json:"last_dns_failure,omitempty"`
+	// Failovers is the number of times the next address was probed.
+	Failovers uint `json:"failovers,omitempty"`
+
+	// LongestUptime in seconds.
+	//
+	// It's a string on purpose, as we'd like to have exactly
+	// 3 decimal places without doing extra math.
+	LongestUptime      string     `json:"longest_uptime,omitempty"`
+	LongestUptimeEnd   *time.Time `json:"longest_uptime_end,omitempty"`
+	LongestUptimeStart *time.Time `json:"longest_uptime_start,omitempty"`
+
+	// LongestDowntime in seconds.
+	//
+	// It's a string on purpose, as we'd like to have exactly
+	// 3 decimal places without doing extra math.
+	LongestDowntime      string     `json:"longest_downtime,omitempty"`
+	LongestDowntimeEnd   *time.Time `json:"longest_downtime_end,omitempty"`
+	LongestDowntimeStart *time.Time `json:"longest_downtime_start,omitempty"`
+
+	// TotalPacketLoss in seconds.
+	//
+	// It's a string on purpose, as we'd like to have exactly
+	// 3 decimal places without doing extra math.
+	TotalPacketLoss         string `json:"total_packet_loss,omitempty"`
+	TotalPackets            uint   `json:"total_packets,omitempty"`
+	TotalSuccessfulProbes   uint   `json:"total_successful_probes,omitempty"`
+	TotalUnsuccessfulProbes uint   `json:"total_unsuccessful_probes,omitempty"`
+	// TotalUptime in seconds.
+	TotalUptime float64 `json:"total_uptime,omitempty"`
+	// TotalDowntime in seconds.
+	TotalDowntime float64 `json:"total_downtime,omitempty"`
+
+	// Availability is the percentage of the monitored time the target was up.
+	//
+	// It's a string on purpose, as we'd like to have exactly
+	// 3 decimal places without doing extra math.
+	Availability        string                   `json:"availability,omitempty"`
+	AvailabilityWindows []JSONAvailabilityWindow `json:"availability_windows,omitempty"`
+	// PerIP are the statistics of every probed address, if it changed.
+	PerIP []JSONIPStatistics `json:"per_ip,omitempty"`
+}
+
+// JSONAvailabilityWindow is the availability and the probes
+// of one window of Options.AvailabilityWindow.
+type JSONAvailabilityWindow struct {
+	Start time.Time `json:"start"`
+	// Availability and LatencyAvg are strings like the overall ones.
+	Availability       string `json:"availability"`
+	LatencyAvg         string `json:"latency_avg,omitempty"`
+	SuccessfulProbes   uint   `json:"successful_probes"`
+	UnsuccessfulProbes uint   `json:"unsuccessful_probes"`
+}
+
+// JSONIPStatistics are the probes of one of the probed addresses.
+type JSONIPStatistics struct {
+	IP string `json:"ip"`
+	// Latencies are strings like the overall ones.
+	LatencyMin         string `json:"latency_min,omitempty"`
+	LatencyAvg         string `json:"latency_avg,omitempty"`
+	LatencyMax         string `json:"latency_max,omitempty"`
+	SuccessfulProbes   uint   `json:"successful_probes"`
+	UnsuccessfulProbes uint   `json:"unsuccessful_probes"`
+}
+
+// newJSONIPStatistics converts the statistics of the address to JSON.
+func newJSONIPStatistics(s IPStatistics) JSONIPStatistics {
+	data := JSONIPStatistics{
+		IP:                 s.IP.String(),
+		SuccessfulProbes:   s.SuccessfulProbes,
+		UnsuccessfulProbes: s.UnsuccessfulProbes,
+	}
+	if r := s.RttResults; r.HasResults {
+		data.LatencyMin = fmt.Sprintf("%.3f", r.Min)
+		data.LatencyAvg = fmt.Sprintf("%.3f", r.Average)
+		data.LatencyMax = fmt.Sprintf("%.3f", r.Max)
+	}
+
+	return data
+}
+
+// PrintStart prints the initial message before doing probes.
+func (p *jsonPrinter) PrintStart(hostname string, port uint16) {
+	p.print(JSONData{
+		Type:     startEvent,
+		Message:  fmt.Sprintf("TCPinging %s on port %d", hostname, port),
+		Hostname: hostname,
+		Port:     port,
+	})
+}
+
+// PrintStartService prints the initial message with the name of the service of the port.
+func (p *jsonPrinter) PrintStartService(hostname string, port uint16, service string) {
+	p.print(JSONData{
+		Type:     startEvent,
+		Message:  fmt.Sprintf("TCPinging %s on port %s", hostname, formatPort(port, service)),
+		Hostname: hostname,
+		Port:     port,
+		Service:  service,
+	})
+}
+
+// PrintProbe prints the probe, followed by the events of why it
+// failed or of the details of the successful probe.
+func (p *jsonPrinter) PrintProbe(r Result) {
+	var (
+		// for *bool fields
+		f    = false
+		t    = true
+		data = JSONData{
+			Type:     probeEvent,
+			Seq:      r.Seq,
+			Hostname: r.Hostname,
+			Addr:     r.IP.String(),
+			Port:     r.Port,
+			IsIP:     &t,
+		}
+	)
+
+	target := r.IP.String()
+	if r.Hostname != "" {
+		data.IsIP = &f
+		target = fmt.Sprintf("%s (%s)", r.Hostname, r.IP)
+	}
+
+	switch {
+	case !r.Success:
+		data.Success = &f
+		data.TotalUnsuccessfulProbes = r.Streak
+		data.Message = fmt.Sprintf("No reply from %s on port %d", target, r.Port)
+	case r.Degraded:
+		data.Success = &t
+		data.Degraded = true
+		data.Rtt = r.RTT
+		data.TotalSuccessfulProbes = r.Streak
+		data.Message = fmt.Sprintf("Degraded reply from %s on port %d time=%.3f", target, r.Port, r.RTT)
+	default:
+		data.Success = &t
+		data.Rtt = r.RTT
+		data.TotalSuccessfulProbes = r.Streak
+		data.Message = fmt.Sprintf("Reply from %s on port %d time=%.3f", target, r.Port, r.RTT)
+	}
+	p.print(data)
+
+	if !r.Success {
+		if r.Err != nil {
+			p.print(JSONData{
+				Type:    failureEvent,
+				Message: r.Err.Error(),
+				Reason:  r.FailureReason,
+			})
+		}
+		return
+	}
+
+	if info := r.TCPInfo; info != nil {
+		p.print(JSONData{
+			Type:         tcpInfoEvent,
+			Message:      fmt.Sprintf("kernel srtt=%.3f ms", info.SRTT),
+			Rtt:          r.RTT,
+			SRTT:         info.SRTT,
+			RTTVar:       info.RTTVar,
+			Retransmits:  info.Retransmits,
+			Cwnd:         info.Cwnd,
+			DeliveryRate: info.DeliveryRate,
+		})
+	}
+	if r.LocalAddr.IsValid() {
+		p.print(JSONData{
+			Type:      localAddrEvent,
+			Message:   fmt.Sprintf("connected from %s", r.LocalAddr),
+			LocalAddr: r.LocalAddr.String(),
+		})
+	}
+	if r.SmoothedRTT > 0 {
+		p.print(JSONData{
+			Type:        smoothedRTTEvent,
+			Message:     fmt.Sprintf("smoothed rtt=%.3f ms", r.SmoothedRTT),
+			SmoothedRTT: r.SmoothedRTT,
+		})
+	}
+	for _, detail := range probeDetails(r) {
+		p.PrintInfo("%s", detail)
+	}
+}
+
+// PrintStateChange prints the total downtime,
+// when the target is back up.
+func (p *jsonPrinter) PrintStateChange(c StateChange) {
+	if !c.isRecovery() {
+		return
+	}
+
+	p.print(JSONData{
+		Type:          retrySuccessEvent,
+		Message:       fmt.Sprintf("no response received for %s", DurationToString(c.Downtime)),
+		TotalDowntime: c.Downtime.Seconds(),
+	})
+}
+
+// PrintStatistics prints all gathered stats when program exits.
+func (p *jsonPrinter) PrintStatistics(s Statistics) {
+	p.print(NewStatisticsJSONData(s))
+}
+
+// NewStatisticsJSONData fills the JSONData with all gathered stats.
+func NewStatisticsJSONData(s Statistics) JSONData {
+	data := JSONData{
+		Type:     statisticsEvent,
+		Message:  fmt.Sprintf("stats for %s", s.Hostname),
+		Addr:     s.IP.String(),
+		Hostname: s.Hostname,
+		Service:  s.Service,
+
+		StartTimestamp:          &s.StartTime,
+		TotalDowntime:           s.TotalDowntime.Seconds(),
+		TotalPackets:            s.TotalSuccessfulProbes + s.TotalUnsuccessfulProbes,
+		TotalSuccessfulProbes:   s.TotalSuccessfulProbes,
+		TotalUnsuccessfulProbes: s.TotalUnsuccessfulProbes,
+		TotalUptime:             s.TotalUptime.Seconds(),
+	}
+
+	if len(s.HostnameChanges) > 1 {
+		data.HostnameChanges = s.HostnameChanges
+	}
+
+	if len(s.ResolvedAddrs) > 0 {
+		data.ResolvedAddrs = addrStrings(s.ResolvedAddrs)
+	}
+
+	data.PTRNames = s.PTRNames
+	data.FailureReasons = s.FailureReasons
+	data.PeerCloses = s.PeerCloses
+	data.RetriedProbes = s.RetriedProbes
+	data.BurstFailures = s.BurstFailures
+
+	loss := (float32(data.TotalUnsuccessfulProbes) / float32(data.TotalPackets)) * 100
+	if math.IsNaN(float64(loss)) {
+		loss = 0
+	}
+	data.TotalPacketLoss = fmt.Sprintf("%.2f", loss)
+
+	if s.TotalUptime+s.TotalDowntime > 0 {
+		data.Availability = fmt.Sprintf("%.3f", s.Availability)
+	}
+	for _, ip := range s.PerIP {
+		data.PerIP = append(data.PerIP, newJSONIPStatistics(ip))
+	}
+
+	for _, w := range s.AvailabilityWindows {
+		window := JSONAvailabilityWindow{
+			Start:              w.Start,
+			Availability:       fmt.Sprintf("%.3f", w.Availability()),
+			SuccessfulProbes:   w.SuccessfulProbes,
+			UnsuccessfulProbes: w.UnsuccessfulProbes,
+		}
+		if w.SuccessfulProbes > 0 {
+			window.LatencyAvg = fmt.Sprintf("%.3f", w.AverageRTT)
+		}
+		data.AvailabilityWindows = append(data.AvailabilityWindows, window)
+	}
+
+	if !s.LastSuccessfulProbe.IsZero() {
+		data.LastSuccessfulProbe = &s.LastSuccessfulProbe
+	}
+	if !s.LastUnsuccessfulProbe.IsZero() {
+		data.LastUnsuccessfulProbe = &s.LastUnsuccessfulProbe
+	}
+
+	if s.LongestUptime.Duration != 0 {
+		data.LongestUptime = fmt.Sprintf("%.0f", s.LongestUptime.Duration.Seconds())
+		data.LongestUptimeStart = &s.LongestUptime.Start
+		data.LongestUptimeEnd = &s.LongestUptime.End
+	}
+
+	if s.LongestDowntime.Duration != 0 {
+		data.LongestDowntime = fmt.Sprintf("%.0f", s.LongestDowntime.Duration.Seconds())
+		data.LongestDowntimeStart = &s.LongestDowntime.Start
+		data.LongestDowntimeEnd = &s.LongestDowntime.End
+	}
+
+	if !s.IsIP {
+		data.HostnameResolveTries = s.RetriedHostnameLookups
+	}
+
+	data.Failovers = s.Failovers
+
+	if s.DNSFailures > 0 {
+		data.DNSFailures = s.DNSFailures
+		data.LastDNSFailure = &s.LastDNSFailure
+	}
+
+	if s.RttResults.HasResults {
+		data.LatencyMin = fmt.Sprintf("%.3f", s.RttResults.Min)
+		data.LatencyAvg = fmt.Sprintf("%.3f", s.RttResults.Average)
+		data.LatencyMax = fmt.Sprintf("%.3f", s.RttResults.Max)
+		data.LatencyMedian = fmt.Sprintf("%.3f", s.RttResults.Median)
+		data.LatencyTrimmedMean = fmt.Sprintf("%.3f", s.RttResults.TrimmedMean)
+		data.LatencyStdDev = fmt.Sprintf("%.3f", s.RttResults.StdDev)
+	}
+	data.Outliers = s.Outliers
+	data.LatencyTrends = s.LatencyTrends
+	data.DegradedProbes = s.DegradedProbes
+	data.SmoothedRTT = s.SmoothedRTT
+
+	if s.KernelRttResults.HasResults {
+		data.KernelSRTTMin = fmt.Sprintf("%.3f", s.KernelRttResults.Min)
+		data.KernelSRTTAvg = fmt.Sprintf("%.3f", s.KernelRttResults.Average)
+		data.KernelSRTTMax = fmt.Sprintf("%.3f", s.KernelRttResults.Max)
+	}
+	data.RetransmittedSegments = s.Retransmits
+
+	if s.NetworkRttResults.HasResults {
+		data.WireRTTMin = fmt.Sprintf("%.3f", s.NetworkRttResults.Min)
+		data.WireRTTAvg = fmt.Sprintf("%.3f", s.NetworkRttResults.Average)
+		data.WireRTTMax = fmt.Sprintf("%.3f", s.NetworkRttResults.Max)
+	}
+
+	if s.UserRttResults.HasResults {
+		data.UserRTTMin = fmt.Sprintf("%.3f", s.UserRttResults.Min)
+		data.UserRTTAvg = fmt.Sprintf("%.3f", s.UserRttResults.Average)
+		data.UserRTTMax = fmt.Sprintf("%.3f", s.UserRttResults.Max)
+	}
+
+	if s.TunnelSetupResults.HasResults {
+		data.TunnelSetupMin = fmt.Sprintf("%.3f", s.TunnelSetupResults.Min)
+		data.TunnelSetupAvg = fmt.Sprintf("%.3f", s.TunnelSetupResults.Average)
+		data.TunnelSetupMax = fmt.Sprintf("%.3f", s.TunnelSetupResults.Max)
+	}
+
+	if s.BurstRttResults.HasResults {
+		data.BurstRTTMin = fmt.Sprintf("%.3f", s.BurstRttResults.Min)
+		data.BurstRTTAvg = fmt.Sprintf("%.3f", s.BurstRttResults.Average)
+		data.BurstRTTMax = fmt.Sprintf("%.3f", s.BurstRttResults.Max)
+	}
+
+	if s.ResponseTimeResults.HasResults {
+		data.ResponseTimeMin = fmt.Sprintf("%.3f", s.ResponseTimeResults.Min)
+		data.ResponseTimeAvg = fmt.Sprintf("%.3f", s.ResponseTimeResults.Average)
+		data.ResponseTimeMax = fmt.Sprintf("%.3f", s.ResponseTimeResults.Max)
+	}
+
+	if s.GoodputResults.HasResults {
+		data.GoodputMin = fmt.Sprintf("%.3f", s.GoodputResults.Min)
+		data.GoodputAvg = fmt.Sprintf("%.3f", s.GoodputResults.Average)
+		data.GoodputMax = fmt.Sprintf("%.3f", s.GoodputResults.Max)
+	}
+
+	data.TFOAcceptedProbes = s.TFOAcceptedProbes
+	data.MPTCPProbes = s.MPTCPProbes
+	data.ICMPLostEchoes = s.ICMPLostEchoes
+	data.PathMTU = s.PathMTU
+
+	if s.ICMPRttResults.HasResults {
+		data.ICMPRttMin = fmt.Sprintf("%.3f", s.ICMPRttResults.Min)
+		data.ICMPRttAvg = fmt.Sprintf("%.3f", s.ICMPRttResults.Average)
+		data.ICMPRttMax = fmt.Sprintf("%.3f", s.ICMPRttResults.Max)
+	}
+
+	if !s.CertExpiry.IsZero() {
+		days := certDaysRemaining(s.CertExpiry)
+		data.CertExpiry = &s.CertExpiry
+		data.CertDaysRemaining = &days
+	}
+
+	if s.TFORttResults.HasResults {
+		data.TFORttMin = fmt.Sprintf("%.3f", s.TFORttResults.Min)
+		data.TFORttAvg = fmt.Sprintf("%.3f", s.TFORttResults.Average)
+		data.TFORttMax = fmt.Sprintf("%.3f", s.TFORttResults.Max)
+	}
+
+	if s.RegularRttResults.HasResults {
+		data.RegularRttMin = fmt.Sprintf("%.3f", s.RegularRttResults.Min)
+		data.RegularRttAvg = fmt.Sprintf("%.3f", s.RegularRttResults.Average)
+		data.RegularRttMax = fmt.Sprintf("%.3f", s.RegularRttResults.Max)
+	}
+
+	if !s.IsIP && s.ResolveTimeResults.HasResults {
+		data.ResolveTimeMin = fmt.Sprintf("%.3f", s.ResolveTimeResults.Min)
+		data.ResolveTimeAvg = fmt.Sprintf("%.3f", s.ResolveTimeResults.Average)
+		data.ResolveTimeMax = fmt.Sprintf("%.3f", s.ResolveTimeResults.Max)
+	}
+
+	if !s.EndTime.IsZero() {
+		data.EndTimestamp = &s.EndTime
+	}
+
+	totalDuration := s.TotalDowntime + s.TotalUptime
+	data.TotalDuration = fmt.Sprintf("%.0f", totalDuration.Seconds())
+	data.Timestamp = time.Now()
+
+	return data
+}
+
+// PrintRetryingToResolve print the message retrying to resolve,
+// after n failed probes.
+func (p *jsonPrinter) PrintRetryingToResolve(hostname string) {
+	p.print(JSONData{
+		Type:     retryEvent,
+		Message:  fmt.Sprintf("retrying to resolve %s", hostname),
+		Hostname: hostname,
+	})
+}
+
+// PrintHop prints a hop found by [Trace].
+func (p *jsonPrinter) PrintHop(hop Hop) {
+	data := JSONData{
+		Type:        hopEvent,
+		Message:     strings.TrimSpace(formatHop(hop)),
+		TTL:         hop.TTL,
+		Rtt:         hop.RTT,
+		Reached:     hop.Reached,
+		Open:        hop.Open,
+		Unreachable: hop.Unreachable,
+	}
+	if hop.Addr.IsValid() {
+		data.Addr = hop.Addr.String()
+	}
+
+	p.print(data)
+}
+
+// PrintIPInfo prints the info of the probed IP.
+func (p *jsonPrinter) PrintIPInfo(ip netip.Addr, info IPInfo) {
+	p.print(JSONData{
+		Type:    ipInfoEvent,
+		Message: fmt.Sprintf("%s belongs to %s", ip, info),
+		Addr:    ip.String(),
+		ASN:     info.ASN,
+		Org:     info.Org,
+		Country: info.Country,
+	})
+}
+
+// PrintResolved prints all the addresses the hostname was resolved to.
+func (p *jsonPrinter) PrintResolved(hostname string, addrs []netip.Addr, selected netip.Addr, resolveTime float32) {
+	p.print(JSONData{
+		Type:          resolvedEvent,
+		Message:       fmt.Sprintf("%s resolved to %s", hostname, selected),
+		Hostname:      hostname,
+		Addr:          selected.String(),
+		ResolvedAddrs: addrStrings(addrs),
+		ResolveTime:   resolveTime,
+	})
+}
+
+// addrStrings formats the addresses for the JSON output.
+func addrStrings(addrs []netip.Addr) []string {
+	list := make([]string, 0, len(addrs))
+	for _, addr := range addrs {
+		list = append(list, addr.Unmap().String())
+	}
+
+	return list
+}
+
+func (p *jsonPrinter) PrintInfo(format string, args ...any) {
+	p.print(JSONData{
+		Type:    infoEvent,
+		Message: fmt.Sprintf(format, args...),
+	})
+}
+
+func (p *jsonPrinter) PrintError(format string, args ...any) {
+	p.print(JSONData{
+		Type:    errorEvent,
+		Message: fmt.Sprintf(format, args...),
+	})
+}
+
+func (p *jsonPrinter) PrintVersion() {
+	p.print(JSONData{
+		Type:    versionEvent,
+		Message: fmt.Sprintf("TCPING version %s\n", Version),
+	})
+}
+
+// DurationToString creates a human-readable string for a given duration
+func DurationToString(duration time.Duration) string {
+	hours := math.Floor(duration.Hours())
+	if hours > 0 {
+		duration -= time.Duration(hours * float64(time.Hour))
+	}
+
+	minutes := math.Floor(duration.Minutes())
+	if minutes > 0 {
+		duration -= time.Duration(minutes * float64(time.Minute))
+	}
+
+	seconds := duration.Seconds()
+
+	switch {
+	// Hours
+	case hours >= 2:
+		return fmt.Sprintf("%.0f hours %.0f minutes %.0f seconds", hours, minutes, seconds)
+	case hours == 1 && minutes == 0 && seconds == 0:
+		return fmt.Sprintf("%.0f hour", hours)
+	case hours == 1:
+		return fmt.Sprintf("%.0f hour %.0f minutes %.0f seconds", hours, minutes, seconds)
+
+	// Minutes
+	case minutes >= 2:
+		return fmt.Sprintf("%.0f minutes %.0f seconds", minutes, seconds)
+	case minutes == 1 && seconds == 0:
+		return fmt.Sprintf("%.0f minute", minutes)
+	case minutes == 1:
+		return fmt.Sprintf("%.0f minute %.0f seconds", minutes, seconds)
+
+	// Seconds
+	case seconds == 1:
+		return fmt.Sprintf("%.0f second", seconds)
+	default:
+		return fmt.Sprintf("%.0f seconds", seconds)
+
+	}
+}
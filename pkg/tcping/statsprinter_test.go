@@ -1,4 +1,4 @@
-package main
+package tcping
 
 import (
 	"testing"
@@ -9,15 +9,14 @@ import (
 // of a printer that does nothing.
 type dummyPrinter struct{}
 
-func (fp *dummyPrinter) printStart(_ string, _ uint16)                              {}
-func (fp *dummyPrinter) printProbeFail(_, _ string, _ uint16, _ uint)               {}
-func (fp *dummyPrinter) printRetryingToResolve(_ string)                            {}
-func (fp *dummyPrinter) printTotalDownTime(_ time.Duration)                         {}
-func (fp *dummyPrinter) printStatistics(_ stats)                                    {}
-func (fp *dummyPrinter) printVersion()                                              {}
-func (fp *dummyPrinter) printInfo(_ string, _ ...interface{})                       {}
-func (fp *dummyPrinter) printError(_ string, _ ...interface{})                      {}
-func (fp *dummyPrinter) printProbeSuccess(_, _ string, _ uint16, _ uint, _ float32) {}
+func (fp *dummyPrinter) PrintStart(_ string, _ uint16)         {}
+func (fp *dummyPrinter) PrintProbe(_ Result)                   {}
+func (fp *dummyPrinter) PrintStateChange(_ StateChange)        {}
+func (fp *dummyPrinter) PrintRetryingToResolve(_ string)       {}
+func (fp *dummyPrinter) PrintStatistics(_ Statistics)          {}
+func (fp *dummyPrinter) PrintVersion()                         {}
+func (fp *dummyPrinter) PrintInfo(_ string, _ ...interface{})  {}
+func (fp *dummyPrinter) PrintError(_ string, _ ...interface{}) {}
 
 func TestDurationToString(t *testing.T) {
 	t.Parallel()
@@ -80,7 +79,7 @@ func TestDurationToString(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			if got := durationToString(tt.duration); got != tt.want {
+			if got := DurationToString(tt.duration); got != tt.want {
 				t.Errorf("calcTime() = %v, want %v", got, tt.want)
 			}
 		})
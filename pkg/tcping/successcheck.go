@@ -0,0 +1,45 @@
+package tcping
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// runSuccessCheck runs Options.SuccessCheck once connected,
+// the probe fails if it returns an error. The check has Options.Timeout
+// to return, or the interval between the probes without a timeout,
+// so that a hung check doesn't stall the probing.
+func (tcpStats *stats) runSuccessCheck(ctx context.Context, connTime time.Time, rtt float32) error {
+	timeout := tcpStats.userInput.Timeout
+	if timeout == 0 {
+		timeout = tcpStats.userInput.IntervalBetweenProbes
+	}
+	checkCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	r := Result{
+		Seq:      tcpStats.seq() + 1,
+		Time:     connTime,
+		Hostname: tcpStats.userInput.Hostname,
+		IP:       tcpStats.userInput.ip,
+		Port:     tcpStats.userInput.Port,
+		RTT:      rtt,
+		Attempts: tcpStats.attempts,
+		Goodput:  tcpStats.goodput,
+		Success:  true,
+	}
+
+	err := tcpStats.userInput.SuccessCheck(checkCtx, r)
+	if errors.Is(checkCtx.Err(), context.DeadlineExceeded) && ctx.Err() == nil {
+		err = fmt.Errorf("timed out after %s", timeout)
+	}
+	// the probe is dropped anyway if ctx is done
+	if err != nil && ctx.Err() == nil {
+		tcpStats.printer.PrintError("Check of %s on port %d failed: %s",
+			tcpStats.userInput.ip, tcpStats.userInput.Port, err)
+	}
+
+	return err
+}
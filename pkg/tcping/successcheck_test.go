@@ -0,0 +1,94 @@
+package tcping
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSuccessCheck(t *testing.T) {
+	srv := testServerListen(t)
+	t.Cleanup(func() { srv.Close() })
+
+	var checked []Result
+	var results []Result
+	p, err := New(Options{
+		Printer:               &dummyPrinter{},
+		Hostname:              "127.0.0.1",
+		Port:                  12345,
+		IntervalBetweenProbes: 2 * time.Millisecond,
+		Timeout:               100 * time.Millisecond,
+		ProbesBeforeQuit:      3,
+		// the second probe fails the check
+		SuccessCheck: func(_ context.Context, r Result) error {
+			checked = append(checked, r)
+			if r.Seq == 2 {
+				return errors.New("unhealthy")
+			}
+			return nil
+		},
+		Hooks: Hooks{
+			OnProbe: func(r Result) { results = append(results, r) },
+		},
+	})
+	assert.NoError(t, err)
+
+	p.Run()
+
+	if assert.Len(t, checked, 3) {
+		assert.Equal(t, uint(1), checked[0].Seq)
+		assert.Equal(t, uint16(12345), checked[0].Port)
+		assert.Positive(t, checked[0].RTT)
+	}
+	if assert.Len(t, results, 3) {
+		assert.True(t, results[0].Success)
+		assert.False(t, results[1].Success)
+		assert.True(t, results[2].Success)
+	}
+
+	_, err = New(Options{
+		Printer:      &dummyPrinter{},
+		Hostname:     "127.0.0.1",
+		Port:         12345,
+		Persistent:   true,
+		SuccessCheck: func(context.Context, Result) error { return nil },
+	})
+	assert.EqualError(t, err, "the success check needs a new connection for every probe")
+}
+
+func TestSuccessCheckTimeout(t *testing.T) {
+	srv := testServerListen(t)
+	t.Cleanup(func() { srv.Close() })
+
+	var results []Result
+	p, err := New(Options{
+		Printer:               &dummyPrinter{},
+		Hostname:              "127.0.0.1",
+		Port:                  12345,
+		IntervalBetweenProbes: 2 * time.Millisecond,
+		Timeout:               50 * time.Millisecond,
+		ProbesBeforeQuit:      2,
+		// a hung check
+		SuccessCheck: func(ctx context.Context, _ Result) error {
+			<-ctx.Done()
+			return ctx.Err()
+		},
+		Hooks: Hooks{
+			OnProbe: func(r Result) { results = append(results, r) },
+		},
+	})
+	assert.NoError(t, err)
+
+	start := time.Now()
+	p.Run()
+
+	assert.Less(t, time.Since(start), time.Second)
+	if assert.Len(t, results, 2) {
+		assert.False(t, results[0].Success)
+		assert.EqualError(t, results[0].Err, "timed out after 50ms")
+		assert.False(t, results[1].Success)
+	}
+}
@@ -0,0 +1,47 @@
+//go:build linux
+
+package tcping
+
+import (
+	"errors"
+	"net"
+
+	"golang.org/x/sys/unix"
+)
+
+// tcpInfoSupported reports whether TCP_INFO can be read on this platform.
+const tcpInfoSupported = true
+
+// readTCPInfo reads the kernel's view of the connection.
+func readTCPInfo(conn net.Conn) (TCPInfo, error) {
+	tcpConn, ok := conn.(*net.TCPConn)
+	if !ok {
+		return TCPInfo{}, errors.New("not a TCP connection")
+	}
+
+	raw, err := tcpConn.SyscallConn()
+	if err != nil {
+		return TCPInfo{}, err
+	}
+
+	var info *unix.TCPInfo
+	var sockErr error
+	err = raw.Control(func(fd uintptr) {
+		info, sockErr = unix.GetsockoptTCPInfo(int(fd), unix.IPPROTO_TCP, unix.TCP_INFO)
+	})
+	if err != nil {
+		return TCPInfo{}, err
+	}
+	if sockErr != nil {
+		return TCPInfo{}, sockErr
+	}
+
+	// the kernel measures them in microseconds, and the rate in bytes per second
+	return TCPInfo{
+		SRTT:         float32(info.Rtt) / 1000,
+		RTTVar:       float32(info.Rttvar) / 1000,
+		Retransmits:  info.Total_retrans,
+		Cwnd:         info.Snd_cwnd,
+		DeliveryRate: float32(float64(info.Delivery_rate) * 8 / 1e6),
+	}, nil
+}
@@ -0,0 +1,75 @@
+package tcping
+
+import (
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTCPInfo(t *testing.T) {
+	srv := testServerListen(t)
+	t.Cleanup(func() {
+		if err := srv.Close(); err != nil {
+			t.Errorf("srv close: %v", err)
+		}
+	})
+
+	var results []Result
+	p, err := New(Options{
+		Printer:               &dummyPrinter{},
+		Hostname:              "127.0.0.1",
+		Port:                  12345,
+		IntervalBetweenProbes: 2 * time.Millisecond,
+		Timeout:               time.Second,
+		ProbesBeforeQuit:      2,
+		TCPInfo:               true,
+		Hooks: Hooks{
+			OnProbe: func(r Result) { results = append(results, r) },
+		},
+	})
+	assert.NoError(t, err)
+
+	p.Run()
+
+	if assert.Len(t, results, 2) {
+		for _, r := range results {
+			if assert.NotNil(t, r.TCPInfo) {
+				assert.NotZero(t, r.TCPInfo.SRTT)
+				assert.NotZero(t, r.TCPInfo.Cwnd)
+			}
+		}
+	}
+
+	assert.True(t, p.Statistics().KernelRttResults.HasResults)
+}
+
+func TestTCPInfoAfterPayload(t *testing.T) {
+	port := testStreamServer(t, func(conn net.Conn) { io.Copy(io.Discard, conn) })
+
+	var results []Result
+	p, err := New(Options{
+		Printer:               &dummyPrinter{},
+		Hostname:              "127.0.0.1",
+		Port:                  port,
+		IntervalBetweenProbes: 2 * time.Millisecond,
+		Timeout:               time.Second,
+		ProbesBeforeQuit:      1,
+		TCPInfo:               true,
+		GoodputSize:           1 << 20,
+		Hooks: Hooks{
+			OnProbe: func(r Result) { results = append(results, r) },
+		},
+	})
+	assert.NoError(t, err)
+
+	p.Run()
+
+	// the data was sent before TCP_INFO was read
+	if assert.Len(t, results, 1) && assert.NotNil(t, results[0].TCPInfo) {
+		assert.Positive(t, results[0].TCPInfo.DeliveryRate)
+	}
+	assert.Zero(t, p.Statistics().Retransmits)
+}
@@ -0,0 +1,15 @@
+//go:build !linux
+
+package tcping
+
+import (
+	"errors"
+	"net"
+)
+
+// tcpInfoSupported reports whether TCP_INFO can be read on this platform.
+const tcpInfoSupported = false
+
+func readTCPInfo(_ net.Conn) (TCPInfo, error) {
+	return TCPInfo{}, errors.New("TCP_INFO is only supported on Linux")
+}
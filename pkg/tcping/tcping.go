@@ -0,0 +1,2286 @@
+// Package tcping implements the probing engine of TCPING.
+//
+// A [Pinger] probes a single target over TCP, keeps track of
+// its statistics and reports the results through a [Printer].
+//
+//	pinger, err := tcping.New(tcping.Options{
+//		Hostname:              "example.com",
+//		Port:                  443,
+//		ProbesBeforeQuit:      5,
+//		Timeout:               time.Second,
+//		IntervalBetweenProbes: time.Second,
+//		Printer:               tcping.NewPlainPrinter(),
+//	})
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	pinger.Run()
+//	pinger.Shutdown()
+package tcping
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"log/slog"
+	"maps"
+	"net"
+	"net/netip"
+	"net/url"
+	"os"
+	"regexp"
+	"runtime"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/crypto/ssh"
+)
+
+const (
+	// Version is the current version of TCPING.
+	Version    = "2.4.0"
+	dnsTimeout = 2 * time.Second
+)
+
+// Printer is a set of methods for printers to implement.
+//
+// Printers should NOT modify any existing data nor do any calculations.
+// They should only perform visual operations on given data.
+//
+// New printers can be made selectable by name with [RegisterPrinter].
+type Printer interface {
+	// PrintStart should print the first message, after the program starts.
+	// This message is printed only once, at the very beginning.
+	PrintStart(hostname string, port uint16)
+
+	// PrintProbe should print the result of each probe, successful or
+	// not, with the details measured along with it, e.g. Result.TCPInfo.
+	// The details the user didn't ask to see, e.g. Result.LocalAddr
+	// without Options.ShowLocalAddr, are left empty.
+	PrintProbe(r Result)
+
+	// PrintStateChange should print the target going down or coming back up,
+	// crossing Options.RTTThreshold with Options.NotifyDegraded, its RTTs
+	// starting or stopping trending up with Options.NotifyTrend, and the
+	// outliers starting and stopping with Options.NotifyOutliers.
+	//
+	// The downtime is in StateChange.Downtime when the target comes back up.
+	PrintStateChange(c StateChange)
+
+	// PrintRetryingToResolve should print a message with the hostname
+	// it is trying to resolve an ip for.
+	//
+	// This is only being printed when the -r flag is applied.
+	PrintRetryingToResolve(hostname string)
+
+	// PrintStatistics should print a message with
+	// helpful statistics information.
+	//
+	// This is being called on exit and when user hits "Enter".
+	PrintStatistics(s Statistics)
+
+	// PrintVersion should print the current version.
+	PrintVersion()
+
+	// PrintInfo should a message, which is not directly related
+	// to the pinging and serves as a helpful information.
+	//
+	// Example of such: new version with -u flag.
+	PrintInfo(format string, args ...any)
+
+	// PrintError should print an error message.
+	// Printer should also apply \n to the given string, if needed.
+	PrintError(format string, args ...any)
+}
+
+// ResolvePrinter is implemented by the printers that print all the
+// addresses the hostname was resolved to, with the selected one.
+// The other printers get a summary with PrintInfo instead.
+type ResolvePrinter interface {
+	// PrintResolved is called after the hostname was resolved,
+	// with the time it took in milliseconds.
+	PrintResolved(hostname string, addrs []netip.Addr, selected netip.Addr, resolveTime float32)
+}
+
+// Options configure a [Pinger].
+type Options struct {
+	// Printer is used for outputting information and data. Mandatory.
+	Printer Printer
+	// Results, if set, receives the result of every probe.
+	// Sending to it blocks the probes, so it should be drained continuously.
+	Results chan<- Result
+	// Hostname or IP address of the target.
+	Hostname string
+	// SRV is the name of an SRV record, e.g. _service._tcp.example.com.
+	// If set, Hostname and Port are picked from its records by priority
+	// and weight, and the record is looked up again when retrying to
+	// resolve the hostname, after every failed probe by default.
+	SRV string
+	// InterfaceName is the name or the address of
+	// the interface the probes are sent from.
+	InterfaceName string
+	// IPSelect is how the address to probe is picked among the ones the
+	// hostname resolves to. One of [IPSelectFirst], [IPSelectRandom],
+	// [IPSelectRoundRobin] or [IPSelectFastest], empty meaning the first.
+	IPSelect string
+	// Bell is the event on which the terminal bell is rung.
+	// One of [BellOnFail], [BellOnSuccess], [BellOnChange] or empty for never.
+	Bell string
+	// Notifiers are informed whenever the target goes down or comes back up.
+	Notifiers []Notifier
+	// Hooks are called with the structured events of the Pinger.
+	Hooks Hooks
+	// Resolver is used to resolve the hostname. Defaults to [net.DefaultResolver].
+	Resolver *net.Resolver
+	// Logger logs the decisions of the Pinger, e.g. which address it picked
+	// and why, at the Info level and their details at the Debug level,
+	// separately from the output of the printer. Nil discards the logs.
+	Logger *slog.Logger
+	// Services name the ports in the output, e.g. 8081 as "billing",
+	// see [ReadServices]. The well-known ports are named anyway.
+	Services map[uint16]string
+	// Hosts maps hostnames to the addresses used instead of resolving them,
+	// like /etc/hosts. The hostnames are matched case-insensitively.
+	Hosts map[string][]netip.Addr
+	// RetryHostnameLookupAfter retries resolving target's hostname
+	// after a certain number of failed probes. 0 means never.
+	RetryHostnameLookupAfter uint
+	// Failover is the number of failed probes in a row after which the
+	// next address of the hostname is probed, without resolving it again,
+	// if it has several. 0 means never.
+	Failover uint
+	// ResolveEveryProbe resolves target's hostname before every probe.
+	ResolveEveryProbe bool
+	// HonorTTL re-resolves target's hostname whenever
+	// the TTL of its DNS records expires.
+	HonorTTL bool
+	// TCPInfo reads the kernel's TCP_INFO after every successful
+	// connection to report its smoothed RTT, its retransmissions and its
+	// congestion window. It's read after the prober and the payload of
+	// Options.GoodputSize, so that they cover the data exchanged, e.g.
+	// to surface a lossy path. Only supported on Linux.
+	TCPInfo bool
+	// Handshake times the SYN, the SYN-ACK and the ACK of every probe on the
+	// wire with a packet capture, to tell the RTT of the network from the
+	// time spent in the local stack. The capture needs CAP_NET_RAW, the
+	// probes are timed as usual without it. Only supported on Linux.
+	Handshake bool
+	// Pcap, if set, gets the segments of the probes captured on the wire,
+	// to be analyzed with Wireshark. The capture needs CAP_NET_RAW.
+	// Only supported on Linux.
+	Pcap *PcapWriter
+	// EBPF times the handshakes of the probes in the kernel with an eBPF
+	// program, from tcp_v4_connect or tcp_v6_connect sending the SYN to the
+	// SYN-ACK establishing the connection, without the jitter of the Go
+	// scheduler. It's the RTT of the successful probes then. It needs
+	// CAP_BPF and CAP_PERFMON and tracefs, the probes are timed as usual
+	// without them. Only supported on Linux.
+	EBPF bool
+	// TFO connects with TCP Fast Open, sending a blank line in the SYN
+	// once the server has given a cookie. Only supported on Linux.
+	TFO bool
+	// MPTCP requests Multipath TCP connections, falling back to TCP if
+	// the kernel or the server doesn't support it. Only supported on Linux.
+	MPTCP bool
+	// Nagle enables Nagle's algorithm on the connections by clearing
+	// TCP_NODELAY, which Go sets, to mimic the applications that don't.
+	Nagle bool
+	// Linger sets SO_LINGER on the connections, so that closing them waits
+	// for up to this long, rounded up to seconds, for the unsent data to be
+	// acknowledged. Negative resets them on close instead, zero keeps the
+	// default of the OS.
+	Linger time.Duration
+	// KeepAlive is the period of the TCP keepalives of the connections,
+	// like net.Dialer.KeepAlive: zero keeps the default of Go and negative
+	// disables them. It can't be used with Persistent.
+	KeepAlive time.Duration
+	// Mark sets SO_MARK on the sockets of the probes, so that they follow
+	// the routing policy of the mark, e.g. the routing table of a VPN
+	// tunnel. It needs CAP_NET_ADMIN. Only supported on Linux.
+	Mark uint32
+	// BindDevice binds the sockets of the probes to the given interface
+	// or VRF with SO_BINDTODEVICE, so that they leave through it whatever
+	// the routing table says. Unlike InterfaceName, it doesn't pick the
+	// source address. Only supported on Linux.
+	BindDevice string
+	// Netns sends the probes from within the given network namespace,
+	// named like with `ip netns` or given by its path, e.g.
+	// /proc/PID/ns/net. The hostname is still resolved from the namespace
+	// of the process. It needs CAP_SYS_ADMIN. Only supported on Linux.
+	Netns string
+	// Proxy connects to the target through the proxy, with a CONNECT
+	// tunnel for the http and https schemes or with SOCKS5 for socks5
+	// and socks5h. The proxy resolves the hostname. The probes are timed
+	// until the tunnel is open and the failures of the proxy are reported
+	// with FailureProxy. The username and the password of the URL
+	// authenticate to the proxy, with Basic authentication over HTTP or
+	// with the username/password method of SOCKS5, and the probes whose
+	// credentials are rejected fail with FailureProxyAuth.
+	Proxy *url.URL
+	// Jump tunnels the probes through the SSH server, to reach the targets
+	// only reachable from it. The tunnel is set up once, within the timeout,
+	// and again whenever it breaks, see Result.TunnelSetup. Every probe
+	// opens a direct-tcpip channel to the target, its RTT is the time the
+	// server took to connect to it. The failures of the tunnel are reported
+	// with FailureProxy, or FailureProxyAuth if the server rejected the
+	// credentials. It can't be used with Proxy.
+	Jump *JumpHost
+	// Persistent keeps the connection of a successful probe open and
+	// checks it with the next probes, reconnecting once it's dropped.
+	// Only supported on Linux.
+	Persistent bool
+	// Banner reads the first line the server sends after connecting, like
+	// the banners of SSH or SMTP. The first one and its changes are printed,
+	// and the probes without a banner fail.
+	Banner bool
+	// TLSConfig performs a TLS handshake after connecting, its ServerName
+	// defaulting to Hostname. The certificate chain is printed once and
+	// whenever it changes, see Statistics.CertExpiry. nil means no TLS.
+	TLSConfig *tls.Config
+	// CertWarning warns when the certificate chain expires within it with TLSConfig.
+	CertWarning time.Duration
+	// Send is written after connecting, e.g. to ask the service for a response.
+	Send []byte
+	// Expect fails the probes whose response doesn't match it within the timeout.
+	// The first 4 KiB of the response are matched.
+	Expect *regexp.Regexp
+	// Prober checks the service after connecting, see [NewProber].
+	// It can't be used with Send and Expect.
+	Prober Prober
+	// GoodputSize streams a payload of this many bytes after connecting
+	// and reports the goodput, to tell a reachable target from one fast
+	// enough. The peer must close the connection once it has read it,
+	// like the discard services do, see Options.GoodputEcho otherwise.
+	// The transfer must be over within the timeout. 0 means none.
+	GoodputSize int64
+	// GoodputEcho reads the payload of Options.GoodputSize back as it's
+	// sent, for the peers echoing what they receive.
+	GoodputEcho bool
+	// SuccessCheck, if set, is called once connected and after the prober,
+	// with the probe as it stands. The probe fails if it returns an error,
+	// e.g. to check the health of the service in a way of one's own.
+	// The probes wait for it, and ctx is done when the pinger stops or after
+	// Options.Timeout, or the interval between probes without a timeout,
+	// which fails the probe.
+	SuccessCheck func(ctx context.Context, r Result) error
+	// CloseWait, if set, keeps the successful connections open for up to
+	// this long to find out whether the peer closes them with a FIN, resets
+	// them or keeps them open, see [PeerClose]. It must be shorter than
+	// IntervalBetweenProbes.
+	CloseWait time.Duration
+	// CompareICMP sends an ICMP echo to the target alongside every probe
+	// and prints its RTT, to tell slow networks from slow services.
+	// It needs unprivileged ICMP sockets or the privileges of raw ones.
+	CompareICMP bool
+	// PathMTU probes the path MTU to the target after the first successful
+	// connection with ICMP echoes that mustn't be fragmented, see
+	// Statistics.PathMTU. It needs the same sockets as CompareICMP.
+	// Only supported on Linux.
+	PathMTU bool
+	// IPLookup annotates the probed IP, e.g. with its ASN, at the start
+	// and whenever it changes. See [OpenMMDB].
+	IPLookup IPLookup
+	// ReverseDNS resolves the names of the probed IP from its PTR records
+	// with the Resolver, at the start and whenever the IP changes.
+	ReverseDNS bool
+	// Warmup is the number of first probes whose RTTs are left out of
+	// the statistics. They're still printed and counted as probes.
+	Warmup uint
+	// SmoothedRTT prints the exponentially weighted moving average of the
+	// RTTs after every successful probe, see Statistics.SmoothedRTT.
+	SmoothedRTT bool
+	// RecentRTTs is the number of most recent RTTs kept in memory. The median
+	// and the trimmed mean are exact as long as all the RTTs fit, and
+	// approximated within 1% after that, so that long runs use bounded memory.
+	// 0 means 10000.
+	RecentRTTs uint
+	// OutlierStdDevs flags the successful probes whose RTT is more than
+	// this many standard deviations above the mean of the previous ones,
+	// once there are 10 of them. 0 means never.
+	OutlierStdDevs float64
+	// OutlierWindow compares the RTTs to the mean of the last this many
+	// ones instead of all the previous ones, so that the baseline follows
+	// the slow changes of the path. 0 means all, it's at least 10 otherwise.
+	OutlierWindow uint
+	// NotifyOutliers notifies the hooks and the notifiers about the first
+	// outlier of a row, like when the target goes down, and about the next
+	// probe that isn't one, like when it comes back up.
+	NotifyOutliers bool
+	// RTTThreshold marks the successful probes slower than this as degraded,
+	// see Statistics.DegradedProbes. 0 means never.
+	RTTThreshold time.Duration
+	// NotifyDegraded notifies the hooks and the notifiers when the target
+	// becomes degraded and when it recovers, like when it goes down and up.
+	NotifyDegraded bool
+	// TrendWindow warns when the RTTs of this many most recent successful
+	// probes trend up, by the Mann-Kendall test, and rise by more than
+	// a tenth of their median, which often comes before the target fails.
+	// See Statistics.LatencyTrends. 0 means never, it's from 10 to 1000 otherwise.
+	TrendWindow uint
+	// NotifyTrend notifies the hooks and the notifiers when the RTTs
+	// start trending up and when they stop, like when the target goes
+	// down and up.
+	NotifyTrend bool
+	// AvailabilityWindow splits the statistics into windows of this size,
+	// e.g. an hour or a day, with the availability, the probes
+	// and the average RTT of each one.
+	// See Statistics.AvailabilityWindows. 0 means no windows.
+	AvailabilityWindow time.Duration
+	// Retries are the attempts made after a failed connection before
+	// the probe counts as failed, within the interval between the probes.
+	// It keeps a single dropped SYN from failing the probe.
+	Retries uint
+	// Burst makes this many connections back to back for every probe
+	// instead of one. The probe succeeds if any of them does, with the
+	// lowest of their RTTs, so that a single dropped SYN neither fails it
+	// nor skews its RTT, while an outage still does. 0 or 1 means one.
+	Burst uint
+	// BurstMedian takes the median RTT of the connections
+	// of Options.Burst instead of the lowest one.
+	BurstMedian bool
+	// BurstConcurrent makes the connections of Options.Burst at the same
+	// time and keeps them open until they're all done, to test how many
+	// the target accepts at once, e.g. to catch a full accept queue or
+	// a limit of connections per IP.
+	BurstConcurrent bool
+	// ShowLocalAddr prints the local address and port the OS picked for
+	// every successful connection, e.g. to debug NAT or the exhaustion
+	// of the ephemeral ports.
+	ShowLocalAddr bool
+	// ResolveInterval re-resolves target's hostname periodically,
+	// even while it's up. 0 means never.
+	ResolveInterval time.Duration
+	// ProbesBeforeQuit stops [Pinger.Run] after a certain number of probes.
+	// 0 means no limit.
+	ProbesBeforeQuit uint
+	// Timeout is the time to wait for a response. 0 means no timeout.
+	Timeout time.Duration
+	// IntervalBetweenProbes should be at least 2 ms.
+	IntervalBetweenProbes time.Duration
+	// Port of the target.
+	Port uint16
+	// UseIPv4 only uses the IPv4 addresses of the target.
+	UseIPv4 bool
+	// UseIPv6 only uses the IPv6 addresses of the target.
+	UseIPv6 bool
+}
+
+// Hooks are callbacks for the events of a [Pinger].
+// Any of them can be nil.
+//
+// They are called synchronously from the probing loop,
+// so they should return quickly.
+type Hooks struct {
+	// OnProbe is called after every probe.
+	OnProbe func(r Result)
+	// OnStateChange is called when the target goes down or comes back up.
+	OnStateChange func(change StateChange)
+	// OnStatistics is called whenever the statistics are printed,
+	// including on [Pinger.Shutdown].
+	OnStatistics func(s Statistics)
+}
+
+// Result is the outcome of a single probe.
+type Result struct {
+	// Seq is the sequence number of the probe, starting at 1.
+	Seq uint
+	// Time when the probe was sent.
+	Time     time.Time
+	Hostname string
+	IP       netip.Addr
+	// RTT in milliseconds, only set for successful probes.
+	RTT float32
+	// UserRTT is the RTT measured by tcping in milliseconds when RTT
+	// was measured in the kernel, only set with Options.EBPF.
+	UserRTT float32
+	// TunnelSetup is the time the SSH tunnel of Options.Jump took to
+	// set up in milliseconds, only set for the probes that set it up.
+	TunnelSetup float32
+	// TCPInfo is the kernel's view of the connection,
+	// only set for successful probes with Options.TCPInfo.
+	TCPInfo *TCPInfo
+	// Handshake is the timing of the handshake on the wire,
+	// only set for successful probes with Options.Handshake.
+	Handshake *Handshake
+	// TFO is true if the server accepted the data sent in
+	// the SYN, only set for successful probes with Options.TFO.
+	TFO bool
+	// MPTCP is true if Multipath TCP was negotiated,
+	// only set for successful probes with Options.MPTCP.
+	MPTCP bool
+	// LocalAddr is the address and the port the connection
+	// came from, only set for successful probes.
+	LocalAddr netip.AddrPort
+	// Banner is the first line sent by the server,
+	// only set for successful probes with Options.Banner.
+	Banner string
+	// ResponseTime is the time the service took to answer Options.Prober
+	// or Options.Expect in milliseconds, only set for successful probes.
+	ResponseTime float32
+	// Goodput is the goodput of the payload of Options.GoodputSize
+	// in Mbit/s, only set for successful probes.
+	Goodput float32
+	// ResolveTime is the time spent resolving the hostname since
+	// the previous probe, in milliseconds. 0 if it wasn't resolved.
+	ResolveTime float32
+	// SmoothedRTT is the exponentially weighted moving average
+	// of the RTTs in milliseconds, only set for successful probes.
+	SmoothedRTT float32
+	// Outlier is true if the RTT is far above the previous ones,
+	// only set for successful probes with Options.OutlierStdDevs.
+	Outlier bool
+	// Degraded is true if the RTT is above Options.RTTThreshold,
+	// only set for successful probes.
+	Degraded bool
+	// TrendingUp is true while the RTTs trend up, only set
+	// for successful probes with Options.TrendWindow.
+	TrendingUp bool
+	// Attempts is the number of connections attempted by the probe, see Options.Retries.
+	Attempts uint
+	// BurstMin, BurstMedian and BurstMax are the lowest, the median and
+	// the highest RTT of the connections of Options.Burst that succeeded
+	// in milliseconds, only set for successful probes.
+	BurstMin    float32
+	BurstMedian float32
+	BurstMax    float32
+	// BurstFailures is the number of connections of Options.Burst that failed.
+	BurstFailures uint
+	// Burst is the number of connections of Options.Burst made by the probe.
+	Burst uint
+	// FailureReason is the category of Err, only set for failed probes.
+	FailureReason FailureReason
+	// Err is why the probe failed, only set for failed probes.
+	Err error
+	// PeerClose is how the peer ended the connection and PeerCloseAfter
+	// how long after connecting, only set for successful probes with
+	// Options.CloseWait.
+	PeerClose      PeerClose
+	PeerCloseAfter time.Duration
+	// Streak is the number of consecutive probes with the same outcome.
+	Streak  uint
+	Port    uint16
+	Success bool
+}
+
+// TCPInfo is the kernel's view of a connection, read from TCP_INFO.
+type TCPInfo struct {
+	// SRTT is the smoothed RTT measured by the kernel, in milliseconds.
+	SRTT float32
+	// RTTVar is the variation of the RTT, in milliseconds.
+	RTTVar float32
+	// Retransmits is the number of segments retransmitted on the
+	// connection, the SYN included, or since the previous probe
+	// with Options.Persistent.
+	Retransmits uint32
+	// Cwnd is the congestion window in segments.
+	Cwnd uint32
+	// DeliveryRate is the most recent rate at which the data was
+	// delivered in Mbit/s, 0 if none was sent or the kernel doesn't
+	// report it.
+	DeliveryRate float32
+}
+
+// Handshake is the timing of the handshake of a probe on the wire,
+// captured with Options.Handshake. The times are in milliseconds.
+type Handshake struct {
+	// Network is the time from the SYN leaving to the SYN-ACK arriving.
+	Network float32
+	// Stack is the rest of the RTT of the probe, spent in the
+	// local stack and in tcping before the SYN and after the SYN-ACK.
+	Stack float32
+	// ACK is the time from the SYN-ACK arriving to the ACK leaving.
+	ACK float32
+}
+
+// Statistics is a snapshot of the statistics gathered by a [Pinger].
+type Statistics struct {
+	StartTime time.Time
+	// EndTime is zero until the Pinger is shut down.
+	EndTime               time.Time
+	LastSuccessfulProbe   time.Time
+	LastUnsuccessfulProbe time.Time
+	LongestUptime         LongestTime
+	LongestDowntime       LongestTime
+	// HostnameChanges starts with the initially resolved address.
+	HostnameChanges         []HostnameChange
+	Hostname                string
+	IP                      netip.Addr
+	TotalDowntime           time.Duration
+	TotalUptime             time.Duration
+	TotalSuccessfulProbes   uint
+	TotalUnsuccessfulProbes uint
+	RetriedHostnameLookups  uint
+	RttResults              RttResult
+	// DNSFailures is the number of failed resolutions while probing,
+	// which kept the previous IP, and LastDNSFailure when the last one was.
+	DNSFailures    uint
+	LastDNSFailure time.Time
+	// Failovers is the number of times the next address of the
+	// hostname was probed instead with Options.Failover.
+	Failovers uint
+	// Availability is the percentage of the monitored time the target was up.
+	Availability float64
+	// AvailabilityWindows are the windows of Options.AvailabilityWindow
+	// in which the target was probed, oldest first.
+	AvailabilityWindows []AvailabilityWindow
+	// PerIP are the statistics of every probed address, in the order
+	// they were first probed, only set if the probed address changed.
+	PerIP []IPStatistics
+	// KernelRttResults are the RTTs measured by the kernel,
+	// only set with Options.TCPInfo.
+	KernelRttResults RttResult
+	// Retransmits is the number of segments retransmitted on the
+	// connections of the successful probes, only set with Options.TCPInfo.
+	Retransmits uint
+	// NetworkRttResults are the times from the SYN to the SYN-ACK
+	// on the wire, only set with Options.Handshake.
+	NetworkRttResults RttResult
+	// UserRttResults are the RTTs measured by tcping when
+	// they were measured in the kernel, only set with Options.EBPF.
+	UserRttResults RttResult
+	// TunnelSetupResults are the times the SSH tunnel
+	// took to set up, only set with Options.Jump.
+	TunnelSetupResults RttResult
+	// BurstRttResults are the RTTs of all the connections
+	// of Options.Burst that succeeded.
+	BurstRttResults RttResult
+	// TFOAcceptedProbes is the number of successful probes whose data in the SYN
+	// was accepted. Their RTTs are in TFORttResults and the others' in
+	// RegularRttResults, all of them only set with Options.TFO.
+	TFOAcceptedProbes uint
+	TFORttResults     RttResult
+	RegularRttResults RttResult
+	// ResponseTimeResults are the times the service took to answer
+	// Options.Prober or Options.Expect after connecting.
+	ResponseTimeResults RttResult
+	// GoodputResults are the goodputs of the payload of Options.GoodputSize
+	// in Mbit/s rather than in milliseconds.
+	GoodputResults RttResult
+	// CertExpiry is when the first certificate of the last chain
+	// expires, only set with Options.TLSConfig.
+	CertExpiry time.Time
+	// MPTCPProbes is the number of successful probes
+	// that negotiated Multipath TCP with Options.MPTCP.
+	MPTCPProbes uint
+	// ICMPRttResults are the RTTs of the ICMP echoes sent alongside
+	// the probes and ICMPLostEchoes the number of unanswered ones,
+	// only set with Options.CompareICMP.
+	ICMPRttResults RttResult
+	ICMPLostEchoes uint
+	// PathMTU is the path MTU to the target in bytes,
+	// only set with Options.PathMTU once it's probed.
+	PathMTU int
+	// SmoothedRTT is the exponentially weighted moving average of the
+	// RTTs in milliseconds, weighting the last one by 1/8 like TCP does.
+	SmoothedRTT float32
+	// Outliers is the number of successful probes
+	// flagged with Options.OutlierStdDevs.
+	Outliers uint
+	// LatencyTrends is the number of times the RTTs
+	// started trending up, see Options.TrendWindow.
+	LatencyTrends uint
+	// DegradedProbes is the number of successful probes
+	// above Options.RTTThreshold.
+	DegradedProbes uint
+	// RetriedProbes is the number of successful probes
+	// that needed more than one attempt with Options.Retries.
+	RetriedProbes uint
+	// BurstFailures is the number of connections of Options.Burst that
+	// failed while the probe succeeded, the losses the burst smoothed out.
+	BurstFailures uint
+	// FailureReasons are the numbers of failed probes per reason.
+	FailureReasons map[FailureReason]uint
+	// PeerCloses are the numbers of successful probes per way
+	// the peer ended them, only set with Options.CloseWait.
+	PeerCloses map[PeerClose]uint
+	// PTRNames are the names of the PTR records of the IP,
+	// only set with Options.ReverseDNS.
+	PTRNames []string
+	// ResolvedAddrs are the addresses of the last successful resolution.
+	ResolvedAddrs []netip.Addr
+	// ResolveTimeResults are the times spent resolving the hostname,
+	// in milliseconds, including the initial resolution.
+	ResolveTimeResults RttResult
+	Port               uint16
+	// Service is the name of the service of the port, if it has one.
+	Service string
+	// IsIP is set when the target was given as an IP address.
+	IsIP bool
+}
+
+// Pinger probes a single target over TCP and keeps track of its statistics.
+type Pinger struct {
+	stats *stats
+	// statsRequests makes the probing loop print the statistics,
+	// so that they're not printed in the middle of a probe.
+	statsRequests chan struct{}
+	// snapshot is updated after every probe, so that the
+	// statistics can be read while the probing continues.
+	snapshot   Statistics
+	snapshotMu sync.RWMutex
+	// stop makes Run return, it's closed by Stop.
+	stop     chan struct{}
+	stopOnce sync.Once
+	// printer is the actual printer, which prints the events
+	// queued by the probes on its own goroutine while probing.
+	printer Printer
+	events  chan printEvent
+}
+
+type stats struct {
+	startTime                 time.Time
+	endTime                   time.Time
+	startOfUptime             time.Time
+	startOfDowntime           time.Time
+	lastSuccessfulProbe       time.Time
+	lastUnsuccessfulProbe     time.Time
+	lastProbe                 time.Time
+	printer                   Printer      // printer holds the chosen printer implementation for outputting information and data.
+	ticker                    *time.Ticker // ticker is used to handle time between probes.
+	longestUptime             LongestTime
+	longestDowntime           LongestTime
+	rtt                       rttStats
+	kernelRtt                 rttStats     // kernelRtt are the smoothed RTTs read from TCP_INFO.
+	retransmits               uint         // retransmits are the retransmissions read from TCP_INFO.
+	tcpInfo                   *TCPInfo     // tcpInfo is reported with the next successful probe.
+	networkRtt                rttStats     // networkRtt are the times from the SYN to the SYN-ACK captured with Options.Handshake.
+	handshake                 *Handshake   // handshake is reported with the next successful probe.
+	userRtts                  rttStats     // userRtts are the RTTs measured by tcping of the probes timed with Options.EBPF.
+	userRtt                   float32      // userRtt is reported with the next successful probe.
+	jumpClient                *ssh.Client  // jumpClient is the SSH tunnel of Options.Jump, nil until it's set up.
+	tunnelSetups              rttStats     // tunnelSetups are the times the SSH tunnel took to set up.
+	burstRtts                 rttStats     // burstRtts are the RTTs of the connections of the bursts.
+	tunnelSetup               float32      // tunnelSetup is reported with the next probe.
+	tfoAccepted               bool         // tfoAccepted is reported with the next successful probe.
+	tfoRtt                    rttStats     // tfoRtt are the RTTs of the probes whose data in the SYN was accepted.
+	regularRtt                rttStats     // regularRtt are the RTTs of the other probes made with Options.TFO.
+	mptcp                     bool         // mptcp is reported with the next successful probe.
+	lastMPTCP                 *bool        // lastMPTCP is the last printed MPTCP negotiation, nil before the first one.
+	mptcpProbes               uint         // mptcpProbes are the successful probes that negotiated MPTCP.
+	resolveTimes              rttStats     // resolveTimes are the durations of the hostname resolutions in ms.
+	lastResolveTime           float32      // lastResolveTime is reported with the next probe.
+	resolvedAddrs             []netip.Addr // resolvedAddrs are the addresses of the last successful resolution.
+	resolveExpiry             time.Time    // resolveExpiry is when the TTL of the resolved address expires, zero if unknown.
+	nextResolve               time.Time    // nextResolve is when the hostname is resolved again with Options.ResolveInterval.
+	nextAddr                  uint         // nextAddr is the index of the address picked next with IPSelectRoundRobin.
+	addrFailures              uint         // addrFailures are the failed probes in a row of the current address.
+	failovers                 uint         // failovers are the times the next address was probed with Options.Failover.
+	perIP                     []ipStats    // perIP are the probes of every probed address, in the order they were first probed.
+	hostnameChanges           []HostnameChange
+	failureReasons            map[FailureReason]uint
+	peerCloses                map[PeerClose]uint
+	notifiers                 []Notifier        // notifiers are informed whenever the target goes down or comes back up.
+	persistent                *persistentConn   // persistent is the connection kept open with Options.Persistent.
+	banner                    string            // banner is the last banner read with Options.Banner.
+	responseTimes             rttStats          // responseTimes are the times the service took to answer the prober in ms.
+	goodputs                  rttStats          // goodputs are the goodputs of Options.GoodputSize in Mbit/s.
+	goodput                   float32           // goodput is reported with the next successful probe.
+	lastResponseTime          float32           // lastResponseTime is reported with the next successful probe.
+	certificate               *x509.Certificate // certificate is the leaf of the last chain with Options.TLSConfig.
+	certExpiry                time.Time         // certExpiry is when the first certificate of the chain expires.
+	certWarned                bool              // certWarned is set once the expiry of the chain has been warned about.
+	clientCertRequested       bool              // clientCertRequested is set when the server of the last handshake asked for a client certificate.
+	icmpSeq                   uint16            // icmpSeq is the sequence number of the last ICMP echo.
+	icmpRtt                   rttStats          // icmpRtt are the RTTs of the ICMP echoes sent with Options.CompareICMP.
+	icmpLost                  uint              // icmpLost is the number of unanswered ICMP echoes.
+	mtuProbed                 bool              // mtuProbed is set once the path MTU has been probed with Options.PathMTU.
+	pathMTU                   int               // pathMTU is the path MTU found with Options.PathMTU.
+	lookedUpIP                netip.Addr        // lookedUpIP is the last IP looked up with Options.IPLookup.
+	localAddr                 netip.AddrPort    // localAddr is reported with the next successful probe.
+	peerClose                 PeerClose         // peerClose is reported with the next successful probe.
+	peerCloseAfter            time.Duration     // peerCloseAfter is how long the connection stayed open with Options.CloseWait.
+	attempts                  uint              // attempts is reported with the next probe.
+	retriedProbes             uint              // retriedProbes are the successful probes that needed retries.
+	burst                     burst             // burst is reported with the next probe.
+	burstFailures             uint              // burstFailures are the failed connections of the successful bursts.
+	outliers                  uint              // outliers are the probes flagged with Options.OutlierStdDevs.
+	outlierRtts               []float32         // outlierRtts are the last RTTs with Options.OutlierWindow, oldest first.
+	outlying                  bool              // outlying is set while the probes are outliers.
+	startOfOutliers           time.Time         // startOfOutliers is when the outliers started.
+	srtt                      float32           // srtt is the exponentially weighted moving average of the RTTs.
+	degraded                  bool              // degraded is set while the probes are above Options.RTTThreshold.
+	degradedProbes            uint              // degradedProbes are the probes above Options.RTTThreshold.
+	startOfDegradation        time.Time         // startOfDegradation is when the target became degraded.
+	trend                     trend             // trend follows the recent RTTs with Options.TrendWindow.
+	latencyTrends             uint              // latencyTrends are the times the RTTs started trending up.
+	ptrIP                     netip.Addr        // ptrIP is the last IP whose PTR records were resolved with Options.ReverseDNS.
+	ptrNames                  []string          // ptrNames are the names of the PTR records of ptrIP.
+	userInput                 userInput
+	ongoingSuccessfulProbes   uint
+	ongoingUnsuccessfulProbes uint
+	totalDowntime             time.Duration
+	totalUptime               time.Duration
+	totalSuccessfulProbes     uint
+	totalUnsuccessfulProbes   uint
+	retriedHostnameLookups    uint
+	dnsFailures               uint
+	lastDNSFailure            time.Time
+	availabilityWindows       []AvailabilityWindow
+	rttResults                RttResult
+	wasDown                   bool // wasDown is used to determine the duration of a downtime
+	lastProbeUp               bool // lastProbeUp is the result of lastProbe, whose time isn't accounted yet
+	isIP                      bool // isIP suppresses printing the IP information twice when hostname is not provided
+}
+
+type userInput struct {
+	Options
+	prober             Prober
+	ip                 netip.Addr
+	networkInterface   networkInterface
+	shouldRetryResolve bool
+	netns              *os.File       // netns is the namespace of Options.Netns.
+	capture            *capture       // capture captures the probes with Options.Handshake.
+	handshakes         *handshakes    // handshakes are the handshakes captured with Options.Handshake.
+	pcapFlows          *pcapFlows     // pcapFlows writes the probes captured with Options.Pcap.
+	captureErr         error          // captureErr is why the probes couldn't be captured.
+	connectTracer      *connectTracer // connectTracer times the probes with Options.EBPF.
+	tracerErr          error          // tracerErr is why the probes couldn't be timed with Options.EBPF.
+}
+
+type networkInterface struct {
+	raddr  *net.TCPAddr
+	dialer net.Dialer
+	use    bool
+}
+
+// LongestTime is the longest period of time the target was up or down.
+type LongestTime struct {
+	Start    time.Time
+	End      time.Time
+	Duration time.Duration
+}
+
+// RttResult holds the min, max and average RTT in milliseconds.
+type RttResult struct {
+	Min     float32
+	Max     float32
+	Average float32
+	// Median and TrimmedMean, the average without the lowest and the highest
+	// tenth of the RTTs, are barely moved by a few hiccups, unlike Average.
+	Median      float32
+	TrimmedMean float32
+	// StdDev is the standard deviation of the RTTs.
+	StdDev float32
+	// HasResults is false when none of the probes succeeded.
+	HasResults bool
+}
+
+// HostnameChange records the target's hostname resolving to a new address.
+type HostnameChange struct {
+	Addr netip.Addr `json:"addr,omitempty"`
+	When time.Time  `json:"when,omitempty"`
+}
+
+// New validates the options, resolves the target and
+// returns a Pinger that is ready to [Pinger.Run].
+func New(opts Options) (*Pinger, error) {
+	tcpStats := &stats{
+		printer:   opts.Printer,
+		notifiers: opts.Notifiers,
+		userInput: userInput{Options: opts},
+	}
+	tcpStats.keepRecentRtts(opts.RecentRTTs)
+
+	if opts.Printer == nil {
+		return nil, errors.New("a printer is required")
+	}
+
+	if opts.Hostname == "" && opts.SRV == "" {
+		return nil, errors.New("a hostname or an IP address is required")
+	}
+
+	if opts.Port == 0 && opts.SRV == "" {
+		return nil, errors.New("port should be in 1..65535 range")
+	}
+
+	if opts.UseIPv4 && opts.UseIPv6 {
+		return nil, errors.New("only one IP version can be specified")
+	}
+
+	if opts.TCPInfo && !tcpInfoSupported {
+		return nil, errors.New("TCP_INFO is only supported on Linux")
+	}
+
+	if opts.Handshake && !captureSupported {
+		return nil, errors.New("capturing the handshakes is only supported on Linux")
+	}
+
+	if opts.Pcap != nil && !captureSupported {
+		return nil, errors.New("capturing the probes is only supported on Linux")
+	}
+
+	if opts.EBPF && !ebpfSupported {
+		return nil, errors.New("eBPF is only supported on Linux")
+	}
+
+	if opts.TFO && !tfoSupported {
+		return nil, errors.New("TCP Fast Open is only supported on Linux")
+	}
+
+	if opts.MPTCP && runtime.GOOS != "linux" {
+		return nil, errors.New("MPTCP is only supported on Linux")
+	}
+
+	if opts.Mark != 0 && !socketOptionsSupported {
+		return nil, errors.New("SO_MARK is only supported on Linux")
+	}
+
+	if opts.BindDevice != "" {
+		if !socketOptionsSupported {
+			return nil, errors.New("SO_BINDTODEVICE is only supported on Linux")
+		}
+		// the interfaces of another namespace can't be listed from here
+		if _, err := net.InterfaceByName(opts.BindDevice); err != nil && opts.Netns == "" {
+			return nil, fmt.Errorf("interface %s not found", opts.BindDevice)
+		}
+	}
+
+	if opts.Netns != "" && !netnsSupported {
+		return nil, errors.New("network namespaces are only supported on Linux")
+	}
+
+	if opts.PathMTU && !mtuSupported {
+		return nil, errors.New("probing the path MTU is only supported on Linux")
+	}
+
+	if opts.Persistent && !persistentSupported {
+		return nil, errors.New("persistent connections are only supported on Linux")
+	}
+
+	if opts.Persistent && opts.TFO {
+		return nil, errors.New("TCP Fast Open needs a new connection for every probe")
+	}
+
+	if opts.Persistent && opts.Burst > 1 {
+		return nil, errors.New("a burst needs new connections for every probe")
+	}
+
+	if (opts.BurstMedian || opts.BurstConcurrent) && opts.Burst < 2 {
+		return nil, errors.New("the median and the concurrency of the burst need a burst of at least 2 connections")
+	}
+
+	// they'd test the proxy or the jump host, and the data in the SYN is for one connection
+	if opts.BurstConcurrent && (opts.Proxy != nil || opts.Jump != nil || opts.TFO) {
+		return nil, errors.New("the concurrent connections can't go through a proxy or a jump host, or use TCP Fast Open")
+	}
+
+	if opts.Proxy != nil {
+		if err := checkProxy(opts.Proxy); err != nil {
+			return nil, err
+		}
+	}
+
+	if opts.Proxy != nil && opts.Jump != nil {
+		return nil, errors.New("a proxy can't be used with a jump host")
+	}
+
+	if opts.Proxy != nil || opts.Jump != nil {
+		if opts.Persistent || opts.TFO || opts.MPTCP {
+			return nil, errors.New("persistent, TCP Fast Open and MPTCP connections can't go through a proxy or a jump host")
+		}
+		// they'd see the connection to the proxy
+		if opts.TCPInfo || opts.Handshake || opts.EBPF || opts.Pcap != nil {
+			return nil, errors.New("the connections through a proxy or a jump host can't be inspected")
+		}
+	}
+
+	prober := opts.Prober
+	if opts.Send != nil || opts.Expect != nil {
+		if prober != nil {
+			return nil, errors.New("a prober can't be used with a payload")
+		}
+		prober = exchangeProber{{send: opts.Send, expect: opts.Expect}}
+	}
+
+	if opts.Persistent && prober != nil {
+		return nil, errors.New("the prober needs a new connection for every probe")
+	}
+
+	if opts.GoodputSize < 0 {
+		return nil, errors.New("the size of the goodput payload can't be negative")
+	}
+
+	if opts.GoodputEcho && opts.GoodputSize == 0 {
+		return nil, errors.New("the echo needs the size of the goodput payload")
+	}
+
+	if opts.GoodputSize > 0 && (opts.Persistent || prober != nil) {
+		return nil, errors.New("the goodput needs a new connection for every probe, without a prober or a payload")
+	}
+
+	if opts.Persistent && opts.KeepAlive != 0 {
+		return nil, errors.New("the keepalives of the persistent connection are sent every interval")
+	}
+
+	if opts.Persistent && opts.CloseWait > 0 {
+		return nil, errors.New("the close wait needs a new connection for every probe")
+	}
+
+	if opts.CloseWait > 0 && opts.CloseWait >= opts.IntervalBetweenProbes {
+		return nil, errors.New("the close wait must be shorter than the interval between probes")
+	}
+
+	if opts.Persistent && opts.Handshake {
+		return nil, errors.New("the handshake breakdown needs a new connection for every probe")
+	}
+
+	if opts.Persistent && opts.EBPF {
+		return nil, errors.New("the eBPF timing needs a new connection for every probe")
+	}
+
+	if opts.Persistent && opts.SuccessCheck != nil {
+		return nil, errors.New("the success check needs a new connection for every probe")
+	}
+
+	if opts.Persistent && opts.TLSConfig != nil {
+		return nil, errors.New("TLS needs a new connection for every probe")
+	}
+
+	if opts.Persistent && opts.Banner {
+		return nil, errors.New("the banner needs a new connection for every probe")
+	}
+
+	// the probers read the greeting of the server on their own
+	if opts.Banner && opts.Prober != nil {
+		return nil, errors.New("the banner can't be read with a prober")
+	}
+
+	tcpStats.userInput.prober = prober
+
+	if opts.CompareICMP {
+		conn, _, err := listenICMP(opts.UseIPv6)
+		if err != nil {
+			return nil, fmt.Errorf("unable to open an ICMP socket: %w", err)
+		}
+		conn.Close()
+	}
+
+	if opts.OutlierStdDevs < 0 {
+		return nil, errors.New("the standard deviations of the outliers can't be negative")
+	}
+
+	if opts.OutlierWindow > 0 && opts.OutlierWindow < minOutlierSamples {
+		return nil, fmt.Errorf("the outlier window needs at least %d probes", minOutlierSamples)
+	}
+
+	if opts.TrendWindow > 0 && opts.TrendWindow < minTrendWindow {
+		return nil, fmt.Errorf("the trend window needs at least %d probes", minTrendWindow)
+	}
+
+	if opts.TrendWindow > maxTrendWindow {
+		return nil, fmt.Errorf("the trend window can't be more than %d probes", maxTrendWindow)
+	}
+
+	if opts.RTTThreshold < 0 {
+		return nil, errors.New("the RTT threshold can't be negative")
+	}
+
+	if opts.AvailabilityWindow < 0 {
+		return nil, errors.New("the availability window can't be negative")
+	}
+
+	if opts.IntervalBetweenProbes < 2*time.Millisecond {
+		return nil, errors.New("wait interval should be more than 2 ms")
+	}
+
+	switch opts.IPSelect {
+	case "", IPSelectFirst, IPSelectRandom, IPSelectRoundRobin, IPSelectFastest:
+	default:
+		return nil, fmt.Errorf("invalid IP selection policy: %s. Use one of '%s', '%s', '%s' or '%s'",
+			opts.IPSelect, IPSelectFirst, IPSelectRandom, IPSelectRoundRobin, IPSelectFastest)
+	}
+
+	switch opts.Bell {
+	case "", BellOnFail, BellOnSuccess, BellOnChange:
+	default:
+		return nil, fmt.Errorf("invalid bell event: %s. Use one of '%s', '%s' or '%s'",
+			opts.Bell, BellOnFail, BellOnSuccess, BellOnChange)
+	}
+
+	if opts.SRV != "" {
+		hostname, port, err := lookupSRV(context.Background(), opts)
+		if err != nil {
+			return nil, err
+		}
+		tcpStats.userInput.Hostname = hostname
+		tcpStats.userInput.Port = port
+
+		if opts.RetryHostnameLookupAfter == 0 {
+			tcpStats.userInput.RetryHostnameLookupAfter = 1
+		}
+	}
+
+	ip, err := resolveHostname(context.Background(), tcpStats)
+	if err != nil {
+		return nil, err
+	}
+
+	// link-local addresses are only meaningful on a given interface
+	if ip.Is6() && ip.IsLinkLocalUnicast() && ip.Zone() == "" {
+		if _, err := net.InterfaceByName(opts.InterfaceName); opts.InterfaceName == "" || err != nil {
+			return nil, fmt.Errorf("link-local address %s needs a zone or an interface, e.g. %s%%eth0", ip, ip)
+		}
+
+		ip = ip.WithZone(opts.InterfaceName)
+		if tcpStats.userInput.Hostname == ip.WithZone("").String() {
+			tcpStats.userInput.Hostname = ip.String()
+		}
+	}
+
+	tcpStats.userInput.ip = ip
+	tcpStats.startTime = time.Now()
+
+	// this serves as a default starting value for tracking changes.
+	tcpStats.hostnameChanges = []HostnameChange{
+		{tcpStats.userInput.ip, time.Now()},
+	}
+
+	if tcpStats.userInput.Hostname == tcpStats.userInput.ip.String() {
+		tcpStats.isIP = true
+	}
+
+	if tcpStats.userInput.RetryHostnameLookupAfter > 0 && !tcpStats.isIP {
+		tcpStats.userInput.shouldRetryResolve = true
+	}
+
+	if opts.InterfaceName != "" {
+		tcpStats.userInput.networkInterface, err = newNetworkInterface(tcpStats, opts.InterfaceName)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if opts.Netns != "" {
+		if tcpStats.userInput.netns, err = openNetns(opts.Netns); err != nil {
+			return nil, fmt.Errorf("unable to open the network namespace: %w", err)
+		}
+	}
+
+	if opts.Handshake || opts.Pcap != nil {
+		if err := tcpStats.openCapture(); err != nil {
+			return nil, err
+		}
+	}
+
+	if opts.EBPF {
+		tcpStats.openConnectTracer()
+	}
+
+	p := &Pinger{
+		stats:         tcpStats,
+		statsRequests: make(chan struct{}, 1),
+		stop:          make(chan struct{}),
+		printer:       tcpStats.printer,
+	}
+	p.updateSnapshot()
+
+	return p, nil
+}
+
+// Run prints the start message and probes the target until
+// Options.ProbesBeforeQuit is reached, [Pinger.Stop] is called
+// or forever otherwise.
+//
+// The probes don't wait for the printer, which prints them on its own
+// goroutine until [Pinger.Shutdown]. Everything they printed is printed
+// by the time Run returns.
+func (p *Pinger) Run() {
+	p.RunContext(context.Background())
+}
+
+// RunContext is like [Pinger.Run], but it also returns once ctx is done.
+// Stopping the pinger either way cancels the resolution or the probe in
+// flight, which isn't counted.
+func (p *Pinger) RunContext(ctx context.Context) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	go func() {
+		select {
+		case <-p.stop:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	tcpStats := p.stats
+	if _, ok := tcpStats.printer.(queuePrinter); !ok {
+		p.startPrinting()
+	}
+	defer p.flush()
+
+	tcpStats.ticker = time.NewTicker(tcpStats.userInput.IntervalBetweenProbes)
+	defer tcpStats.ticker.Stop()
+	defer tcpStats.closePersistent()
+	defer tcpStats.closeJump()
+
+	tcpStats.printStart()
+	if err := tcpStats.userInput.captureErr; err != nil {
+		tcpStats.printer.PrintInfo("Unable to capture the handshakes, timing the probes as usual: %s", err)
+	}
+	if err := tcpStats.userInput.tracerErr; err != nil {
+		tcpStats.printer.PrintInfo("Unable to time the probes with eBPF, timing them as usual: %s", err)
+	}
+	if u := tcpStats.userInput.Proxy; u != nil {
+		tcpStats.printer.PrintInfo("Connecting through the proxy %s", u.Redacted())
+	}
+	if jump := tcpStats.userInput.Jump; jump != nil {
+		tcpStats.printer.PrintInfo("Connecting through the SSH tunnel to %s", jump.Addr)
+	}
+
+	var probeCount uint = 0
+	for {
+		if tcpStats.userInput.ResolveEveryProbe && !tcpStats.isIP {
+			resolveBeforeProbe(ctx, tcpStats)
+		} else {
+			if tcpStats.userInput.Failover > 0 {
+				tcpStats.failover()
+			}
+			if tcpStats.userInput.shouldRetryResolve {
+				retryResolveHostname(ctx, tcpStats)
+			}
+			if tcpStats.resolveIsDue() {
+				refreshHostname(ctx, tcpStats)
+			}
+		}
+
+		if tcpStats.userInput.ReverseDNS {
+			tcpStats.lookupPTR()
+		}
+
+		if tcpStats.userInput.IPLookup != nil {
+			tcpStats.lookupIP()
+		}
+
+		tcping(ctx, tcpStats)
+		p.updateSnapshot()
+
+		if !p.wait(ctx) {
+			return
+		}
+
+		if tcpStats.userInput.ProbesBeforeQuit != 0 {
+			probeCount++
+			if probeCount == tcpStats.userInput.ProbesBeforeQuit {
+				return
+			}
+		}
+	}
+}
+
+// wait waits for the next probe, printing the statistics whenever
+// they're requested in the meantime. It returns false once ctx is done.
+func (p *Pinger) wait(ctx context.Context) bool {
+	for {
+		select {
+		case <-p.stats.ticker.C:
+			return true
+		case <-p.statsRequests:
+			p.stats.printStats()
+		case <-ctx.Done():
+			return false
+		}
+	}
+}
+
+// RequestStatistics makes [Pinger.Run] print the statistics
+// between the probes, without stopping.
+func (p *Pinger) RequestStatistics() {
+	select {
+	case p.statsRequests <- struct{}{}:
+	default:
+		// statistics are already requested
+	}
+}
+
+// Stop makes [Pinger.Run] return, cancelling the current probe.
+// It is safe to call it from other goroutines and more than once.
+func (p *Pinger) Stop() {
+	p.stopOnce.Do(func() {
+		close(p.stop)
+	})
+}
+
+// Shutdown calculates endTime, prints the final statistics,
+// lets the notifiers deliver theirs and closes the printer.
+// The pinger can't be run anymore afterwards.
+func (p *Pinger) Shutdown() {
+	tcpStats := p.stats
+	tcpStats.endTime = time.Now()
+	tcpStats.printStats()
+	p.updateSnapshot()
+
+	// give the notifiers a chance to deliver the
+	// final statistics and the pending notifications
+	for _, n := range tcpStats.notifiers {
+		if sn, ok := n.(StatisticsNotifier); ok {
+			sn.NotifyStatistics(tcpStats.statistics())
+		}
+	}
+	p.stopPrinting()
+
+	// if the printer type is `database`, then close the db before
+	// exiting to prevent any memory leaks
+	eachPrinter(p.printer, func(printer Printer) {
+		if db, ok := printer.(*database); ok {
+			db.conn.Close()
+		}
+	})
+
+	if tcpStats.userInput.netns != nil {
+		tcpStats.userInput.netns.Close()
+	}
+
+	if tcpStats.userInput.capture != nil {
+		tcpStats.userInput.capture.close()
+	}
+
+	if tcpStats.userInput.connectTracer != nil {
+		tcpStats.userInput.connectTracer.close()
+	}
+}
+
+// Statistics returns the statistics as of the last probe.
+//
+// Unlike the rest of the methods, it is safe to call it
+// from other goroutines while [Pinger.Run] is probing.
+func (p *Pinger) Statistics() Statistics {
+	p.snapshotMu.RLock()
+	defer p.snapshotMu.RUnlock()
+
+	return p.snapshot
+}
+
+// updateSnapshot takes a snapshot of the statistics for [Pinger.Statistics].
+func (p *Pinger) updateSnapshot() {
+	s := p.stats.statistics()
+	s.RttResults = p.stats.rtt.results()
+
+	p.snapshotMu.Lock()
+	p.snapshot = s
+	p.snapshotMu.Unlock()
+}
+
+// printStats is a helper method for PrintStatistics
+// for the current printer.
+//
+// This should be used instead, as it makes
+// all the necessary calculations beforehand.
+func (tcpStats *stats) printStats() {
+	now := tcpStats.now()
+	if tcpStats.wasDown {
+		calcLongestDowntime(tcpStats, now.Sub(tcpStats.startOfDowntime))
+	} else {
+		calcLongestUptime(tcpStats, now.Sub(tcpStats.startOfUptime))
+	}
+	tcpStats.rttResults = tcpStats.rtt.results()
+
+	s := tcpStats.statistics()
+	tcpStats.printer.PrintStatistics(s)
+
+	if hook := tcpStats.userInput.Hooks.OnStatistics; hook != nil {
+		hook(s)
+	}
+}
+
+// statistics takes a snapshot of the current statistics.
+func (tcpStats *stats) statistics() Statistics {
+	uptime, downtime, windows := tcpStats.accountedTime()
+
+	return Statistics{
+		StartTime:               tcpStats.startTime,
+		EndTime:                 tcpStats.endTime,
+		LastSuccessfulProbe:     tcpStats.lastSuccessfulProbe,
+		LastUnsuccessfulProbe:   tcpStats.lastUnsuccessfulProbe,
+		LongestUptime:           tcpStats.longestUptime,
+		LongestDowntime:         tcpStats.longestDowntime,
+		HostnameChanges:         append([]HostnameChange(nil), tcpStats.hostnameChanges...),
+		Hostname:                tcpStats.userInput.Hostname,
+		IP:                      tcpStats.userInput.ip,
+		TotalDowntime:           downtime,
+		TotalUptime:             uptime,
+		TotalSuccessfulProbes:   tcpStats.totalSuccessfulProbes,
+		TotalUnsuccessfulProbes: tcpStats.totalUnsuccessfulProbes,
+		RetriedHostnameLookups:  tcpStats.retriedHostnameLookups,
+		DNSFailures:             tcpStats.dnsFailures,
+		LastDNSFailure:          tcpStats.lastDNSFailure,
+		Failovers:               tcpStats.failovers,
+		Availability:            availability(uptime, downtime),
+		AvailabilityWindows:     windows,
+		PerIP:                   tcpStats.ipStatistics(),
+		RttResults:              tcpStats.rttResults,
+		KernelRttResults:        tcpStats.kernelRtt.results(),
+		Retransmits:             tcpStats.retransmits,
+		NetworkRttResults:       tcpStats.networkRtt.results(),
+		UserRttResults:          tcpStats.userRtts.results(),
+		TunnelSetupResults:      tcpStats.tunnelSetups.results(),
+		BurstRttResults:         tcpStats.burstRtts.results(),
+		TFOAcceptedProbes:       tcpStats.tfoRtt.count,
+		TFORttResults:           tcpStats.tfoRtt.results(),
+		RegularRttResults:       tcpStats.regularRtt.results(),
+		MPTCPProbes:             tcpStats.mptcpProbes,
+		ICMPRttResults:          tcpStats.icmpRtt.results(),
+		ICMPLostEchoes:          tcpStats.icmpLost,
+		PathMTU:                 tcpStats.pathMTU,
+		PTRNames:                append([]string(nil), tcpStats.ptrNames...),
+		FailureReasons:          maps.Clone(tcpStats.failureReasons),
+		PeerCloses:              maps.Clone(tcpStats.peerCloses),
+		RetriedProbes:           tcpStats.retriedProbes,
+		BurstFailures:           tcpStats.burstFailures,
+		Outliers:                tcpStats.outliers,
+		LatencyTrends:           tcpStats.latencyTrends,
+		DegradedProbes:          tcpStats.degradedProbes,
+		SmoothedRTT:             tcpStats.srtt,
+		CertExpiry:              tcpStats.certExpiry,
+		ResponseTimeResults:     tcpStats.responseTimes.results(),
+		GoodputResults:          tcpStats.goodputs.results(),
+		ResolvedAddrs:           append([]netip.Addr(nil), tcpStats.resolvedAddrs...),
+		ResolveTimeResults:      tcpStats.resolveTimes.results(),
+		Port:                    tcpStats.userInput.Port,
+		Service:                 tcpStats.userInput.service(),
+		IsIP:                    tcpStats.isIP,
+	}
+}
+
+// newNetworkInterface uses the 1st ip address of the interface
+// or returns an error if the interface or its address can't be found.
+func newNetworkInterface(tcpStats *stats, netInterface string) (networkInterface, error) {
+	target := tcpStats.userInput.ip
+
+	// if netinterface is the address `interfaceAddress` will be valid
+	interfaceAddress, err := netip.ParseAddr(netInterface)
+
+	if err != nil {
+		ief, err := net.InterfaceByName(netInterface)
+		if err != nil {
+			return networkInterface{}, fmt.Errorf("interface %s not found", netInterface)
+		}
+
+		addrs, err := ief.Addrs()
+		if err != nil {
+			return networkInterface{}, errors.New("unable to get interface addresses")
+		}
+
+		// Iterating through the available addresses to find one of the same family as the target
+		for _, addr := range addrs {
+			ipNet, ok := addr.(*net.IPNet)
+			if !ok {
+				continue
+			}
+
+			nipAddr, ok := netip.AddrFromSlice(ipNet.IP)
+			if !ok {
+				continue
+			}
+			nipAddr = nipAddr.Unmap()
+
+			if nipAddr.Is4() && target.Is4() {
+				interfaceAddress = nipAddr
+				break
+			} else if nipAddr.Is6() && target.Is6() {
+				// link-local targets are only reachable from link-local addresses
+				if nipAddr.IsLinkLocalUnicast() != target.IsLinkLocalUnicast() {
+					continue
+				}
+				if nipAddr.IsLinkLocalUnicast() {
+					nipAddr = nipAddr.WithZone(ief.Name)
+				}
+				interfaceAddress = nipAddr
+				break
+			}
+		}
+
+		if !interfaceAddress.IsValid() {
+			return networkInterface{}, errors.New("unable to get interface's IP address")
+		}
+	}
+
+	// Initializing a networkInterface struct and setting the 'use' field to true
+	ni := networkInterface{
+		use: true,
+	}
+
+	// remote address
+	ni.raddr = &net.TCPAddr{
+		IP:   target.AsSlice(),
+		Zone: target.Zone(),
+		Port: int(tcpStats.userInput.Port),
+	}
+
+	// local address
+	laddr := &net.TCPAddr{
+		IP:   interfaceAddress.AsSlice(),
+		Zone: interfaceAddress.Zone(),
+	}
+
+	ni.dialer = net.Dialer{
+		LocalAddr: laddr,
+		Timeout:   tcpStats.userInput.Timeout, // Set the timeout duration
+	}
+	tcpStats.log().Info("binding the probes to the interface", "interface", netInterface, "local", interfaceAddress)
+
+	return ni, nil
+}
+
+// resolveHostname handles hostname resolution with a timeout value of a second
+func resolveHostname(ctx context.Context, tcpStats *stats) (netip.Addr, error) {
+	ip, err := netip.ParseAddr(tcpStats.userInput.Hostname)
+	if err == nil {
+		return ip, nil
+	}
+
+	tcpStats.log().Debug("resolving the hostname", "hostname", tcpStats.userInput.Hostname,
+		"custom_resolver", tcpStats.userInput.Resolver != nil)
+	resolveStart := time.Now()
+	ipAddrs, err := lookupAddrs(ctx, tcpStats.userInput.Options)
+	if ctx.Err() != nil {
+		// the pinger is stopping, keep the current address
+		return tcpStats.userInput.ip, nil
+	}
+	resolveTime := time.Since(resolveStart)
+	tcpStats.lastResolveTime = nanoToMillisecond(resolveTime.Nanoseconds())
+	tcpStats.resolveTimes.add(tcpStats.lastResolveTime)
+
+	if err != nil {
+		tcpStats.log().Debug("failed to resolve the hostname", "hostname", tcpStats.userInput.Hostname, "error", err)
+	}
+
+	// Prevent tcping to exit if it has been running for a while
+	if err != nil && (tcpStats.totalSuccessfulProbes != 0 || tcpStats.totalUnsuccessfulProbes != 0) {
+		tcpStats.recordDNSFailure(fmt.Errorf("failed to resolve %s: %w", tcpStats.userInput.Hostname, err))
+		return tcpStats.userInput.ip, nil
+	} else if err != nil {
+		return ip, fmt.Errorf("failed to resolve %s: %w", tcpStats.userInput.Hostname, err)
+	}
+
+	tcpStats.log().Info("resolved the hostname", "hostname", tcpStats.userInput.Hostname, "addrs", ipAddrs,
+		"resolve_time", resolveTime, "static", lookupHosts(tcpStats.userInput.Hosts, tcpStats.userInput.Hostname) != nil)
+
+	ip, err = selectResolvedIP(ctx, tcpStats, ipAddrs)
+	if err != nil {
+		return ip, err
+	}
+
+	if tcpStats.userInput.HonorTTL {
+		tcpStats.updateResolveExpiry()
+	}
+
+	// the static hosts are not resolved
+	if lookupHosts(tcpStats.userInput.Hosts, tcpStats.userInput.Hostname) == nil {
+		if tcpStats.resolvedAddrs != nil && !sameAddrs(tcpStats.resolvedAddrs, ipAddrs) {
+			tcpStats.printer.PrintInfo("The addresses of %s changed from %s to %s", tcpStats.userInput.Hostname,
+				formatResolvedAddrs(tcpStats.resolvedAddrs, netip.Addr{}), formatResolvedAddrs(ipAddrs, netip.Addr{}))
+		}
+
+		tcpStats.resolvedAddrs = ipAddrs
+		tcpStats.printResolved(ip)
+
+		if tcpStats.userInput.ResolveInterval > 0 {
+			tcpStats.nextResolve = time.Now().Add(tcpStats.userInput.ResolveInterval)
+		}
+	}
+
+	return ip, nil
+}
+
+// printResolved prints the addresses of the last resolution.
+func (tcpStats *stats) printResolved(selected netip.Addr) {
+	hostname := tcpStats.userInput.Hostname
+	addrs := slices.Clone(tcpStats.resolvedAddrs)
+	resolveTime := tcpStats.lastResolveTime
+
+	tcpStats.print(func(printer Printer) {
+		if p, ok := printer.(ResolvePrinter); ok {
+			p.PrintResolved(hostname, addrs, selected, resolveTime)
+			return
+		}
+
+		printer.PrintInfo("Resolved %s in %.3f ms to %s", hostname,
+			resolveTime, formatResolvedAddrs(addrs, selected))
+	})
+}
+
+// sameAddrs reports whether both lists have the same addresses, in any order.
+func sameAddrs(a, b []netip.Addr) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	for _, addr := range a {
+		if !slices.Contains(b, addr) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// formatResolvedAddrs lists the addresses, marking the selected one.
+func formatResolvedAddrs(addrs []netip.Addr, selected netip.Addr) string {
+	list := make([]string, 0, len(addrs))
+	for _, addr := range addrs {
+		if addr.Unmap() == selected {
+			list = append(list, addr.Unmap().String()+" (selected)")
+		} else {
+			list = append(list, addr.Unmap().String())
+		}
+	}
+
+	return strings.Join(list, ", ")
+}
+
+// lookupAddrs returns the addresses of the hostname from
+// the static hosts or otherwise from the resolver.
+func lookupAddrs(ctx context.Context, opts Options) ([]netip.Addr, error) {
+	if ipAddrs := lookupHosts(opts.Hosts, opts.Hostname); len(ipAddrs) > 0 {
+		return ipAddrs, nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, dnsTimeout)
+	defer cancel()
+
+	resolver := opts.Resolver
+	if resolver == nil {
+		resolver = net.DefaultResolver
+	}
+
+	return resolver.LookupNetIP(ctx, "ip", opts.Hostname)
+}
+
+// ResolveAll returns every address of the target that a [Pinger]
+// created with the same options could pick from, honoring
+// the Hosts, Resolver, UseIPv4 and UseIPv6 options.
+func ResolveAll(opts Options) ([]netip.Addr, error) {
+	if ip, err := netip.ParseAddr(opts.Hostname); err == nil {
+		return []netip.Addr{ip}, nil
+	}
+
+	ipAddrs, err := lookupAddrs(context.Background(), opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve %s: %w", opts.Hostname, err)
+	}
+
+	ipList := filterAddrs(opts, ipAddrs)
+	if len(ipList) == 0 {
+		return nil, fmt.Errorf("failed to find a suitable address for %s", opts.Hostname)
+	}
+
+	return ipList, nil
+}
+
+// lookupHosts returns the static addresses of the hostname, if any.
+func lookupHosts(hosts map[string][]netip.Addr, hostname string) []netip.Addr {
+	hostname = strings.TrimSuffix(hostname, ".")
+	for name, addrs := range hosts {
+		if strings.EqualFold(name, hostname) {
+			return addrs
+		}
+	}
+
+	return nil
+}
+
+// retryResolveHostname retries resolving a hostname after certain number of failures
+func retryResolveHostname(ctx context.Context, tcpStats *stats) {
+	if tcpStats.ongoingUnsuccessfulProbes >= tcpStats.userInput.RetryHostnameLookupAfter {
+		if tcpStats.userInput.SRV != "" {
+			retryLookupSRV(ctx, tcpStats)
+		}
+
+		tcpStats.log().Info("resolving again after the failed probes",
+			"failed_probes", tcpStats.ongoingUnsuccessfulProbes)
+		tcpStats.printer.PrintRetryingToResolve(tcpStats.userInput.Hostname)
+
+		ip, err := resolveHostname(ctx, tcpStats)
+		if err != nil {
+			tcpStats.printer.PrintError("%s", err)
+		} else {
+			tcpStats.updateIP(ip)
+		}
+		tcpStats.ongoingUnsuccessfulProbes = 0
+		tcpStats.retriedHostnameLookups += 1
+	}
+}
+
+// recordDNSFailure counts a failed resolution while probing,
+// which keeps probing the previous target.
+func (tcpStats *stats) recordDNSFailure(err error) {
+	tcpStats.dnsFailures += 1
+	tcpStats.lastDNSFailure = time.Now()
+	tcpStats.printer.PrintError("DNS failure, still probing %s: %s", tcpStats.userInput.ip, err)
+}
+
+// lookupSRV picks the target from the SRV record by priority and weight.
+func lookupSRV(ctx context.Context, opts Options) (string, uint16, error) {
+	ctx, cancel := context.WithTimeout(ctx, dnsTimeout)
+	defer cancel()
+
+	resolver := opts.Resolver
+	if resolver == nil {
+		resolver = net.DefaultResolver
+	}
+
+	// the records are already sorted by priority and randomized by weight
+	_, records, err := resolver.LookupSRV(ctx, "", "", opts.SRV)
+	if err != nil {
+		return "", 0, fmt.Errorf("failed to look up %s: %w", opts.SRV, err)
+	}
+
+	if len(records) == 0 || records[0].Target == "." {
+		return "", 0, fmt.Errorf("%s has no available target", opts.SRV)
+	}
+
+	return strings.TrimSuffix(records[0].Target, "."), records[0].Port, nil
+}
+
+// retryLookupSRV looks up the SRV record again, picking a new target if needed.
+func retryLookupSRV(ctx context.Context, tcpStats *stats) {
+	hostname, port, err := lookupSRV(ctx, tcpStats.userInput.Options)
+	if ctx.Err() != nil {
+		return
+	}
+	if err != nil {
+		tcpStats.recordDNSFailure(err)
+		return
+	}
+
+	if hostname == tcpStats.userInput.Hostname && port == tcpStats.userInput.Port {
+		return
+	}
+
+	tcpStats.printer.PrintInfo("%s now points to %s on port %d", tcpStats.userInput.SRV, hostname, port)
+	tcpStats.userInput.Hostname = hostname
+	tcpStats.userInput.Port = port
+	if tcpStats.userInput.networkInterface.use {
+		tcpStats.userInput.networkInterface.raddr.Port = int(port)
+	}
+}
+
+// resolveIsDue reports whether the TTL of the resolved address
+// or the interval between the resolutions has expired.
+func (tcpStats *stats) resolveIsDue() bool {
+	now := time.Now()
+
+	if !tcpStats.resolveExpiry.IsZero() && now.After(tcpStats.resolveExpiry) {
+		tcpStats.log().Info("resolving again, the TTL has expired", "expiry", tcpStats.resolveExpiry)
+		return true
+	}
+
+	if !tcpStats.nextResolve.IsZero() && now.After(tcpStats.nextResolve) {
+		tcpStats.log().Info("resolving again, the resolve interval has elapsed",
+			"interval", tcpStats.userInput.ResolveInterval)
+		return true
+	}
+
+	return false
+}
+
+// refreshHostname resolves the hostname again once it's due.
+func refreshHostname(ctx context.Context, tcpStats *stats) {
+	tcpStats.printer.PrintRetryingToResolve(tcpStats.userInput.Hostname)
+
+	ip, err := resolveHostname(ctx, tcpStats)
+	if err != nil {
+		tcpStats.printer.PrintError("%s", err)
+	} else {
+		tcpStats.updateIP(ip)
+	}
+	tcpStats.retriedHostnameLookups += 1
+}
+
+// updateResolveExpiry sets when the TTL of the hostname expires.
+// The static hosts never expire.
+func (tcpStats *stats) updateResolveExpiry() {
+	tcpStats.resolveExpiry = time.Time{}
+
+	if lookupHosts(tcpStats.userInput.Hosts, tcpStats.userInput.Hostname) != nil {
+		return
+	}
+
+	ttl, err := lookupTTL(tcpStats.userInput.Options)
+	if err != nil {
+		tcpStats.printer.PrintError("Unable to get the TTL of %s, it won't be re-resolved when it expires: %s",
+			tcpStats.userInput.Hostname, err)
+		return
+	}
+
+	tcpStats.resolveExpiry = time.Now().Add(ttl)
+}
+
+// resolveBeforeProbe resolves the hostname before every probe.
+func resolveBeforeProbe(ctx context.Context, tcpStats *stats) {
+	ip, err := resolveHostname(ctx, tcpStats)
+	if err != nil {
+		tcpStats.printer.PrintError("%s", err)
+		return
+	}
+
+	tcpStats.updateIP(ip)
+}
+
+// updateIP starts probing the newly resolved address
+// and records it if it has changed.
+func (tcpStats *stats) updateIP(ip netip.Addr) {
+	// the open connection is to the previous address
+	if ip != tcpStats.userInput.ip {
+		tcpStats.log().Info("probing a new address", "previous", tcpStats.userInput.ip, "ip", ip)
+		tcpStats.closePersistent()
+		tcpStats.addrFailures = 0
+	}
+
+	tcpStats.userInput.ip = ip
+	if tcpStats.userInput.networkInterface.use {
+		tcpStats.userInput.networkInterface.raddr.IP = ip.AsSlice()
+		tcpStats.userInput.networkInterface.raddr.Zone = ip.Zone()
+	}
+
+	// At this point hostnameChanges should have len > 0, but just in case
+	if len(tcpStats.hostnameChanges) == 0 {
+		return
+	}
+
+	lastAddr := tcpStats.hostnameChanges[len(tcpStats.hostnameChanges)-1].Addr
+	if lastAddr != ip {
+		tcpStats.hostnameChanges = append(tcpStats.hostnameChanges, HostnameChange{
+			Addr: ip,
+			When: time.Now(),
+		})
+	}
+}
+
+// newLongestTime creates LongestTime structure
+func newLongestTime(startTime time.Time, duration time.Duration) LongestTime {
+	return LongestTime{
+		Start:    startTime,
+		End:      startTime.Add(duration),
+		Duration: duration,
+	}
+}
+
+// calcLongestUptime calculates the longest uptime and sets it to tcpStats.
+func calcLongestUptime(tcpStats *stats, duration time.Duration) {
+	if tcpStats.startOfUptime.IsZero() || duration == 0 {
+		return
+	}
+
+	longestUptime := newLongestTime(tcpStats.startOfUptime, duration)
+
+	// It means it is the first time we're calling this function
+	if tcpStats.longestUptime.End.IsZero() {
+		tcpStats.longestUptime = longestUptime
+		return
+	}
+
+	if longestUptime.Duration >= tcpStats.longestUptime.Duration {
+		tcpStats.longestUptime = longestUptime
+	}
+}
+
+// calcLongestDowntime calculates the longest downtime and sets it to tcpStats.
+func calcLongestDowntime(tcpStats *stats, duration time.Duration) {
+	if tcpStats.startOfDowntime.IsZero() || duration == 0 {
+		return
+	}
+
+	longestDowntime := newLongestTime(tcpStats.startOfDowntime, duration)
+
+	// It means it is the first time we're calling this function
+	if tcpStats.longestDowntime.End.IsZero() {
+		tcpStats.longestDowntime = longestDowntime
+		return
+	}
+
+	if longestDowntime.Duration >= tcpStats.longestDowntime.Duration {
+		tcpStats.longestDowntime = longestDowntime
+	}
+}
+
+// nanoToMillisecond returns an amount of milliseconds from nanoseconds.
+// Using duration.Milliseconds() is not an option, because it drops
+// decimal points, returning an int.
+func nanoToMillisecond(nano int64) float32 {
+	return float32(nano) / float32(time.Millisecond)
+}
+
+// handleConnError processes failed probes
+func (tcpStats *stats) handleConnError(connTime time.Time, err error) {
+	if !tcpStats.wasDown {
+		tcpStats.startOfDowntime = connTime
+		uptime := tcpStats.startOfDowntime.Sub(tcpStats.startOfUptime)
+		calcLongestUptime(tcpStats, uptime)
+		tcpStats.startOfUptime = time.Time{}
+		tcpStats.wasDown = true
+		// the down state supersedes the degraded one, the outliers
+		// and the trend, which end first
+		tcpStats.recordDegraded(false, 0, connTime)
+		tcpStats.recordOutlier(false, 0, connTime)
+		tcpStats.endTrend(0, connTime)
+		tcpStats.trend = trend{}
+
+		tcpStats.notifyStateChange(StateChange{
+			When:     connTime,
+			Hostname: tcpStats.userInput.Hostname,
+			IP:       tcpStats.userInput.ip.String(),
+			Port:     tcpStats.userInput.Port,
+		})
+		tcpStats.ringBell(BellOnChange)
+	}
+
+	tcpStats.recordAvailability(connTime, 0, false)
+	tcpStats.addrStats().unsuccessful += 1
+	tcpStats.lastUnsuccessfulProbe = connTime
+	tcpStats.totalUnsuccessfulProbes += 1
+	tcpStats.ongoingUnsuccessfulProbes += 1
+	tcpStats.addrFailures += 1
+
+	// the goodput of a probe failing the success check isn't reported
+	tcpStats.goodput = 0
+
+	reason := tcpStats.recordFailure(err)
+	tcpStats.ringBell(BellOnFail)
+	burst := tcpStats.takeBurst()
+	tcpStats.publishResult(Result{
+		Seq:           tcpStats.seq(),
+		Time:          connTime,
+		Hostname:      tcpStats.userInput.Hostname,
+		IP:            tcpStats.userInput.ip,
+		Port:          tcpStats.userInput.Port,
+		Streak:        tcpStats.ongoingUnsuccessfulProbes,
+		Attempts:      tcpStats.takeAttempts(),
+		Burst:         burst.size,
+		BurstFailures: burst.failures,
+		TunnelSetup:   tcpStats.takeTunnelSetup(),
+		FailureReason: reason,
+		Err:           err,
+	})
+}
+
+// handleConnSuccess processes successful probes
+func (tcpStats *stats) handleConnSuccess(rtt float32, connTime time.Time) {
+	if tcpStats.wasDown {
+		tcpStats.startOfUptime = connTime
+		downtime := tcpStats.startOfUptime.Sub(tcpStats.startOfDowntime)
+		calcLongestDowntime(tcpStats, downtime)
+		tcpStats.startOfDowntime = time.Time{}
+		tcpStats.wasDown = false
+		tcpStats.ongoingUnsuccessfulProbes = 0
+		tcpStats.ongoingSuccessfulProbes = 0
+		tcpStats.addrFailures = 0
+
+		tcpStats.notifyStateChange(StateChange{
+			When:     connTime,
+			Hostname: tcpStats.userInput.Hostname,
+			IP:       tcpStats.userInput.ip.String(),
+			Port:     tcpStats.userInput.Port,
+			Downtime: downtime,
+			RTT:      rtt,
+			Up:       true,
+		})
+		tcpStats.ringBell(BellOnChange)
+	}
+
+	if tcpStats.startOfUptime.IsZero() {
+		tcpStats.startOfUptime = connTime
+	}
+
+	tcpStats.recordAvailability(connTime, rtt, true)
+	tcpStats.addrStats().successful += 1
+	tcpStats.lastSuccessfulProbe = connTime
+	tcpStats.totalSuccessfulProbes += 1
+	tcpStats.ongoingSuccessfulProbes += 1
+
+	// the first handshakes are skewed by ARP, ND or conntrack
+	outlier := false
+	if tcpStats.totalSuccessfulProbes+tcpStats.totalUnsuccessfulProbes > tcpStats.userInput.Warmup {
+		outlier = tcpStats.recordRtt(rtt)
+	}
+
+	degraded := tcpStats.isDegraded(rtt)
+
+	info := tcpStats.tcpInfo
+	tcpStats.tcpInfo = nil
+	if info != nil {
+		tcpStats.kernelRtt.add(info.SRTT)
+		tcpStats.retransmits += uint(info.Retransmits)
+	}
+
+	userRtt := tcpStats.userRtt
+	tcpStats.userRtt = 0
+	if userRtt > 0 {
+		tcpStats.userRtts.add(userRtt)
+	}
+
+	burst := tcpStats.takeBurst()
+	tcpStats.burstFailures += burst.failures
+
+	handshake := tcpStats.handshake
+	tcpStats.handshake = nil
+	if handshake != nil {
+		tcpStats.networkRtt.add(handshake.Network)
+	}
+
+	localAddr := tcpStats.localAddr
+	tcpStats.localAddr = netip.AddrPort{}
+
+	peerClose := tcpStats.peerClose
+	tcpStats.peerClose = ""
+	tcpStats.recordPeerClose(peerClose)
+
+	attempts := tcpStats.takeAttempts()
+	if attempts > 1 {
+		tcpStats.retriedProbes += 1
+	}
+
+	trendingUp := tcpStats.recordTrend(rtt, connTime)
+
+	tcpStats.ringBell(BellOnSuccess)
+	tcpStats.publishResult(Result{
+		Seq:            tcpStats.seq(),
+		Time:           connTime,
+		Hostname:       tcpStats.userInput.Hostname,
+		IP:             tcpStats.userInput.ip,
+		Port:           tcpStats.userInput.Port,
+		RTT:            rtt,
+		UserRTT:        userRtt,
+		TunnelSetup:    tcpStats.takeTunnelSetup(),
+		TCPInfo:        info,
+		Handshake:      handshake,
+		LocalAddr:      localAddr,
+		PeerClose:      peerClose,
+		PeerCloseAfter: tcpStats.peerCloseAfter,
+		Attempts:       attempts,
+		Burst:          burst.size,
+		BurstMin:       burst.min,
+		BurstMedian:    burst.median,
+		BurstMax:       burst.max,
+		BurstFailures:  burst.failures,
+		Outlier:        outlier,
+		Degraded:       degraded,
+		TrendingUp:     trendingUp,
+		SmoothedRTT:    tcpStats.srtt,
+		TFO:            tcpStats.userInput.TFO && tcpStats.tfoAccepted,
+		MPTCP:          tcpStats.userInput.MPTCP && tcpStats.mptcp,
+		Banner:         tcpStats.banner,
+		ResponseTime:   tcpStats.lastResponseTime,
+		Goodput:        tcpStats.takeGoodput(),
+		Streak:         tcpStats.ongoingSuccessfulProbes,
+		Success:        true,
+	})
+
+	// the changes of state and the messages that follow the probe
+	tcpStats.recordDegraded(degraded, rtt, connTime)
+
+	if tcpStats.userInput.OutlierStdDevs > 0 {
+		tcpStats.recordOutlier(outlier, rtt, connTime)
+	}
+
+	if tcpStats.userInput.TFO {
+		tcpStats.recordTFO(rtt)
+	}
+
+	if tcpStats.userInput.MPTCP {
+		tcpStats.recordMPTCP()
+	}
+}
+
+// formatTCPInfo formats the retransmissions, the congestion window and
+// the delivery rate of the kernel's view of the connection.
+func formatTCPInfo(info TCPInfo) string {
+	s := fmt.Sprintf("retransmits=%d cwnd=%d", info.Retransmits, info.Cwnd)
+	if info.DeliveryRate > 0 {
+		s += fmt.Sprintf(" delivery_rate=%.3f Mbit/s", info.DeliveryRate)
+	}
+
+	return s
+}
+
+// srttWeight is the weight of the last RTT in the smoothed RTT, see RFC 6298.
+const srttWeight = 1.0 / 8
+
+// minOutlierSamples is the number of RTTs needed
+// before flagging the outliers with Options.OutlierStdDevs.
+const minOutlierSamples = 10
+
+// recordRtt adds the RTT to the statistics and reports whether it's
+// an outlier compared to the previous ones, see Options.OutlierStdDevs.
+func (tcpStats *stats) recordRtt(rtt float32) bool {
+	outlier := false
+	if k := tcpStats.userInput.OutlierStdDevs; k > 0 {
+		if mean, stdDev, ok := tcpStats.outlierBaseline(); ok {
+			outlier = float64(rtt) > mean+k*stdDev
+		}
+	}
+
+	if tcpStats.rtt.count == 0 {
+		tcpStats.srtt = rtt
+	} else {
+		tcpStats.srtt += (rtt - tcpStats.srtt) * srttWeight
+	}
+
+	tcpStats.rtt.add(rtt)
+	tcpStats.addrStats().rtt.add(rtt)
+	tcpStats.addOutlierRtt(rtt)
+	if outlier {
+		tcpStats.outliers += 1
+	}
+
+	return outlier
+}
+
+// takeAttempts returns the attempts of the probe, 1 for the
+// persistent connections that are checked without connecting.
+func (tcpStats *stats) takeAttempts() uint {
+	attempts := max(tcpStats.attempts, 1)
+	tcpStats.attempts = 0
+
+	return attempts
+}
+
+// localAddrOf returns the local address of the connection.
+func localAddrOf(conn net.Conn) netip.AddrPort {
+	// the channels of the SSH tunnel have no address
+	addr, ok := conn.LocalAddr().(*net.TCPAddr)
+	if !ok || addr.IP.IsUnspecified() {
+		return netip.AddrPort{}
+	}
+
+	return netip.AddrPortFrom(addr.AddrPort().Addr().Unmap(), addr.AddrPort().Port())
+}
+
+// recordTFO separates the RTT of the successful probe
+// depending on whether the data in the SYN was accepted.
+func (tcpStats *stats) recordTFO(rtt float32) {
+	if tcpStats.tfoAccepted {
+		tcpStats.tfoRtt.add(rtt)
+		tcpStats.printer.PrintInfo("TCP Fast Open data accepted by the server")
+		return
+	}
+
+	tcpStats.regularRtt.add(rtt)
+	tcpStats.printer.PrintInfo("TCP Fast Open data not accepted by the server")
+}
+
+// recordMPTCP counts the successful probe if it negotiated MPTCP.
+// Only the first negotiation and its changes are printed.
+func (tcpStats *stats) recordMPTCP() {
+	mptcp := tcpStats.mptcp
+	if mptcp {
+		tcpStats.mptcpProbes += 1
+	}
+
+	if tcpStats.lastMPTCP != nil && *tcpStats.lastMPTCP == mptcp {
+		return
+	}
+	tcpStats.lastMPTCP = &mptcp
+
+	if mptcp {
+		tcpStats.printer.PrintInfo("MPTCP was negotiated with %s", tcpStats.userInput.ip)
+		return
+	}
+
+	tcpStats.printer.PrintInfo("MPTCP was not negotiated with %s, falling back to TCP", tcpStats.userInput.ip)
+}
+
+// publishResult sends the result of a probe to the hook,
+// the results channel and the notifiers interested in it.
+func (tcpStats *stats) publishResult(r Result) {
+	r.ResolveTime = tcpStats.lastResolveTime
+	tcpStats.lastResolveTime = 0
+
+	tcpStats.printProbe(r)
+
+	if hook := tcpStats.userInput.Hooks.OnProbe; hook != nil {
+		hook(r)
+	}
+
+	tcpStats.notifyProbe(r)
+
+	if tcpStats.userInput.Results != nil {
+		tcpStats.userInput.Results <- r
+	}
+}
+
+// tcping pings a host, TCP style. The probe is dropped if ctx is done
+// before it's over, as it's neither a success nor a failure.
+func tcping(ctx context.Context, tcpStats *stats) {
+	tcpStats.setPcapProbe()
+
+	var icmpDone <-chan icmpReply
+	if tcpStats.userInput.CompareICMP {
+		icmpDone = tcpStats.startICMPEcho()
+	}
+
+	if tcpStats.persistent != nil {
+		probePersistent(tcpStats)
+		if icmpDone != nil {
+			tcpStats.recordICMPEcho(icmpDone)
+		}
+		return
+	}
+
+	var err error
+	var conn net.Conn
+	connStart := time.Now()
+
+	dialer := net.Dialer{Timeout: tcpStats.userInput.Timeout}
+	address := netip.AddrPortFrom(tcpStats.userInput.ip, tcpStats.userInput.Port).String()
+	if tcpStats.userInput.networkInterface.use {
+		// dialer already contains the timeout value
+		dialer = tcpStats.userInput.networkInterface.dialer
+		address = tcpStats.userInput.networkInterface.raddr.String()
+	}
+
+	dialer.KeepAlive = tcpStats.userInput.KeepAlive
+	dialer.Control = tcpStats.control
+	dialer.SetMultipathTCP(tcpStats.userInput.MPTCP)
+	tcpStats.log().Debug("connecting", "address", address, "interface", tcpStats.userInput.InterfaceName,
+		"timeout", dialer.Timeout, "tfo", tcpStats.userInput.TFO, "mptcp", tcpStats.userInput.MPTCP)
+
+	dial := func() (net.Conn, error) {
+		var conn net.Conn
+		err := tcpStats.inNetns(func() error {
+			var err error
+			if tcpStats.userInput.Proxy != nil {
+				conn, err = tcpStats.dialProxy(ctx, dialer)
+			} else if tcpStats.jumpClient != nil {
+				conn, err = tcpStats.dialJump(ctx, dialer.Timeout, address)
+			} else if tcpStats.userInput.TFO {
+				conn, tcpStats.tfoAccepted, err = dialTFO(ctx, dialer, address)
+			} else {
+				conn, err = dialer.DialContext(ctx, "tcp", address)
+			}
+			return err
+		})
+		return conn, err
+	}
+
+	// the failed attempts are retried while the interval lasts,
+	// the RTT is the one of the last attempt
+	var attempts uint
+	var connDuration time.Duration
+	for {
+		// the SSH tunnel is timed apart from the probe
+		if err = tcpStats.openJump(ctx, dialer); err == nil {
+			if tcpStats.userInput.Burst > 1 {
+				conn, connDuration, err = tcpStats.dialBurst(ctx, dial)
+			} else {
+				attemptStart := time.Now()
+				conn, err = dial()
+				connDuration = time.Since(attemptStart)
+			}
+		}
+		attempts++
+
+		if err == nil || ctx.Err() != nil || attempts > tcpStats.userInput.Retries {
+			break
+		}
+		if time.Since(connStart) >= tcpStats.userInput.IntervalBetweenProbes {
+			tcpStats.log().Info("not retrying, the next probe is due", "attempts", attempts, "error", err)
+			break
+		}
+		tcpStats.log().Info("retrying the connection", "attempt", attempts+1, "error", err)
+	}
+	tcpStats.attempts = attempts
+
+	rtt := nanoToMillisecond(connDuration.Nanoseconds())
+
+	if err == nil && tcpStats.userInput.connectTracer != nil {
+		if kernelRtt, ok := tcpStats.userInput.connectTracer.connectTime(localAddrOf(conn).Port()); ok {
+			tcpStats.userRtt = rtt
+			rtt = kernelRtt
+		}
+	}
+
+	if err == nil {
+		if err := tcpStats.setSocketOptions(conn); err != nil {
+			tcpStats.printer.PrintError("Unable to set the socket options: %s", err)
+		}
+	}
+
+	// the banner and the prober are read over TLS in TLS mode
+	appConn := conn
+	if err == nil && tcpStats.userInput.TLSConfig != nil {
+		if appConn, err = tcpStats.handshakeTLS(ctx, conn); err != nil {
+			conn.Close()
+		}
+	}
+
+	if err == nil && tcpStats.userInput.Banner {
+		if err = tcpStats.checkBanner(appConn); err != nil {
+			appConn.Close()
+		}
+	}
+
+	if err == nil && tcpStats.userInput.prober != nil {
+		if err = tcpStats.runProber(appConn); err != nil {
+			appConn.Close()
+		}
+	}
+
+	if err == nil && tcpStats.userInput.GoodputSize > 0 {
+		if err = tcpStats.measureGoodput(appConn); err != nil {
+			appConn.Close()
+		}
+	}
+
+	if err == nil && tcpStats.userInput.SuccessCheck != nil {
+		if err = tcpStats.runSuccessCheck(ctx, connStart, rtt); err != nil {
+			appConn.Close()
+		}
+	}
+
+	if err == nil && tcpStats.userInput.CloseWait > 0 {
+		tcpStats.peerClose, tcpStats.peerCloseAfter = waitPeerClose(conn, tcpStats.userInput.CloseWait)
+	}
+
+	if ctx.Err() != nil {
+		if err == nil {
+			appConn.Close()
+		}
+		return
+	}
+
+	if err != nil {
+		tcpStats.handleConnError(connStart, err)
+	} else {
+		if tcpStats.userInput.TCPInfo {
+			info, err := readTCPInfo(conn)
+			if err != nil {
+				tcpStats.printer.PrintError("Unable to read TCP_INFO: %s", err)
+			} else {
+				tcpStats.tcpInfo = &info
+			}
+		}
+
+		if tcpStats.userInput.MPTCP {
+			tcpStats.mptcp, _ = conn.(*net.TCPConn).MultipathTCP()
+		}
+
+		tcpStats.localAddr = localAddrOf(conn)
+		if h := tcpStats.userInput.handshakes; h != nil {
+			if hs, ok := h.wait(tcpStats.localAddr.Port(), tcpStats.userInput.ip, rtt); ok {
+				tcpStats.handshake = &hs
+			} else {
+				tcpStats.log().Debug("the handshake wasn't captured", "local_addr", tcpStats.localAddr)
+			}
+		}
+
+		tcpStats.handleConnSuccess(rtt, connStart)
+		if tcpStats.userInput.PathMTU && !tcpStats.mtuProbed {
+			tcpStats.checkPathMTU()
+		}
+
+		if tcpStats.userInput.Persistent {
+			tcpStats.keepOpen(conn)
+		} else {
+			appConn.Close()
+		}
+	}
+
+	if icmpDone != nil {
+		tcpStats.recordICMPEcho(icmpDone)
+	}
+}
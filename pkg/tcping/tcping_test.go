@@ -0,0 +1,631 @@
+package tcping
+
+import (
+	"context"
+	"net"
+	"net/netip"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/net/dns/dnsmessage"
+)
+
+// createTestStats should be used to create new stats structs.
+// it uses "127.0.0.1:12345" as default values, because
+// [testServerListen] use the same values.
+// It'll call t.Errorf if netip.ParseAddr has failed.
+func createTestStats(t *testing.T) *stats {
+	addr, err := netip.ParseAddr("127.0.0.1")
+	s := stats{
+		printer: &dummyPrinter{},
+		userInput: userInput{
+			ip: addr,
+			Options: Options{
+				Port:                  12345,
+				IntervalBetweenProbes: time.Second,
+				Timeout:               time.Second,
+			},
+		},
+		ticker: time.NewTicker(time.Second),
+	}
+	if err != nil {
+		t.Errorf("ip parse: %v", err)
+	}
+
+	return &s
+}
+
+// testServerListen creates a new listener
+// on port 12345 and automatically starts it.
+//
+// Use t.Cleanup with srv.Close() to close it after
+// the test, so that other tests are not affected.
+//
+// It could fail if net.Listen or Accept has failed.
+func testServerListen(t *testing.T) net.Listener {
+	srv, err := net.Listen("tcp", ":12345")
+	if err != nil {
+		t.Errorf("test server: %v", err)
+	}
+
+	go func() {
+		for {
+			c, err := srv.Accept()
+			if err != nil {
+				return
+			}
+
+			c.Close()
+		}
+	}()
+
+	return srv
+}
+
+func TestProbeSuccess(t *testing.T) {
+	stats := createTestStats(t)
+	stats.ticker = time.NewTicker(time.Nanosecond)
+	srv := testServerListen(t)
+	t.Cleanup(func() {
+		if err := srv.Close(); err != nil {
+			t.Errorf("srv close: %v", err)
+		}
+	})
+
+	expectedSuccessful := 100
+
+	for i := 0; i < expectedSuccessful; i++ {
+		tcping(context.Background(), stats)
+	}
+
+	assert.Equal(t, stats.totalSuccessfulProbes, uint(expectedSuccessful))
+	assert.Equal(t, stats.ongoingSuccessfulProbes, uint(expectedSuccessful))
+
+	// every probe lasts until the next one
+	assert.Equal(t, stats.lastSuccessfulProbe.Sub(stats.startOfUptime), stats.totalUptime)
+	assert.Equal(t, time.Duration(0), stats.totalDowntime)
+}
+
+func TestProbeFail(t *testing.T) {
+	stats := createTestStats(t)
+	stats.ticker = time.NewTicker(time.Nanosecond)
+
+	expectedFailed := 100
+
+	for i := 0; i < expectedFailed; i++ {
+		tcping(context.Background(), stats)
+	}
+
+	assert.Equal(t, stats.totalUnsuccessfulProbes, uint(expectedFailed))
+	assert.Equal(t, stats.ongoingUnsuccessfulProbes, uint(expectedFailed))
+
+	// every probe lasts until the next one
+	assert.Equal(t, stats.lastUnsuccessfulProbe.Sub(stats.startOfDowntime), stats.totalDowntime)
+	assert.Equal(t, time.Duration(0), stats.totalUptime)
+}
+
+func TestHooks(t *testing.T) {
+	stats := createTestStats(t)
+	stats.ticker = time.NewTicker(time.Nanosecond)
+
+	var results []Result
+	var changes []StateChange
+	var statistics []Statistics
+	stats.userInput.Hooks = Hooks{
+		OnProbe:       func(r Result) { results = append(results, r) },
+		OnStateChange: func(change StateChange) { changes = append(changes, change) },
+		OnStatistics:  func(s Statistics) { statistics = append(statistics, s) },
+	}
+
+	tcping(context.Background(), stats)
+	tcping(context.Background(), stats)
+	stats.printStats()
+
+	if assert.Len(t, results, 2) {
+		assert.False(t, results[1].Success)
+		assert.Equal(t, uint(2), results[1].Streak)
+		assert.Equal(t, uint16(12345), results[1].Port)
+	}
+	if assert.Len(t, changes, 1) {
+		assert.False(t, changes[0].Up)
+		assert.Equal(t, "127.0.0.1", changes[0].IP)
+	}
+	if assert.Len(t, statistics, 1) {
+		assert.Equal(t, uint(2), statistics[0].TotalUnsuccessfulProbes)
+	}
+}
+
+func TestLocalAddr(t *testing.T) {
+	stats := createTestStats(t)
+	stats.ticker = time.NewTicker(time.Nanosecond)
+	stats.userInput.ShowLocalAddr = true
+	srv := testServerListen(t)
+	t.Cleanup(func() { srv.Close() })
+
+	var results []Result
+	stats.userInput.Hooks.OnProbe = func(r Result) { results = append(results, r) }
+
+	tcping(context.Background(), stats)
+
+	if assert.Len(t, results, 1) {
+		assert.Equal(t, netip.MustParseAddr("127.0.0.1"), results[0].LocalAddr.Addr())
+		assert.NotZero(t, results[0].LocalAddr.Port())
+	}
+}
+
+func TestRetries(t *testing.T) {
+	stats := createTestStats(t)
+	stats.ticker = time.NewTicker(time.Nanosecond)
+	stats.userInput.Retries = 2
+
+	var results []Result
+	stats.userInput.Hooks.OnProbe = func(r Result) { results = append(results, r) }
+
+	// refused right away, so every retry fits in the interval
+	tcping(context.Background(), stats)
+
+	srv := testServerListen(t)
+	t.Cleanup(func() { srv.Close() })
+	tcping(context.Background(), stats)
+
+	if assert.Len(t, results, 2) {
+		assert.False(t, results[0].Success)
+		assert.Equal(t, uint(3), results[0].Attempts)
+		assert.True(t, results[1].Success)
+		assert.Equal(t, uint(1), results[1].Attempts)
+	}
+	assert.Zero(t, stats.retriedProbes)
+}
+
+func TestWarmup(t *testing.T) {
+	stats := createTestStats(t)
+	stats.ticker = time.NewTicker(time.Nanosecond)
+	stats.userInput.Warmup = 2
+	srv := testServerListen(t)
+	t.Cleanup(func() { srv.Close() })
+
+	for i := 0; i < 5; i++ {
+		tcping(context.Background(), stats)
+	}
+
+	assert.Equal(t, uint(5), stats.totalSuccessfulProbes)
+	assert.Equal(t, uint(3), stats.rtt.count)
+}
+
+func TestOutliers(t *testing.T) {
+	stats := createTestStats(t)
+	stats.userInput.OutlierStdDevs = 3
+
+	for i := 0; i < minOutlierSamples; i++ {
+		assert.False(t, stats.recordRtt(float32(10+i%2)))
+	}
+
+	assert.False(t, stats.recordRtt(11))
+	assert.True(t, stats.recordRtt(3000))
+	assert.Equal(t, uint(1), stats.statistics().Outliers)
+}
+
+func TestSmoothedRTT(t *testing.T) {
+	stats := createTestStats(t)
+
+	stats.recordRtt(8)
+	assert.Equal(t, float32(8), stats.srtt)
+
+	stats.recordRtt(16)
+	assert.Equal(t, float32(9), stats.srtt)
+	assert.Equal(t, float32(9), stats.statistics().SmoothedRTT)
+}
+
+func TestDegraded(t *testing.T) {
+	stats := createTestStats(t)
+	stats.userInput.RTTThreshold = 150 * time.Millisecond
+	stats.userInput.NotifyDegraded = true
+
+	var results []Result
+	var changes []StateChange
+	stats.userInput.Hooks.OnProbe = func(r Result) { results = append(results, r) }
+	stats.userInput.Hooks.OnStateChange = func(change StateChange) { changes = append(changes, change) }
+
+	start := time.Now()
+	stats.handleConnSuccess(20, start)
+	stats.handleConnSuccess(200, start.Add(time.Second))
+	stats.handleConnSuccess(300, start.Add(2*time.Second))
+	stats.handleConnSuccess(20, start.Add(4*time.Second))
+
+	if assert.Len(t, results, 4) {
+		assert.False(t, results[0].Degraded)
+		assert.True(t, results[1].Degraded)
+		assert.True(t, results[2].Degraded)
+		assert.False(t, results[3].Degraded)
+	}
+	if assert.Len(t, changes, 2) {
+		assert.True(t, changes[0].Degraded)
+		assert.False(t, changes[0].Up)
+		assert.Equal(t, float32(200), changes[0].RTT)
+		assert.True(t, changes[1].Degraded)
+		assert.True(t, changes[1].Up)
+		assert.Equal(t, 3*time.Second, changes[1].Downtime)
+	}
+	assert.Equal(t, uint(2), stats.statistics().DegradedProbes)
+}
+
+func TestDegradedThenDown(t *testing.T) {
+	stats := createTestStats(t)
+	stats.userInput.RTTThreshold = 150 * time.Millisecond
+	stats.userInput.NotifyDegraded = true
+
+	var changes []StateChange
+	stats.userInput.Hooks.OnStateChange = func(change StateChange) { changes = append(changes, change) }
+
+	start := time.Now()
+	stats.handleConnSuccess(200, start)
+	stats.handleConnError(start.Add(2*time.Second), nil)
+
+	// the degradation ends before the target goes down
+	if assert.Len(t, changes, 3) {
+		assert.True(t, changes[0].Degraded)
+		assert.False(t, changes[0].Up)
+		assert.True(t, changes[1].Degraded)
+		assert.True(t, changes[1].Up)
+		assert.Equal(t, 2*time.Second, changes[1].Downtime)
+		assert.False(t, changes[2].Degraded)
+		assert.False(t, changes[2].Up)
+	}
+	assert.False(t, stats.degraded)
+	assert.True(t, stats.startOfDegradation.IsZero())
+}
+
+func TestNanoToMilliseconds(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		d    time.Duration
+		want float32
+	}{
+		{d: time.Millisecond, want: 1},
+		{d: 100*time.Millisecond + 123*time.Nanosecond, want: 100.000123},
+		{d: time.Second, want: 1000},
+		{d: time.Second + 100*time.Nanosecond, want: 1000.000123},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.d.String(), func(t *testing.T) {
+			t.Parallel()
+			got := nanoToMillisecond(tt.d.Nanoseconds())
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestSelectResolvedIPv4(t *testing.T) {
+	userInputV4 := userInput{
+		Options: Options{UseIPv4: true},
+	}
+
+	stats := createTestStats(t)
+	stats.userInput = userInputV4
+
+	var (
+		ip1 = netip.MustParseAddr("172.20.10.238")
+		ip2 = netip.MustParseAddr("8.8.8.8")
+	)
+
+	t.Run("IPv4 Selection", func(t *testing.T) {
+		actual, err := selectResolvedIP(context.Background(), stats, []netip.Addr{ip1, ip2})
+		if err != nil {
+			t.Fatalf("Expected an IP but got error: %v", err)
+		}
+
+		if !actual.IsValid() {
+			t.Errorf("Expected an IP but got invalid address")
+		}
+		if actual != ip1 && actual != ip2 {
+			t.Errorf("Expected an IP but got invalid address")
+		}
+	})
+}
+
+func TestSelectResolvedIPv6(t *testing.T) {
+	userInputV6 := userInput{
+		Options: Options{UseIPv6: true},
+	}
+
+	stats := createTestStats(t)
+	stats.userInput = userInputV6
+
+	var (
+		ip1 = netip.MustParseAddr("2001:0db8:85a3:0000:0000:8a2e:0370:7334")
+		ip2 = netip.MustParseAddr("2001:4860:4860::8888")
+	)
+
+	t.Run("IPv6 Selection", func(t *testing.T) {
+		actual, err := selectResolvedIP(context.Background(), stats, []netip.Addr{ip1, ip2})
+		if err != nil {
+			t.Fatalf("Expected an IP but got error: %v", err)
+		}
+		if !actual.IsValid() {
+			t.Errorf("Expected an IP but got invalid address")
+		}
+		if actual != ip1 && actual != ip2 {
+			t.Errorf("Expected an IP but got invalid address")
+		}
+	})
+}
+
+func TestResolveHostnameWithHosts(t *testing.T) {
+	stats := createTestStats(t)
+	stats.userInput.Hostname = "Backend.Example.test."
+	stats.userInput.Hosts = map[string][]netip.Addr{
+		"backend.example.test": {netip.MustParseAddr("192.0.2.10")},
+	}
+
+	ip, err := resolveHostname(context.Background(), stats)
+	assert.NoError(t, err)
+	assert.Equal(t, netip.MustParseAddr("192.0.2.10"), ip)
+
+	stats.userInput.UseIPv6 = true
+	_, err = resolveHostname(context.Background(), stats)
+	assert.Error(t, err)
+}
+
+func TestResolveAll(t *testing.T) {
+	opts := Options{
+		Hostname: "backend.example.test",
+		Hosts: map[string][]netip.Addr{
+			"backend.example.test": {
+				netip.MustParseAddr("192.0.2.10"),
+				netip.MustParseAddr("::ffff:192.0.2.10"),
+				netip.MustParseAddr("192.0.2.11"),
+				netip.MustParseAddr("2001:db8::10"),
+			},
+		},
+	}
+
+	addrs, err := ResolveAll(opts)
+	assert.NoError(t, err)
+	assert.Equal(t, []netip.Addr{
+		netip.MustParseAddr("192.0.2.10"),
+		netip.MustParseAddr("192.0.2.11"),
+		netip.MustParseAddr("2001:db8::10"),
+	}, addrs)
+
+	opts.UseIPv6 = true
+	addrs, err = ResolveAll(opts)
+	assert.NoError(t, err)
+	assert.Equal(t, []netip.Addr{netip.MustParseAddr("2001:db8::10")}, addrs)
+
+	opts.Hostname = "192.0.2.20"
+	addrs, err = ResolveAll(opts)
+	assert.NoError(t, err)
+	assert.Equal(t, []netip.Addr{netip.MustParseAddr("192.0.2.20")}, addrs)
+}
+
+func TestResolveEveryProbe(t *testing.T) {
+	var results []Result
+	p, err := New(Options{
+		Printer:               &dummyPrinter{},
+		Hostname:              "backend.example.test",
+		Port:                  12345,
+		IntervalBetweenProbes: 2 * time.Millisecond,
+		Timeout:               time.Millisecond,
+		ProbesBeforeQuit:      2,
+		ResolveEveryProbe:     true,
+		Hosts: map[string][]netip.Addr{
+			"backend.example.test": {netip.MustParseAddr("127.0.0.1")},
+		},
+		Hooks: Hooks{
+			OnProbe: func(r Result) { results = append(results, r) },
+		},
+	})
+	assert.NoError(t, err)
+
+	p.Run()
+
+	if assert.Len(t, results, 2) {
+		assert.NotZero(t, results[0].ResolveTime)
+		assert.NotZero(t, results[1].ResolveTime)
+	}
+
+	// the initial resolution and one before every probe
+	assert.Equal(t, uint(3), p.stats.resolveTimes.count)
+	assert.True(t, p.Statistics().ResolveTimeResults.HasResults)
+	assert.Equal(t, uint(0), p.Statistics().RetriedHostnameLookups)
+}
+
+func TestSRV(t *testing.T) {
+	srvTargets := []dnsmessage.SRVResource{
+		{Target: dnsmessage.MustNewName("backup.example.test."), Priority: 20, Weight: 1, Port: 12346},
+		{Target: dnsmessage.MustNewName("primary.example.test."), Priority: 10, Weight: 1, Port: 12345},
+	}
+	var mu sync.Mutex
+	resolver := serveTestDNSAnswers(t, func(q dnsmessage.Question) []dnsmessage.Resource {
+		if q.Type != dnsmessage.TypeSRV {
+			return nil
+		}
+
+		mu.Lock()
+		defer mu.Unlock()
+
+		var records []dnsmessage.Resource
+		for i := range srvTargets {
+			records = append(records, dnsmessage.Resource{
+				Header: dnsmessage.ResourceHeader{Name: q.Name, Type: q.Type, Class: q.Class, TTL: 60},
+				Body:   &srvTargets[i],
+			})
+		}
+		return records
+	})
+
+	p, err := New(Options{
+		Printer:               &dummyPrinter{},
+		SRV:                   "_test._tcp.example.test",
+		Resolver:              resolver,
+		IntervalBetweenProbes: time.Second,
+		Hosts: map[string][]netip.Addr{
+			"primary.example.test": {netip.MustParseAddr("127.0.0.1")},
+			"backup.example.test":  {netip.MustParseAddr("127.0.0.2")},
+		},
+	})
+	assert.NoError(t, err)
+
+	s := p.Statistics()
+	assert.Equal(t, "primary.example.test", s.Hostname)
+	assert.Equal(t, uint16(12345), s.Port)
+	assert.Equal(t, netip.MustParseAddr("127.0.0.1"), s.IP)
+
+	// the primary target goes away
+	mu.Lock()
+	srvTargets = srvTargets[:1]
+	mu.Unlock()
+	p.stats.ongoingUnsuccessfulProbes = 1
+	retryResolveHostname(context.Background(), p.stats)
+	p.updateSnapshot()
+
+	s = p.Statistics()
+	assert.Equal(t, "backup.example.test", s.Hostname)
+	assert.Equal(t, uint16(12346), s.Port)
+	assert.Equal(t, netip.MustParseAddr("127.0.0.2"), s.IP)
+}
+
+func TestResolvedAddrs(t *testing.T) {
+	opts := Options{
+		Printer:               &dummyPrinter{},
+		Hostname:              "www.example.test",
+		Port:                  12345,
+		IntervalBetweenProbes: time.Second,
+		Resolver:              serveTestDNS(t, 300, 300),
+	}
+
+	p, err := New(opts)
+	assert.NoError(t, err)
+	assert.Equal(t, []netip.Addr{netip.MustParseAddr("127.0.0.1")}, p.Statistics().ResolvedAddrs)
+
+	// the static hosts are not resolved
+	opts.Hosts = map[string][]netip.Addr{"www.example.test": {netip.MustParseAddr("127.0.0.2")}}
+	p, err = New(opts)
+	assert.NoError(t, err)
+	assert.Empty(t, p.Statistics().ResolvedAddrs)
+}
+
+func TestResolveInterval(t *testing.T) {
+	var mu sync.Mutex
+	addr := [4]byte{127, 0, 0, 1}
+	resolver := serveTestDNSAnswers(t, func(q dnsmessage.Question) []dnsmessage.Resource {
+		if q.Type != dnsmessage.TypeA {
+			return nil
+		}
+
+		mu.Lock()
+		defer mu.Unlock()
+
+		return []dnsmessage.Resource{{
+			Header: dnsmessage.ResourceHeader{Name: q.Name, Type: q.Type, Class: q.Class, TTL: 300},
+			Body:   &dnsmessage.AResource{A: addr},
+		}}
+	})
+
+	p, err := New(Options{
+		Printer:               &dummyPrinter{},
+		Hostname:              "www.example.test",
+		Port:                  12345,
+		IntervalBetweenProbes: 2 * time.Millisecond,
+		Timeout:               time.Millisecond,
+		ProbesBeforeQuit:      2,
+		ResolveInterval:       time.Nanosecond,
+		Resolver:              resolver,
+	})
+	assert.NoError(t, err)
+
+	// a silent DNS failover
+	mu.Lock()
+	addr = [4]byte{127, 0, 0, 2}
+	mu.Unlock()
+
+	p.Run()
+
+	s := p.Statistics()
+	assert.Equal(t, uint(2), s.RetriedHostnameLookups)
+	assert.Equal(t, netip.MustParseAddr("127.0.0.2"), s.IP)
+	if assert.Len(t, s.HostnameChanges, 2) {
+		assert.Equal(t, netip.MustParseAddr("127.0.0.2"), s.HostnameChanges[1].Addr)
+	}
+}
+
+func TestSameAddrs(t *testing.T) {
+	a := netip.MustParseAddr("192.0.2.1")
+	b := netip.MustParseAddr("192.0.2.2")
+
+	assert.True(t, sameAddrs([]netip.Addr{a, b}, []netip.Addr{b, a}))
+	assert.False(t, sameAddrs([]netip.Addr{a, b}, []netip.Addr{a}))
+	assert.False(t, sameAddrs([]netip.Addr{a}, []netip.Addr{b}))
+}
+
+func TestLinkLocalZone(t *testing.T) {
+	opts := Options{
+		Printer:               &dummyPrinter{},
+		Hostname:              "fe80::1%lo",
+		Port:                  12345,
+		IntervalBetweenProbes: time.Second,
+	}
+
+	p, err := New(opts)
+	assert.NoError(t, err)
+	assert.Equal(t, netip.MustParseAddr("fe80::1%lo"), p.Statistics().IP)
+	assert.True(t, p.Statistics().IsIP)
+
+	// the interface is needed without a zone
+	opts.Hostname = "fe80::1"
+	_, err = New(opts)
+	assert.EqualError(t, err, "link-local address fe80::1 needs a zone or an interface, e.g. fe80::1%eth0")
+
+	stats := createTestStats(t)
+	stats.userInput.ip = netip.MustParseAddr("fe80::1%lo")
+	ni, err := newNetworkInterface(stats, "fe80::2%lo")
+	assert.NoError(t, err)
+	assert.Equal(t, "lo", ni.raddr.Zone)
+	assert.Equal(t, "[fe80::1%lo]:12345", ni.raddr.String())
+	assert.Equal(t, "lo", ni.dialer.LocalAddr.(*net.TCPAddr).Zone)
+}
+
+func TestDNSFailures(t *testing.T) {
+	var mu sync.Mutex
+	up := true
+	resolver := serveTestDNSAnswers(t, func(q dnsmessage.Question) []dnsmessage.Resource {
+		mu.Lock()
+		defer mu.Unlock()
+
+		if q.Type != dnsmessage.TypeA || !up {
+			return nil
+		}
+
+		return []dnsmessage.Resource{{
+			Header: dnsmessage.ResourceHeader{Name: q.Name, Type: q.Type, Class: q.Class, TTL: 300},
+			Body:   &dnsmessage.AResource{A: [4]byte{127, 0, 0, 1}},
+		}}
+	})
+
+	p, err := New(Options{
+		Printer:                  &dummyPrinter{},
+		Hostname:                 "www.example.test",
+		Port:                     12345,
+		IntervalBetweenProbes:    2 * time.Millisecond,
+		Timeout:                  time.Millisecond,
+		ProbesBeforeQuit:         3,
+		RetryHostnameLookupAfter: 1,
+		Resolver:                 resolver,
+	})
+	assert.NoError(t, err)
+
+	// the name server stops answering once tcping runs
+	mu.Lock()
+	up = false
+	mu.Unlock()
+
+	p.Run()
+
+	s := p.Statistics()
+	assert.Equal(t, uint(2), s.DNSFailures)
+	assert.False(t, s.LastDNSFailure.IsZero())
+	assert.Equal(t, netip.MustParseAddr("127.0.0.1"), s.IP)
+}
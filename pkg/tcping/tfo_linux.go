@@ -0,0 +1,138 @@
+//go:build linux
+
+package tcping
+
+import (
+	"context"
+	"errors"
+	"net"
+	"syscall"
+	"time"
+
+	"golang.org/x/sys/unix"
+)
+
+// tfoSupported reports whether TCP Fast Open can be used on this platform.
+const tfoSupported = true
+
+const (
+	// tcpiOptSynData is set in tcpi_options when the
+	// server acknowledged the data sent in the SYN.
+	tcpiOptSynData = 0x20
+
+	// the states of include/net/tcp_states.h
+	tcpEstablished = 1
+	tcpSynSent     = 2
+	tcpClose       = 7
+)
+
+// tfoPayload is sent in the SYN. Unlike an arbitrary byte,
+// servers speaking line based protocols like HTTP ignore it.
+var tfoPayload = []byte("\r\n")
+
+// dialTFO connects with TCP_FASTOPEN_CONNECT and waits for the handshake.
+//
+// Without a cookie from the server, the kernel connects as usual and
+// requests one. With a cookie, the connection is only initiated by the
+// first write, so tfoPayload is sent in the SYN. It reports whether
+// the server accepted the payload.
+func dialTFO(ctx context.Context, d net.Dialer, address string) (net.Conn, bool, error) {
+	control := d.Control
+	d.Control = func(network, address string, c syscall.RawConn) error {
+		if control != nil {
+			if err := control(network, address, c); err != nil {
+				return err
+			}
+		}
+
+		var sockErr error
+		err := c.Control(func(fd uintptr) {
+			sockErr = unix.SetsockoptInt(int(fd), unix.IPPROTO_TCP, unix.TCP_FASTOPEN_CONNECT, 1)
+		})
+		if err != nil {
+			return err
+		}
+
+		return sockErr
+	}
+
+	conn, err := d.DialContext(ctx, "tcp", address)
+	if err != nil {
+		return nil, false, err
+	}
+
+	accepted, err := writeTFO(conn.(*net.TCPConn), d.Timeout)
+	if err != nil {
+		conn.Close()
+		return nil, false, err
+	}
+
+	return conn, accepted, nil
+}
+
+// writeTFO writes tfoPayload in the SYN and waits until the handshake is over.
+func writeTFO(conn *net.TCPConn, timeout time.Duration) (bool, error) {
+	raw, err := conn.SyscallConn()
+	if err != nil {
+		return false, err
+	}
+
+	var info *unix.TCPInfo
+	var sockErr error
+	err = raw.Control(func(fd uintptr) {
+		info, sockErr = unix.GetsockoptTCPInfo(int(fd), unix.IPPROTO_TCP, unix.TCP_INFO)
+	})
+	if err != nil {
+		return false, err
+	}
+	if sockErr != nil {
+		return false, sockErr
+	}
+
+	// without a cookie, the handshake is already over
+	if info.State != tcpSynSent {
+		return false, nil
+	}
+
+	if timeout > 0 {
+		conn.SetWriteDeadline(time.Now().Add(timeout))
+	}
+
+	if _, err := conn.Write(tfoPayload); err != nil {
+		return false, err
+	}
+
+	// returning false waits for the socket to become writable,
+	// which happens once the SYN-ACK or a RST is received
+	err = raw.Write(func(fd uintptr) bool {
+		info, sockErr = unix.GetsockoptTCPInfo(int(fd), unix.IPPROTO_TCP, unix.TCP_INFO)
+		if sockErr != nil {
+			return true
+		}
+
+		if info.State == tcpClose {
+			errno, err := unix.GetsockoptInt(int(fd), unix.SOL_SOCKET, unix.SO_ERROR)
+			switch {
+			case err != nil:
+				sockErr = err
+			case errno == int(unix.ECONNRESET):
+				// the server closed the connection after the handshake
+				// without reading the payload, which is fine
+			case errno != 0:
+				sockErr = syscall.Errno(errno)
+			default:
+				sockErr = errors.New("connection closed during the handshake")
+			}
+		}
+
+		return info.State != tcpSynSent
+	})
+	if err != nil {
+		return false, err
+	}
+	if sockErr != nil {
+		return false, sockErr
+	}
+
+	return info.Options&tcpiOptSynData != 0, nil
+}
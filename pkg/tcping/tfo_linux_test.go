@@ -0,0 +1,106 @@
+package tcping
+
+import (
+	"context"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/sys/unix"
+)
+
+// runTFOProbes runs the probes with TCP Fast Open against port 12345.
+func runTFOProbes(t *testing.T, probes uint) ([]Result, Statistics) {
+	var results []Result
+	p, err := New(Options{
+		Printer:               &dummyPrinter{},
+		Hostname:              "127.0.0.1",
+		Port:                  12345,
+		IntervalBetweenProbes: 2 * time.Millisecond,
+		Timeout:               time.Second,
+		ProbesBeforeQuit:      probes,
+		TFO:                   true,
+		Hooks: Hooks{
+			OnProbe: func(r Result) { results = append(results, r) },
+		},
+	})
+	assert.NoError(t, err)
+
+	p.Run()
+
+	return results, p.Statistics()
+}
+
+func TestTFONotAccepted(t *testing.T) {
+	srv := testServerListen(t)
+	t.Cleanup(func() { srv.Close() })
+
+	results, s := runTFOProbes(t, 2)
+
+	if assert.Len(t, results, 2) {
+		for _, r := range results {
+			assert.True(t, r.Success)
+			assert.False(t, r.TFO)
+		}
+	}
+
+	assert.Equal(t, uint(0), s.TFOAcceptedProbes)
+	assert.False(t, s.TFORttResults.HasResults)
+	assert.True(t, s.RegularRttResults.HasResults)
+}
+
+func TestTFOAccepted(t *testing.T) {
+	// the server side must be enabled by the second bit
+	sysctl, err := os.ReadFile("/proc/sys/net/ipv4/tcp_fastopen")
+	if err != nil {
+		t.Skipf("tcp_fastopen: %v", err)
+	}
+	if mode, _ := strconv.Atoi(strings.TrimSpace(string(sysctl))); mode&3 != 3 {
+		t.Skip("TCP Fast Open is not enabled for both clients and servers")
+	}
+
+	lc := net.ListenConfig{
+		Control: func(_, _ string, c syscall.RawConn) error {
+			var sockErr error
+			err := c.Control(func(fd uintptr) {
+				sockErr = unix.SetsockoptInt(int(fd), unix.IPPROTO_TCP, unix.TCP_FASTOPEN, 16)
+			})
+			if err != nil {
+				return err
+			}
+			return sockErr
+		},
+	}
+	srv, err := lc.Listen(context.Background(), "tcp", "127.0.0.1:12345")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	t.Cleanup(func() { srv.Close() })
+
+	go func() {
+		for {
+			c, err := srv.Accept()
+			if err != nil {
+				return
+			}
+
+			c.Close()
+		}
+	}()
+
+	results, s := runTFOProbes(t, 3)
+
+	// the first probe gets the cookie, unless the kernel kept it from a previous run
+	if assert.Len(t, results, 3) {
+		assert.True(t, results[1].TFO)
+		assert.True(t, results[2].TFO)
+	}
+
+	assert.GreaterOrEqual(t, s.TFOAcceptedProbes, uint(2))
+	assert.True(t, s.TFORttResults.HasResults)
+}
@@ -0,0 +1,16 @@
+//go:build !linux
+
+package tcping
+
+import (
+	"context"
+	"errors"
+	"net"
+)
+
+// tfoSupported reports whether TCP Fast Open can be used on this platform.
+const tfoSupported = false
+
+func dialTFO(_ context.Context, _ net.Dialer, _ string) (net.Conn, bool, error) {
+	return nil, false, errors.New("TCP Fast Open is only supported on Linux")
+}
@@ -0,0 +1,116 @@
+package tcping
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+)
+
+// handshakeTLS performs the TLS handshake over the connection of
+// the probe and checks the certificates of the server.
+func (tcpStats *stats) handshakeTLS(ctx context.Context, conn net.Conn) (net.Conn, error) {
+	if tcpStats.userInput.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, tcpStats.userInput.Timeout)
+		defer cancel()
+	}
+
+	config := tcpStats.userInput.TLSConfig.Clone()
+	if config.ServerName == "" {
+		config.ServerName = tcpStats.userInput.Hostname
+	}
+
+	// tell the failures of the client authentication apart
+	tcpStats.clientCertRequested = false
+	getClientCertificate := config.GetClientCertificate
+	config.GetClientCertificate = func(cri *tls.CertificateRequestInfo) (*tls.Certificate, error) {
+		tcpStats.clientCertRequested = true
+		if getClientCertificate != nil {
+			return getClientCertificate(cri)
+		}
+
+		for i := range config.Certificates {
+			if cri.SupportsCertificate(&config.Certificates[i]) == nil {
+				return &config.Certificates[i], nil
+			}
+		}
+
+		// no certificate is sent
+		return &tls.Certificate{}, nil
+	}
+
+	tlsConn := tls.Client(conn, config)
+	if err := tlsConn.HandshakeContext(ctx); err != nil {
+		err = tcpStats.clientAuthError(err)
+		tcpStats.printer.PrintError("TLS handshake with %s on port %d failed: %s",
+			tcpStats.userInput.ip, tcpStats.userInput.Port, err)
+		return nil, err
+	}
+
+	tcpStats.checkCertificates(tlsConn.ConnectionState().PeerCertificates)
+
+	return tlsConn, nil
+}
+
+// checkCertificates prints the chain when it's first seen or
+// changes, and warns once when it's about to expire.
+func (tcpStats *stats) checkCertificates(chain []*x509.Certificate) {
+	if len(chain) == 0 {
+		return
+	}
+
+	leaf := chain[0]
+	if tcpStats.certificate == nil || !tcpStats.certificate.Equal(leaf) {
+		if tcpStats.certificate != nil {
+			tcpStats.printer.PrintInfo("The certificate of %s on port %d changed",
+				tcpStats.userInput.ip, tcpStats.userInput.Port)
+		}
+
+		tcpStats.certificate = leaf
+		tcpStats.certExpiry = leaf.NotAfter
+		tcpStats.certWarned = false
+
+		for _, cert := range chain {
+			tcpStats.printer.PrintInfo("Certificate %q issued by %q expires on %s",
+				cert.Subject, cert.Issuer, cert.NotAfter.Format(timeFormat))
+
+			if cert.NotAfter.Before(tcpStats.certExpiry) {
+				tcpStats.certExpiry = cert.NotAfter
+			}
+		}
+	}
+
+	remaining := time.Until(tcpStats.certExpiry)
+	if !tcpStats.certWarned && remaining < tcpStats.userInput.CertWarning {
+		tcpStats.printer.PrintError("The certificate chain of %s expires in %d days, on %s",
+			tcpStats.userInput.Hostname, certDaysRemaining(tcpStats.certExpiry), tcpStats.certExpiry.Format(timeFormat))
+		tcpStats.certWarned = true
+	}
+}
+
+// clientAuthError tells apart the alerts of servers rejecting the client
+// certificate, or its absence. With TLS 1.3, they're only received after
+// the handshake, when reading from the connection.
+func (tcpStats *stats) clientAuthError(err error) error {
+	var opErr *net.OpError
+	if !errors.As(err, &opErr) || opErr.Op != "remote error" {
+		return err
+	}
+
+	// the alerts about certificates received by clients are about their own
+	if tcpStats.clientCertRequested || strings.Contains(opErr.Err.Error(), "certificate") {
+		return fmt.Errorf("client authentication failed: %w", err)
+	}
+
+	return err
+}
+
+// certDaysRemaining returns the number of whole days until the expiry.
+func certDaysRemaining(expiry time.Time) int {
+	return int(time.Until(expiry).Hours() / 24)
+}
@@ -0,0 +1,147 @@
+package tcping
+
+import (
+	"crypto/tls"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// serveTestTLS starts a TLS server on port 12345 and returns the
+// config of the clients trusting it. configure, if not nil,
+// changes the config of the server.
+func serveTestTLS(t *testing.T, configure func(c *tls.Config)) *tls.Config {
+	// borrow the certificate of an httptest server
+	certSrv := httptest.NewTLSServer(http.NotFoundHandler())
+	t.Cleanup(certSrv.Close)
+
+	serverConfig := &tls.Config{Certificates: certSrv.TLS.Certificates}
+	if configure != nil {
+		configure(serverConfig)
+	}
+
+	ln, err := tls.Listen("tcp", "127.0.0.1:12345", serverConfig)
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	t.Cleanup(func() { ln.Close() })
+
+	go func() {
+		for {
+			c, err := ln.Accept()
+			if err != nil {
+				return
+			}
+
+			c.(*tls.Conn).Handshake()
+			c.Close()
+		}
+	}()
+
+	return certSrv.Client().Transport.(*http.Transport).TLSClientConfig.Clone()
+}
+
+func TestTLS(t *testing.T) {
+	config := serveTestTLS(t, nil)
+
+	var out strings.Builder
+	var results []Result
+	p, err := New(Options{
+		Printer:               &journalPrinter{w: &out},
+		Hostname:              "127.0.0.1",
+		Port:                  12345,
+		IntervalBetweenProbes: 2 * time.Millisecond,
+		Timeout:               time.Second,
+		ProbesBeforeQuit:      2,
+		TLSConfig:             config,
+		// the certificate of httptest expires in decades
+		CertWarning: 100 * 365 * 24 * time.Hour,
+		Hooks: Hooks{
+			OnProbe: func(r Result) { results = append(results, r) },
+		},
+	})
+	assert.NoError(t, err)
+
+	p.Run()
+
+	if assert.Len(t, results, 2) {
+		assert.True(t, results[0].Success)
+		assert.True(t, results[1].Success)
+	}
+
+	// the chain and the warning are only printed once
+	assert.Equal(t, 1, strings.Count(out.String(), "<6>Certificate "), out.String())
+	assert.Equal(t, 1, strings.Count(out.String(), "<3>The certificate chain of 127.0.0.1 expires in "), out.String())
+
+	assert.True(t, p.Statistics().CertExpiry.After(time.Now().AddDate(10, 0, 0)))
+}
+
+func TestTLSUntrusted(t *testing.T) {
+	serveTestTLS(t, nil)
+
+	var results []Result
+	p, err := New(Options{
+		Printer:               &dummyPrinter{},
+		Hostname:              "127.0.0.1",
+		Port:                  12345,
+		IntervalBetweenProbes: 2 * time.Millisecond,
+		Timeout:               time.Second,
+		ProbesBeforeQuit:      1,
+		TLSConfig:             &tls.Config{},
+		Hooks: Hooks{
+			OnProbe: func(r Result) { results = append(results, r) },
+		},
+	})
+	assert.NoError(t, err)
+
+	p.Run()
+
+	if assert.Len(t, results, 1) {
+		assert.False(t, results[0].Success)
+	}
+	assert.True(t, p.Statistics().CertExpiry.IsZero())
+}
+
+func TestTLSClientAuth(t *testing.T) {
+	var clientCert tls.Certificate
+	config := serveTestTLS(t, func(c *tls.Config) {
+		// with TLS 1.3, the server would only reject the client after the handshake
+		c.MaxVersion = tls.VersionTLS12
+		c.ClientAuth = tls.RequireAnyClientCert
+		clientCert = c.Certificates[0]
+	})
+
+	probe := func(config *tls.Config) (Result, string) {
+		var out strings.Builder
+		var result Result
+		p, err := New(Options{
+			Printer:               &journalPrinter{w: &out},
+			Hostname:              "127.0.0.1",
+			Port:                  12345,
+			IntervalBetweenProbes: 2 * time.Millisecond,
+			Timeout:               time.Second,
+			ProbesBeforeQuit:      1,
+			TLSConfig:             config,
+			Hooks: Hooks{
+				OnProbe: func(r Result) { result = r },
+			},
+		})
+		assert.NoError(t, err)
+
+		p.Run()
+
+		return result, out.String()
+	}
+
+	result, out := probe(config)
+	assert.False(t, result.Success)
+	assert.Contains(t, out, "failed: client authentication failed: remote error: tls:")
+
+	config.Certificates = []tls.Certificate{clientCert}
+	result, out = probe(config)
+	assert.True(t, result.Success, out)
+}
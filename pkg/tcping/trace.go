@@ -0,0 +1,225 @@
+package tcping
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net"
+	"net/netip"
+	"time"
+
+	"golang.org/x/net/icmp"
+)
+
+// Hop is a step of the path to the target, see [Trace].
+type Hop struct {
+	// TTL of the SYN that found the hop.
+	TTL int
+	// Addr is the router that answered, or the target once it's reached.
+	// It's invalid when nothing answered within the timeout.
+	Addr netip.Addr
+	// RTT of the answer in milliseconds.
+	RTT float32
+	// Reached is set when the target answered, and Open
+	// when it accepted the connection instead of resetting it.
+	Reached bool
+	Open    bool
+	// Unreachable is set when a router reported that the target is unreachable.
+	Unreachable bool
+}
+
+// HopPrinter is implemented by the printers that print the hops
+// found by [Trace]. The other printers get them with PrintInfo instead.
+type HopPrinter interface {
+	// PrintHop is called once the hop answered or timed out.
+	PrintHop(hop Hop)
+}
+
+// icmpQuote is an ICMP error quoting a SYN sent to the target.
+type icmpQuote struct {
+	from        netip.Addr
+	srcPort     int
+	unreachable bool
+	received    time.Time
+}
+
+// Trace finds the routers on the path to the target, like traceroute,
+// by connecting with increasing TTLs until the target answers or
+// maxHops is reached. The hops are printed as they're found.
+//
+// The target is resolved like with [ResolveAll] and the first address
+// is traced. Only supported on Linux, with the privileges of raw sockets
+// to read the ICMP errors of the routers.
+func Trace(opts Options, maxHops int) ([]Hop, error) {
+	if !traceSupported {
+		return nil, errors.New("tracing is only supported on Linux")
+	}
+
+	if opts.Printer == nil {
+		return nil, errors.New("printer is required")
+	}
+
+	if opts.Port == 0 {
+		return nil, errors.New("port should be in 1..65535 range")
+	}
+
+	addrs, err := ResolveAll(opts)
+	if err != nil {
+		return nil, err
+	}
+	ip := addrs[0]
+
+	network, address := "ip4:icmp", "0.0.0.0"
+	if ip.Is6() {
+		network, address = "ip6:ipv6-icmp", "::"
+	}
+	conn, err := icmp.ListenPacket(network, address)
+	if err != nil {
+		return nil, fmt.Errorf("unable to open a raw ICMP socket, which needs root or CAP_NET_RAW: %w", err)
+	}
+	defer conn.Close()
+
+	quotes := make(chan icmpQuote, 16)
+	go readQuotes(conn, ip, opts.Port, quotes)
+
+	timeout := opts.Timeout
+	if timeout <= 0 {
+		timeout = time.Second
+	}
+
+	opts.Printer.PrintInfo("Tracing the path to %s (%s) on port %d, %d hops max",
+		opts.Hostname, ip, opts.Port, maxHops)
+
+	var hops []Hop
+	for ttl := 1; ttl <= maxHops; ttl++ {
+		hop, err := traceHop(ip, opts.Port, ttl, timeout, quotes)
+		if err != nil {
+			return hops, err
+		}
+
+		hops = append(hops, hop)
+		printHop(opts.Printer, hop)
+
+		if hop.Reached || hop.Unreachable {
+			break
+		}
+	}
+
+	return hops, nil
+}
+
+// printHop prints the hop found by the trace.
+func printHop(printer Printer, hop Hop) {
+	eachPrinter(printer, func(printer Printer) {
+		if p, ok := printer.(HopPrinter); ok {
+			p.PrintHop(hop)
+			return
+		}
+
+		printer.PrintInfo("%s", formatHop(hop))
+	})
+}
+
+// formatHop describes the hop on a single line, like traceroute.
+func formatHop(hop Hop) string {
+	if !hop.Addr.IsValid() {
+		return fmt.Sprintf("%2d  *", hop.TTL)
+	}
+
+	line := fmt.Sprintf("%2d  %s  %.3f ms", hop.TTL, hop.Addr, hop.RTT)
+	switch {
+	case hop.Open:
+		line += "  (reached, port open)"
+	case hop.Reached:
+		line += "  (reached, port closed)"
+	case hop.Unreachable:
+		line += "  (target unreachable)"
+	}
+
+	return line
+}
+
+// readQuotes sends the ICMP errors about the SYNs sent to the
+// target to the channel, until the connection is closed.
+func readQuotes(conn *icmp.PacketConn, target netip.Addr, port uint16, quotes chan<- icmpQuote) {
+	proto := protocolICMP
+	if target.Is6() {
+		proto = protocolICMPIPv6
+	}
+
+	buf := make([]byte, 1500)
+	for {
+		n, peer, err := conn.ReadFrom(buf)
+		if err != nil {
+			return
+		}
+		received := time.Now()
+
+		msg, err := icmp.ParseMessage(proto, buf[:n])
+		if err != nil {
+			continue
+		}
+
+		var data []byte
+		var unreachable bool
+		switch body := msg.Body.(type) {
+		case *icmp.TimeExceeded:
+			data = body.Data
+		case *icmp.DstUnreach:
+			data, unreachable = body.Data, true
+		default:
+			continue
+		}
+
+		srcPort, ok := quotedSourcePort(data, target, port)
+		if !ok {
+			continue
+		}
+
+		ipAddr, ok := peer.(*net.IPAddr)
+		if !ok {
+			continue
+		}
+		from, _ := netip.AddrFromSlice(ipAddr.IP)
+
+		select {
+		case quotes <- icmpQuote{from: from.Unmap(), srcPort: srcPort, unreachable: unreachable, received: received}:
+		default:
+		}
+	}
+}
+
+// quotedSourcePort returns the source port of the SYN quoted
+// by an ICMP error, if it was sent to the target.
+func quotedSourcePort(data []byte, target netip.Addr, port uint16) (int, bool) {
+	var dst netip.Addr
+	var tcp []byte
+
+	if target.Is4() {
+		if len(data) < 20 || data[0]>>4 != 4 || data[9] != 6 {
+			return 0, false
+		}
+
+		ihl := int(data[0]&0x0f) * 4
+		if len(data) < ihl+4 {
+			return 0, false
+		}
+
+		dst = netip.AddrFrom4([4]byte(data[16:20]))
+		tcp = data[ihl:]
+	} else {
+		// the SYNs are sent without extension headers
+		if len(data) < 44 || data[0]>>4 != 6 || data[6] != 6 {
+			return 0, false
+		}
+
+		dst = netip.AddrFrom16([16]byte(data[24:40]))
+		tcp = data[40:]
+	}
+
+	if dst != target.WithZone("") || binary.BigEndian.Uint16(tcp[2:4]) != port {
+		return 0, false
+	}
+
+	return int(binary.BigEndian.Uint16(tcp[0:2])), true
+}
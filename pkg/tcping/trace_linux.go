@@ -0,0 +1,128 @@
+//go:build linux
+
+package tcping
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/netip"
+	"syscall"
+	"time"
+
+	"golang.org/x/sys/unix"
+)
+
+// traceSupported reports whether [Trace] can be used on this platform.
+const traceSupported = true
+
+// traceHop connects to the target with the given TTL and waits for
+// the ICMP error of the router where the SYN expired, or the target.
+func traceHop(ip netip.Addr, port uint16, ttl int, timeout time.Duration, quotes <-chan icmpQuote) (Hop, error) {
+	hop := Hop{TTL: ttl}
+
+	network := "tcp4"
+	if ip.Is6() {
+		network = "tcp6"
+	}
+
+	// the source port tells the ICMP errors of this SYN apart
+	localPort := make(chan int, 1)
+	d := net.Dialer{
+		Control: func(_, _ string, c syscall.RawConn) error {
+			var err error
+			ctrlErr := c.Control(func(fd uintptr) {
+				var port int
+				if port, err = setTTLAndBind(int(fd), ip.Is6(), ttl); err == nil {
+					localPort <- port
+				}
+			})
+			if ctrlErr != nil {
+				return ctrlErr
+			}
+			return err
+		},
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	start := time.Now()
+	dialed := make(chan error, 1)
+	go func() {
+		conn, err := d.DialContext(ctx, network, netip.AddrPortFrom(ip, port).String())
+		if err == nil {
+			conn.Close()
+		}
+		dialed <- err
+	}()
+
+	var srcPort int
+	select {
+	case srcPort = <-localPort:
+	case err := <-dialed:
+		return hop, err
+	}
+
+	for {
+		select {
+		case q := <-quotes:
+			if q.srcPort != srcPort {
+				continue
+			}
+
+			hop.Addr = q.from
+			hop.RTT = nanoToMillisecond(q.received.Sub(start).Nanoseconds())
+			hop.Unreachable = q.unreachable
+			return hop, nil
+		case err := <-dialed:
+			if err == nil || errors.Is(err, syscall.ECONNREFUSED) {
+				hop.Addr = ip
+				hop.RTT = nanoToMillisecond(time.Since(start).Nanoseconds())
+				hop.Reached = true
+				hop.Open = err == nil
+				return hop, nil
+			}
+
+			// the ICMP error of the router also aborts the
+			// connection, its copy is read from the raw socket
+			dialed = nil
+		case <-ctx.Done():
+			return hop, nil
+		}
+	}
+}
+
+// setTTLAndBind sets the TTL of the socket and binds it
+// to an ephemeral port, which is returned.
+func setTTLAndBind(fd int, v6 bool, ttl int) (int, error) {
+	if v6 {
+		if err := unix.SetsockoptInt(fd, unix.IPPROTO_IPV6, unix.IPV6_UNICAST_HOPS, ttl); err != nil {
+			return 0, err
+		}
+		if err := unix.Bind(fd, &unix.SockaddrInet6{}); err != nil {
+			return 0, err
+		}
+	} else {
+		if err := unix.SetsockoptInt(fd, unix.IPPROTO_IP, unix.IP_TTL, ttl); err != nil {
+			return 0, err
+		}
+		if err := unix.Bind(fd, &unix.SockaddrInet4{}); err != nil {
+			return 0, err
+		}
+	}
+
+	sa, err := unix.Getsockname(fd)
+	if err != nil {
+		return 0, err
+	}
+
+	switch sa := sa.(type) {
+	case *unix.SockaddrInet4:
+		return sa.Port, nil
+	case *unix.SockaddrInet6:
+		return sa.Port, nil
+	}
+
+	return 0, errors.New("unexpected address of the socket")
+}
@@ -0,0 +1,39 @@
+package tcping
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/net/icmp"
+)
+
+func TestTrace(t *testing.T) {
+	conn, err := icmp.ListenPacket("ip4:icmp", "0.0.0.0")
+	if err != nil {
+		t.Skipf("raw ICMP sockets aren't allowed: %v", err)
+	}
+	conn.Close()
+
+	srv, err := net.Listen("tcp", "127.0.0.1:12345")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	t.Cleanup(func() { srv.Close() })
+
+	hops, err := Trace(Options{
+		Printer:  &dummyPrinter{},
+		Hostname: "127.0.0.1",
+		Port:     12345,
+		Timeout:  time.Second,
+	}, 5)
+	assert.NoError(t, err)
+
+	// the target is the first hop
+	if assert.Len(t, hops, 1) {
+		assert.True(t, hops[0].Reached)
+		assert.True(t, hops[0].Open)
+		assert.Equal(t, "127.0.0.1", hops[0].Addr.String())
+	}
+}
@@ -0,0 +1,16 @@
+//go:build !linux
+
+package tcping
+
+import (
+	"errors"
+	"net/netip"
+	"time"
+)
+
+// traceSupported reports whether [Trace] can be used on this platform.
+const traceSupported = false
+
+func traceHop(_ netip.Addr, _ uint16, _ int, _ time.Duration, _ <-chan icmpQuote) (Hop, error) {
+	return Hop{}, errors.New("tracing is only supported on Linux")
+}
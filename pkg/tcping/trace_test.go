@@ -0,0 +1,44 @@
+package tcping
+
+import (
+	"net/netip"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestQuotedSourcePort(t *testing.T) {
+	target := netip.MustParseAddr("192.0.2.1")
+
+	// the IPv4 header and the ports of a SYN from 40000 to 443
+	syn := []byte{
+		0x45, 0, 0, 60, 0, 0, 0x40, 0, 1, 6, 0, 0,
+		198, 51, 100, 1,
+		192, 0, 2, 1,
+		0x9c, 0x40, 0x01, 0xbb, 0, 0, 0, 0,
+	}
+
+	port, ok := quotedSourcePort(syn, target, 443)
+	assert.True(t, ok)
+	assert.Equal(t, 40000, port)
+
+	_, ok = quotedSourcePort(syn, target, 80)
+	assert.False(t, ok)
+
+	_, ok = quotedSourcePort(syn, netip.MustParseAddr("192.0.2.2"), 443)
+	assert.False(t, ok)
+
+	_, ok = quotedSourcePort(syn[:22], target, 443)
+	assert.False(t, ok)
+}
+
+func TestFormatHop(t *testing.T) {
+	addr := netip.MustParseAddr("192.0.2.1")
+
+	assert.Equal(t, " 3  *", formatHop(Hop{TTL: 3}))
+	assert.Equal(t, " 3  192.0.2.1  1.500 ms", formatHop(Hop{TTL: 3, Addr: addr, RTT: 1.5}))
+	assert.Equal(t, "12  192.0.2.1  1.500 ms  (reached, port open)",
+		formatHop(Hop{TTL: 12, Addr: addr, RTT: 1.5, Reached: true, Open: true}))
+	assert.Equal(t, " 3  192.0.2.1  1.500 ms  (target unreachable)",
+		formatHop(Hop{TTL: 3, Addr: addr, RTT: 1.5, Unreachable: true}))
+}
@@ -0,0 +1,165 @@
+package tcping
+
+import (
+	"math"
+	"slices"
+	"time"
+)
+
+// minTrendWindow is the fewest RTTs the trend is computed over, and
+// maxTrendWindow the most, as the Mann-Kendall test compares every
+// pair of RTTs of the window after each successful probe.
+const (
+	minTrendWindow = 10
+	maxTrendWindow = 1000
+)
+
+// the RTTs start trending up when the Z statistic of the Mann-Kendall
+// test is above trendStartZ, a one-sided p-value of 1%, and they rise by
+// more than trendMinRise of their median over the window, so that a
+// tenth of a millisecond on a LAN isn't a trend. They stop once Z drops
+// below trendEndZ, so that the warning doesn't flap.
+const (
+	trendStartZ  = 2.326
+	trendEndZ    = 1.0
+	trendMinRise = 0.1
+)
+
+// trend follows the RTTs of the recent successful probes,
+// see Options.TrendWindow.
+type trend struct {
+	rtts []float32 // rtts are the RTTs of the window, oldest first.
+	up   bool      // up is set while the RTTs are trending up.
+	// start is when the RTTs started trending up.
+	start time.Time
+}
+
+// mannKendall returns the Z statistic of the Mann-Kendall test of the
+// RTTs, positive when they rise, and their Sen's slope, the median of the
+// slopes between all the pairs of them, in ms per probe. The RTTs are
+// seldom equal to the microsecond, the ties aren't corrected for.
+func mannKendall(rtts []float32) (float64, float64) {
+	n := len(rtts)
+	s := 0
+	slopes := make([]float64, 0, n*(n-1)/2)
+	for i := 0; i < n; i++ {
+		for j := i + 1; j < n; j++ {
+			diff := float64(rtts[j] - rtts[i])
+			switch {
+			case diff > 0:
+				s++
+			case diff < 0:
+				s--
+			}
+			slopes = append(slopes, diff/float64(j-i))
+		}
+	}
+
+	variance := float64(n*(n-1)*(2*n+5)) / 18
+	var z float64
+	switch {
+	case s > 0:
+		z = float64(s-1) / math.Sqrt(variance)
+	case s < 0:
+		z = float64(s+1) / math.Sqrt(variance)
+	}
+
+	slices.Sort(slopes)
+	return z, median(slopes)
+}
+
+// median returns the median of the sorted values.
+func median(sorted []float64) float64 {
+	n := len(sorted)
+	if n == 0 {
+		return 0
+	}
+	if n%2 == 1 {
+		return sorted[n/2]
+	}
+	return (sorted[n/2-1] + sorted[n/2]) / 2
+}
+
+// recordTrend adds the RTT of the successful probe to the window and
+// reports whether the RTTs are trending up. The warning is printed when
+// they start and stop, and the hooks and the notifiers are notified
+// with Options.NotifyTrend.
+func (tcpStats *stats) recordTrend(rtt float32, connTime time.Time) bool {
+	window := int(tcpStats.userInput.TrendWindow)
+	if window == 0 {
+		return false
+	}
+
+	t := &tcpStats.trend
+	t.rtts = append(t.rtts, rtt)
+	if len(t.rtts) > window {
+		t.rtts = t.rtts[1:]
+	}
+	if len(t.rtts) < window {
+		return t.up
+	}
+
+	z, slope := mannKendall(t.rtts)
+	sorted := make([]float64, len(t.rtts))
+	for i, r := range t.rtts {
+		sorted[i] = float64(r)
+	}
+	slices.Sort(sorted)
+	rise := slope * float64(window-1)
+
+	change := StateChange{
+		When:     connTime,
+		Hostname: tcpStats.userInput.Hostname,
+		IP:       tcpStats.userInput.ip.String(),
+		Port:     tcpStats.userInput.Port,
+		RTT:      rtt,
+		Trend:    true,
+	}
+
+	switch {
+	case !t.up && z > trendStartZ && rise > trendMinRise*median(sorted):
+		t.up = true
+		t.start = connTime
+		tcpStats.latencyTrends += 1
+		tcpStats.printer.PrintInfo("Latency trending up: %+.3f ms per probe over the last %d probes", slope, window)
+	case t.up && z < trendEndZ:
+		tcpStats.endTrend(rtt, connTime)
+		return false
+	default:
+		return t.up
+	}
+
+	if tcpStats.userInput.NotifyTrend {
+		tcpStats.notifyStateChange(change)
+		tcpStats.ringBell(BellOnChange)
+	}
+
+	return t.up
+}
+
+// endTrend ends the ongoing trend, e.g. when the target goes down,
+// notifying it with Options.NotifyTrend.
+func (tcpStats *stats) endTrend(rtt float32, connTime time.Time) {
+	t := &tcpStats.trend
+	if !t.up {
+		return
+	}
+	t.up = false
+
+	change := StateChange{
+		When:     connTime,
+		Hostname: tcpStats.userInput.Hostname,
+		IP:       tcpStats.userInput.ip.String(),
+		Port:     tcpStats.userInput.Port,
+		RTT:      rtt,
+		Downtime: connTime.Sub(t.start),
+		Up:       true,
+		Trend:    true,
+	}
+	tcpStats.printer.PrintInfo("Latency no longer trending up after %s", DurationToString(change.Downtime))
+
+	if tcpStats.userInput.NotifyTrend {
+		tcpStats.notifyStateChange(change)
+		tcpStats.ringBell(BellOnChange)
+	}
+}
@@ -0,0 +1,126 @@
+package tcping
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMannKendall(t *testing.T) {
+	z, slope := mannKendall([]float32{1, 2, 3, 4, 5, 6, 7, 8, 9, 10})
+	assert.InDelta(t, 3.935, z, 0.001)
+	assert.InDelta(t, 1, slope, 0.001)
+
+	z, slope = mannKendall([]float32{10, 9, 8, 7, 6, 5, 4, 3, 2, 1})
+	assert.InDelta(t, -3.935, z, 0.001)
+	assert.InDelta(t, -1, slope, 0.001)
+
+	z, _ = mannKendall([]float32{20, 21, 20, 21, 20, 21, 20, 21, 20, 21})
+	assert.Less(t, z, trendEndZ)
+}
+
+func TestTrend(t *testing.T) {
+	stats := createTestStats(t)
+	stats.userInput.TrendWindow = minTrendWindow
+	stats.userInput.NotifyTrend = true
+
+	var results []Result
+	var changes []StateChange
+	stats.userInput.Hooks.OnProbe = func(r Result) { results = append(results, r) }
+	stats.userInput.Hooks.OnStateChange = func(change StateChange) { changes = append(changes, change) }
+
+	rtts := []float32{
+		// steady
+		20, 21, 20, 22, 21, 20, 21, 22, 20, 21,
+		// creeping up
+		23, 25, 27, 29, 31, 33, 35, 37,
+		// steady again, a bit higher
+		30, 31, 30, 31, 30, 31, 30, 31, 30, 31,
+	}
+	start := time.Now()
+	for i, rtt := range rtts {
+		stats.handleConnSuccess(rtt, start.Add(time.Duration(i)*time.Second))
+	}
+
+	if !assert.Len(t, results, len(rtts)) {
+		return
+	}
+	for _, r := range results[:10] {
+		assert.False(t, r.TrendingUp)
+	}
+	assert.True(t, results[17].TrendingUp)
+	assert.False(t, results[len(rtts)-1].TrendingUp)
+
+	if assert.Len(t, changes, 2) {
+		assert.True(t, changes[0].Trend)
+		assert.False(t, changes[0].Up)
+		assert.True(t, changes[1].Trend)
+		assert.True(t, changes[1].Up)
+		assert.Positive(t, changes[1].Downtime)
+	}
+	assert.Equal(t, uint(1), stats.statistics().LatencyTrends)
+
+	// the RTTs of before going down don't count
+	stats.handleConnError(start.Add(time.Minute), nil)
+	assert.Empty(t, stats.trend.rtts)
+}
+
+func TestTrendThenDown(t *testing.T) {
+	stats := createTestStats(t)
+	stats.userInput.TrendWindow = minTrendWindow
+	stats.userInput.NotifyTrend = true
+
+	var changes []StateChange
+	stats.userInput.Hooks.OnStateChange = func(change StateChange) { changes = append(changes, change) }
+
+	start := time.Now()
+	for i := 0; i < minTrendWindow; i++ {
+		stats.handleConnSuccess(20+2*float32(i), start.Add(time.Duration(i)*time.Second))
+	}
+	assert.True(t, stats.trend.up)
+	stats.handleConnError(start.Add(time.Minute), nil)
+
+	// the trend ends before the target goes down
+	if assert.Len(t, changes, 3) {
+		assert.True(t, changes[0].Trend)
+		assert.False(t, changes[0].Up)
+		assert.True(t, changes[1].Trend)
+		assert.True(t, changes[1].Up)
+		assert.Equal(t, time.Minute-(minTrendWindow-1)*time.Second, changes[1].Downtime)
+		assert.False(t, changes[2].Trend)
+		assert.False(t, changes[2].Up)
+	}
+	assert.False(t, stats.trend.up)
+}
+
+func TestTrendLAN(t *testing.T) {
+	stats := createTestStats(t)
+	stats.userInput.TrendWindow = minTrendWindow
+
+	// rising steadily, but by a few microseconds
+	for i := 0; i < 2*minTrendWindow; i++ {
+		assert.False(t, stats.recordTrend(0.2+float32(i)*0.001, time.Now()))
+	}
+}
+
+func TestTrendWindow(t *testing.T) {
+	opts := Options{
+		Printer:               &dummyPrinter{},
+		Hostname:              "127.0.0.1",
+		Port:                  12345,
+		IntervalBetweenProbes: time.Second,
+	}
+
+	opts.TrendWindow = minTrendWindow - 1
+	_, err := New(opts)
+	assert.EqualError(t, err, "the trend window needs at least 10 probes")
+
+	opts.TrendWindow = maxTrendWindow + 1
+	_, err = New(opts)
+	assert.EqualError(t, err, "the trend window can't be more than 1000 probes")
+
+	opts.TrendWindow = maxTrendWindow
+	_, err = New(opts)
+	assert.NoError(t, err)
+}
@@ -0,0 +1,188 @@
+package tcping
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"net"
+	"os"
+	"strings"
+	"time"
+
+	"golang.org/x/net/dns/dnsmessage"
+)
+
+// resolvConfPath is where the system's name servers are read from.
+const resolvConfPath = "/etc/resolv.conf"
+
+// errNoRecords is returned when the answer has no address records.
+var errNoRecords = errors.New("no address records")
+
+// lookupTTL returns the smallest TTL of the records the addresses
+// of the hostname were resolved from, CNAMEs included.
+//
+// net.Resolver doesn't expose the TTLs, so the same query is sent
+// once more through the resolver's Dial or to the system's name server.
+func lookupTTL(opts Options) (time.Duration, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), dnsTimeout)
+	defer cancel()
+
+	qtype := dnsmessage.TypeA
+	if opts.UseIPv6 {
+		qtype = dnsmessage.TypeAAAA
+	}
+
+	ttl, err := queryTTL(ctx, opts.Resolver, opts.Hostname, qtype)
+	if errors.Is(err, errNoRecords) && !opts.UseIPv4 && !opts.UseIPv6 {
+		// an IPv6 only target
+		return queryTTL(ctx, opts.Resolver, opts.Hostname, dnsmessage.TypeAAAA)
+	}
+
+	return ttl, err
+}
+
+// queryTTL sends a single query and returns the smallest TTL of the answer.
+func queryTTL(ctx context.Context, resolver *net.Resolver, hostname string, qtype dnsmessage.Type) (time.Duration, error) {
+	name, err := dnsmessage.NewName(strings.TrimSuffix(hostname, ".") + ".")
+	if err != nil {
+		return 0, err
+	}
+
+	id := uint16(rand.Intn(1 << 16))
+	query, err := (&dnsmessage.Message{
+		Header:    dnsmessage.Header{ID: id, RecursionDesired: true},
+		Questions: []dnsmessage.Question{{Name: name, Type: qtype, Class: dnsmessage.ClassINET}},
+	}).Pack()
+	if err != nil {
+		return 0, err
+	}
+
+	conn, err := dialNameServer(ctx, resolver)
+	if err != nil {
+		return 0, err
+	}
+	defer conn.Close()
+
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+
+	answer, err := exchange(conn, query)
+	if err != nil {
+		return 0, err
+	}
+
+	var msg dnsmessage.Message
+	if err := msg.Unpack(answer); err != nil {
+		return 0, err
+	}
+
+	if msg.Header.ID != id || !msg.Header.Response {
+		return 0, errors.New("unexpected answer from the name server")
+	}
+
+	switch msg.Header.RCode {
+	case dnsmessage.RCodeSuccess:
+	case dnsmessage.RCodeNameError:
+		return 0, errors.New("no such host")
+	default:
+		return 0, fmt.Errorf("the name server answered with %s", msg.Header.RCode)
+	}
+
+	var ttl uint32
+	var hasRecords bool
+	for i, rr := range msg.Answers {
+		if i == 0 || rr.Header.TTL < ttl {
+			ttl = rr.Header.TTL
+		}
+		if rr.Header.Type == qtype {
+			hasRecords = true
+		}
+	}
+
+	if !hasRecords {
+		return 0, errNoRecords
+	}
+
+	return time.Duration(ttl) * time.Second, nil
+}
+
+// dialNameServer connects to the name server used by the resolver.
+func dialNameServer(ctx context.Context, resolver *net.Resolver) (net.Conn, error) {
+	server := systemNameServer()
+
+	// a custom Dial decides the name server on its own
+	if resolver != nil && resolver.Dial != nil {
+		return resolver.Dial(ctx, "udp", server)
+	}
+
+	var d net.Dialer
+	return d.DialContext(ctx, "udp", server)
+}
+
+// exchange sends the query and reads the answer, prefixed
+// with their length unless conn is a packet connection.
+func exchange(conn net.Conn, query []byte) ([]byte, error) {
+	if _, ok := conn.(net.PacketConn); ok {
+		if _, err := conn.Write(query); err != nil {
+			return nil, err
+		}
+
+		answer := make([]byte, 1232)
+		n, err := conn.Read(answer)
+		if err != nil {
+			return nil, err
+		}
+
+		return answer[:n], nil
+	}
+
+	framed := make([]byte, 2+len(query))
+	binary.BigEndian.PutUint16(framed, uint16(len(query)))
+	copy(framed[2:], query)
+
+	if _, err := conn.Write(framed); err != nil {
+		return nil, err
+	}
+
+	var length uint16
+	if err := binary.Read(conn, binary.BigEndian, &length); err != nil {
+		return nil, err
+	}
+
+	answer := make([]byte, length)
+	if _, err := io.ReadFull(conn, answer); err != nil {
+		return nil, err
+	}
+
+	return answer, nil
+}
+
+// systemNameServer returns the first name server of resolv.conf,
+// or the local one like the Go resolver does without it.
+func systemNameServer() string {
+	f, err := os.Open(resolvConfPath)
+	if err != nil {
+		return "127.0.0.1:53"
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 2 || fields[0] != "nameserver" {
+			continue
+		}
+
+		// link-local IPv6 addresses might have a zone
+		if net.ParseIP(strings.SplitN(fields[1], "%", 2)[0]) != nil {
+			return net.JoinHostPort(fields[1], "53")
+		}
+	}
+
+	return "127.0.0.1:53"
+}
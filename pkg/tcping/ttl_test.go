@@ -0,0 +1,123 @@
+package tcping
+
+import (
+	"context"
+	"net"
+	"net/netip"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/net/dns/dnsmessage"
+)
+
+// serveTestDNSAnswers starts a DNS server over UDP answering
+// the questions with the given records, and returns a resolver that uses it.
+func serveTestDNSAnswers(t *testing.T, answer func(q dnsmessage.Question) []dnsmessage.Resource) *net.Resolver {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	go func() {
+		buf := make([]byte, 512)
+		for {
+			n, addr, err := conn.ReadFrom(buf)
+			if err != nil {
+				return
+			}
+
+			var msg dnsmessage.Message
+			if err := msg.Unpack(buf[:n]); err != nil {
+				continue
+			}
+
+			msg.Header.Response = true
+			for _, q := range msg.Questions {
+				msg.Answers = append(msg.Answers, answer(q)...)
+			}
+
+			packed, err := msg.Pack()
+			if err == nil {
+				conn.WriteTo(packed, addr)
+			}
+		}
+	}()
+
+	return &net.Resolver{
+		PreferGo: true,
+		Dial: func(ctx context.Context, network, _ string) (net.Conn, error) {
+			var d net.Dialer
+			return d.DialContext(ctx, network, conn.LocalAddr().String())
+		},
+	}
+}
+
+// serveTestDNS starts a DNS server answering the A queries
+// through a CNAME, and returns a resolver that uses it.
+func serveTestDNS(t *testing.T, cnameTTL, addrTTL uint32) *net.Resolver {
+	return serveTestDNSAnswers(t, func(q dnsmessage.Question) []dnsmessage.Resource {
+		if q.Type != dnsmessage.TypeA {
+			return nil
+		}
+
+		target := dnsmessage.MustNewName("backend.example.test.")
+		return []dnsmessage.Resource{
+			{
+				Header: dnsmessage.ResourceHeader{Name: q.Name, Type: dnsmessage.TypeCNAME, Class: q.Class, TTL: cnameTTL},
+				Body:   &dnsmessage.CNAMEResource{CNAME: target},
+			},
+			{
+				Header: dnsmessage.ResourceHeader{Name: target, Type: q.Type, Class: q.Class, TTL: addrTTL},
+				Body:   &dnsmessage.AResource{A: [4]byte{127, 0, 0, 1}},
+			},
+		}
+	})
+}
+
+func TestLookupTTL(t *testing.T) {
+	opts := Options{Hostname: "www.example.test", Resolver: serveTestDNS(t, 300, 30)}
+
+	ttl, err := lookupTTL(opts)
+	assert.NoError(t, err)
+	assert.Equal(t, 30*time.Second, ttl)
+
+	opts.Resolver = serveTestDNS(t, 10, 30)
+	ttl, err = lookupTTL(opts)
+	assert.NoError(t, err)
+	assert.Equal(t, 10*time.Second, ttl)
+
+	opts.UseIPv6 = true
+	_, err = lookupTTL(opts)
+	assert.ErrorIs(t, err, errNoRecords)
+}
+
+func TestHonorTTL(t *testing.T) {
+	opts := Options{
+		Printer:               &dummyPrinter{},
+		Hostname:              "www.example.test",
+		Port:                  12345,
+		IntervalBetweenProbes: 2 * time.Millisecond,
+		Timeout:               time.Millisecond,
+		ProbesBeforeQuit:      2,
+		HonorTTL:              true,
+		Resolver:              serveTestDNS(t, 300, 300),
+	}
+
+	p, err := New(opts)
+	assert.NoError(t, err)
+	assert.Equal(t, netip.MustParseAddr("127.0.0.1"), p.Statistics().IP)
+	assert.WithinDuration(t, time.Now().Add(300*time.Second), p.stats.resolveExpiry, 5*time.Second)
+
+	p.Run()
+	assert.Equal(t, uint(0), p.Statistics().RetriedHostnameLookups)
+
+	// a TTL of 0 expires right away
+	opts.Resolver = serveTestDNS(t, 0, 0)
+	p, err = New(opts)
+	assert.NoError(t, err)
+
+	p.Run()
+	assert.Equal(t, uint(2), p.Statistics().RetriedHostnameLookups)
+}
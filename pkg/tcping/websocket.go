@@ -0,0 +1,239 @@
+package tcping
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/tls"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"time"
+)
+
+// webSocketGUID is appended to the key of the upgrade
+// to compute the Sec-WebSocket-Accept of the server.
+const webSocketGUID = "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"
+
+// the opcodes of the WebSocket frames
+const (
+	wsOpClose = 0x8
+	wsOpPing  = 0x9
+	wsOpPong  = 0xa
+)
+
+func init() {
+	RegisterProber("ws", func(cfg ProberConfig) (Prober, error) {
+		return newWebSocketProber(cfg), nil
+	})
+	RegisterProber("wss", func(cfg ProberConfig) (Prober, error) {
+		config := cfg.TLSConfig.Clone()
+		if config == nil {
+			config = &tls.Config{}
+		}
+		if config.ServerName == "" {
+			config.ServerName = cfg.Hostname
+		}
+
+		return &tlsProber{config: config, prober: newWebSocketProber(cfg)}, nil
+	})
+}
+
+// webSocketProber completes a WebSocket upgrade over the connection of
+// the probe and optionally checks that the server answers a ping.
+type webSocketProber struct {
+	hostname string
+	path     string
+	header   http.Header
+	ping     bool
+}
+
+func newWebSocketProber(cfg ProberConfig) *webSocketProber {
+	p := &webSocketProber{
+		hostname: cfg.Hostname,
+		path:     cfg.HTTP.Path,
+		header:   cfg.HTTP.Header,
+		ping:     cfg.WebSocketPing,
+	}
+	if p.path == "" {
+		p.path = "/"
+	}
+
+	return p
+}
+
+func (p *webSocketProber) Probe(conn net.Conn, timeout time.Duration) error {
+	if timeout > 0 {
+		conn.SetDeadline(time.Now().Add(timeout))
+	}
+
+	req, err := newHTTPRequest(http.MethodGet, p.hostname, p.path, p.header, conn)
+	if err != nil {
+		return err
+	}
+
+	key := make([]byte, 16)
+	rand.Read(key)
+	req.Header.Set("Upgrade", "websocket")
+	req.Header.Set("Connection", "Upgrade")
+	req.Header.Set("Sec-WebSocket-Key", base64.StdEncoding.EncodeToString(key))
+	req.Header.Set("Sec-WebSocket-Version", "13")
+
+	if err := req.Write(conn); err != nil {
+		return fmt.Errorf("unable to send the upgrade: %w", err)
+	}
+
+	r := bufio.NewReader(conn)
+	resp, err := http.ReadResponse(r, req)
+	if err != nil {
+		return fmt.Errorf("unable to read the response to the upgrade: %w", err)
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusSwitchingProtocols {
+		return fmt.Errorf("the upgrade was refused with status %q", resp.Status)
+	}
+
+	accept := sha1.Sum([]byte(req.Header.Get("Sec-WebSocket-Key") + webSocketGUID))
+	if resp.Header.Get("Sec-WebSocket-Accept") != base64.StdEncoding.EncodeToString(accept[:]) {
+		return errors.New("the upgrade was answered with an invalid Sec-WebSocket-Accept")
+	}
+
+	if p.ping {
+		if err := pingWebSocket(conn, r); err != nil {
+			return err
+		}
+	}
+
+	// 1000 is the status of a normal closure
+	writeWebSocketFrame(conn, wsOpClose, []byte{0x03, 0xe8})
+
+	return nil
+}
+
+// pingWebSocket sends a ping and waits for its pong,
+// skipping the messages the server sends meanwhile.
+func pingWebSocket(conn net.Conn, r *bufio.Reader) error {
+	payload := []byte("tcping")
+	if err := writeWebSocketFrame(conn, wsOpPing, payload); err != nil {
+		return fmt.Errorf("unable to send the ping: %w", err)
+	}
+
+	for {
+		opcode, data, err := readWebSocketFrame(r)
+		if err != nil {
+			return fmt.Errorf("no pong received: %w", err)
+		}
+
+		switch {
+		case opcode == wsOpClose:
+			return errors.New("the server closed the WebSocket instead of answering the ping")
+		case opcode == wsOpPong && bytes.Equal(data, payload):
+			return nil
+		}
+	}
+}
+
+// writeWebSocketFrame writes a final frame with a payload shorter
+// than 126 bytes, masked as required from clients.
+func writeWebSocketFrame(w io.Writer, opcode byte, payload []byte) error {
+	frame := []byte{0x80 | opcode, 0x80 | byte(len(payload)), 0, 0, 0, 0}
+	mask := frame[2:6]
+	rand.Read(mask)
+
+	for i, b := range payload {
+		frame = append(frame, b^mask[i%4])
+	}
+
+	_, err := w.Write(frame)
+	return err
+}
+
+// readWebSocketFrame reads a frame from the server. The payloads
+// longer than maxResponseSize are discarded.
+func readWebSocketFrame(r *bufio.Reader) (byte, []byte, error) {
+	var header [2]byte
+	if _, err := io.ReadFull(r, header[:]); err != nil {
+		return 0, nil, err
+	}
+
+	opcode := header[0] & 0x0f
+	length := uint64(header[1] & 0x7f)
+	switch length {
+	case 126:
+		var ext [2]byte
+		if _, err := io.ReadFull(r, ext[:]); err != nil {
+			return 0, nil, err
+		}
+		length = uint64(binary.BigEndian.Uint16(ext[:]))
+	case 127:
+		var ext [8]byte
+		if _, err := io.ReadFull(r, ext[:]); err != nil {
+			return 0, nil, err
+		}
+		length = binary.BigEndian.Uint64(ext[:])
+	}
+
+	var mask [4]byte
+	masked := header[1]&0x80 != 0
+	if masked {
+		if _, err := io.ReadFull(r, mask[:]); err != nil {
+			return 0, nil, err
+		}
+	}
+
+	if length > maxResponseSize {
+		_, err := io.CopyN(io.Discard, r, int64(length))
+		return opcode, nil, err
+	}
+
+	payload := make([]byte, length)
+	if _, err := io.ReadFull(r, payload); err != nil {
+		return 0, nil, err
+	}
+	if masked {
+		for i := range payload {
+			payload[i] ^= mask[i%4]
+		}
+	}
+
+	return opcode, payload, nil
+}
+
+// tlsProber performs a TLS handshake before running the prober,
+// for the protocols whose name implies TLS, e.g. wss.
+type tlsProber struct {
+	config *tls.Config
+	prober Prober
+}
+
+func (p *tlsProber) Probe(conn net.Conn, timeout time.Duration) error {
+	ctx := context.Background()
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	tlsConn := tls.Client(conn, p.config)
+	if err := tlsConn.HandshakeContext(ctx); err != nil {
+		return fmt.Errorf("TLS handshake failed: %w", err)
+	}
+
+	// the prober gets what remains of the timeout
+	if deadline, ok := ctx.Deadline(); ok {
+		timeout = time.Until(deadline)
+		if timeout <= 0 {
+			return os.ErrDeadlineExceeded
+		}
+	}
+
+	return p.prober.Probe(tlsConn, timeout)
+}
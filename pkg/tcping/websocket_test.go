@@ -0,0 +1,110 @@
+package tcping
+
+import (
+	"crypto/sha1"
+	"encoding/base64"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// webSocketHandler upgrades the requests to /ws and answers the first ping.
+func webSocketHandler(t *testing.T) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/ws" || r.Header.Get("Upgrade") != "websocket" {
+			http.NotFound(w, r)
+			return
+		}
+
+		accept := sha1.Sum([]byte(r.Header.Get("Sec-WebSocket-Key") + webSocketGUID))
+		w.Header().Set("Upgrade", "websocket")
+		w.Header().Set("Connection", "Upgrade")
+		w.Header().Set("Sec-WebSocket-Accept", base64.StdEncoding.EncodeToString(accept[:]))
+		w.WriteHeader(http.StatusSwitchingProtocols)
+
+		conn, rw, err := http.NewResponseController(w).Hijack()
+		if err != nil {
+			t.Errorf("hijack: %v", err)
+			return
+		}
+		defer conn.Close()
+
+		opcode, payload, err := readWebSocketFrame(rw.Reader)
+		if err != nil || opcode != wsOpPing {
+			return
+		}
+
+		// a message before the pong
+		conn.Write([]byte{0x81, 2, 'h', 'i'})
+		conn.Write(append([]byte{0x80 | wsOpPong, byte(len(payload))}, payload...))
+	})
+}
+
+func TestWebSocketProber(t *testing.T) {
+	srv := httptest.NewServer(webSocketHandler(t))
+	defer srv.Close()
+
+	tlsSrv := httptest.NewTLSServer(webSocketHandler(t))
+	defer tlsSrv.Close()
+
+	tests := []struct {
+		name    string
+		prober  string
+		srv     *httptest.Server
+		cfg     ProberConfig
+		wantErr string
+	}{
+		{
+			name:   "upgrade",
+			prober: "ws",
+			srv:    srv,
+			cfg:    ProberConfig{HTTP: HTTPConfig{Path: "/ws"}},
+		},
+		{
+			name:   "ping",
+			prober: "ws",
+			srv:    srv,
+			cfg:    ProberConfig{HTTP: HTTPConfig{Path: "/ws"}, WebSocketPing: true},
+		},
+		{
+			name:    "refused",
+			prober:  "ws",
+			srv:     srv,
+			wantErr: `the upgrade was refused with status "404 Not Found"`,
+		},
+		{
+			name:   "wss",
+			prober: "wss",
+			srv:    tlsSrv,
+			cfg: ProberConfig{
+				HTTP:          HTTPConfig{Path: "/ws"},
+				WebSocketPing: true,
+				TLSConfig:     tlsSrv.Client().Transport.(*http.Transport).TLSClientConfig,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conn, err := net.Dial("tcp", tt.srv.Listener.Addr().String())
+			if err != nil {
+				t.Fatalf("dial: %v", err)
+			}
+			defer conn.Close()
+
+			tt.cfg.Hostname = "127.0.0.1"
+			prober, err := NewProber(tt.prober, tt.cfg)
+			assert.NoError(t, err)
+
+			err = prober.Probe(conn, time.Second)
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
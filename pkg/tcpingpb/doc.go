@@ -0,0 +1,5 @@
+// Package tcpingpb contains the gRPC service and the messages
+// served by tcping with the --grpc flag.
+package tcpingpb
+
+//go:generate protoc --go_out=. --go_opt=paths=source_relative --go-grpc_out=. --go-grpc_opt=paths=source_relative tcping.proto
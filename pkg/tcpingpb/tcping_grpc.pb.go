@@ -0,0 +1,180 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.3.0
+// - protoc             (unknown)
+// source: tcping.proto
+
+package tcpingpb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.32.0 or later.
+const _ = grpc.SupportPackageIsVersion7
+
+const (
+	TCPing_StreamEvents_FullMethodName  = "/tcping.v1.TCPing/StreamEvents"
+	TCPing_GetStatistics_FullMethodName = "/tcping.v1.TCPing/GetStatistics"
+)
+
+// TCPingClient is the client API for TCPing service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type TCPingClient interface {
+	// StreamEvents streams the probe results and the state
+	// transitions of the target as they happen.
+	StreamEvents(ctx context.Context, in *StreamEventsRequest, opts ...grpc.CallOption) (TCPing_StreamEventsClient, error)
+	// GetStatistics returns the aggregated statistics so far.
+	GetStatistics(ctx context.Context, in *GetStatisticsRequest, opts ...grpc.CallOption) (*Statistics, error)
+}
+
+type tCPingClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewTCPingClient(cc grpc.ClientConnInterface) TCPingClient {
+	return &tCPingClient{cc}
+}
+
+func (c *tCPingClient) StreamEvents(ctx context.Context, in *StreamEventsRequest, opts ...grpc.CallOption) (TCPing_StreamEventsClient, error) {
+	stream, err := c.cc.NewStream(ctx, &TCPing_ServiceDesc.Streams[0], TCPing_StreamEvents_FullMethodName, opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &tCPingStreamEventsClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type TCPing_StreamEventsClient interface {
+	Recv() (*Event, error)
+	grpc.ClientStream
+}
+
+type tCPingStreamEventsClient struct {
+	grpc.ClientStream
+}
+
+func (x *tCPingStreamEventsClient) Recv() (*Event, error) {
+	m := new(Event)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *tCPingClient) GetStatistics(ctx context.Context, in *GetStatisticsRequest, opts ...grpc.CallOption) (*Statistics, error) {
+	out := new(Statistics)
+	err := c.cc.Invoke(ctx, TCPing_GetStatistics_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// TCPingServer is the server API for TCPing service.
+// All implementations must embed UnimplementedTCPingServer
+// for forward compatibility
+type TCPingServer interface {
+	// StreamEvents streams the probe results and the state
+	// transitions of the target as they happen.
+	StreamEvents(*StreamEventsRequest, TCPing_StreamEventsServer) error
+	// GetStatistics returns the aggregated statistics so far.
+	GetStatistics(context.Context, *GetStatisticsRequest) (*Statistics, error)
+	mustEmbedUnimplementedTCPingServer()
+}
+
+// UnimplementedTCPingServer must be embedded to have forward compatible implementations.
+type UnimplementedTCPingServer struct {
+}
+
+func (UnimplementedTCPingServer) StreamEvents(*StreamEventsRequest, TCPing_StreamEventsServer) error {
+	return status.Errorf(codes.Unimplemented, "method StreamEvents not implemented")
+}
+func (UnimplementedTCPingServer) GetStatistics(context.Context, *GetStatisticsRequest) (*Statistics, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetStatistics not implemented")
+}
+func (UnimplementedTCPingServer) mustEmbedUnimplementedTCPingServer() {}
+
+// UnsafeTCPingServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to TCPingServer will
+// result in compilation errors.
+type UnsafeTCPingServer interface {
+	mustEmbedUnimplementedTCPingServer()
+}
+
+func RegisterTCPingServer(s grpc.ServiceRegistrar, srv TCPingServer) {
+	s.RegisterService(&TCPing_ServiceDesc, srv)
+}
+
+func _TCPing_StreamEvents_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(StreamEventsRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(TCPingServer).StreamEvents(m, &tCPingStreamEventsServer{stream})
+}
+
+type TCPing_StreamEventsServer interface {
+	Send(*Event) error
+	grpc.ServerStream
+}
+
+type tCPingStreamEventsServer struct {
+	grpc.ServerStream
+}
+
+func (x *tCPingStreamEventsServer) Send(m *Event) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _TCPing_GetStatistics_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetStatisticsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TCPingServer).GetStatistics(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TCPing_GetStatistics_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TCPingServer).GetStatistics(ctx, req.(*GetStatisticsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// TCPing_ServiceDesc is the grpc.ServiceDesc for TCPing service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var TCPing_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "tcping.v1.TCPing",
+	HandlerType: (*TCPingServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "GetStatistics",
+			Handler:    _TCPing_GetStatistics_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "StreamEvents",
+			Handler:       _TCPing_StreamEvents_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "tcping.proto",
+}
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"fmt"
+	"net"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// parsePorts parses the comma-separated ports of -p, e.g. 80,443,8443.
+func parsePorts(list string) ([]uint16, error) {
+	var ports []uint16
+	for _, p := range strings.Split(list, ",") {
+		port, err := strconv.ParseUint(strings.TrimSpace(p), 10, 16)
+		if err != nil || port == 0 {
+			return nil, fmt.Errorf("invalid port %q", p)
+		}
+		if slices.Contains(ports, uint16(port)) {
+			return nil, fmt.Errorf("port %d is given twice", port)
+		}
+		ports = append(ports, uint16(port))
+	}
+
+	return ports, nil
+}
+
+// checkPorts parses the ports of -p, probed instead of the port of the
+// target. The target must be given without a port then.
+func checkPorts(list *string, args []string) []uint16 {
+	if *list == "" {
+		return nil
+	}
+
+	if len(args) != 1 || isURLTarget(args[0]) {
+		colorRed("-p takes the hostname or the IP of the target alone.\n")
+		usage()
+	}
+	if _, _, err := net.SplitHostPort(args[0]); err == nil {
+		colorRed("The port of the target cannot be given with -p.\n")
+		usage()
+	}
+
+	ports, err := parsePorts(*list)
+	if err != nil {
+		colorRed("Invalid -p: %s\n", err)
+		usage()
+	}
+
+	return ports
+}
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"net"
+	"net/url"
+	"os"
+	"strconv"
+
+	"github.com/pouriyajamshidi/tcping/v2/pkg/tcping"
+	"golang.org/x/net/http/httpproxy"
+)
+
+// setProxy connects the probes of the HTTP and WebSocket probers and of
+// the TLS handshakes through the proxy of the environment, like curl does,
+// unless --no-env-proxy is given. The plain TCP probes are never proxied.
+// The credentials of the proxy come from its URL or from the configuration
+// file, never from the command line.
+func setProxy(opts *tcping.Options, noEnvProxy *bool, probe *string, speaksTLS bool, configPath *string) {
+	if *noEnvProxy {
+		return
+	}
+
+	speaksHTTP := *probe == "http" || *probe == "ws" || *probe == "wss"
+	if !speaksHTTP && !speaksTLS {
+		return
+	}
+
+	// the connections inspected on the wire or by the kernel go straight
+	// to the target, and the jump host has a network of its own
+	if opts.Jump != nil || opts.Persistent || opts.TFO || opts.MPTCP || opts.TCPInfo || opts.Handshake || opts.EBPF || opts.Pcap != nil {
+		return
+	}
+
+	hostname := opts.Hostname
+	if opts.SRV != "" {
+		hostname = opts.SRV
+	}
+
+	proxyURL, err := envProxy(hostname, opts.Port, speaksTLS)
+	if err != nil {
+		opts.Printer.PrintError("Invalid proxy in the environment: %s", err)
+		os.Exit(1)
+	}
+
+	if proxyURL != nil && proxyURL.User == nil && *configPath != "" {
+		cfg, err := loadConfig(*configPath)
+		if err != nil {
+			opts.Printer.PrintError("Invalid configuration file: %s", err)
+			os.Exit(1)
+		}
+
+		if cfg.Proxy != nil {
+			withUser := *proxyURL
+			withUser.User = url.UserPassword(cfg.Proxy.Username, cfg.Proxy.Password)
+			proxyURL = &withUser
+		}
+	}
+
+	opts.Proxy = proxyURL
+}
+
+// envProxy returns the proxy of the target from HTTPS_PROXY for TLS and
+// from HTTP_PROXY otherwise, falling back to ALL_PROXY, or nil if it's in
+// NO_PROXY or if it's a loopback address. The lowercase variables are
+// also honored.
+func envProxy(hostname string, port uint16, https bool) (*url.URL, error) {
+	cfg := httpproxy.FromEnvironment()
+	allProxy := os.Getenv("ALL_PROXY")
+	if allProxy == "" {
+		allProxy = os.Getenv("all_proxy")
+	}
+	if cfg.HTTPProxy == "" {
+		cfg.HTTPProxy = allProxy
+	}
+	if cfg.HTTPSProxy == "" {
+		cfg.HTTPSProxy = allProxy
+	}
+
+	scheme := "http"
+	if https {
+		scheme = "https"
+	}
+
+	return cfg.ProxyFunc()(&url.URL{
+		Scheme: scheme,
+		Host:   net.JoinHostPort(hostname, strconv.Itoa(int(port))),
+	})
+}
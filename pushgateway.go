@@ -0,0 +1,105 @@
+package main
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/pouriyajamshidi/tcping/v2/pkg/tcping"
+)
+
+// pushgatewayNotifier pushes the final statistics to
+// a Prometheus Pushgateway, e.g. for short runs from cron.
+type pushgatewayNotifier struct {
+	client *http.Client
+	url    string
+	job    string
+}
+
+func newPushgatewayNotifier(gatewayURL, job string) *pushgatewayNotifier {
+	return &pushgatewayNotifier{
+		client: &http.Client{Timeout: webhookTimeout},
+		url:    strings.TrimSuffix(gatewayURL, "/"),
+		job:    job,
+	}
+}
+
+// Notify does nothing, only the final statistics are pushed.
+func (n *pushgatewayNotifier) Notify(_ tcping.StateChange) {}
+
+func (n *pushgatewayNotifier) NotifyStatistics(s tcping.Statistics) {
+	instance := net.JoinHostPort(s.Hostname, strconv.Itoa(int(s.Port)))
+
+	// PUT replaces all the metrics of the previous run of the same instance
+	pushURL := fmt.Sprintf("%s/metrics/job/%s/instance/%s",
+		n.url, url.PathEscape(n.job), url.PathEscape(instance))
+	body := []byte(newPushgatewayMetrics(s))
+	headers := map[string]string{"Content-Type": "text/plain; version=0.0.4"}
+
+	err := withRetries(func() error {
+		return request(n.client, http.MethodPut, pushURL, body, headers)
+	})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to push the metrics to the Pushgateway: %s\n", err)
+	}
+}
+
+// newPushgatewayMetrics formats the statistics in the
+// Prometheus text exposition format.
+func newPushgatewayMetrics(s tcping.Statistics) string {
+	var b strings.Builder
+
+	metric := func(name, help string, value float64) {
+		fmt.Fprintf(&b, "# HELP %s %s\n", name, help)
+		fmt.Fprintf(&b, "# TYPE %s gauge\n", name)
+		fmt.Fprintf(&b, "%s %s\n", name, strconv.FormatFloat(value, 'g', -1, 64))
+	}
+
+	fmt.Fprintf(&b, "# HELP tcping_probes_total Number of probes sent.\n")
+	fmt.Fprintf(&b, "# TYPE tcping_probes_total gauge\n")
+	fmt.Fprintf(&b, "tcping_probes_total{result=\"success\"} %d\n", s.TotalSuccessfulProbes)
+	fmt.Fprintf(&b, "tcping_probes_total{result=\"failure\"} %d\n", s.TotalUnsuccessfulProbes)
+
+	totalPackets := s.TotalSuccessfulProbes + s.TotalUnsuccessfulProbes
+	if totalPackets > 0 {
+		metric("tcping_packet_loss_ratio", "Ratio of the failed probes.",
+			float64(s.TotalUnsuccessfulProbes)/float64(totalPackets))
+	}
+
+	if s.RttResults.HasResults {
+		metric("tcping_rtt_min_seconds", "Minimum RTT of the successful probes.", float64(s.RttResults.Min)/1000)
+		metric("tcping_rtt_avg_seconds", "Average RTT of the successful probes.", float64(s.RttResults.Average)/1000)
+		metric("tcping_rtt_max_seconds", "Maximum RTT of the successful probes.", float64(s.RttResults.Max)/1000)
+	}
+
+	if !s.IsIP && s.ResolveTimeResults.HasResults {
+		metric("tcping_resolve_min_seconds", "Minimum time spent resolving the hostname.", float64(s.ResolveTimeResults.Min)/1000)
+		metric("tcping_resolve_avg_seconds", "Average time spent resolving the hostname.", float64(s.ResolveTimeResults.Average)/1000)
+		metric("tcping_resolve_max_seconds", "Maximum time spent resolving the hostname.", float64(s.ResolveTimeResults.Max)/1000)
+	}
+
+	metric("tcping_uptime_seconds", "Total time the target was up.", s.TotalUptime.Seconds())
+	metric("tcping_downtime_seconds", "Total time the target was down.", s.TotalDowntime.Seconds())
+	metric("tcping_longest_uptime_seconds", "Longest consecutive uptime.", s.LongestUptime.Duration.Seconds())
+	metric("tcping_longest_downtime_seconds", "Longest consecutive downtime.", s.LongestDowntime.Duration.Seconds())
+	metric("tcping_hostname_lookup_retries", "Number of retried hostname lookups.", float64(s.RetriedHostnameLookups))
+	metric("tcping_start_time_seconds", "Unix time when the probing started.", float64(s.StartTime.Unix()))
+
+	if !s.EndTime.IsZero() {
+		metric("tcping_end_time_seconds", "Unix time when the probing ended.", float64(s.EndTime.Unix()))
+	}
+
+	if !s.CertExpiry.IsZero() {
+		metric("tcping_cert_expiry_time_seconds", "Unix time when the certificate chain expires.", float64(s.CertExpiry.Unix()))
+	}
+
+	if !s.LastSuccessfulProbe.IsZero() {
+		metric("tcping_last_success_time_seconds", "Unix time of the last successful probe.", float64(s.LastSuccessfulProbe.Unix()))
+	}
+
+	return b.String()
+}
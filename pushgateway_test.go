@@ -0,0 +1,44 @@
+package main
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"testing"
+	"time"
+
+	"github.com/pouriyajamshidi/tcping/v2/pkg/tcping"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPushgatewayNotifier(t *testing.T) {
+	var path, contentType, body string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPut, r.Method)
+		path = r.URL.EscapedPath()
+		contentType = r.Header.Get("Content-Type")
+		b, _ := io.ReadAll(r.Body)
+		body = string(b)
+	}))
+	t.Cleanup(srv.Close)
+
+	newPushgatewayNotifier(srv.URL+"/", "cron probes").NotifyStatistics(tcping.Statistics{
+		Hostname:                "example.com",
+		IP:                      netip.MustParseAddr("93.184.216.34"),
+		Port:                    443,
+		StartTime:               time.Unix(1700000000, 0),
+		TotalSuccessfulProbes:   3,
+		TotalUnsuccessfulProbes: 1,
+		RttResults:              tcping.RttResult{Min: 10, Average: 12.5, Max: 15, HasResults: true},
+	})
+
+	assert.Equal(t, "/metrics/job/cron%20probes/instance/example.com:443", path)
+	assert.Equal(t, "text/plain; version=0.0.4", contentType)
+	assert.Contains(t, body, "tcping_probes_total{result=\"success\"} 3\n")
+	assert.Contains(t, body, "tcping_probes_total{result=\"failure\"} 1\n")
+	assert.Contains(t, body, "tcping_packet_loss_ratio 0.25\n")
+	assert.Contains(t, body, "tcping_rtt_avg_seconds 0.0125\n")
+	assert.Contains(t, body, "tcping_start_time_seconds 1.7e+09\n")
+	assert.NotContains(t, body, "tcping_end_time_seconds")
+}
@@ -0,0 +1,134 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/pouriyajamshidi/tcping/v2/pkg/tcping"
+)
+
+// gzipMagic starts the files written with --compress.
+var gzipMagic = []byte{0x1f, 0x8b}
+
+// samplesFile is the file of --samples-file, gzipped with --compress
+// and synced to the disk every --fsync.
+//
+// A gzipped file only gets the samples when it's synced and when it's
+// closed, as flushing the compressor after every sample would undo most
+// of the compression of long runs.
+type samplesFile struct {
+	mu       sync.Mutex
+	f        *os.File
+	gz       *gzip.Writer // gz is nil without --compress.
+	w        *tcping.SampleWriter
+	fsync    time.Duration
+	lastSync time.Time
+	once     sync.Once
+}
+
+// newSamplesFile creates the samples file at path and writes the header.
+func newSamplesFile(path string, compress bool, fsync time.Duration) (*samplesFile, error) {
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, err
+	}
+
+	sf := &samplesFile{f: f, fsync: fsync, lastSync: time.Now()}
+	var w io.Writer = f
+	if compress {
+		sf.gz = gzip.NewWriter(f)
+		w = sf.gz
+	}
+
+	if sf.w, err = tcping.NewSampleWriter(w); err != nil {
+		f.Close()
+		return nil, err
+	}
+
+	return sf, nil
+}
+
+// record writes the probe to the file, syncing it if it's due.
+// The first error is printed, the next ones are dropped.
+// It is meant to be used as the OnProbe hook.
+func (sf *samplesFile) record(r tcping.Result) {
+	sf.mu.Lock()
+	defer sf.mu.Unlock()
+
+	err := sf.w.Write(tcping.SampleFromResult(r))
+	if err == nil && sf.fsync > 0 && time.Since(sf.lastSync) >= sf.fsync {
+		err = sf.sync()
+	}
+	if err != nil {
+		sf.once.Do(func() {
+			fmt.Fprintf(os.Stderr, "Failed to write the samples file: %s\n", err)
+		})
+	}
+}
+
+// sync writes the compressed samples out, if any, and syncs the file.
+func (sf *samplesFile) sync() error {
+	sf.lastSync = time.Now()
+	if sf.gz != nil {
+		if err := sf.gz.Flush(); err != nil {
+			return err
+		}
+	}
+
+	return sf.f.Sync()
+}
+
+// close completes the gzip stream, if any, and closes the file.
+func (sf *samplesFile) close() error {
+	sf.mu.Lock()
+	defer sf.mu.Unlock()
+
+	if sf.gz != nil {
+		if err := sf.gz.Close(); err != nil {
+			sf.f.Close()
+			return err
+		}
+	}
+	if sf.fsync > 0 {
+		if err := sf.f.Sync(); err != nil {
+			sf.f.Close()
+			return err
+		}
+	}
+
+	return sf.f.Close()
+}
+
+// openSamples opens a samples file for reading,
+// decompressing it if it was written with --compress.
+func openSamples(path string) (io.ReadCloser, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+
+	br := bufio.NewReader(f)
+	if magic, _ := br.Peek(len(gzipMagic)); !bytes.Equal(magic, gzipMagic) {
+		return struct {
+			io.Reader
+			io.Closer
+		}{br, f}, nil
+	}
+
+	gz, err := gzip.NewReader(br)
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+
+	return struct {
+		io.Reader
+		io.Closer
+	}{gz, f}, nil
+}
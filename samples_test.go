@@ -0,0 +1,42 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/pouriyajamshidi/tcping/v2/pkg/tcping"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSamplesFile(t *testing.T) {
+	for _, compress := range []bool{false, true} {
+		path := filepath.Join(t.TempDir(), "samples.csv")
+		sf, err := newSamplesFile(path, compress, time.Nanosecond)
+		if err != nil {
+			t.Fatalf("create: %v", err)
+		}
+
+		now := time.Now()
+		sf.record(tcping.Result{Time: now, Success: true, RTT: 12.5, Port: 443})
+		sf.record(tcping.Result{Time: now.Add(time.Second), FailureReason: tcping.FailureTimeout, Port: 443})
+		assert.NoError(t, sf.close())
+
+		raw, err := os.ReadFile(path)
+		assert.NoError(t, err)
+		assert.Equal(t, compress, len(raw) > 2 && raw[0] == gzipMagic[0] && raw[1] == gzipMagic[1])
+
+		f, err := openSamples(path)
+		if err != nil {
+			t.Fatalf("open: %v", err)
+		}
+		samples, err := tcping.ReadSamples(f)
+		f.Close()
+		assert.NoError(t, err)
+		if assert.Len(t, samples, 2) {
+			assert.Equal(t, float32(12.5), samples[0].RTT)
+			assert.Equal(t, tcping.FailureTimeout, samples[1].FailureReason)
+		}
+	}
+}
@@ -0,0 +1,11 @@
+//go:build !windows
+
+package main
+
+import (
+	"os"
+	"syscall"
+)
+
+// statsSignals make tcping print the statistics without exiting, like ping.
+var statsSignals = []os.Signal{syscall.SIGQUIT, syscall.SIGUSR1}
@@ -0,0 +1,56 @@
+//go:build !windows
+
+package main
+
+import (
+	"context"
+	"net"
+	"os/signal"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/pouriyajamshidi/tcping/v2/pkg/tcping"
+	"github.com/stretchr/testify/assert"
+)
+
+// statsPrinter signals the statistics it prints.
+type statsPrinter struct {
+	discardPrinter
+	printed chan tcping.Statistics
+}
+
+func (sp *statsPrinter) PrintStatistics(s tcping.Statistics) {
+	sp.printed <- s
+}
+
+func TestMonitorSignals(t *testing.T) {
+	srv, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	t.Cleanup(func() { srv.Close() })
+
+	printer := &statsPrinter{printed: make(chan tcping.Statistics, 1)}
+	pinger, err := tcping.New(tcping.Options{
+		Printer:               printer,
+		Hostname:              "127.0.0.1",
+		Port:                  uint16(srv.Addr().(*net.TCPAddr).Port),
+		IntervalBetweenProbes: time.Hour,
+	})
+	assert.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	go pinger.RunContext(ctx)
+
+	monitorSignals([]*tcping.Pinger{pinger})
+	t.Cleanup(func() { signal.Reset(statsSignals...) })
+	syscall.Kill(syscall.Getpid(), syscall.SIGUSR1)
+
+	select {
+	case <-printer.printed:
+	case <-time.After(time.Second):
+		t.Fatal("the statistics weren't printed")
+	}
+}
@@ -0,0 +1,7 @@
+package main
+
+import "os"
+
+// statsSignals make tcping print the statistics without exiting,
+// Windows doesn't have any signal for it.
+var statsSignals []os.Signal
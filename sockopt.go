@@ -0,0 +1,32 @@
+package main
+
+import (
+	"math"
+	"time"
+
+	"github.com/pouriyajamshidi/tcping/v2/pkg/tcping"
+)
+
+// setSocketOptions sets the options of the sockets of the probes,
+// to mimic the ones of the application being debugged.
+func setSocketOptions(opts *tcping.Options, noDelay *bool, linger *int, keepAlive *time.Duration, mark *uint) {
+	opts.Nagle = !*noDelay
+
+	switch {
+	case *linger < -1:
+		colorRed("--so-linger must be -1 to keep the default, 0 to reset the connections or a number of seconds.")
+		usage()
+	case *linger == 0:
+		opts.Linger = -1
+	case *linger > 0:
+		opts.Linger = time.Duration(*linger) * time.Second
+	}
+
+	opts.KeepAlive = *keepAlive
+
+	if *mark > math.MaxUint32 {
+		colorRed("--fwmark must fit in 32 bits.")
+		usage()
+	}
+	opts.Mark = uint32(*mark)
+}
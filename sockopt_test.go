@@ -0,0 +1,35 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/pouriyajamshidi/tcping/v2/pkg/tcping"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSetSocketOptions(t *testing.T) {
+	tests := []struct {
+		noDelay    bool
+		linger     int
+		wantNagle  bool
+		wantLinger time.Duration
+	}{
+		{noDelay: true, linger: -1},
+		{noDelay: false, linger: -1, wantNagle: true},
+		{noDelay: true, linger: 0, wantLinger: -1},
+		{noDelay: true, linger: 5, wantLinger: 5 * time.Second},
+	}
+
+	for _, tt := range tests {
+		var opts tcping.Options
+		keepAlive := 30 * time.Second
+		mark := uint(0x10)
+		setSocketOptions(&opts, &tt.noDelay, &tt.linger, &keepAlive, &mark)
+
+		assert.Equal(t, tt.wantNagle, opts.Nagle)
+		assert.Equal(t, tt.wantLinger, opts.Linger)
+		assert.Equal(t, keepAlive, opts.KeepAlive)
+		assert.Equal(t, uint32(0x10), opts.Mark)
+	}
+}
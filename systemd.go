@@ -0,0 +1,86 @@
+package main
+
+import (
+	"net"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/pouriyajamshidi/tcping/v2/pkg/tcping"
+)
+
+// underSystemd reports whether tcping's output goes to the journal.
+func underSystemd() bool {
+	return os.Getenv("JOURNAL_STREAM") != ""
+}
+
+// sdNotify sends the state to the service manager, see sd_notify(3).
+// It does nothing when tcping is not run by systemd with Type=notify.
+func sdNotify(state string) error {
+	socketAddr := os.Getenv("NOTIFY_SOCKET")
+	if socketAddr == "" {
+		return nil
+	}
+
+	// abstract sockets start with a null byte instead of "@"
+	if socketAddr[0] == '@' {
+		socketAddr = "\x00" + socketAddr[1:]
+	}
+
+	conn, err := net.DialUnix("unixgram", nil, &net.UnixAddr{Name: socketAddr, Net: "unixgram"})
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	_, err = conn.Write([]byte(state))
+	return err
+}
+
+// watchdogInterval returns how often the watchdog should be pinged,
+// or 0 if the watchdog is not enabled for this process.
+//
+// It's half of WatchdogSec, as recommended by sd_watchdog_enabled(3).
+func watchdogInterval() time.Duration {
+	usec, err := strconv.ParseInt(os.Getenv("WATCHDOG_USEC"), 10, 64)
+	if err != nil || usec <= 0 {
+		return 0
+	}
+
+	if pid := os.Getenv("WATCHDOG_PID"); pid != "" && pid != strconv.Itoa(os.Getpid()) {
+		return 0
+	}
+
+	return time.Duration(usec) * time.Microsecond / 2
+}
+
+// watchdog pings the systemd watchdog as long as the probes keep coming,
+// so that a stuck probing loop gets the service restarted.
+type watchdog struct {
+	lastPing time.Time
+	interval time.Duration
+	mu       sync.Mutex
+}
+
+// ping pings the watchdog, unless it was pinged less than an interval ago.
+// It is meant to be used as the OnProbe hook.
+func (w *watchdog) ping(_ tcping.Result) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if time.Since(w.lastPing) < w.interval {
+		return
+	}
+	w.lastPing = time.Now()
+
+	sdNotify("WATCHDOG=1")
+}
+
+// setSystemd pings the watchdog on every probe, if it's enabled.
+func setSystemd(opts *tcping.Options) {
+	if interval := watchdogInterval(); interval > 0 {
+		w := &watchdog{interval: interval}
+		addHooks(opts, tcping.Hooks{OnProbe: w.ping})
+	}
+}
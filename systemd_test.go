@@ -0,0 +1,74 @@
+package main
+
+import (
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/pouriyajamshidi/tcping/v2/pkg/tcping"
+	"github.com/stretchr/testify/assert"
+)
+
+// listenNotifySocket creates a socket to receive the
+// sd_notify messages and sets NOTIFY_SOCKET to it.
+func listenNotifySocket(t *testing.T) *net.UnixConn {
+	path := filepath.Join(t.TempDir(), "notify.sock")
+	conn, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Name: path, Net: "unixgram"})
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	t.Setenv("NOTIFY_SOCKET", path)
+
+	return conn
+}
+
+// readNotification reads a single sd_notify message.
+func readNotification(t *testing.T, conn *net.UnixConn) string {
+	buf := make([]byte, 64)
+	conn.SetReadDeadline(time.Now().Add(time.Second))
+	n, err := conn.Read(buf)
+	if err != nil {
+		t.Fatalf("read: %v", err)
+	}
+
+	return string(buf[:n])
+}
+
+func TestSdNotify(t *testing.T) {
+	t.Setenv("NOTIFY_SOCKET", "")
+	assert.NoError(t, sdNotify("READY=1"), "sdNotify should do nothing outside of systemd")
+
+	conn := listenNotifySocket(t)
+	assert.NoError(t, sdNotify("READY=1"))
+	assert.Equal(t, "READY=1", readNotification(t, conn))
+}
+
+func TestWatchdogInterval(t *testing.T) {
+	t.Setenv("WATCHDOG_USEC", "")
+	assert.Equal(t, time.Duration(0), watchdogInterval())
+
+	t.Setenv("WATCHDOG_USEC", "10000000")
+	t.Setenv("WATCHDOG_PID", strconv.Itoa(os.Getpid()))
+	assert.Equal(t, 5*time.Second, watchdogInterval())
+
+	t.Setenv("WATCHDOG_PID", "1")
+	assert.Equal(t, time.Duration(0), watchdogInterval(), "the watchdog is meant for another process")
+}
+
+func TestWatchdogPing(t *testing.T) {
+	conn := listenNotifySocket(t)
+	w := &watchdog{interval: time.Hour}
+
+	w.ping(tcping.Result{})
+	w.ping(tcping.Result{})
+	assert.Equal(t, "WATCHDOG=1", readNotification(t, conn))
+
+	// the second ping is within the interval and should be skipped
+	conn.SetReadDeadline(time.Now().Add(50 * time.Millisecond))
+	_, err := conn.Read(make([]byte, 64))
+	assert.Error(t, err)
+}
@@ -0,0 +1,113 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// schemePorts are the default ports of the schemes
+// the resolver doesn't know about.
+var schemePorts = map[string]uint16{
+	"ws":  80,
+	"wss": 443,
+}
+
+// urlTarget is a target given as a URL, e.g. https://example.com:8443/health.
+type urlTarget struct {
+	scheme string
+	host   string
+	port   uint16
+	// path includes the query, it's empty when the URL has neither.
+	path string
+}
+
+// isURLTarget reports whether the target is given as a URL.
+func isURLTarget(target string) bool {
+	return strings.Contains(target, "://")
+}
+
+// parseURLTarget parses the target given as a URL.
+// The port defaults to the one of the scheme.
+func parseURLTarget(target string) (urlTarget, error) {
+	u, err := url.Parse(target)
+	if err != nil {
+		return urlTarget{}, err
+	}
+
+	t := urlTarget{
+		scheme: strings.ToLower(u.Scheme),
+		host:   u.Hostname(),
+		path:   u.EscapedPath(),
+	}
+	if t.host == "" {
+		return t, errors.New("no host in the URL")
+	}
+	if u.RawQuery != "" {
+		t.path += "?" + u.RawQuery
+	}
+
+	if p := u.Port(); p != "" {
+		port, err := strconv.ParseUint(p, 10, 16)
+		if err != nil || port == 0 {
+			return t, fmt.Errorf("invalid port %s", p)
+		}
+		t.port = uint16(port)
+		return t, nil
+	}
+
+	if port, ok := schemePorts[t.scheme]; ok {
+		t.port = port
+		return t, nil
+	}
+	port, err := net.LookupPort("tcp", t.scheme)
+	if err != nil {
+		return t, fmt.Errorf("no default port for %s, add it to the URL", t.scheme)
+	}
+	t.port = uint16(port)
+
+	return t, nil
+}
+
+// setTarget splits the target given as a single argument, as host:port,
+// e.g. [2001:db8::1]:443, or as a URL, into its host and port.
+// The port of a host given on its own is defaultPort.
+// The path of the URL is requested by the probers speaking HTTP,
+// over TLS for https URLs, unless --http-path is given.
+func setTarget(args []string, defaultPort *uint, probe, httpPath *string, probeTLS *bool) []string {
+	if len(args) != 1 {
+		return args
+	}
+
+	if !isURLTarget(args[0]) {
+		host, port, err := net.SplitHostPort(args[0])
+		if err != nil {
+			// e.g. a hostname or an IPv6 address without a port
+			return []string{args[0], strconv.FormatUint(uint64(*defaultPort), 10)}
+		}
+		return []string{host, port}
+	}
+
+	t, err := parseURLTarget(args[0])
+	if err != nil {
+		colorRed("Invalid URL %s: %s\n", args[0], err)
+		usage()
+	}
+
+	switch *probe {
+	case "http":
+		if t.scheme == "https" {
+			*probeTLS = true
+		}
+		fallthrough
+	case "ws", "wss":
+		if *httpPath == "" && t.path != "" {
+			*httpPath = t.path
+		}
+	}
+
+	return []string{t.host, strconv.Itoa(int(t.port))}
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseURLTarget(t *testing.T) {
+	target, err := parseURLTarget("https://example.com:8443/health?full=1")
+	assert.NoError(t, err)
+	assert.Equal(t, urlTarget{scheme: "https", host: "example.com", port: 8443, path: "/health?full=1"}, target)
+
+	// the port defaults to the one of the scheme
+	target, err = parseURLTarget("HTTP://[2001:db8::1]")
+	assert.NoError(t, err)
+	assert.Equal(t, urlTarget{scheme: "http", host: "2001:db8::1", port: 80}, target)
+
+	target, err = parseURLTarget("wss://example.com/socket")
+	assert.NoError(t, err)
+	assert.Equal(t, uint16(443), target.port)
+
+	_, err = parseURLTarget("unknown://example.com")
+	assert.ErrorContains(t, err, "no default port")
+
+	_, err = parseURLTarget("https://example.com:99999")
+	assert.ErrorContains(t, err, "invalid port")
+}
+
+func TestSetTarget(t *testing.T) {
+	defaultPort := uint(443)
+	probe, httpPath, probeTLS := "", "", false
+	assert.Equal(t, []string{"example.com", "443"},
+		setTarget([]string{"example.com", "443"}, &defaultPort, &probe, &httpPath, &probeTLS))
+
+	assert.Equal(t, []string{"example.com", "443"},
+		setTarget([]string{"example.com:443"}, &defaultPort, &probe, &httpPath, &probeTLS))
+	assert.Equal(t, []string{"2001:db8::1", "443"},
+		setTarget([]string{"[2001:db8::1]:443"}, &defaultPort, &probe, &httpPath, &probeTLS))
+	// the hosts without a port get the default one
+	defaultPort = 80
+	assert.Equal(t, []string{"2001:db8::1", "80"},
+		setTarget([]string{"2001:db8::1"}, &defaultPort, &probe, &httpPath, &probeTLS))
+
+	// the path is only requested by the HTTP probes
+	assert.Equal(t, []string{"example.com", "443"},
+		setTarget([]string{"https://example.com/health"}, &defaultPort, &probe, &httpPath, &probeTLS))
+	assert.Empty(t, httpPath)
+	assert.False(t, probeTLS)
+
+	probe = "http"
+	setTarget([]string{"https://example.com/health"}, &defaultPort, &probe, &httpPath, &probeTLS)
+	assert.Equal(t, "/health", httpPath)
+	assert.True(t, probeTLS)
+
+	// --http-path wins over the path of the URL
+	httpPath = "/ready"
+	setTarget([]string{"http://example.com/health"}, &defaultPort, &probe, &httpPath, &probeTLS)
+	assert.Equal(t, "/ready", httpPath)
+}
@@ -4,203 +4,126 @@ import (
 	"bufio"
 	"context"
 	"flag"
-	"math/rand"
+	"fmt"
+	"log/slog"
 	"net"
-	"net/netip"
+	"net/url"
 	"os"
 	"os/signal"
 	"regexp"
+	"slices"
 	"strconv"
+	"strings"
 	"syscall"
 	"time"
 
 	"github.com/google/go-github/v45/github"
+	"github.com/gookit/color"
+	"github.com/pouriyajamshidi/tcping/v2/pkg/tcping"
 )
 
 const (
-	version    = "2.4.0"
-	owner      = "pouriyajamshidi"
-	repo       = "tcping"
-	dnsTimeout = 2 * time.Second
+	owner = "pouriyajamshidi"
+	repo  = "tcping"
 )
 
-// printer is a set of methods for printers to implement.
-//
-// Printers should NOT modify any existing data nor do any calculations.
-// They should only perform visual operations on given data.
-type printer interface {
-	// printStart should print the first message, after the program starts.
-	// This message is printed only once, at the very beginning.
-	printStart(hostname string, port uint16)
-
-	// printProbeSuccess should print a message after each successful probe.
-	// hostname could be empty, meaning it's pinging an address.
-	// streak is the number of successful consecutive probes.
-	printProbeSuccess(hostname, ip string, port uint16, streak uint, rtt float32)
-
-	// printProbeFail should print a message after each failed probe.
-	// hostname could be empty, meaning it's pinging an address.
-	// streak is the number of successful consecutive probes.
-	printProbeFail(hostname, ip string, port uint16, streak uint)
-
-	// printRetryingToResolve should print a message with the hostname
-	// it is trying to resolve an ip for.
-	//
-	// This is only being printed when the -r flag is applied.
-	printRetryingToResolve(hostname string)
-
-	// printTotalDownTime should print a downtime duration.
-	//
-	// This is being called when host was unavailable for some time
-	// but the latest probe was successful (became available).
-	printTotalDownTime(downtime time.Duration)
-
-	// printStatistics should print a message with
-	// helpful statistics information.
-	//
-	// This is being called on exit and when user hits "Enter".
-	printStatistics(s stats)
-
-	// printVersion should print the current version.
-	printVersion()
-
-	// printInfo should a message, which is not directly related
-	// to the pinging and serves as a helpful information.
-	//
-	// Example of such: new version with -u flag.
-	printInfo(format string, args ...any)
-
-	// printError should print an error message.
-	// Printer should also apply \n to the given string, if needed.
-	printError(format string, args ...any)
-}
-
-type stats struct {
-	startTime                 time.Time
-	endTime                   time.Time
-	startOfUptime             time.Time
-	startOfDowntime           time.Time
-	lastSuccessfulProbe       time.Time
-	lastUnsuccessfulProbe     time.Time
-	printer                   printer      // printer holds the chosen printer implementation for outputting information and data.
-	ticker                    *time.Ticker // ticker is used to handle time between probes.
-	longestUptime             longestTime
-	longestDowntime           longestTime
-	rtt                       []float32
-	hostnameChanges           []hostnameChange
-	userInput                 userInput
-	ongoingSuccessfulProbes   uint
-	ongoingUnsuccessfulProbes uint
-	totalDowntime             time.Duration
-	totalUptime               time.Duration
-	totalSuccessfulProbes     uint
-	totalUnsuccessfulProbes   uint
-	retriedHostnameLookups    uint
-	rttResults                rttResult
-	wasDown                   bool // wasDown is used to determine the duration of a downtime
-	isIP                      bool // isIP suppresses printing the IP information twice when hostname is not provided
-}
-
-type userInput struct {
-	ip                       netip.Addr
-	hostname                 string
-	networkInterface         networkInterface
-	retryHostnameLookupAfter uint // Retry resolving target's hostname after a certain number of failed requests
-	probesBeforeQuit         uint
-	timeout                  time.Duration
-	intervalBetweenProbes    time.Duration
-	port                     uint16
-	useIPv4                  bool
-	useIPv6                  bool
-	shouldRetryResolve       bool
-}
-
-type networkInterface struct {
-	raddr  *net.TCPAddr
-	dialer net.Dialer
-	use    bool
-}
-
-type longestTime struct {
-	start    time.Time
-	end      time.Time
-	duration time.Duration
-}
-
-type rttResult struct {
-	min        float32
-	max        float32
-	average    float32
-	hasResults bool
-}
-
-type hostnameChange struct {
-	Addr netip.Addr `json:"addr,omitempty"`
-	When time.Time  `json:"when,omitempty"`
-}
-
-// signalHandler catches SIGINT and SIGTERM then prints tcping stats
-func signalHandler(tcpStats *stats) {
-	sigChan := make(chan os.Signal, 1)
-	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
+var (
+	colorYellow    = color.Yellow.Printf
+	colorRed       = color.Red.Printf
+	colorLightCyan = color.LightCyan.Printf
+)
 
-	go func() {
-		<-sigChan
-		shutdown(tcpStats)
-	}()
+// server serves the live data of the pinger in the background.
+type server interface {
+	// listen starts serving, it only returns an error
+	// if the server can't be started.
+	listen(statistics func() tcping.Statistics) error
 }
 
 // monitorStdin checks stdin to see whether the 'Enter' key was pressed
-func monitorStdin(stdinChan chan bool) {
+// and asks the pingers to print the statistics if so.
+// It returns once stdin is closed, e.g. when it's /dev/null.
+func monitorStdin(pingers []*tcping.Pinger) {
 	reader := bufio.NewReader(os.Stdin)
 	for {
-		input, _ := reader.ReadString('\n')
+		input, err := reader.ReadString('\n')
+		if err != nil {
+			return
+		}
 
 		if input == "\n" || input == "\r" || input == "\r\n" {
-			stdinChan <- true
+			for _, pinger := range pingers {
+				pinger.RequestStatistics()
+			}
 		}
 	}
 }
 
-// printStats is a helper method for printStatistics
-// for the current printer.
-//
-// This should be used instead, as it makes
-// all the necessary calculations beforehand.
-func (tcpStats *stats) printStats() {
-	if tcpStats.wasDown {
-		calcLongestDowntime(tcpStats, time.Since(tcpStats.startOfDowntime))
-	} else {
-		calcLongestUptime(tcpStats, time.Since(tcpStats.startOfUptime))
+// monitorSignals asks the pingers to print the statistics whenever
+// one of statsSignals is received, which unlike the 'Enter' key
+// also works when stdin isn't a terminal.
+func monitorSignals(pingers []*tcping.Pinger) {
+	if len(statsSignals) == 0 {
+		return
 	}
-	tcpStats.rttResults = calcMinAvgMaxRttTime(tcpStats.rtt)
 
-	tcpStats.printer.printStatistics(*tcpStats)
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, statsSignals...)
+
+	go func() {
+		for range sigChan {
+			for _, pinger := range pingers {
+				pinger.RequestStatistics()
+			}
+		}
+	}()
 }
 
-// shutdown calculates endTime, prints statistics and calls os.Exit(0).
-// This should be used as a main exit-point.
-func shutdown(tcpStats *stats) {
-	tcpStats.endTime = time.Now()
-	tcpStats.printStats()
+// shutdown prints the final statistics, once the pingers are stopped,
+// followed by their combined summary if there are several, runs the atExit
+// functions, e.g. drawing the graph of --graph, and returns the exit code:
+// 0 if any probe succeeded, 1 otherwise.
+func shutdown(printer tcping.Printer, pingers []*tcping.Pinger, atExit []func()) int {
+	sdNotify("STOPPING=1")
+
+	code := 1
+	for _, pinger := range pingers {
+		pinger.Shutdown()
+		if pinger.Statistics().TotalSuccessfulProbes > 0 {
+			code = 0
+		}
+	}
+	printSummary(printer, pingers)
 
-	// if the printer type is `database`, then close the db before
-	// exiting to prevent any memory leaks
-	if db, ok := tcpStats.printer.(*database); ok {
-		db.conn.Close()
+	for _, f := range atExit {
+		f()
 	}
 
-	os.Exit(0)
+	return code
 }
 
 // usage prints how tcping should be run
 func usage() {
 	executableName := os.Args[0]
 
-	colorLightCyan("\nTCPING version %s\n\n", version)
+	colorLightCyan("\nTCPING version %s\n\n", tcping.Version)
 	colorRed("Try running %s like:\n", executableName)
 	colorRed("%s <hostname/ip> <port number>. For example:\n", executableName)
 	colorRed("%s www.example.com 443\n", executableName)
+	colorRed("%s www.example.com:443\n", executableName)
+	colorRed("%s [2001:db8::1]:443\n", executableName)
+	colorRed("%s www.example.com, probing port 443 or the one of --default-port\n", executableName)
+	colorRed("\nThe target can be given as a URL, the port defaults to the one of its scheme:\n")
+	colorRed("%s https://www.example.com/health --probe http\n", executableName)
+	colorRed("\nTo probe the target of an SRV record:\n")
+	colorRed("%s --srv _service._tcp.example.com\n", executableName)
+	colorRed("\nTo probe multiple targets in the background, see:\n")
+	colorRed("%s daemon -h\n", executableName)
+	colorRed("%s ctl -h\n", executableName)
+	colorRed("\nTo find where along the path connections die:\n")
+	colorRed("%s trace -h\n", executableName)
+	colorRed("\nTo analyze the probes written with --samples-file again:\n")
+	colorRed("%s analyze -h\n", executableName)
 	colorYellow("\n[optional flags]\n")
 
 	flag.VisitAll(func(f *flag.Flag) {
@@ -215,117 +138,297 @@ func usage() {
 	os.Exit(1)
 }
 
-func checkSetPrinters(tcpstats *stats, outputtoJSON, prettyJSON *bool, outputDb *string, args []string) {
-	// check if prettyjson an outputtojson are true, if so printError and exit
-	if *prettyJSON && !*outputtoJSON {
+// outputFlag collects the values of the repeatable --output flag,
+// either a printer name or name=path to write to a file.
+type outputFlag []string
+
+func (f *outputFlag) String() string {
+	return strings.Join(*f, " ")
+}
+
+func (f *outputFlag) Set(value string) error {
+	*f = append(*f, value)
+	return nil
+}
+
+// checkSetPrinters sets the printers, combined if there are several,
+// and returns the names of their outputs.
+func checkSetPrinters(opts *tcping.Options, outputtoJSON, prettyJSON, elapsed *bool, outputDb *string, output outputFlag, args []string) string {
+	outputs := slices.Clone(output)
+	if *outputtoJSON {
+		outputs = append(outputs, "json")
+	}
+	if *outputDb != "" {
+		outputs = append(outputs, "database")
+	}
+	if len(outputs) == 0 {
+		outputs = append(outputs, "plain")
+		if underSystemd() {
+			outputs[0] = "journal"
+		}
+	}
+
+	// the database printer exits on errors, it goes last so that
+	// the other printers get to print them first
+	isDatabase := func(o string) bool { return o == "database" || strings.HasPrefix(o, "database=") }
+	databases := slices.DeleteFunc(slices.Clone(outputs), func(o string) bool { return !isDatabase(o) })
+	outputs = append(slices.DeleteFunc(outputs, isDatabase), databases...)
+
+	// check if prettyjson is set without a json output, if so printError and exit
+	if *prettyJSON && !slices.ContainsFunc(outputs, func(o string) bool { return strings.HasPrefix(o, "json") }) {
 		colorRed("--pretty has no effect without the -j flag.")
 		usage()
 	}
-	if *outputtoJSON {
-		tcpstats.printer = newJSONPrinter(*prettyJSON)
-	} else if *outputDb != "" {
-		tcpstats.printer = newDb(args, *outputDb)
-	} else {
-		tcpstats.printer = &planePrinter{}
+
+	var printers []tcping.Printer
+	var names []string
+	for _, o := range outputs {
+		name, path, _ := strings.Cut(o, "=")
+
+		cfg := tcping.PrinterConfig{
+			DBPath:     *outputDb,
+			PrettyJSON: *prettyJSON,
+			Elapsed:    *elapsed,
+		}
+		if len(args) == 2 {
+			port, _ := strconv.ParseUint(args[1], 10, 16)
+			cfg.Hostname = args[0]
+			cfg.Port = uint16(port)
+		} else if name == "database" {
+			// host and port must be specified
+			usage()
+		}
+
+		switch {
+		case name == "database":
+			if path != "" {
+				cfg.DBPath = path
+			}
+			names = append(names, name+" "+cfg.DBPath)
+		case path != "":
+			f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0o644)
+			if err != nil {
+				colorRed("Unable to open the output file: %s\n", err)
+				os.Exit(1)
+			}
+			cfg.Output = f
+			names = append(names, name+" to "+path)
+		default:
+			names = append(names, name)
+		}
+
+		p, err := tcping.NewPrinter(name, cfg)
+		if err != nil {
+			colorRed("%s\n", err)
+			os.Exit(1)
+		}
+		printers = append(printers, p)
 	}
+	opts.Printer = tcping.NewMultiPrinter(printers...)
+
+	return strings.Join(names, ", ")
 }
 
-func checkUpdateVersion(update, version *bool, args []string, nflags int, tcpstats *stats) {
+func checkUpdateVersion(update, version *bool, args []string, nflags int, opts *tcping.Options) {
 	// -u works on its own
 	if *update {
 		if len(args) == 0 && nflags == 1 {
-			checkLatestVersion(tcpstats.printer)
+			checkLatestVersion(opts.Printer)
 		} else {
 			usage()
 		}
 	}
 
 	if *version {
-		tcpstats.printer.printVersion()
+		opts.Printer.PrintVersion()
 		os.Exit(0)
 	}
 }
 
-func checkSetIPFlags(tcpstats *stats, ip4, ip6 *bool) {
+func checkSetIPFlags(opts *tcping.Options, ip4, ip6 *bool) {
 	// check if ip4 or ip6 are true, if so printError and exit
 	if *ip4 && *ip6 {
-		tcpstats.printer.printError("Only one IP version can be specified")
+		opts.Printer.PrintError("Only one IP version can be specified")
 		usage()
 	}
-	if *ip4 {
-		tcpstats.userInput.useIPv4 = true
-	}
-	if *ip6 {
-		tcpstats.userInput.useIPv6 = true
-	}
-
+	opts.UseIPv4 = *ip4
+	opts.UseIPv6 = *ip6
 }
 
-func checkPort(tcpstats *stats, args []string) {
+func checkPort(opts *tcping.Options, args []string) {
 	// the non-flag command-line arguments
 	port, err := strconv.ParseUint(args[1], 10, 16)
 	if err != nil {
-		tcpstats.printer.printError("Invalid port number: %s", args[1])
+		opts.Printer.PrintError("Invalid port number: %s", args[1])
 		os.Exit(1)
 	}
 
 	if port < 1 || port > 65535 {
-		tcpstats.printer.printError("Port should be in 1..65535 range")
+		opts.Printer.PrintError("Port should be in 1..65535 range")
 		os.Exit(1)
 	}
-	tcpstats.userInput.port = uint16(port)
-
+	opts.Port = uint16(port)
 }
 
-func setGenericArgs(tcpstats *stats, args []string, retryResolve, probesbfrquit *uint, timeout, secbtwprobes *float64, intName *string) {
-	if *retryResolve > 0 {
-		tcpstats.userInput.retryHostnameLookupAfter = *retryResolve
+func setGenericArgs(opts *tcping.Options, args []string, retryResolve, probesbfrquit *uint, timeout, secbtwprobes *float64, intName, bell *string) {
+	if len(args) > 0 {
+		opts.Hostname = args[0]
 	}
+	opts.RetryHostnameLookupAfter = *retryResolve
+	opts.ProbesBeforeQuit = *probesbfrquit
+	opts.Timeout = secondsToDuration(*timeout)
+	opts.IntervalBetweenProbes = secondsToDuration(*secbtwprobes)
+	opts.InterfaceName = *intName
+	opts.Bell = *bell
+}
 
-	tcpstats.userInput.hostname = args[0]
-	tcpstats.userInput.ip = resolveHostname(tcpstats)
-	tcpstats.startTime = time.Now()
-	tcpstats.userInput.probesBeforeQuit = *probesbfrquit
-	tcpstats.userInput.timeout = secondsToDuration(*timeout)
-
-	tcpstats.userInput.intervalBetweenProbes = secondsToDuration(*secbtwprobes)
-	if tcpstats.userInput.intervalBetweenProbes < 2*time.Millisecond {
-		tcpstats.printer.printError("Wait interval should be more than 2 ms")
-		os.Exit(1)
+// setServices names the ports with the services of the file
+// in the format of /etc/services.
+func setServices(opts *tcping.Options, path *string) {
+	if *path == "" {
+		return
 	}
 
-	// this serves as a default starting value for tracking changes.
-	tcpstats.hostnameChanges = []hostnameChange{
-		{tcpstats.userInput.ip, time.Now()},
+	f, err := os.Open(*path)
+	if err == nil {
+		opts.Services, err = tcping.ReadServices(f)
+		f.Close()
 	}
-
-	if tcpstats.userInput.hostname == tcpstats.userInput.ip.String() {
-		tcpstats.isIP = true
+	if err != nil {
+		opts.Printer.PrintError("Unable to read the services file %s: %s", *path, err)
+		os.Exit(1)
 	}
+}
 
-	if tcpstats.userInput.retryHostnameLookupAfter > 0 && !tcpstats.isIP {
-		tcpstats.userInput.shouldRetryResolve = true
+// setLookup annotates the probed IP with the MaxMind DB files
+// given as a comma-separated list, e.g. the ASN and country ones.
+func setLookup(opts *tcping.Options, lookup *string) {
+	if *lookup == "" {
+		return
 	}
 
-	if *intName != "" {
-		tcpstats.userInput.networkInterface = newNetworkInterface(tcpstats, *intName)
+	db, err := tcping.OpenMMDB(strings.Split(*lookup, ",")...)
+	if err != nil {
+		opts.Printer.PrintError("Invalid --lookup: %s", err)
+		os.Exit(1)
 	}
+	opts.IPLookup = db
 }
 
 // processUserInput gets and validate user input
-func processUserInput(tcpStats *stats) {
+func processUserInput() (tcping.Options, []server, bool, []uint16, []func()) {
+	var opts tcping.Options
+
 	useIPv4 := flag.Bool("4", false, "only use IPv4.")
 	useIPv6 := flag.Bool("6", false, "only use IPv6.")
+	allIPs := flag.Bool("all-ips", false, "probe every resolved address of the target in parallel, with separate statistics per address.")
+	graph := flag.Bool("graph", false, "draw the RTTs of the run as a graph in the terminal when it ends.")
+	fastestIP := flag.Bool("fastest-ip", false, "probe every resolved address of the target briefly before starting and keep probing the one with the lowest RTT.")
 	retryHostnameResolveAfter := flag.Uint("r", 0, "retry resolving target's hostname after <n> number of failed probes. e.g. -r 10 to retry after 10 failed probes.")
+	failover := flag.Uint("failover", 0, "probe the next address of target's hostname after <n> failed probes in a row, if it has several, e.g. --failover 3.")
+	resolveInterval := flag.Duration("resolve-interval", 0, "resolve target's hostname again periodically, even while it's up, e.g. --resolve-interval 5m.")
+	honorTTL := flag.Bool("honor-ttl", false, "resolve target's hostname again whenever the TTL of its DNS records expires.")
+	resolveEveryProbe := flag.Bool("resolve-every-probe", false, "resolve target's hostname before every probe and report how long it took.")
+	smoothedRTT := flag.Bool("srtt", false, "print the smoothed RTT, a moving average of the RTTs like the one of TCP, after every successful probe.")
+	outliers := flag.Float64("outliers", 0, "flag the probes whose RTT is more than <k> standard deviations above the mean of the previous ones, e.g. --outliers 3.")
+	outliersWindow := flag.Uint("outliers-window", 0, "compare the RTTs to the mean of the last <n> ones with --outliers instead of all the previous ones, so that the baseline follows the slow changes of the path, e.g. --outliers-window 100.")
+	outliersNotify := flag.Bool("outliers-notify", false, "run the hooks and send the notifications of a down event on the first outlier of a row with --outliers, and the ones of an up event on the next probe that isn't one.")
+	rttThreshold := flag.Duration("rtt-threshold", 0, "mark the successful probes slower than this as degraded and count them in the statistics, e.g. --rtt-threshold 150ms.")
+	degradedNotify := flag.Bool("degraded-notify", false, "run the hooks and send the notifications of a down event when the RTT goes above --rtt-threshold, and the ones of an up event once it's back under it.")
+	trendWindow := flag.Uint("trend", 0, "warn when the RTTs of the last <n> successful probes trend up steadily, which often comes before the target fails, from 10 to 1000 probes, e.g. --trend 30.")
+	trendNotify := flag.Bool("trend-notify", false, "run the hooks and send the notifications of a down event when the RTTs start trending up with --trend, and the ones of an up event once they stop.")
+	availabilityWindow := flag.Duration("availability-window", 0, "break the statistics down into windows of this size, with the probes, the failures, the average RTT and the availability of each one, e.g. --availability-window 1h or 24h for days.")
+	warmup := flag.Uint("warmup", 0, "leave the RTTs of the first <n> probes out of the statistics, as the first handshakes are skewed by ARP, ND or conntrack. They're still printed.")
+	retries := flag.Uint("retries", 0, "attempt to connect <n> more times within the interval before a probe counts as failed, e.g. --retries 2 with -t 0.3. Single dropped SYNs then don't fail the probes.")
+	burst := flag.Uint("burst", 0, "make <k> connections back to back for every probe and take the lowest RTT, so that a single dropped SYN neither fails the probe nor skews its RTT, e.g. --burst 3.")
+	burstMedian := flag.Bool("burst-median", false, "take the median RTT of the connections of --burst instead of the lowest one.")
+	concurrent := flag.Bool("concurrent", false, "make the connections of --burst at the same time and keep them open until they're all done, to test how many the target accepts at once.")
 	probesBeforeQuit := flag.Uint("c", 0, "stop after <n> probes, regardless of the result. By default, no limit will be applied.")
 	outputJSON := flag.Bool("j", false, "output in JSON format.")
 	prettyJSON := flag.Bool("pretty", false, "use indentation when using json output format. No effect without the '-j' flag.")
+	elapsed := flag.Bool("elapsed", false, "prefix the lines of the plain and the journal output with the time elapsed since the start, e.g. [+00:02:13].")
 	showVersion := flag.Bool("v", false, "show version.")
 	shouldCheckUpdates := flag.Bool("u", false, "check for updates.")
 	secondsBetweenProbes := flag.Float64("i", 1, "interval between sending probes. Real number allowed with dot as a decimal separator. The default is one second")
 	timeout := flag.Float64("t", 1, "time to wait for a response, in seconds. Real number allowed. 0 means infinite timeout.")
 	outputDb := flag.String("db", "", "path and file name to store tcping output to sqlite database.")
+	var output outputFlag
+	flag.Var(&output, "output", fmt.Sprintf("output format, one of: %s. Repeat it to print to several outputs, the json and the journal ones can be written to a file with name=path, e.g. --output plain --output json=tcping.jsonl. Defaults to journal under systemd and to plain otherwise.", strings.Join(tcping.Printers(), ", ")))
 	interfaceName := flag.String("I", "", "interface name or address")
+	tfo := flag.Bool("tfo", false, "connect with TCP Fast Open and report whether the server accepted the data in the SYN. Linux only.")
+	probe := flag.String("probe", "", fmt.Sprintf("check the service with its protocol after connecting, one of: %s.", strings.Join(tcping.Probers(), ", ")))
+	probeService := flag.String("probe-service", "", "name of the service checked by the prober, e.g. --probe grpc --probe-service my.package.Service.")
+	httpMethod := flag.String("http-method", "", "method of the request sent by --probe http. Defaults to GET.")
+	httpPath := flag.String("http-path", "", "path of the request sent by --probe http. Defaults to /.")
+	var headers headerFlag
+	flag.Var(&headers, "header", "header of the request sent by --probe http, e.g. --header 'Host: www.example.com'. Can be repeated.")
+	expectStatus := flag.String("expect-status", "", "status codes accepted by --probe http, e.g. --expect-status 200-299,301. Defaults to 200-399.")
+	expectBody := flag.String("expect-body", "", "regular expression the body must match with --probe http, e.g. --expect-body healthy.")
+	query := flag.String("query", "", "name looked up by --probe dns, e.g. --query example.com.")
+	queryType := flag.String("query-type", "", "type of the records looked up by --probe dns, e.g. --query-type AAAA. Defaults to A.")
+	queryUDP := flag.Bool("query-udp", false, "send the query of --probe dns over UDP instead of the TCP connection.")
+	wsPing := flag.Bool("ws-ping", false, "send a ping after the upgrade of --probe ws or wss and wait for the pong.")
+	probeTLS := flag.Bool("probe-tls", false, "speak the protocol of the prober over TLS, e.g. --probe grpc --probe-tls.")
+	useTLS := flag.Bool("tls", false, "perform a TLS handshake after connecting, print the certificate chain once and fail the probes whose handshake fails.")
+	sni := flag.String("sni", "", "server name sent and verified in the TLS handshake instead of the hostname, e.g. --sni www.example.com.")
+	insecureTLS := flag.Bool("insecure", false, "don't verify the certificate of the server in the TLS handshake.")
+	caFile := flag.String("ca-file", "", "verify the certificate of the server with the CAs in the given PEM file instead of the system ones.")
+	certFile := flag.String("cert", "", "PEM file of the client certificate presented in the TLS handshake, with --key.")
+	keyFile := flag.String("key", "", "PEM file of the private key of the client certificate given with --cert.")
+	certWarnDays := flag.Uint("cert-warn-days", 30, "warn when the certificate chain expires within <n> days with --tls.")
+	banner := flag.Bool("banner", false, "read the banner the server sends after connecting, print it once and fail the probes where it disappears.")
+	send := flag.String("send", "", "payload to send after connecting. Escape sequences like \\r\\n are interpreted, e.g. --send 'PING\\r\\n'.")
+	payloadSize := flag.String("payload-size", "", "stream a payload of this size after connecting and print the goodput, e.g. --payload-size 1MB. The peer must close the connection once it has read it, like a discard service.")
+	payloadEcho := flag.Bool("payload-echo", false, "read the payload of --payload-size back as it's sent, for a peer echoing what it receives.")
+	expect := flag.String("expect", "", "regular expression the response must match for the probe to succeed, e.g. --expect '^\\+PONG'.")
+	closeWait := flag.Duration("close-wait", 0, "keep the successful connections open for up to this long and print whether the server closed them, reset them or kept them open, e.g. --close-wait 500ms.")
+	persistent := flag.Bool("persistent", false, "keep the connection open and check it with every probe instead of connecting again, to catch silent drops. Linux only.")
+	noDelay := flag.Bool("tcp-nodelay", true, "set TCP_NODELAY on the connections like Go does, --tcp-nodelay=false enables Nagle's algorithm like the applications that don't.")
+	linger := flag.Int("so-linger", -1, "set SO_LINGER on the connections to this many seconds, 0 resets them on close instead of closing them gracefully. Defaults to the OS' behavior.")
+	keepAlive := flag.Duration("tcp-keepalive", 0, "period of the TCP keepalives of the connections, negative disables them. Defaults to Go's 15s, e.g. --tcp-keepalive 30s.")
+	fwmark := flag.Uint("fwmark", 0, "set SO_MARK on the sockets of the probes to follow the routing policy of the mark, e.g. the routing table of a VPN tunnel, e.g. --fwmark 0x10. Needs CAP_NET_ADMIN. Linux only.")
+	bindDevice := flag.String("bind-device", "", "bind the sockets of the probes to this interface or VRF with SO_BINDTODEVICE, so that they leave through it whatever the routing table says, e.g. --bind-device eth1. Linux only.")
+	netns := flag.String("netns", "", "send the probes from within this network namespace, named like with `ip netns` or given by its path, e.g. --netns blue or --netns /proc/1234/ns/net. Linux only.")
+	jump := flag.String("jump", "", "tunnel the probes through an SSH connection to this jump host, given as [user@]host[:port], to probe the targets only reachable from it, e.g. --jump admin@bastion.example.com. Uses the keys of ssh-agent and ~/.ssh and checks ~/.ssh/known_hosts.")
+	noEnvProxy := flag.Bool("no-env-proxy", false, "don't connect the probes of --tls, --probe-tls and --probe http, ws and wss through the proxy of HTTPS_PROXY, HTTP_PROXY or ALL_PROXY.")
+	mptcp := flag.Bool("mptcp", false, "request Multipath TCP connections and report whether MPTCP was negotiated, falling back to TCP otherwise. Linux only.")
+	pathMTU := flag.Bool("mtu", false, "probe the path MTU to the target after the first successful probe, with ICMP echoes that mustn't be fragmented. Linux only.")
+	compareICMP := flag.Bool("compare-icmp", false, "send an ICMP echo alongside every probe and print both RTTs, to tell slow networks from slow services.")
+	verbose := flag.Bool("verbose", false, "show the local address and port the OS picked for every successful connection, e.g. to debug NAT or ephemeral port exhaustion, and log why tcping picked the address it probes and retried on stderr.")
+	debug := flag.Bool("debug", false, "like --verbose, also logging the details of the resolutions, the connections and the socket options.")
+	reverseDNS := flag.Bool("rdns", false, "resolve the names of the probed IP from its PTR records and print them at the start and in the statistics.")
+	lookup := flag.String("lookup", "", "annotate the probed IP with its ASN, organization and country from the given MaxMind DB files, e.g. --lookup GeoLite2-ASN.mmdb,GeoLite2-Country.mmdb.")
+	tcpInfo := flag.Bool("tcpinfo", false, "report the smoothed RTT, the retransmissions, the congestion window and the delivery rate of the kernel after every successful probe, after the data of --payload-size if any. Linux only.")
+	pcapPath := flag.String("pcap", "", "capture the segments of the probes to the given file, in the pcapng format for Wireshark. The first segment of every probe is commented with its sequence number, e.g. 'tcping seq=3 to 192.0.2.1:443'. Needs CAP_NET_RAW. Linux only.")
+	useEBPF := flag.Bool("ebpf", false, "time the handshakes of the probes in the kernel with eBPF, from the SYN to the established connection, without the jitter of the Go scheduler. Needs CAP_BPF and CAP_PERFMON, the probes are timed as usual without them. Linux only.")
+	handshake := flag.Bool("handshake", false, "capture the SYN, SYN-ACK and ACK of every probe to tell the RTT on the wire from the time spent in the local stack. Needs CAP_NET_RAW, the probes are timed as usual without it. Linux only.")
+	srvName := flag.String("srv", "", "probe the target of the given SRV record instead of a hostname and a port, e.g. --srv _service._tcp.example.com.")
+	dnsServer := flag.String("dns", "", "resolve the hostname using the given DNS server instead of the system resolver, e.g. --dns 1.1.1.1:53.")
+	dohURL := flag.String("doh", "", "resolve the hostname using the given DNS-over-HTTPS server, e.g. --doh https://cloudflare-dns.com/dns-query.")
+	dotServer := flag.String("dot", "", "resolve the hostname using the given DNS-over-TLS server, e.g. --dot one.one.one.one:853.")
+	var resolve resolveFlag
+	flag.Var(&resolve, "resolve", "use the given address for a hostname instead of resolving it, e.g. --resolve example.com:192.0.2.10. Can be repeated.")
+	portList := flag.String("p", "", "probe the given comma-separated ports of the target in parallel, with statistics per port and a combined summary, e.g. -p 80,443,8443.")
+	ipSelect := flag.String("ip-select", tcping.IPSelectFirst, "how the address to probe is picked among the resolved ones: 'first', 'random', 'round-robin' on every resolution or 'fastest' to connect.")
+	defaultPort := flag.Uint("default-port", 443, "port probed when the target is given without one, e.g. tcping www.example.com.")
+	servicesFile := flag.String("services", "", "name the ports in the output with the services in the given file, in the format of /etc/services, in addition to the well-known ones, e.g. --services services.txt.")
+	hostsFile := flag.String("hosts-file", "", "use the addresses of the hostnames in the given file, in the format of /etc/hosts.")
+	desktopNotify := flag.Bool("notify", false, "show a desktop notification when the target goes down or comes back up.")
+	bell := flag.String("bell", "", "ring the terminal bell on probe 'fail', 'success' or on state 'change'.")
+	onDown := flag.String("on-down", "", "command to run when the target goes down. Details are passed in TCPING_* environment variables.")
+	onUp := flag.String("on-up", "", "command to run when the target comes back up. Details are passed in TCPING_* environment variables.")
+	checkCmd := flag.String("check-cmd", "", "command to run after every successful connection, the probe fails if it exits with a nonzero status or runs for longer than -t. Details are passed in TCPING_* environment variables.")
+	webhookURL := flag.String("webhook", "", "URL to POST a JSON payload to when the target goes down or comes back up.")
+	webhookStats := flag.Bool("webhook-stats", false, "also POST the statistics to the webhook on exit. No effect without the '--webhook' flag.")
+	configPath := flag.String("config", "", "path to a JSON configuration file, e.g. for the chat, email and incident notifiers.")
+	listenAddr := flag.String("listen", "", "serve the live statistics over HTTP on the given address, e.g. --listen :8080.")
+	grpcAddr := flag.String("grpc", "", "serve the probes and the statistics over gRPC on the given address, e.g. --grpc :50051.")
+	pushgatewayURL := flag.String("pushgateway", "", "push the final statistics to the Prometheus Pushgateway at the given URL on exit.")
+	pushgatewayJob := flag.String("pushgateway-job", "tcping", "job label of the metrics pushed to the Pushgateway.")
+	check := flag.Bool("check", false, "validate the flags and the configuration, resolve the target and print what would be probed and where the results would go, then exit without probing.")
+	samplesFile := flag.String("samples-file", "", "write the timestamp, the IP, the outcome and the RTT of every probe to the given CSV file as they happen, for offline analysis.")
+	compress := flag.Bool("compress", false, "gzip the file of --samples-file, for long runs.")
+	fsync := flag.Duration("fsync", 0, "sync the file of --samples-file to the disk at most this often, e.g. --fsync 1m. With --compress, the samples are only written out then and at exit.")
 
 	flag.CommandLine.Usage = usage
 
@@ -333,515 +436,677 @@ func processUserInput(tcpStats *stats) {
 	flag.Parse()
 
 	// validation for flag and args
-	args := flag.Args()
+	ports := checkPorts(portList, flag.Args())
+	args := setTarget(flag.Args(), defaultPort, probe, httpPath, probeTLS)
 	nFlag := flag.NFlag()
 
 	// we need to set printers first, because they're used for
 	// errors reporting and other output.
-	checkSetPrinters(tcpStats, outputJSON, prettyJSON, outputDb, args)
+	outputName := checkSetPrinters(&opts, outputJSON, prettyJSON, elapsed, outputDb, output, args)
 	// Check if admin command passed in an render respons.
-	checkUpdateVersion(shouldCheckUpdates, showVersion, args, nFlag, tcpStats)
+	checkUpdateVersion(shouldCheckUpdates, showVersion, args, nFlag, &opts)
 
-	// host and port must be specified
-	if len(args) != 2 {
+	// host and port must be specified, unless they come from an SRV record
+	if *srvName != "" {
+		if len(args) != 0 {
+			colorRed("The hostname and the port cannot be given with --srv.")
+			usage()
+		}
+		opts.SRV = *srvName
+	} else if len(args) != 2 {
 		usage()
 	}
 
 	// Check whether both the ipv4 and ipv6 flags are attempted set if ony one, error otherwise.
-	checkSetIPFlags(tcpStats, useIPv4, useIPv6)
+	checkSetIPFlags(&opts, useIPv4, useIPv6)
 	// Check if the port is valid and set it.
-	checkPort(tcpStats, args)
+	if opts.SRV == "" {
+		checkPort(&opts, args)
+	}
 	// set generic args
-	setGenericArgs(tcpStats, args, retryHostnameResolveAfter,
+	setGenericArgs(&opts, args, retryHostnameResolveAfter,
 		probesBeforeQuit, timeout, secondsBetweenProbes,
-		interfaceName)
-}
-
-/*
-permuteArgs permute args for flag parsing stops just before the first non-flag argument.
-
-see: https://pkg.go.dev/flag
-*/
-func permuteArgs(args []string) {
-	var flagArgs []string
-	var nonFlagArgs []string
-
-	for i := 0; i < len(args); i++ {
-		v := args[i]
-		if v[0] == '-' {
-			var optionName string
-			if v[1] == '-' {
-				optionName = v[2:]
-			} else {
-				optionName = v[1:]
-			}
-			switch optionName {
-			case "c":
-				fallthrough
-			case "t":
-				fallthrough
-			case "db":
-				fallthrough
-			case "I":
-				fallthrough
-			case "i":
-				fallthrough
-			case "r":
-				/* out of index */
-				if len(args) <= i+1 {
-					usage()
-				}
-				/* the next flag has come */
-				optionVal := args[i+1]
-				if optionVal[0] == '-' {
-					usage()
+		interfaceName, bell)
+	// force the addresses of the hostnames given by the user
+	setHosts(&opts, resolve, hostsFile)
+	// name the internal services of the user
+	setServices(&opts, servicesFile)
+	// set the resolver used for the hostname
+	setResolver(&opts, dnsServer, dohURL, dotServer)
+	opts.ResolveEveryProbe = *resolveEveryProbe
+	opts.HonorTTL = *honorTTL
+	opts.IPSelect = *ipSelect
+	opts.Failover = *failover
+	opts.ResolveInterval = *resolveInterval
+	opts.TCPInfo = *tcpInfo
+	opts.Handshake = *handshake
+	opts.EBPF = *useEBPF
+	opts.TFO = *tfo
+	opts.MPTCP = *mptcp
+	opts.Persistent = *persistent
+	opts.CloseWait = *closeWait
+	setSocketOptions(&opts, noDelay, linger, keepAlive, fwmark)
+	opts.BindDevice = *bindDevice
+	opts.Netns = *netns
+	opts.Banner = *banner
+	opts.CompareICMP = *compareICMP
+	opts.PathMTU = *pathMTU
+	opts.ReverseDNS = *reverseDNS
+	opts.ShowLocalAddr = *verbose || *debug
+	opts.Retries = *retries
+	opts.Burst = *burst
+	opts.BurstMedian = *burstMedian
+	opts.BurstConcurrent = *concurrent
+	opts.Warmup = *warmup
+	opts.OutlierStdDevs = *outliers
+	opts.OutlierWindow = *outliersWindow
+	opts.NotifyOutliers = *outliersNotify
+	opts.SmoothedRTT = *smoothedRTT
+	opts.RTTThreshold = *rttThreshold
+	opts.NotifyDegraded = *degradedNotify
+	opts.TrendWindow = *trendWindow
+	opts.NotifyTrend = *trendNotify
+	opts.AvailabilityWindow = *availabilityWindow
+	// annotate the probed IP with the offline databases
+	setLookup(&opts, lookup)
+	// set the TLS handshake and how the certificates are verified
+	// wss always speaks TLS
+	proberSpeaksTLS := *probeTLS || *probe == "wss"
+	proberTLS := setTLS(&opts, useTLS, &proberSpeaksTLS, certWarnDays, sni, insecureTLS, caFile, certFile, keyFile)
+	// set the payload and the response expected from the service
+	setPayload(&opts, send, expect)
+	setGoodput(&opts, payloadSize, payloadEcho)
+	// set the prober of the service's protocol
+	setProber(&opts, probe, tcping.ProberConfig{
+		Service:       *probeService,
+		HTTP:          setHTTP(&opts, probe, httpMethod, httpPath, headers, expectStatus, expectBody),
+		DNS:           setQuery(&opts, probe, query, queryType, queryUDP),
+		WebSocketPing: *wsPing,
+		TLSConfig:     proberTLS,
+	})
+	// check the service with the user's command
+	setCheckCmd(&opts, checkCmd)
+	// set the notifiers that alert about state changes
+	setNotifiers(&opts, desktopNotify, onDown, onUp, webhookURL, webhookStats, configPath)
+	// push the final statistics to the Pushgateway
+	setPushgateway(&opts, pushgatewayURL, pushgatewayJob)
+	// write the raw data and the segments of every probe, the check doesn't create the files
+	var atExit []func()
+	if !*check {
+		if sf := setSamplesFile(&opts, samplesFile, compress, fsync); sf != nil {
+			atExit = append(atExit, func() {
+				if err := sf.close(); err != nil {
+					opts.Printer.PrintError("Unable to close the samples file: %s", err)
 				}
-				flagArgs = append(flagArgs, args[i:i+2]...)
-				i++
-			default:
-				flagArgs = append(flagArgs, args[i])
-			}
-		} else {
-			nonFlagArgs = append(nonFlagArgs, args[i])
+			})
+		}
+		if closePcap := setPcapFile(&opts, pcapPath); closePcap != nil {
+			atExit = append(atExit, closePcap)
 		}
 	}
-	permutedArgs := append(flagArgs, nonFlagArgs...)
-
-	/* replace args */
-	for i := 0; i < len(args); i++ {
-		args[i] = permutedArgs[i]
+	// tunnel the probes through the jump host
+	setJump(&opts, jump)
+	// connect through the proxy of the environment
+	setProxy(&opts, noEnvProxy, probe, opts.TLSConfig != nil || proberSpeaksTLS, configPath)
+	// log the decisions of the pinger
+	setLogger(&opts, verbose, debug)
+	// set the servers that serve the live statistics
+	servers := setServers(&opts, listenAddr, grpcAddr)
+	// ping the systemd watchdog if it's enabled
+	setSystemd(&opts)
+
+	if *allIPs && len(servers) > 0 {
+		colorRed("--all-ips cannot be used with --listen or --grpc.")
+		usage()
 	}
-}
 
-// newNetworkInterface uses the 1st ip address of the interface
-// if any err occurs it calls `tcpStats.printer.printError` and exits with statuscode 1.
-// or return `networkInterface`
-func newNetworkInterface(tcpStats *stats, netInterface string) networkInterface {
-	var interfaceAddress net.IP
+	if *allIPs && opts.SRV != "" {
+		colorRed("--all-ips cannot be used with --srv.")
+		usage()
+	}
 
-	// if netinterface is the addres `interfaceAddress` var will not be `nil`
-	interfaceAddress = net.ParseIP(netInterface)
+	if *fastestIP && *allIPs {
+		colorRed("--fastest-ip cannot be used with --all-ips.")
+		usage()
+	}
 
-	if interfaceAddress == nil {
-		ief, err := net.InterfaceByName(netInterface)
-		if err != nil {
-			tcpStats.printer.printError("Interface %s not found", netInterface)
-			os.Exit(1)
-		}
+	if *fastestIP && opts.SRV != "" {
+		colorRed("--fastest-ip cannot be used with --srv.")
+		usage()
+	}
 
-		addrs, err := ief.Addrs()
-		if err != nil {
-			tcpStats.printer.printError("Unable to get Interface addresses")
-			os.Exit(1)
-		}
+	// the selected address is pinned, the hostname isn't resolved again
+	if *fastestIP && (opts.RetryHostnameLookupAfter > 0 || opts.Failover > 0 ||
+		opts.ResolveInterval > 0 || opts.HonorTTL || opts.ResolveEveryProbe) {
+		colorRed("--fastest-ip cannot be used with -r, --failover, --resolve-interval, --honor-ttl or --resolve-every-probe.")
+		usage()
+	}
 
-		// Iterating through the available addresses to identify valid IP configurations
-		for _, addr := range addrs {
-			if ip := addr.(*net.IPNet).IP; ip != nil {
-				// netip.Addr
-				nipAddr, err := netip.ParseAddr(ip.String())
-				if err != nil {
-					continue
-				}
+	if len(ports) > 0 && len(servers) > 0 {
+		colorRed("-p cannot be used with --listen or --grpc.")
+		usage()
+	}
 
-				if nipAddr.Is4() && !tcpStats.userInput.useIPv6 {
-					interfaceAddress = ip
-					break
-				} else if nipAddr.Is6() && !tcpStats.userInput.useIPv4 {
-					if nipAddr.IsLinkLocalUnicast() {
-						continue
-					}
-					interfaceAddress = ip
-					break
-				}
-			}
-		}
+	if opts.NotifyDegraded && opts.RTTThreshold == 0 {
+		colorRed("--degraded-notify cannot be used without --rtt-threshold.")
+		usage()
+	}
 
-		if interfaceAddress == nil {
-			tcpStats.printer.printError("Unable to get Interface's IP Address")
-			os.Exit(1)
-		}
+	if (opts.NotifyOutliers || opts.OutlierWindow > 0) && opts.OutlierStdDevs == 0 {
+		colorRed("--outliers-window and --outliers-notify cannot be used without --outliers.")
+		usage()
 	}
 
-	// Initializing a networkInterface struct and setting the 'use' field to true
-	ni := networkInterface{
-		use: true,
+	if opts.NotifyTrend && opts.TrendWindow == 0 {
+		colorRed("--trend-notify cannot be used without --trend.")
+		usage()
 	}
 
-	// remote address
-	ni.raddr = &net.TCPAddr{
-		IP:   net.ParseIP(tcpStats.userInput.ip.String()),
-		Port: int(tcpStats.userInput.port),
+	// the check doesn't send any probe
+	if !*check {
+		setFastestIP(&opts, fastestIP)
 	}
 
-	// local address
-	laddr := &net.TCPAddr{
-		IP: interfaceAddress,
+	if *check {
+		runCheck(opts, servers, *allIPs, ports, outputName, *samplesFile)
 	}
 
-	ni.dialer = net.Dialer{
-		LocalAddr: laddr,
-		Timeout:   tcpStats.userInput.timeout, // Set the timeout duration
+	if g := setGraph(&opts, graph); g != nil {
+		atExit = append(atExit, func() {
+			for _, line := range g.lines() {
+				opts.Printer.PrintInfo("%s", line)
+			}
+		})
 	}
 
-	return ni
+	return opts, servers, *allIPs, ports, atExit
 }
 
-// checkLatestVersion checks for updates and print a message
-func checkLatestVersion(p printer) {
-	c := github.NewClient(nil)
+func setResolver(opts *tcping.Options, dnsServer, dohURL, dotServer *string) {
+	resolvers := 0
+	for _, s := range []*string{dnsServer, dohURL, dotServer} {
+		if *s != "" {
+			resolvers++
+		}
+	}
+	if resolvers > 1 {
+		colorRed("Only one of --dns, --doh and --dot can be used.")
+		usage()
+	}
+
+	var (
+		resolver *net.Resolver
+		name     string
+		err      error
+	)
+
+	switch {
+	case *dnsServer != "":
+		resolver, err = newDNSResolver(*dnsServer)
+		name = "the DNS server " + *dnsServer
+	case *dohURL != "":
+		resolver, err = newDoHResolver(*dohURL)
+		name = "the DoH server " + *dohURL
+	case *dotServer != "":
+		resolver, err = newDoTResolver(*dotServer)
+		name = "the DoT server " + *dotServer
+	default:
+		return
+	}
 
-	/* unauthenticated requests from the same IP are limited to 60 per hour. */
-	latestRelease, _, err := c.Repositories.GetLatestRelease(context.Background(), owner, repo)
 	if err != nil {
-		p.printError("Failed to check for updates %s", err.Error())
+		opts.Printer.PrintError("Invalid DNS server: %s", err)
 		os.Exit(1)
 	}
+	opts.Resolver = resolver
 
-	reg := `^v?(\d+\.\d+\.\d+)$`
-	latestTagName := latestRelease.GetTagName()
-	latestVersion := regexp.MustCompile(reg).FindStringSubmatch(latestTagName)
-
-	if len(latestVersion) == 0 {
-		p.printError("Failed to check for updates. The version name does not match the rule: %s", latestTagName)
-		os.Exit(1)
+	if opts.SRV != "" {
+		opts.Printer.PrintInfo("Looking up %s with %s", opts.SRV, name)
+		return
 	}
 
-	if latestVersion[1] != version {
-		p.printInfo("Found newer version %s", latestVersion[1])
-		p.printInfo("Please update TCPING from the URL below:")
-		p.printInfo("https://github.com/%s/%s/releases/tag/%s",
-			owner, repo, latestTagName)
-	} else {
-		p.printInfo("Newer version not found. %s is the latest version.",
-			version)
+	// neither IP addresses nor forced hostnames are resolved
+	if _, forced := opts.Hosts[strings.ToLower(opts.Hostname)]; !forced && net.ParseIP(opts.Hostname) == nil {
+		opts.Printer.PrintInfo("Resolving %s with %s", opts.Hostname, name)
 	}
-	os.Exit(0)
 }
 
-// selectResolvedIP returns a single IPv4 or IPv6 address from the net.IP slice of resolved addresses
-func selectResolvedIP(tcpStats *stats, ipAddrs []netip.Addr) netip.Addr {
-	var index int
-	var ipList []netip.Addr
-	var ip netip.Addr
-
-	switch {
-	case tcpStats.userInput.useIPv4:
-		for _, ip := range ipAddrs {
-			if ip.Is4() {
-				ipList = append(ipList, ip)
-			}
-		}
-
-		if len(ipList) == 0 {
-			tcpStats.printer.printError("Failed to find IPv4 address for %s", tcpStats.userInput.hostname)
+func setNotifiers(opts *tcping.Options, desktopNotify *bool, onDown, onUp, webhookURL *string, webhookStats *bool, configPath *string) {
+	if *desktopNotify {
+		n, err := newDesktopNotifier()
+		if err != nil {
+			opts.Printer.PrintError("Unable to enable desktop notifications: %s", err)
 			os.Exit(1)
 		}
+		opts.Notifiers = append(opts.Notifiers, n)
+	}
 
-		if len(ipList) > 1 {
-			index = rand.Intn(len(ipList))
-		} else {
-			index = 0
-		}
-
-		ip, _ = netip.ParseAddr(ipList[index].Unmap().String())
+	if *onDown != "" || *onUp != "" {
+		opts.Notifiers = append(opts.Notifiers, &commandNotifier{
+			onDown: *onDown,
+			onUp:   *onUp,
+		})
+	}
 
-	case tcpStats.userInput.useIPv6:
-		for _, ip := range ipAddrs {
-			if ip.Is6() {
-				ipList = append(ipList, ip)
-			}
-		}
+	if *webhookStats && *webhookURL == "" {
+		colorRed("--webhook-stats has no effect without the --webhook flag.")
+		usage()
+	}
 
-		if len(ipList) == 0 {
-			tcpStats.printer.printError("Failed to find IPv6 address for %s", tcpStats.userInput.hostname)
+	if *webhookURL != "" {
+		u, err := url.Parse(*webhookURL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+			opts.Printer.PrintError("Invalid webhook URL: %s", *webhookURL)
 			os.Exit(1)
 		}
+		opts.Notifiers = append(opts.Notifiers, newWebhookNotifier(*webhookURL, *webhookStats))
+	}
 
-		if len(ipList) > 1 {
-			index = rand.Intn(len(ipList))
-		} else {
-			index = 0
-		}
+	if *configPath == "" {
+		return
+	}
 
-		ip, _ = netip.ParseAddr(ipList[index].Unmap().String())
+	cfg, err := loadConfig(*configPath)
+	if err != nil {
+		opts.Printer.PrintError("Invalid configuration file: %s", err)
+		os.Exit(1)
+	}
 
-	default:
-		if len(ipAddrs) > 1 {
-			index = rand.Intn(len(ipAddrs))
-		} else {
-			index = 0
-		}
+	notifiers := newConfigNotifiers(cfg.Notifiers)
+	opts.Notifiers = append(opts.Notifiers, notifiers)
+	reloadOnHangup(*configPath, opts.Printer, func(cfg config) {
+		notifiers.reload(cfg.Notifiers)
+	})
+}
 
-		ip, _ = netip.ParseAddr(ipAddrs[index].Unmap().String())
+// setLogger logs the decisions of the pinger on stderr, separately from
+// the output of the probes, and their details as well with --debug.
+func setLogger(opts *tcping.Options, verbose, debug *bool) {
+	level := slog.LevelInfo
+	switch {
+	case *debug:
+		level = slog.LevelDebug
+	case !*verbose:
+		return
 	}
 
-	return ip
+	opts.Logger = slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: level}))
 }
 
-// resolveHostname handles hostname resolution with a timeout value of a second
-func resolveHostname(tcpStats *stats) netip.Addr {
-	ip, err := netip.ParseAddr(tcpStats.userInput.hostname)
-	if err == nil {
-		return ip
+func setPushgateway(opts *tcping.Options, gatewayURL, job *string) {
+	if *gatewayURL == "" {
+		return
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), dnsTimeout)
-	defer cancel()
-
-	ipAddrs, err := net.DefaultResolver.LookupNetIP(ctx, "ip", tcpStats.userInput.hostname)
+	u, err := url.Parse(*gatewayURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+		opts.Printer.PrintError("Invalid Pushgateway URL: %s", *gatewayURL)
+		os.Exit(1)
+	}
 
-	// Prevent tcping to exit if it has been running for a while
-	if err != nil && (tcpStats.totalSuccessfulProbes != 0 || tcpStats.totalUnsuccessfulProbes != 0) {
-		return tcpStats.userInput.ip
-	} else if err != nil {
-		tcpStats.printer.printError("Failed to resolve %s: %s", tcpStats.userInput.hostname, err)
+	if *job == "" {
+		opts.Printer.PrintError("The Pushgateway job can't be empty")
 		os.Exit(1)
 	}
 
-	return selectResolvedIP(tcpStats, ipAddrs)
+	opts.Notifiers = append(opts.Notifiers, newPushgatewayNotifier(*gatewayURL, *job))
 }
 
-// retryResolveHostname retries resolving a hostname after certain number of failures
-func retryResolveHostname(tcpStats *stats) {
-	if tcpStats.ongoingUnsuccessfulProbes >= tcpStats.userInput.retryHostnameLookupAfter {
-		tcpStats.printer.printRetryingToResolve(tcpStats.userInput.hostname)
-		tcpStats.userInput.ip = resolveHostname(tcpStats)
-		tcpStats.ongoingUnsuccessfulProbes = 0
-		tcpStats.retriedHostnameLookups += 1
-
-		// At this point hostnameChanges should have len > 0, but just in case
-		if len(tcpStats.hostnameChanges) == 0 {
-			return
-		}
-
-		lastAddr := tcpStats.hostnameChanges[len(tcpStats.hostnameChanges)-1].Addr
-		if lastAddr != tcpStats.userInput.ip {
-			tcpStats.hostnameChanges = append(tcpStats.hostnameChanges, hostnameChange{
-				Addr: tcpStats.userInput.ip,
-				When: time.Now(),
-			})
+// setSamplesFile writes every probe to the CSV file at the given path,
+// gzipped with --compress and synced every --fsync. The samples already
+// written are kept if tcping is killed, unless they're compressed and
+// weren't synced yet.
+func setSamplesFile(opts *tcping.Options, path *string, compress *bool, fsync *time.Duration) *samplesFile {
+	if *path == "" {
+		if *compress || *fsync != 0 {
+			colorRed("--compress and --fsync cannot be used without --samples-file.")
+			usage()
 		}
+		return nil
 	}
-}
 
-// newLongestTime creates LongestTime structure
-func newLongestTime(startTime time.Time, duration time.Duration) longestTime {
-	return longestTime{
-		start:    startTime,
-		end:      startTime.Add(duration),
-		duration: duration,
+	sf, err := newSamplesFile(*path, *compress, *fsync)
+	if err != nil {
+		opts.Printer.PrintError("Unable to create the samples file: %s", err)
+		os.Exit(1)
 	}
-}
 
-// calcMinAvgMaxRttTime calculates min, avg and max RTT values
-func calcMinAvgMaxRttTime(timeArr []float32) rttResult {
-	var sum float32
-	var result rttResult
+	addHooks(opts, tcping.Hooks{OnProbe: sf.record})
+	return sf
+}
 
-	arrLen := len(timeArr)
-	// rttResults.min = ^uint(0.0)
-	if arrLen > 0 {
-		result.min = timeArr[0]
+// setPcapFile writes the segments of the probes to the pcapng file at the
+// given path. It returns the function closing the file, nil without --pcap.
+func setPcapFile(opts *tcping.Options, path *string) func() {
+	if *path == "" {
+		return nil
 	}
 
-	for i := 0; i < arrLen; i++ {
-		sum += timeArr[i]
-
-		if timeArr[i] > result.max {
-			result.max = timeArr[i]
-		}
-
-		if timeArr[i] < result.min {
-			result.min = timeArr[i]
+	f, err := os.Create(*path)
+	if err == nil {
+		if opts.Pcap, err = tcping.NewPcapWriter(f); err != nil {
+			f.Close()
 		}
 	}
-
-	if arrLen > 0 {
-		result.hasResults = true
-		result.average = sum / float32(arrLen)
+	if err != nil {
+		opts.Printer.PrintError("Unable to create the pcap file: %s", err)
+		os.Exit(1)
 	}
 
-	return result
-}
-
-// calcLongestUptime calculates the longest uptime and sets it to tcpStats.
-func calcLongestUptime(tcpStats *stats, duration time.Duration) {
-	if tcpStats.startOfUptime.IsZero() || duration == 0 {
-		return
+	w := opts.Pcap
+	return func() {
+		if err := w.Err(); err != nil {
+			opts.Printer.PrintError("Failed to write the pcap file: %s", err)
+		}
+		if err := f.Close(); err != nil {
+			opts.Printer.PrintError("Unable to close the pcap file: %s", err)
+		}
 	}
+}
 
-	longestUptime := newLongestTime(tcpStats.startOfUptime, duration)
+func setServers(opts *tcping.Options, listenAddr, grpcAddr *string) []server {
+	var servers []server
 
-	// It means it is the first time we're calling this function
-	if tcpStats.longestUptime.end.IsZero() {
-		tcpStats.longestUptime = longestUptime
-		return
+	if *listenAddr != "" {
+		api := newAPIServer(*listenAddr)
+		addHooks(opts, tcping.Hooks{OnProbe: api.recordProbe})
+		servers = append(servers, api)
 	}
 
-	if longestUptime.duration >= tcpStats.longestUptime.duration {
-		tcpStats.longestUptime = longestUptime
+	if *grpcAddr != "" {
+		g := newGRPCServer(*grpcAddr)
+		addHooks(opts, tcping.Hooks{
+			OnProbe:       g.publishProbe,
+			OnStateChange: g.publishStateChange,
+		})
+		servers = append(servers, g)
 	}
+
+	return servers
 }
 
-// calcLongestDowntime calculates the longest downtime and sets it to tcpStats.
-func calcLongestDowntime(tcpStats *stats, duration time.Duration) {
-	if tcpStats.startOfDowntime.IsZero() || duration == 0 {
-		return
+// addHooks chains the given hooks after the ones already set.
+func addHooks(opts *tcping.Options, hooks tcping.Hooks) {
+	if prev, next := opts.Hooks.OnProbe, hooks.OnProbe; prev != nil && next != nil {
+		opts.Hooks.OnProbe = func(r tcping.Result) {
+			prev(r)
+			next(r)
+		}
+	} else if next != nil {
+		opts.Hooks.OnProbe = next
 	}
 
-	longestDowntime := newLongestTime(tcpStats.startOfDowntime, duration)
-
-	// It means it is the first time we're calling this function
-	if tcpStats.longestDowntime.end.IsZero() {
-		tcpStats.longestDowntime = longestDowntime
-		return
+	if prev, next := opts.Hooks.OnStateChange, hooks.OnStateChange; prev != nil && next != nil {
+		opts.Hooks.OnStateChange = func(change tcping.StateChange) {
+			prev(change)
+			next(change)
+		}
+	} else if next != nil {
+		opts.Hooks.OnStateChange = next
 	}
 
-	if longestDowntime.duration >= tcpStats.longestDowntime.duration {
-		tcpStats.longestDowntime = longestDowntime
+	if prev, next := opts.Hooks.OnStatistics, hooks.OnStatistics; prev != nil && next != nil {
+		opts.Hooks.OnStatistics = func(s tcping.Statistics) {
+			prev(s)
+			next(s)
+		}
+	} else if next != nil {
+		opts.Hooks.OnStatistics = next
 	}
 }
 
-// nanoToMillisecond returns an amount of milliseconds from nanoseconds.
-// Using duration.Milliseconds() is not an option, because it drops
-// decimal points, returning an int.
-func nanoToMillisecond(nano int64) float32 {
-	return float32(nano) / float32(time.Millisecond)
-}
+/*
+permuteArgs permute args for flag parsing stops just before the first non-flag argument.
 
-// secondsToDuration returns the corresonding duration from seconds expressed with a float.
-func secondsToDuration(seconds float64) time.Duration {
-	return time.Duration(1000*seconds) * time.Millisecond
-}
+see: https://pkg.go.dev/flag
+*/
+func permuteArgs(args []string) {
+	var flagArgs []string
+	var nonFlagArgs []string
 
-// maxDuration is the implementation of the math.Max function for time.Duration types.
-// returns the longest duration of x or y.
-func maxDuration(x, y time.Duration) time.Duration {
-	if x > y {
-		return x
+	for i := 0; i < len(args); i++ {
+		v := args[i]
+		if v[0] == '-' {
+			var optionName string
+			if v[1] == '-' {
+				optionName = v[2:]
+			} else {
+				optionName = v[1:]
+			}
+			switch optionName {
+			case "c":
+				fallthrough
+			case "t":
+				fallthrough
+			case "db":
+				fallthrough
+			case "srv":
+				fallthrough
+			case "dns":
+				fallthrough
+			case "doh":
+				fallthrough
+			case "dot":
+				fallthrough
+			case "resolve":
+				fallthrough
+			case "resolve-interval":
+				fallthrough
+			case "hosts-file":
+				fallthrough
+			case "services":
+				fallthrough
+			case "default-port":
+				fallthrough
+			case "ip-select":
+				fallthrough
+			case "p":
+				fallthrough
+			case "send":
+				fallthrough
+			case "payload-size":
+				fallthrough
+			case "probe":
+				fallthrough
+			case "check-cmd":
+				fallthrough
+			case "close-wait":
+				fallthrough
+			case "so-linger":
+				fallthrough
+			case "tcp-keepalive":
+				fallthrough
+			case "fwmark":
+				fallthrough
+			case "bind-device":
+				fallthrough
+			case "netns":
+				fallthrough
+			case "jump":
+				fallthrough
+			case "pcap":
+				fallthrough
+			case "cert-warn-days":
+				fallthrough
+			case "sni":
+				fallthrough
+			case "ca-file":
+				fallthrough
+			case "cert":
+				fallthrough
+			case "key":
+				fallthrough
+			case "probe-service":
+				fallthrough
+			case "http-method":
+				fallthrough
+			case "query":
+				fallthrough
+			case "query-type":
+				fallthrough
+			case "http-path":
+				fallthrough
+			case "header":
+				fallthrough
+			case "lookup":
+				fallthrough
+			case "retries":
+				fallthrough
+			case "burst":
+				fallthrough
+			case "warmup":
+				fallthrough
+			case "outliers":
+				fallthrough
+			case "outliers-window":
+				fallthrough
+			case "rtt-threshold":
+				fallthrough
+			case "trend":
+				fallthrough
+			case "availability-window":
+				fallthrough
+			case "expect-status":
+				fallthrough
+			case "expect-body":
+				fallthrough
+			case "expect":
+				fallthrough
+			case "output":
+				fallthrough
+			case "bell":
+				fallthrough
+			case "on-down":
+				fallthrough
+			case "on-up":
+				fallthrough
+			case "webhook":
+				fallthrough
+			case "config":
+				fallthrough
+			case "listen":
+				fallthrough
+			case "grpc":
+				fallthrough
+			case "samples-file":
+				fallthrough
+			case "fsync":
+				fallthrough
+			case "pushgateway":
+				fallthrough
+			case "pushgateway-job":
+				fallthrough
+			case "I":
+				fallthrough
+			case "i":
+				fallthrough
+			case "failover":
+				fallthrough
+			case "r":
+				/* out of index */
+				if len(args) <= i+1 {
+					usage()
+				}
+				/* the value is taken as is, it can be negative, e.g. --tcp-keepalive -1s */
+				flagArgs = append(flagArgs, args[i:i+2]...)
+				i++
+			default:
+				flagArgs = append(flagArgs, args[i])
+			}
+		} else {
+			nonFlagArgs = append(nonFlagArgs, args[i])
+		}
 	}
-	return y
-}
-
-// handleConnError processes failed probes
-func (tcpStats *stats) handleConnError(connTime time.Time, elapsed time.Duration) {
-	if !tcpStats.wasDown {
-		tcpStats.startOfDowntime = connTime
-		uptime := tcpStats.startOfDowntime.Sub(tcpStats.startOfUptime)
-		calcLongestUptime(tcpStats, uptime)
-		tcpStats.startOfUptime = time.Time{}
-		tcpStats.wasDown = true
-	}
-
-	tcpStats.totalDowntime += elapsed
-	tcpStats.lastUnsuccessfulProbe = connTime
-	tcpStats.totalUnsuccessfulProbes += 1
-	tcpStats.ongoingUnsuccessfulProbes += 1
-
-	tcpStats.printer.printProbeFail(
-		tcpStats.userInput.hostname,
-		tcpStats.userInput.ip.String(),
-		tcpStats.userInput.port,
-		tcpStats.ongoingUnsuccessfulProbes,
-	)
-}
+	permutedArgs := append(flagArgs, nonFlagArgs...)
 
-// handleConnSuccess processes successful probes
-func (tcpStats *stats) handleConnSuccess(rtt float32, connTime time.Time, elapsed time.Duration) {
-	if tcpStats.wasDown {
-		tcpStats.startOfUptime = connTime
-		downtime := tcpStats.startOfUptime.Sub(tcpStats.startOfDowntime)
-		calcLongestDowntime(tcpStats, downtime)
-		tcpStats.printer.printTotalDownTime(downtime)
-		tcpStats.startOfDowntime = time.Time{}
-		tcpStats.wasDown = false
-		tcpStats.ongoingUnsuccessfulProbes = 0
-		tcpStats.ongoingSuccessfulProbes = 0
-	}
-
-	if tcpStats.startOfUptime.IsZero() {
-		tcpStats.startOfUptime = connTime
-	}
-
-	tcpStats.totalUptime += elapsed
-	tcpStats.lastSuccessfulProbe = connTime
-	tcpStats.totalSuccessfulProbes += 1
-	tcpStats.ongoingSuccessfulProbes += 1
-	tcpStats.rtt = append(tcpStats.rtt, rtt)
-
-	tcpStats.printer.printProbeSuccess(
-		tcpStats.userInput.hostname,
-		tcpStats.userInput.ip.String(),
-		tcpStats.userInput.port,
-		tcpStats.ongoingSuccessfulProbes,
-		rtt,
-	)
+	/* replace args */
+	for i := 0; i < len(args); i++ {
+		args[i] = permutedArgs[i]
+	}
 }
 
-// tcping pings a host, TCP style
-func tcping(tcpStats *stats) {
-	var err error
-	var conn net.Conn
-	connStart := time.Now()
+// checkLatestVersion checks for updates and print a message
+func checkLatestVersion(p tcping.Printer) {
+	c := github.NewClient(nil)
 
-	if tcpStats.userInput.networkInterface.use {
-		// dialer already contains the timeout value
-		conn, err = tcpStats.userInput.networkInterface.dialer.Dial("tcp", tcpStats.userInput.networkInterface.raddr.String())
-	} else {
-		IPAndPort := netip.AddrPortFrom(tcpStats.userInput.ip, tcpStats.userInput.port)
-		conn, err = net.DialTimeout("tcp", IPAndPort.String(), tcpStats.userInput.timeout)
+	/* unauthenticated requests from the same IP are limited to 60 per hour. */
+	latestRelease, _, err := c.Repositories.GetLatestRelease(context.Background(), owner, repo)
+	if err != nil {
+		p.PrintError("Failed to check for updates %s", err.Error())
+		os.Exit(1)
 	}
 
-	connDuration := time.Since(connStart)
-	rtt := nanoToMillisecond(connDuration.Nanoseconds())
+	reg := `^v?(\d+\.\d+\.\d+)$`
+	latestTagName := latestRelease.GetTagName()
+	latestVersion := regexp.MustCompile(reg).FindStringSubmatch(latestTagName)
 
-	elapsed := maxDuration(connDuration, tcpStats.userInput.intervalBetweenProbes)
+	if len(latestVersion) == 0 {
+		p.PrintError("Failed to check for updates. The version name does not match the rule: %s", latestTagName)
+		os.Exit(1)
+	}
 
-	if err != nil {
-		tcpStats.handleConnError(connStart, elapsed)
+	if latestVersion[1] != tcping.Version {
+		p.PrintInfo("Found newer version %s", latestVersion[1])
+		p.PrintInfo("Please update TCPING from the URL below:")
+		p.PrintInfo("https://github.com/%s/%s/releases/tag/%s",
+			owner, repo, latestTagName)
 	} else {
-		tcpStats.handleConnSuccess(rtt, connStart, elapsed)
-		conn.Close()
+		p.PrintInfo("Newer version not found. %s is the latest version.",
+			tcping.Version)
 	}
-	<-tcpStats.ticker.C
+	os.Exit(0)
+}
 
+// secondsToDuration returns the corresonding duration from seconds expressed with a float.
+func secondsToDuration(seconds float64) time.Duration {
+	return time.Duration(1000*seconds) * time.Millisecond
 }
 
 func main() {
-	tcpStats := &stats{}
-	processUserInput(tcpStats)
-	tcpStats.ticker = time.NewTicker(tcpStats.userInput.intervalBetweenProbes)
-	defer tcpStats.ticker.Stop()
-
-	signalHandler(tcpStats)
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "daemon":
+			runDaemon(os.Args[2:])
+			return
+		case "ctl":
+			runCtl(os.Args[2:])
+			return
+		case "trace":
+			runTrace(os.Args[2:])
+			return
+		case "analyze":
+			runAnalyze(os.Args[2:])
+			return
+		case "chart":
+			runChart(os.Args[2:])
+			return
+		}
+	}
 
-	tcpStats.printer.printStart(tcpStats.userInput.hostname, tcpStats.userInput.port)
+	opts, servers, allIPs, ports, atExit := processUserInput()
 
-	stdinChan := make(chan bool)
-	go monitorStdin(stdinChan)
+	pingers, err := newPingers(opts, allIPs, ports)
+	if err != nil {
+		opts.Printer.PrintError("%s", err)
+		os.Exit(1)
+	}
 
-	var probeCount uint = 0
-	for {
-		if tcpStats.userInput.shouldRetryResolve {
-			retryResolveHostname(tcpStats)
+	for _, srv := range servers {
+		if err := srv.listen(pingers[0].Statistics); err != nil {
+			opts.Printer.PrintError("Unable to start the server: %s", err)
+			os.Exit(1)
 		}
+	}
 
-		tcping(tcpStats)
+	// the first SIGINT or SIGTERM stops the pingers gracefully,
+	// the next one kills tcping
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	go monitorStdin(pingers)
+	monitorSignals(pingers)
 
-		select {
-		case pressedEnter := <-stdinChan:
-			if pressedEnter {
-				tcpStats.printStats()
-			}
-		default:
-		}
+	sdNotify("READY=1")
 
-		if tcpStats.userInput.probesBeforeQuit != 0 {
-			probeCount++
-			if probeCount == tcpStats.userInput.probesBeforeQuit {
-				shutdown(tcpStats)
-			}
-		}
-	}
+	runPingers(ctx, pingers)
+	stop()
+	os.Exit(shutdown(opts.Printer, pingers, atExit))
 }
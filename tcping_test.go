@@ -1,104 +1,15 @@
 package main
 
 import (
-	"net"
-	"net/netip"
+	"os"
+	"path/filepath"
 	"testing"
 	"time"
 
+	"github.com/pouriyajamshidi/tcping/v2/pkg/tcping"
 	"github.com/stretchr/testify/assert"
 )
 
-// createTestStats should be used to create new stats structs.
-// it uses "127.0.0.1:12345" as default values, because
-// [testServerListen] use the same values.
-// It'll call t.Errorf if netip.ParseAddr has failed.
-func createTestStats(t *testing.T) *stats {
-	addr, err := netip.ParseAddr("127.0.0.1")
-	s := stats{
-		printer: &dummyPrinter{},
-		userInput: userInput{
-			ip:                    addr,
-			port:                  12345,
-			intervalBetweenProbes: time.Second,
-			timeout:               time.Second,
-		},
-		ticker: time.NewTicker(time.Second),
-	}
-	if err != nil {
-		t.Errorf("ip parse: %v", err)
-	}
-
-	return &s
-}
-
-// testServerListen creates a new listener
-// on port 12345 and automatically starts it.
-//
-// Use t.Cleanup with srv.Close() to close it after
-// the test, so that other tests are not affected.
-//
-// It could fail if net.Listen or Accept has failed.
-func testServerListen(t *testing.T) net.Listener {
-	srv, err := net.Listen("tcp", ":12345")
-	if err != nil {
-		t.Errorf("test server: %v", err)
-	}
-
-	go func() {
-		for {
-			c, err := srv.Accept()
-			if err != nil {
-				return
-			}
-
-			c.Close()
-		}
-	}()
-
-	return srv
-}
-
-func TestProbeSuccess(t *testing.T) {
-	stats := createTestStats(t)
-	stats.ticker = time.NewTicker(time.Nanosecond)
-	srv := testServerListen(t)
-	t.Cleanup(func() {
-		if err := srv.Close(); err != nil {
-			t.Errorf("srv close: %v", err)
-		}
-	})
-
-	expectedSuccessful := 100
-
-	for i := 0; i < expectedSuccessful; i++ {
-		tcping(stats)
-	}
-
-	assert.Equal(t, stats.totalSuccessfulProbes, uint(expectedSuccessful))
-	assert.Equal(t, stats.ongoingSuccessfulProbes, uint(expectedSuccessful))
-
-	// TODO: change when custom ping intervals will be introduced
-	assert.Equal(t, stats.totalUptime, 100*time.Second)
-}
-
-func TestProbeFail(t *testing.T) {
-	stats := createTestStats(t)
-	stats.ticker = time.NewTicker(time.Nanosecond)
-
-	expectedFailed := 100
-
-	for i := 0; i < expectedFailed; i++ {
-		tcping(stats)
-	}
-
-	assert.Equal(t, stats.totalUnsuccessfulProbes, uint(expectedFailed))
-	assert.Equal(t, stats.ongoingUnsuccessfulProbes, uint(expectedFailed))
-
-	// TODO: change when custom ping intervals will be introduced
-	assert.Equal(t, stats.totalDowntime, 100*time.Second)
-}
-
 func TestPermuteArgs(t *testing.T) {
 	type args struct {
 		args []string
@@ -123,6 +34,16 @@ func TestPermuteArgs(t *testing.T) {
 			args{args: []string{"-u"}},
 			[]string{"-u"},
 		},
+		{
+			"bell event after host/ip",
+			args{args: []string{"127.0.0.1", "8080", "--bell", "change"}},
+			[]string{"--bell", "change", "127.0.0.1", "8080"},
+		},
+		{
+			"negative option value after host/ip",
+			args{args: []string{"127.0.0.1", "8080", "--tcp-keepalive", "-1s"}},
+			[]string{"--tcp-keepalive", "-1s", "127.0.0.1", "8080"},
+		},
 		/**
 		 * cases in which the value of the option does not exist are not listed.
 		 * they call directly usage() and exit with code 1.
@@ -136,74 +57,23 @@ func TestPermuteArgs(t *testing.T) {
 	}
 }
 
-func TestNanoToMilliseconds(t *testing.T) {
-	t.Parallel()
-	tests := []struct {
-		d    time.Duration
-		want float32
-	}{
-		{d: time.Millisecond, want: 1},
-		{d: 100*time.Millisecond + 123*time.Nanosecond, want: 100.000123},
-		{d: time.Second, want: 1000},
-		{d: time.Second + 100*time.Nanosecond, want: 1000.000123},
-	}
-	for _, tt := range tests {
-		tt := tt
-		t.Run(tt.d.String(), func(t *testing.T) {
-			t.Parallel()
-			got := nanoToMillisecond(tt.d.Nanoseconds())
-			assert.Equal(t, tt.want, got)
-		})
-	}
-}
-
-func TestSelectResolvedIPv4(t *testing.T) {
-	userInputV4 := userInput{
-		useIPv4: true,
-	}
-
-	stats := createTestStats(t)
-	stats.userInput = userInputV4
-
-	var (
-		ip1 = netip.MustParseAddr("172.20.10.238")
-		ip2 = netip.MustParseAddr("8.8.8.8")
-	)
+func TestAddHooks(t *testing.T) {
+	var calls []string
+	var opts tcping.Options
 
-	t.Run("IPv4 Selection", func(t *testing.T) {
-		actual := selectResolvedIP(stats, []netip.Addr{ip1, ip2})
-
-		if !actual.IsValid() {
-			t.Errorf("Expected an IP but got invalid address")
-		}
-		if actual != ip1 && actual != ip2 {
-			t.Errorf("Expected an IP but got invalid address")
-		}
+	addHooks(&opts, tcping.Hooks{
+		OnProbe: func(_ tcping.Result) { calls = append(calls, "first") },
+	})
+	addHooks(&opts, tcping.Hooks{
+		OnProbe:       func(_ tcping.Result) { calls = append(calls, "second") },
+		OnStateChange: func(_ tcping.StateChange) { calls = append(calls, "change") },
 	})
-}
-
-func TestSelectResolvedIPv6(t *testing.T) {
-	userInputV6 := userInput{
-		useIPv6: true,
-	}
-
-	stats := createTestStats(t)
-	stats.userInput = userInputV6
 
-	var (
-		ip1 = netip.MustParseAddr("2001:0db8:85a3:0000:0000:8a2e:0370:7334")
-		ip2 = netip.MustParseAddr("2001:4860:4860::8888")
-	)
+	opts.Hooks.OnProbe(tcping.Result{})
+	opts.Hooks.OnStateChange(tcping.StateChange{})
 
-	t.Run("IPv6 Selection", func(t *testing.T) {
-		actual := selectResolvedIP(stats, []netip.Addr{ip1, ip2})
-		if !actual.IsValid() {
-			t.Errorf("Expected an IP but got invalid address")
-		}
-		if actual != ip1 && actual != ip2 {
-			t.Errorf("Expected an IP but got invalid address")
-		}
-	})
+	assert.Equal(t, []string{"first", "second", "change"}, calls)
+	assert.Nil(t, opts.Hooks.OnStatistics)
 }
 
 func TestSecondsToDuration(t *testing.T) {
@@ -239,3 +109,27 @@ func TestSecondsToDuration(t *testing.T) {
 		})
 	}
 }
+
+func TestCheckSetPrinters(t *testing.T) {
+	dir := t.TempDir()
+	jsonPath := filepath.Join(dir, "tcping.jsonl")
+	journalPath := filepath.Join(dir, "tcping.log")
+
+	var opts tcping.Options
+	no := false
+	outputDb := ""
+	output := outputFlag{"json=" + jsonPath, "journal=" + journalPath}
+
+	name := checkSetPrinters(&opts, &no, &no, &no, &outputDb, output, []string{"localhost", "443"})
+	assert.Equal(t, "json to "+jsonPath+", journal to "+journalPath, name)
+
+	opts.Printer.PrintInfo("to %s", "both")
+
+	out, err := os.ReadFile(jsonPath)
+	assert.NoError(t, err)
+	assert.Contains(t, string(out), `"message":"to both"`)
+
+	out, err = os.ReadFile(journalPath)
+	assert.NoError(t, err)
+	assert.Equal(t, "<6>to both\n", string(out))
+}
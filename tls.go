@@ -0,0 +1,78 @@
+package main
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/pouriyajamshidi/tcping/v2/pkg/tcping"
+)
+
+// newTLSConfig returns the config of the TLS handshakes, with the server
+// name, the verification of the certificates and the client certificate
+// given by the user.
+func newTLSConfig(sni string, insecure bool, caFile, certFile, keyFile string) (*tls.Config, error) {
+	config := &tls.Config{
+		ServerName:         sni,
+		InsecureSkipVerify: insecure,
+	}
+
+	if caFile != "" {
+		pem, err := os.ReadFile(caFile)
+		if err != nil {
+			return nil, err
+		}
+
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates found in %s", caFile)
+		}
+		config.RootCAs = pool
+	}
+
+	if certFile != "" || keyFile != "" {
+		if certFile == "" || keyFile == "" {
+			return nil, errors.New("--cert and --key must be given together")
+		}
+
+		cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+		if err != nil {
+			return nil, err
+		}
+		config.Certificates = []tls.Certificate{cert}
+	}
+
+	return config, nil
+}
+
+// setTLS sets the TLS handshake performed after connecting and returns
+// the config the prober speaks its protocol with, nil for plaintext.
+func setTLS(opts *tcping.Options, useTLS, probeTLS *bool, certWarnDays *uint, sni *string, insecure *bool, caFile, certFile, keyFile *string) *tls.Config {
+	opts.CertWarning = time.Duration(*certWarnDays) * 24 * time.Hour
+
+	if !*useTLS && !*probeTLS {
+		if *sni != "" || *insecure || *caFile != "" || *certFile != "" || *keyFile != "" {
+			opts.Printer.PrintError("--sni, --insecure, --ca-file, --cert and --key can only be used with --tls or --probe-tls.")
+			os.Exit(1)
+		}
+		return nil
+	}
+
+	config, err := newTLSConfig(*sni, *insecure, *caFile, *certFile, *keyFile)
+	if err != nil {
+		opts.Printer.PrintError("Invalid TLS settings: %s", err)
+		os.Exit(1)
+	}
+
+	if *useTLS {
+		opts.TLSConfig = config
+	}
+	if !*probeTLS {
+		return nil
+	}
+
+	return config
+}
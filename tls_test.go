@@ -0,0 +1,85 @@
+package main
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewTLSConfig(t *testing.T) {
+	srv := httptest.NewTLSServer(http.NotFoundHandler())
+	defer srv.Close()
+
+	caFile := filepath.Join(t.TempDir(), "ca.pem")
+	err := os.WriteFile(caFile, pem.EncodeToMemory(&pem.Block{
+		Type:  "CERTIFICATE",
+		Bytes: srv.Certificate().Raw,
+	}), 0o600)
+	assert.NoError(t, err)
+
+	handshake := func(config *tls.Config) error {
+		conn, err := tls.Dial("tcp", srv.Listener.Addr().String(), config)
+		if err != nil {
+			return err
+		}
+		return conn.Close()
+	}
+
+	// the certificate of the test server is valid for example.com
+	config, err := newTLSConfig("example.com", false, caFile, "", "")
+	assert.NoError(t, err)
+	assert.NoError(t, handshake(config))
+
+	config, err = newTLSConfig("www.example.org", false, caFile, "", "")
+	assert.NoError(t, err)
+	assert.Error(t, handshake(config))
+
+	config, err = newTLSConfig("example.com", false, "", "", "")
+	assert.NoError(t, err)
+	assert.Error(t, handshake(config))
+
+	config, err = newTLSConfig("www.example.org", true, "", "", "")
+	assert.NoError(t, err)
+	assert.NoError(t, handshake(config))
+
+	_, err = newTLSConfig("", false, filepath.Join(t.TempDir(), "missing.pem"), "", "")
+	assert.Error(t, err)
+
+	notPEM := filepath.Join(t.TempDir(), "ca.txt")
+	assert.NoError(t, os.WriteFile(notPEM, []byte("not a certificate"), 0o600))
+	_, err = newTLSConfig("", false, notPEM, "", "")
+	assert.EqualError(t, err, "no certificates found in "+notPEM)
+}
+
+func TestNewTLSConfigClientCert(t *testing.T) {
+	srv := httptest.NewTLSServer(http.NotFoundHandler())
+	defer srv.Close()
+
+	// borrow the certificate of the test server
+	cert := srv.TLS.Certificates[0]
+	key, err := x509.MarshalPKCS8PrivateKey(cert.PrivateKey)
+	assert.NoError(t, err)
+
+	dir := t.TempDir()
+	certFile := filepath.Join(dir, "cert.pem")
+	keyFile := filepath.Join(dir, "key.pem")
+	assert.NoError(t, os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: cert.Certificate[0]}), 0o600))
+	assert.NoError(t, os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: key}), 0o600))
+
+	config, err := newTLSConfig("", false, "", certFile, keyFile)
+	assert.NoError(t, err)
+	assert.Len(t, config.Certificates, 1)
+
+	_, err = newTLSConfig("", false, "", certFile, "")
+	assert.EqualError(t, err, "--cert and --key must be given together")
+
+	_, err = newTLSConfig("", false, "", keyFile, certFile)
+	assert.Error(t, err)
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"flag"
+	"os"
+	"strconv"
+
+	"github.com/pouriyajamshidi/tcping/v2/pkg/tcping"
+)
+
+// runTrace implements the `tcping trace` subcommand.
+func runTrace(args []string) {
+	flags := flag.NewFlagSet("trace", flag.ExitOnError)
+	useIPv4 := flags.Bool("4", false, "only use IPv4.")
+	useIPv6 := flags.Bool("6", false, "only use IPv6.")
+	maxHops := flags.Int("max-hops", 30, "stop after <n> hops if the target wasn't reached.")
+	timeout := flags.Float64("t", 1, "time to wait for the answer of every hop, in seconds.")
+	outputJSON := flags.Bool("j", false, "output in JSON format.")
+	flags.Usage = func() {
+		colorRed("Usage: %s trace [-4|-6] [--max-hops <n>] [-t <seconds>] [-j] <hostname/ip> <port number>\n", os.Args[0])
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+
+	args = flags.Args()
+	if len(args) != 2 || *maxHops < 1 || (*useIPv4 && *useIPv6) {
+		flags.Usage()
+		os.Exit(1)
+	}
+
+	printer := tcping.NewPlainPrinter()
+	if *outputJSON {
+		printer = tcping.NewJSONPrinter(false)
+	}
+
+	port, err := strconv.ParseUint(args[1], 10, 16)
+	if err != nil || port == 0 {
+		printer.PrintError("Invalid port number: %s", args[1])
+		os.Exit(1)
+	}
+
+	hops, err := tcping.Trace(tcping.Options{
+		Printer:  printer,
+		Hostname: args[0],
+		Port:     uint16(port),
+		UseIPv4:  *useIPv4,
+		UseIPv6:  *useIPv6,
+		Timeout:  secondsToDuration(*timeout),
+	}, *maxHops)
+	if err != nil {
+		printer.PrintError("Unable to trace %s: %s", args[0], err)
+		os.Exit(1)
+	}
+
+	if last := hops[len(hops)-1]; !last.Reached {
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,179 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/pouriyajamshidi/tcping/v2/pkg/tcping"
+)
+
+const (
+	webhookTimeout  = 5 * time.Second
+	webhookAttempts = 3
+	// webhookBackoff is multiplied by the number of the attempt
+	// to get the time to wait before the next attempt.
+	webhookBackoff = time.Second
+)
+
+// webhookPayload is the JSON body sent to the webhook.
+type webhookPayload struct {
+	// Event is either "down", "up" or "statistics", or, with the notify
+	// flags, "degraded", "trend" or "outlier" when the target becomes degraded,
+	// its RTTs trend up or are outliers, and "degraded-end", "trend-end" or
+	// "outlier-end" once it's over, see stateChangeEvent.
+	Event     string    `json:"event"`
+	Message   string    `json:"message"`
+	Timestamp time.Time `json:"timestamp"`
+	Hostname  string    `json:"hostname"`
+	Addr      string    `json:"addr"`
+	Port      uint16    `json:"port"`
+	// Downtime in seconds, only set for the "up" and the "-end" events,
+	// the duration of the outage or of the degradation.
+	Downtime float64 `json:"downtime,omitempty"`
+	// Rtt in milliseconds of the probe that ended the outage or the degradation.
+	Rtt float32 `json:"rtt,omitempty"`
+	// Statistics are only set for the "statistics" event and
+	// follow the same format as the JSON output.
+	Statistics *tcping.JSONData `json:"statistics,omitempty"`
+}
+
+// webhookNotifier POSTs a JSON payload to an URL
+// whenever the target goes down or comes back up.
+type webhookNotifier struct {
+	client    *http.Client
+	url       string
+	pending   sync.WaitGroup
+	sendStats bool
+}
+
+func newWebhookNotifier(url string, sendStats bool) *webhookNotifier {
+	return &webhookNotifier{
+		client:    &http.Client{Timeout: webhookTimeout},
+		url:       url,
+		sendStats: sendStats,
+	}
+}
+
+// stateChangeEvent returns the name of the event of the state change,
+// so that the degradations can be told apart from the outages.
+func stateChangeEvent(change tcping.StateChange) string {
+	kind := incidentKind(change)
+	switch {
+	case kind == "" && change.Up:
+		return "up"
+	case kind == "":
+		return "down"
+	case change.Up:
+		return kind + "-end"
+	default:
+		return kind
+	}
+}
+
+func (n *webhookNotifier) Notify(change tcping.StateChange) {
+	payload := webhookPayload{
+		Event:     stateChangeEvent(change),
+		Message:   change.Summary(),
+		Timestamp: change.When,
+		Hostname:  change.Hostname,
+		Addr:      change.IP,
+		Port:      change.Port,
+	}
+
+	if change.Up {
+		payload.Downtime = change.Downtime.Seconds()
+		payload.Rtt = change.RTT
+	}
+
+	n.pending.Add(1)
+	go func() {
+		defer n.pending.Done()
+		n.send(payload)
+	}()
+}
+
+func (n *webhookNotifier) NotifyStatistics(s tcping.Statistics) {
+	n.pending.Wait()
+
+	if !n.sendStats {
+		return
+	}
+
+	data := tcping.NewStatisticsJSONData(s)
+	n.send(webhookPayload{
+		Event:      "statistics",
+		Message:    data.Message,
+		Timestamp:  data.Timestamp,
+		Hostname:   s.Hostname,
+		Addr:       s.IP.String(),
+		Port:       s.Port,
+		Statistics: &data,
+	})
+}
+
+// send delivers the payload and reports the failure on stderr,
+// so that it doesn't interfere with the printer's output.
+func (n *webhookNotifier) send(payload webhookPayload) {
+	if err := postJSON(n.client, n.url, payload, nil); err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to deliver the %q event to the webhook: %s\n", payload.Event, err)
+	}
+}
+
+// postJSON sends the payload to the url as JSON.
+// It retries a few times with an increasing delay
+// if the request fails or the response isn't 2xx.
+func postJSON(client *http.Client, url string, payload any, headers map[string]string) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	return withRetries(func() error {
+		return request(client, http.MethodPost, url, body, headers)
+	})
+}
+
+// withRetries calls do until it succeeds, up to webhookAttempts
+// times, waiting a little longer after every failed attempt.
+func withRetries(do func() error) error {
+	for attempt := 1; ; attempt++ {
+		err := do()
+		if err == nil || attempt == webhookAttempts {
+			return err
+		}
+
+		time.Sleep(time.Duration(attempt) * webhookBackoff)
+	}
+}
+
+// request makes a single request with a body, which is sent
+// as JSON unless the Content-Type is set in the headers.
+func request(client *http.Client, method, url string, body []byte, headers map[string]string) error {
+	req, err := http.NewRequest(method, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "tcping/"+tcping.Version)
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("unexpected response status %q", resp.Status)
+	}
+
+	return nil
+}
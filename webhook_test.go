@@ -0,0 +1,107 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/pouriyajamshidi/tcping/v2/pkg/tcping"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWebhookNotify(t *testing.T) {
+	payloads := make(chan webhookPayload, 1)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var p webhookPayload
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&p))
+		payloads <- p
+	}))
+	t.Cleanup(srv.Close)
+
+	n := newWebhookNotifier(srv.URL, false)
+	n.Notify(tcping.StateChange{
+		When:     time.Now(),
+		Hostname: "example.com",
+		IP:       "93.184.216.34",
+		Port:     443,
+		Downtime: 90 * time.Second,
+		RTT:      12.5,
+		Up:       true,
+	})
+
+	got := <-payloads
+	assert.Equal(t, "up", got.Event)
+	assert.Equal(t, "example.com", got.Hostname)
+	assert.Equal(t, "93.184.216.34", got.Addr)
+	assert.Equal(t, uint16(443), got.Port)
+	assert.Equal(t, float64(90), got.Downtime)
+	assert.Equal(t, float32(12.5), got.Rtt)
+	assert.Nil(t, got.Statistics)
+}
+
+func TestStateChangeEvent(t *testing.T) {
+	tests := []struct {
+		change tcping.StateChange
+		want   string
+	}{
+		{tcping.StateChange{}, "down"},
+		{tcping.StateChange{Up: true}, "up"},
+		{tcping.StateChange{Degraded: true}, "degraded"},
+		{tcping.StateChange{Up: true, Degraded: true}, "degraded-end"},
+		{tcping.StateChange{Trend: true}, "trend"},
+		{tcping.StateChange{Up: true, Trend: true}, "trend-end"},
+		{tcping.StateChange{Outlier: true}, "outlier"},
+		{tcping.StateChange{Up: true, Outlier: true}, "outlier-end"},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, stateChangeEvent(tt.change))
+	}
+}
+
+func TestWebhookNotifyStatistics(t *testing.T) {
+	payloads := make(chan webhookPayload, 1)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var p webhookPayload
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&p))
+		payloads <- p
+	}))
+	t.Cleanup(srv.Close)
+
+	s := tcping.Statistics{
+		Hostname:                "example.com",
+		IP:                      netip.MustParseAddr("93.184.216.34"),
+		Port:                    443,
+		TotalSuccessfulProbes:   3,
+		TotalUnsuccessfulProbes: 1,
+	}
+
+	newWebhookNotifier(srv.URL, true).NotifyStatistics(s)
+
+	got := <-payloads
+	assert.Equal(t, "statistics", got.Event)
+	if assert.NotNil(t, got.Statistics) {
+		assert.Equal(t, tcping.JSONEventType("statistics"), got.Statistics.Type)
+		assert.Equal(t, uint(4), got.Statistics.TotalPackets)
+		assert.Equal(t, "25.00", got.Statistics.TotalPacketLoss)
+	}
+}
+
+func TestPostJSONRetries(t *testing.T) {
+	var requests atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if requests.Add(1) == 1 {
+			w.WriteHeader(http.StatusBadGateway)
+		}
+	}))
+	t.Cleanup(srv.Close)
+
+	err := postJSON(srv.Client(), srv.URL, webhookPayload{Event: "down"}, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, int32(2), requests.Load())
+}